@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs" // Required for errors.Is(walkErr, fs.ErrPermission)
@@ -9,14 +10,18 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
+	"mimir/internal/fileingest"
 	"mimir/internal/services"
 )
 
 var (
-	addTitle  string
-	addSource string
+	addTitle       string
+	addSource      string
+	addFromFile    string
+	addConcurrency int
 	// addInput is removed as we use positional arg now
 )
 
@@ -25,9 +30,28 @@ var addCmd = &cobra.Command{
 	Short: "Add new content to Mimir",
 	Long: `Adds new content from a file path, URL, or raw text string provided as an argument.
 If --title is not provided, it defaults to the base name of the input file path.
+URLs and raw text are left untitled unless content.auto_title is set.
 If --source is not provided, it defaults to 'local'.
-The input will be processed, stored, and an embedding job will be queued.`,
-	Args: cobra.ExactArgs(1), // Exactly one positional argument is required
+The input will be processed, stored, and an embedding job will be queued.
+Directories of at least embedding.batch_job.min_items Markdown files are embedded
+by batch embedding jobs instead of one job per file.
+
+An input of '-' reads the body from stdin, for use in pipelines
+(cat notes.md | mimir add - --title Notes). --title and --source still apply, and
+--content-type sets the body's type (defaults to text/plain).
+
+With --from-file, each non-blank line of the given file (lines starting with '#'
+are comments) is added as a separate item using the same --source.
+
+With --jsonl (implied for .jsonl and .ndjson files), each line of the file is a
+JSON record added as a separate item: --text-field becomes the body, --title-field
+the title, --tags-field the tags, and all other fields the content metadata.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if addFromFile != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args) // Exactly one positional argument is required
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		appInstance, err := GetAppFromContext(cmd.Context())
 		if err != nil {
@@ -38,9 +62,24 @@ The input will be processed, stored, and an embedding job will be queued.`,
 			return fmt.Errorf("content service is not initialized in the application")
 		}
 
+		// --- List File Mode ---
+		if addFromFile != "" {
+			return addFromListFile(cmd.Context(), appInstance.ContentService, addFromFile)
+		}
+
 		// Get input from the positional argument
 		rawInput := args[0]
 
+		// --- Stdin Mode ---
+		if rawInput == stdinInput {
+			return addFromStdin(cmd.Context(), appInstance.ContentService)
+		}
+
+		// --- JSONL Mode ---
+		if addJSONL || fileingest.IsJSONLPath(rawInput) {
+			return addFromJSONLFile(cmd.Context(), appInstance.ContentService, rawInput)
+		}
+
 		// Determine absolute path for consistent handling and directory checking
 		absInput, err := filepath.Abs(rawInput)
 		if err != nil {
@@ -68,6 +107,9 @@ The input will be processed, stored, and an embedding job will be queued.`,
 				log.Printf("Using provided source name '%s' for directory add.", dirSource)
 			}
 
+			// Large directories are embedded in batch jobs instead of one job per file
+			embeddings := appInstance.ContentService.NewEmbeddingBatcher()
+
 			walkErr := filepath.WalkDir(absInput, func(path string, d os.DirEntry, walkErr error) error {
 				if walkErr != nil {
 					// Error accessing path (e.g., permissions)
@@ -114,10 +156,11 @@ The input will be processed, stored, and an embedding job will be queued.`,
 				title := strings.TrimSuffix(d.Name(), filepath.Ext(d.Name()))
 				// Use the determined directory source name
 				params := services.AddContentParams{
-					SourceName: dirSource,
-					Title:      title,
-					RawInput:   path, // Use the full, absolute path to the file
-					SourceType: "cli-directory",
+					SourceName:     dirSource,
+					Title:          title,
+					RawInput:       path, // Use the full, absolute path to the file
+					SourceType:     "cli-directory",
+					DeferEmbedding: true,
 				}
 
 				log.Printf("Adding file: Title='%s', Source='%s', Input='%s'", params.Title, params.SourceName, params.RawInput)
@@ -132,10 +175,18 @@ The input will be processed, stored, and an embedding job will be queued.`,
 				} else {
 					fmt.Printf("  - Added: %s (ID: %d)\n", path, content.ID)
 					filesAdded++
+					if err := embeddings.Add(cmd.Context(), content.ID); err != nil {
+						fmt.Printf("  - ERROR enqueueing embedding: %v\n", err)
+					}
 				}
 				return nil // Continue with the next file
 			}) // End WalkDir
 
+			// Content added before a walk error still needs its embedding enqueued
+			if err := embeddings.Flush(cmd.Context()); err != nil {
+				fmt.Printf("Error enqueueing embedding jobs: %v\n", err)
+			}
+
 			if walkErr != nil {
 				// This error is from WalkDir setup itself, not the callback function
 				fmt.Printf("Error walking directory %s: %v\n", absInput, walkErr)
@@ -169,21 +220,7 @@ The input will be processed, stored, and an embedding job will be queued.`,
 		// Set default title from filename if not provided and input looks like a file path
 		title := addTitle
 		if title == "" {
-			// Only default title if it wasn't explicitly set AND it's not clearly a URL
-			_, urlErr := url.ParseRequestURI(rawInput)
-			// Check if it's NOT a URL AND it doesn't contain common characters suggesting raw text (like spaces)
-			// This is heuristic, might need refinement.
-			if urlErr != nil && !strings.ContainsAny(rawInput, " \n\t") {
-				base := filepath.Base(rawInput)
-				title = strings.TrimSuffix(base, filepath.Ext(base))
-				// Handle cases where TrimSuffix leaves an empty string (e.g., ".bashrc")
-				if title == "" && base != "" {
-					title = base
-				}
-				log.Printf("Defaulting title to '%s' based on input.", title)
-			}
-			// If it was a URL or raw text, title might remain empty here, which is acceptable.
-			// ContentService might apply further defaults if needed.
+			title = defaultTitle(rawInput)
 		}
 
 		params := services.AddContentParams{
@@ -210,10 +247,105 @@ The input will be processed, stored, and an embedding job will be queued.`,
 	},
 }
 
+// defaultTitle derives a title from a file path input. URLs and raw text get an
+// empty title, which is acceptable; ContentService might apply further defaults if needed.
+func defaultTitle(rawInput string) string {
+	// Only default title if it's not clearly a URL
+	_, urlErr := url.ParseRequestURI(rawInput)
+	// Check if it's NOT a URL AND it doesn't contain common characters suggesting raw text (like spaces)
+	// This is heuristic, might need refinement.
+	if urlErr != nil && !strings.ContainsAny(rawInput, " \n\t") {
+		base := filepath.Base(rawInput)
+		title := strings.TrimSuffix(base, filepath.Ext(base))
+		// Handle cases where TrimSuffix leaves an empty string (e.g., ".bashrc")
+		if title == "" && base != "" {
+			title = base
+		}
+		log.Printf("Defaulting title to '%s' based on input.", title)
+		return title
+	}
+	return ""
+}
+
+// addFromListFile adds every input listed in listPath, processing up to
+// addConcurrency items at a time and printing a result line per input.
+func addFromListFile(ctx context.Context, contentService *services.ContentService, listPath string) error {
+	inputs, err := fileingest.ReadInputList(listPath)
+	if err != nil {
+		return fmt.Errorf("failed to read list file '%s': %w", listPath, err)
+	}
+	if len(inputs) == 0 {
+		fmt.Printf("No inputs found in %s\n", listPath)
+		return nil
+	}
+
+	source := addSource
+	if source == "" {
+		source = "local"
+	}
+	concurrency := addConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	fmt.Printf("Processing %d input(s) from %s (concurrency %d)\n", len(inputs), listPath, concurrency)
+	var itemsAdded, itemsSkipped, itemsErrored int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+
+	for _, input := range inputs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(input string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			params := services.AddContentParams{
+				SourceName: source,
+				Title:      defaultTitle(input),
+				RawInput:   input,
+				SourceType: "cli-list",
+			}
+			content, existed, addErr := contentService.AddContent(ctx, params)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if addErr != nil {
+				fmt.Printf("  - ERROR adding %s: %v\n", input, addErr)
+				itemsErrored++
+			} else if existed {
+				fmt.Printf("  - Skipped (exists): %s (ID: %d)\n", input, content.ID)
+				itemsSkipped++
+			} else {
+				fmt.Printf("  - Added: %s (ID: %d)\n", input, content.ID)
+				itemsAdded++
+			}
+		}(input)
+	}
+	wg.Wait()
+
+	fmt.Println("------------------------------------")
+	fmt.Printf("List processing complete.\n")
+	fmt.Printf("Inputs Found:  %d\n", len(inputs))
+	fmt.Printf("Items Added:   %d\n", itemsAdded)
+	fmt.Printf("Items Skipped: %d\n", itemsSkipped)
+	fmt.Printf("Errors:        %d\n", itemsErrored)
+	fmt.Println("------------------------------------")
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(addCmd)
 	addCmd.Flags().StringVarP(&addTitle, "title", "t", "", "Optional title (defaults to input filename)")
 	addCmd.Flags().StringVarP(&addSource, "source", "s", "local", "Optional source name (defaults to 'local')")
+	addCmd.Flags().StringVar(&addFromFile, "from-file", "", "Add each input listed in this file (one path or URL per line)")
+	addCmd.Flags().IntVar(&addConcurrency, "concurrency", 4, "Number of inputs processed in parallel with --from-file or --jsonl")
+	addCmd.Flags().BoolVar(&addJSONL, "jsonl", false, "Treat the input as a JSONL file and add each record as separate content")
+	addCmd.Flags().StringVar(&addTextField, "text-field", "text", "JSONL field holding the content body")
+	addCmd.Flags().StringVar(&addTitleField, "title-field", "title", "JSONL field holding the title")
+	addCmd.Flags().StringVar(&addTagsField, "tags-field", "tags", "JSONL field holding tags (array or comma-separated string)")
+	addCmd.Flags().StringVar(&addContentType, "content-type", "", "Content type of the body read from stdin with '-', e.g. text/markdown (defaults to text/plain)")
 	// Remove the --input flag as it's now a positional argument
 	// Remove MarkFlagRequired calls
 }
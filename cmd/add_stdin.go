@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"mimir/internal/services"
+)
+
+// stdinInput is the add input that reads the content body from stdin.
+const stdinInput = "-"
+
+var addContentType string
+
+// stdinIsPiped reports whether stdin is a pipe or file rather than a terminal.
+func stdinIsPiped() bool {
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice == 0
+}
+
+// readStdinBody reads the whole content body from r, rejecting blank input.
+func readStdinBody(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	body := string(data)
+	if strings.TrimSpace(body) == "" {
+		return "", errors.New("no content read from stdin")
+	}
+	return body, nil
+}
+
+// addFromStdin adds the text piped to stdin as a single item, using --title,
+// --source and --content-type.
+func addFromStdin(ctx context.Context, contentService *services.ContentService) error {
+	if !stdinIsPiped() {
+		return errors.New("input '-' reads from stdin, but stdin is a terminal; pipe content in, e.g. 'cat notes.md | mimir add -'")
+	}
+	body, err := readStdinBody(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	source := addSource
+	if source == "" {
+		source = "local"
+	}
+	params := services.AddContentParams{
+		SourceName:  source,
+		Title:       addTitle,
+		RawInput:    stdinInput,
+		SourceType:  "cli-stdin",
+		Body:        body,
+		ContentType: addContentType,
+	}
+
+	content, existed, err := contentService.AddContent(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to add content: %w", err)
+	}
+	if existed {
+		fmt.Printf("Content already exists (ID: %d). Skipped.\n", content.ID)
+	} else {
+		fmt.Printf("Content added (ID: %d). Embedding and other jobs enqueued.\n", content.ID)
+	}
+	return nil
+}
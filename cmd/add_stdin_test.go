@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadStdinBody(t *testing.T) {
+	body, err := readStdinBody(strings.NewReader("# Notes\n\nsome text\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body != "# Notes\n\nsome text\n" {
+		t.Errorf("body = %q, want input unchanged", body)
+	}
+
+	if _, err := readStdinBody(strings.NewReader(" \n\t\n")); err == nil {
+		t.Error("expected an error for blank stdin")
+	}
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// appendCmd represents the append command
+var appendCmd = &cobra.Command{
+	Use:   "append [content_id] [text...]",
+	Short: "Append text to an existing content item",
+	Long: `Appends text to the body of an existing content item on a new line, then
+recalculates its hash and re-embeds it. Useful for journal or log style
+documents that grow over time without creating duplicates.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contentID, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid content ID provided: '%s'. Please provide a number.", args[0])
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.ContentService == nil {
+			return fmt.Errorf("content service is not initialized in the application")
+		}
+
+		content, err := appInstance.ContentService.AppendToContent(cmd.Context(), contentID, strings.Join(args[1:], " "))
+		if err != nil {
+			return fmt.Errorf("failed to append to content ID %d: %w", contentID, err)
+		}
+
+		fmt.Printf("Appended to content %d: %s (%d bytes)\n", content.ID, content.Title, len(content.Body))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(appendCmd)
+}
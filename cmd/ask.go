@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"mimir/internal/clix"
+	"mimir/internal/services"
+)
+
+var askCmd = &cobra.Command{
+	Use:   "ask \"question\"",
+	Short: "Answer a question from your stored content (RAG)",
+	Long: `Retrieves the content most similar to the question, gives its best matching
+chunks to the configured completion provider and prints the generated answer,
+followed by the sources it was given. The answer cites sources by their number;
+cited sources are marked. Requires rag.enabled in the configuration and embedded content.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		question := strings.Join(args, " ")
+
+		pagination, err := clix.ParsePagination(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		filterTags, err := clix.ParseTags(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.RAGService == nil {
+			if !appInstance.Config.RAG.Enabled {
+				return fmt.Errorf("RAG is disabled: set rag.enabled to true in your config file, then try again")
+			}
+			return fmt.Errorf("RAG is enabled but no completion provider is available: check rag.provider and its API key in your config file")
+		}
+
+		answer, err := appInstance.RAGService.Query(cmd.Context(), question, services.RAGParams{
+			Limit:      pagination.Limit,
+			FilterTags: filterTags,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrNoRAGContext):
+				return fmt.Errorf("%w; add content or run 'mimir worker' to embed pending content", err)
+			case errors.Is(err, services.ErrEmbeddingDisabled):
+				return fmt.Errorf("cannot answer questions: %w", err)
+			}
+			return fmt.Errorf("failed to answer question: %w", err)
+		}
+
+		fmt.Println(answer.Answer)
+		fmt.Println()
+		fmt.Println("Sources:")
+		for i, source := range answer.Sources {
+			cited := ""
+			if source.Cited {
+				cited = " (cited)"
+			}
+			fmt.Printf("[%d] ID %d: %s%s\n", i+1, source.ContentID, source.Title, cited)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(askCmd)
+
+	askCmd.Flags().IntP("limit", "n", 5, "Number of content items whose best matching chunks are retrieved as context")
+	askCmd.Flags().StringP("tags", "T", "", "Comma-separated list of tags to restrict retrieval to (match any)")
+}
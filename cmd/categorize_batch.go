@@ -15,7 +15,8 @@ var categorizeBatchCmd = &cobra.Command{
 	Use:   "batch <content_id1> [content_id2...]",
 	Short: "Suggest categories (tags/collection) for multiple content items",
 	Long: `Fetches categorization suggestions (tags and a collection/category) for the specified
-content IDs using the configured provider. Does NOT automatically apply them.`,
+content IDs using the configured provider. Does NOT apply them unless --apply is given;
+applied suggestions respect categorization.min_confidence and categorization.max_tags.`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		appInstance, err := GetAppFromContext(cmd.Context())
@@ -73,11 +74,24 @@ content IDs using the configured provider. Does NOT automatically apply them.`,
 
 		table.Render()
 
+		apply, _ := cmd.Flags().GetBool("apply")
+		if !apply {
+			return nil
+		}
+		applyResult, err := appInstance.CategorizationService.ApplyBatchSuggestions(cmd.Context(), resultsMap, true)
+		if err != nil {
+			return fmt.Errorf("failed to apply categorization suggestions: %w", err)
+		}
+		fmt.Printf("Applied suggestions to %d items; skipped %d below the confidence threshold.\n",
+			len(applyResult.Applied), len(applyResult.SkippedLowConfidence))
+		for id, reason := range applyResult.Failed {
+			fmt.Printf("Failed to apply suggestions to content %d: %s\n", id, reason)
+		}
 		return nil
 	},
 }
 
 func init() {
 	categorizeCmd.AddCommand(categorizeBatchCmd)
-	// Add flags if needed
+	categorizeBatchCmd.Flags().Bool("apply", false, "Apply the suggestions (tags and collection) after showing them")
 }
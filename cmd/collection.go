@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
+	"mimir/internal/store"
 )
 
 var (
@@ -100,6 +102,77 @@ var listCollectionsCmd = &cobra.Command{
 	},
 }
 
+var updateCollectionCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Rename a collection or change its description or pinned status",
+	Long:  `Update a collection. Only the flags given are changed; --description "" clears the description.`,
+	Args:  cobra.NoArgs, // Use flags
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if collectionID <= 0 {
+			return fmt.Errorf("--collection-id flag must be provided and positive")
+		}
+
+		var name, description *string
+		var pinned *bool
+		if cmd.Flags().Changed("name") {
+			name = &collectionName
+		}
+		if cmd.Flags().Changed("description") {
+			description = &collectionDescription
+		}
+		if cmd.Flags().Changed("pinned") {
+			pinned = &collectionIsPinned
+		}
+		if name == nil && description == nil && pinned == nil {
+			return fmt.Errorf("nothing to update: provide --name, --description or --pinned")
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		collection, err := appInstance.CollectionService.UpdateCollection(cmd.Context(), collectionID, name, description, pinned)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return fmt.Errorf("collection with ID %d not found", collectionID)
+			}
+			if errors.Is(err, store.ErrDuplicate) {
+				return fmt.Errorf("a collection named '%s' already exists", collectionName)
+			}
+			return fmt.Errorf("failed updating collection %d: %w", collectionID, err)
+		}
+		fmt.Printf("Successfully updated collection: ID=%d, Name='%s', Pinned=%v\n", collection.ID, collection.Name, collection.IsPinned)
+		return nil
+	},
+}
+
+var deleteCollectionCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete a collection (its content is kept)",
+	Args:  cobra.NoArgs, // Use flags
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if collectionID <= 0 {
+			return fmt.Errorf("--collection-id flag must be provided and positive")
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		removed, err := appInstance.CollectionService.DeleteCollection(cmd.Context(), collectionID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return fmt.Errorf("collection with ID %d not found", collectionID)
+			}
+			return fmt.Errorf("failed deleting collection %d: %w", collectionID, err)
+		}
+		fmt.Printf("Successfully deleted collection %d (removed %d content associations).\n", collectionID, removed)
+		return nil
+	},
+}
+
 var addContentToCollectionCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add content to a collection",
@@ -224,6 +297,15 @@ func init() {
 	createCollectionCmd.Flags().BoolVar(&collectionIsPinned, "pinned", false, "Pin the collection")
 	createCollectionCmd.MarkFlagRequired("name") // Make name mandatory
 
+	// Flags for update command
+	updateCollectionCmd.Flags().Int64VarP(&collectionID, "collection-id", "l", 0, "ID of the collection (required)")
+	updateCollectionCmd.Flags().StringVarP(&collectionName, "name", "n", "", "New name of the collection")
+	updateCollectionCmd.Flags().StringVarP(&collectionDescription, "description", "d", "", "New description (empty clears it)")
+	updateCollectionCmd.Flags().BoolVar(&collectionIsPinned, "pinned", false, "Pin (--pinned) or unpin (--pinned=false) the collection")
+
+	// Flags for delete command
+	deleteCollectionCmd.Flags().Int64VarP(&collectionID, "collection-id", "l", 0, "ID of the collection (required)")
+
 	// Flags for add/remove commands
 	addContentToCollectionCmd.Flags().Int64VarP(&contentID, "content-id", "c", 0, "ID of the content item (required)")
 	addContentToCollectionCmd.Flags().Int64VarP(&collectionID, "collection-id", "l", 0, "ID of the collection (required)")
@@ -247,6 +329,8 @@ func init() {
 	// Add subcommands to collectionCmd
 	collectionCmd.AddCommand(createCollectionCmd)
 	collectionCmd.AddCommand(listCollectionsCmd)
+	collectionCmd.AddCommand(updateCollectionCmd)
+	collectionCmd.AddCommand(deleteCollectionCmd)
 	collectionCmd.AddCommand(addContentToCollectionCmd)
 	collectionCmd.AddCommand(removeContentFromCollectionCmd)
 	collectionCmd.AddCommand(listContentByCollectionCmd)
@@ -257,6 +341,8 @@ func init() {
 	removeContentFromCollectionCmd.MarkFlagRequired("content-id")
 	removeContentFromCollectionCmd.MarkFlagRequired("collection-id")
 	listContentByCollectionCmd.MarkFlagRequired("collection-id")
+	updateCollectionCmd.MarkFlagRequired("collection-id")
+	deleteCollectionCmd.MarkFlagRequired("collection-id")
 
 	// Add the main collection command to the root command
 	rootCmd.AddCommand(collectionCmd)
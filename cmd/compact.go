@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"mimir/internal/services"
+)
+
+var (
+	compactOlderThan string
+	compactPolicy    string
+	compactDryRun    bool
+)
+
+// compactCmd removes embeddings of old, rarely used content from the vector store
+var compactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Archive old content by removing its embeddings",
+	Long: `Removes the vector embeddings of content that has not been used for a while,
+to keep the embeddings table small on large corpora. Archived content stays in the
+primary database and remains available to keyword search; 'mimir reindex' embeds it again.
+
+With --policy last_accessed (default), content not returned by any search within
+--older-than is archived (content never returned counts from its creation time).
+With --policy age, content created more than --older-than ago is archived.
+Pinned content is never archived.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		olderThan, err := parseAge(compactOlderThan)
+		if err != nil {
+			return err
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.CompactionService == nil {
+			return fmt.Errorf("compaction service is not initialized in the application")
+		}
+
+		result, err := appInstance.CompactionService.Compact(cmd.Context(), services.CompactParams{
+			OlderThan: olderThan,
+			Policy:    compactPolicy,
+			DryRun:    compactDryRun,
+		})
+		if err != nil {
+			return fmt.Errorf("compaction failed: %w", err)
+		}
+
+		verb := "Archived"
+		if compactDryRun {
+			verb = "Would archive"
+		}
+		for _, c := range result.Archived {
+			fmt.Printf("%s content %d: %s\n", verb, c.ID, c.Title)
+		}
+		fmt.Printf("%s %d content item(s).\n", verb, len(result.Archived))
+		if len(result.Failed) > 0 {
+			return fmt.Errorf("%d content item(s) could not be archived: %v", len(result.Failed), result.Failed)
+		}
+		return nil
+	},
+}
+
+// parseAge parses a Go duration, or a whole number of days such as "90d".
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid --older-than '%s': expected a positive number of days like 90d", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid --older-than '%s': expected days like 90d or a duration like 720h", s)
+	}
+	return d, nil
+}
+
+func init() {
+	compactCmd.Flags().StringVar(&compactOlderThan, "older-than", "180d", "Archive content unused for this long (e.g. 90d, 720h)")
+	compactCmd.Flags().StringVar(&compactPolicy, "policy", services.CompactPolicyLastAccessed, "What 'unused' means: last_accessed or age")
+	compactCmd.Flags().BoolVar(&compactDryRun, "dry-run", false, "List the content that would be archived without changing anything")
+	rootCmd.AddCommand(compactCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"mimir/internal/config"
+)
+
+// configCmd represents the base command for configuration operations
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the configuration",
+}
+
+var validateConfigCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration without starting anything",
+	Long: `Loads config.yaml (and environment overrides) and checks it without connecting
+to the databases, Redis or any provider: value ranges, required DSNs, API keys
+for the providers in use, provider names, and that prompt files exist.
+
+Prints one PASS, WARN or FAIL line per check and exits non-zero if any check fails.`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{skipAppInitAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if path := config.FileUsed(); path != "" {
+			fmt.Printf("Config file: %s\n\n", path)
+		} else {
+			fmt.Print("Config file: none found, using defaults and environment variables\n\n")
+		}
+
+		results := cfg.Check()
+		var failed, warned int
+		for _, r := range results {
+			fmt.Printf("  %-4s  %-32s %s\n", r.Status, r.Name, r.Message)
+			switch r.Status {
+			case config.CheckFail:
+				failed++
+			case config.CheckWarn:
+				warned++
+			}
+		}
+		fmt.Println()
+
+		if failed > 0 {
+			fmt.Printf("Configuration is invalid: %d failed, %d warning(s).\n", failed, warned)
+			cmd.SilenceUsage = true
+			return errors.New("configuration check failed")
+		}
+		fmt.Printf("Configuration is valid (%d warning(s)).\n", warned)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(validateConfigCmd)
+}
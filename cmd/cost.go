@@ -1,18 +1,31 @@
 package cmd
 
 import (
+	"encoding/csv"
 	"fmt"
 	"os"
 	"strconv"
 	"text/tabwriter" // For aligned output
+	"time"
 
 	"github.com/spf13/cobra"
 	"mimir/internal/clix" // Use clix for pagination
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
 )
 
 var (
 	costListLimit  int
 	costListOffset int
+
+	costExportFrom    string
+	costExportTo      string
+	costExportFormat  string
+	costExportGroupBy string
+
+	costSummaryFrom string
+	costSummaryTo   string
 )
 
 // costCmd represents the base command for cost operations.
@@ -93,8 +106,19 @@ var costListCmd = &cobra.Command{
 var costSummaryCmd = &cobra.Command{
 	Use:   "summary",
 	Short: "Show summary of total AI costs and token usage",
-	Long:  `Calculates and displays the total cost, total input tokens, and total output tokens across all recorded AI usage.`,
+	Long: `Calculates and displays the total cost, total input tokens, and total output tokens across all recorded AI usage,
+followed by a breakdown per service type (embedding, categorization, summarization, RAG completion, ...).
+--from and --to limit the summary to a date range, as for 'cost export'.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		var r store.UsageRange
+		var err error
+		if r.From, err = services.ParseUsageTime(costSummaryFrom, false); err != nil {
+			return fmt.Errorf("invalid --from: %w", err)
+		}
+		if r.To, err = services.ParseUsageTime(costSummaryTo, true); err != nil {
+			return fmt.Errorf("invalid --to: %w", err)
+		}
+
 		appInstance, err := GetAppFromContext(cmd.Context())
 		if err != nil {
 			return err
@@ -104,26 +128,189 @@ var costSummaryCmd = &cobra.Command{
 			return fmt.Errorf("cost service is not initialized")
 		}
 
-		totalCost, totalInput, totalOutput, err := appInstance.CostService.GetSummary(cmd.Context())
+		summary, err := appInstance.CostService.GetSummaryByService(cmd.Context(), r)
 		if err != nil {
 			return fmt.Errorf("failed to get cost summary: %w", err)
 		}
 
 		fmt.Println("AI Usage Cost Summary:")
 		fmt.Println("----------------------")
-		fmt.Printf("Total Cost:        $%.6f\n", totalCost)
-		fmt.Printf("Total Input Tokens: %d\n", totalInput)
-		fmt.Printf("Total Output Tokens:%d\n", totalOutput)
+		fmt.Printf("Total Cost:        $%.6f\n", summary.TotalCost)
+		fmt.Printf("Total Input Tokens: %d\n", summary.TotalInputTokens)
+		fmt.Printf("Total Output Tokens:%d\n", summary.TotalOutputTokens)
 		fmt.Println("----------------------")
 
+		if len(summary.ByService) > 0 {
+			fmt.Println()
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "Service\tCalls\tIn Tokens\tOut Tokens\tCost\tShare")
+			fmt.Fprintln(w, "-------\t-----\t---------\t----------\t----\t-----")
+			for _, u := range summary.ByService {
+				share := 0.0
+				if summary.TotalCost > 0 {
+					share = u.Cost / summary.TotalCost * 100
+				}
+				fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.6f\t%.1f%%\n", u.ServiceType, u.Calls, u.InputTokens, u.OutputTokens, u.Cost, share)
+			}
+			w.Flush()
+		}
+
+		return nil
+	},
+}
+
+// costOwnersCmd represents the command to view cost per owner.
+var costOwnersCmd = &cobra.Command{
+	Use:   "owners",
+	Short: "Show AI costs and token usage per owner",
+	Long: `Displays the cost and token usage billed to each owner, highest cost first, for billing
+tenants of a multi_tenant deployment. Calls made for a content item are billed to its owner.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if appInstance.CostService == nil {
+			return fmt.Errorf("cost service is not initialized")
+		}
+
+		usage, err := appInstance.CostService.GetUsageByOwner(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get cost per owner: %w", err)
+		}
+
+		if len(usage) == 0 {
+			fmt.Println("No cost logs found.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Owner\tCalls\tIn Tokens\tOut Tokens\tCost")
+		fmt.Fprintln(w, "-----\t-----\t---------\t----------\t----")
+		for _, u := range usage {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.6f\n", u.OwnerID, u.Calls, u.InputTokens, u.OutputTokens, u.Cost)
+		}
+		w.Flush()
 		return nil
 	},
 }
 
+// costExportCmd represents the command to export cost data for accounting.
+var costExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export AI usage logs or grouped totals as CSV",
+	Long: `Writes the AI usage logs in a date range to stdout as CSV, one row per call with its
+timestamp, provider, service type, model, tokens and cost, oldest first. With --group-by day,
+model or day,model it writes one row of totals per group instead.
+
+--from and --to take a date (2006-01-02) or an RFC 3339 timestamp; a --to date includes that
+whole day. Example: mimir cost export --from 2026-01-01 --to 2026-03-31 --group-by day > q1.csv`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if costExportFormat != "csv" {
+			return fmt.Errorf("unsupported --format '%s': only csv is supported", costExportFormat)
+		}
+		byDay, byModel, err := services.ParseUsageGroupBy(costExportGroupBy)
+		if err != nil {
+			return err
+		}
+		var r store.UsageRange
+		if r.From, err = services.ParseUsageTime(costExportFrom, false); err != nil {
+			return fmt.Errorf("invalid --from: %w", err)
+		}
+		if r.To, err = services.ParseUsageTime(costExportTo, true); err != nil {
+			return fmt.Errorf("invalid --to: %w", err)
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.CostService == nil {
+			return fmt.Errorf("cost service is not initialized")
+		}
+
+		w := csv.NewWriter(cmd.OutOrStdout())
+		if byDay || byModel {
+			groups, err := appInstance.CostService.GetUsageGroups(cmd.Context(), r, byDay, byModel)
+			if err != nil {
+				return err
+			}
+			if err := writeUsageGroupsCSV(w, groups, byDay, byModel); err != nil {
+				return err
+			}
+		} else {
+			if err := w.Write([]string{"timestamp", "provider", "service_type", "model", "input_tokens", "output_tokens", "cost"}); err != nil {
+				return err
+			}
+			err := appInstance.CostService.ExportUsage(cmd.Context(), r, func(log *models.AIUsageLog) error {
+				return w.Write([]string{
+					log.Timestamp.Format(time.RFC3339),
+					log.ProviderName,
+					log.ServiceType,
+					log.ModelName,
+					strconv.Itoa(log.InputTokens),
+					strconv.Itoa(log.OutputTokens),
+					strconv.FormatFloat(log.Cost, 'f', -1, 64),
+				})
+			})
+			if err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		return w.Error()
+	},
+}
+
+// writeUsageGroupsCSV writes grouped usage totals as CSV, with a day column
+// and provider and model columns only for the groupings in use.
+func writeUsageGroupsCSV(w *csv.Writer, groups []store.UsageGroup, byDay, byModel bool) error {
+	var header []string
+	if byDay {
+		header = append(header, "day")
+	}
+	if byModel {
+		header = append(header, "provider", "model")
+	}
+	if err := w.Write(append(header, "calls", "input_tokens", "output_tokens", "cost")); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		var row []string
+		if byDay {
+			row = append(row, g.Day.Format("2006-01-02"))
+		}
+		if byModel {
+			row = append(row, g.ProviderName, g.ModelName)
+		}
+		row = append(row,
+			strconv.FormatInt(g.Calls, 10),
+			strconv.FormatInt(g.InputTokens, 10),
+			strconv.FormatInt(g.OutputTokens, 10),
+			strconv.FormatFloat(g.Cost, 'f', -1, 64),
+		)
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func init() {
 	// Add subcommands to the base cost command
 	costCmd.AddCommand(costListCmd)
 	costCmd.AddCommand(costSummaryCmd)
+	costCmd.AddCommand(costOwnersCmd)
+	costCmd.AddCommand(costExportCmd)
+
+	costSummaryCmd.Flags().StringVar(&costSummaryFrom, "from", "", "Start of the range, inclusive: a date (2006-01-02) or RFC 3339 timestamp")
+	costSummaryCmd.Flags().StringVar(&costSummaryTo, "to", "", "End of the range: a date (included) or RFC 3339 timestamp (excluded)")
+
+	costExportCmd.Flags().StringVar(&costExportFrom, "from", "", "Start of the range, inclusive: a date (2006-01-02) or RFC 3339 timestamp")
+	costExportCmd.Flags().StringVar(&costExportTo, "to", "", "End of the range: a date (included) or RFC 3339 timestamp (excluded)")
+	costExportCmd.Flags().StringVar(&costExportFormat, "format", "csv", "Output format; only csv is supported")
+	costExportCmd.Flags().StringVar(&costExportGroupBy, "group-by", "", "Write totals per day, model or day,model instead of one row per call")
 
 	// Add flags for the list subcommand using the clix variables
 	costListCmd.Flags().IntVarP(&costListLimit, "limit", "l", 50, "Number of logs to display")
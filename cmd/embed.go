@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+const embedPreviewValues = 5 // Number of vector components printed by 'embed test'
+
+// embedCmd represents the base command for embedding utilities
+var embedCmd = &cobra.Command{
+	Use:   "embed",
+	Short: "Embedding provider utilities",
+}
+
+var embedTestCmd = &cobra.Command{
+	Use:   "test <text>",
+	Short: "Generate an embedding to verify the configured provider",
+	Long: `Embeds the given text with the configured embedding service and prints the provider,
+model, dimension and the first few vector values. Nothing is stored.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.EmbeddingService == nil {
+			return fmt.Errorf("embedding service is not initialized")
+		}
+
+		es := appInstance.EmbeddingService
+		fmt.Printf("Provider:  %s\n", es.Name())
+		fmt.Printf("Model:     %s\n", es.ModelName())
+		fmt.Printf("Dimension: %d\n", es.Dimension())
+
+		vec, err := es.GenerateEmbedding(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf("embedding test failed: %w", err)
+		}
+
+		values := vec.Slice()
+		preview := values
+		if len(preview) > embedPreviewValues {
+			preview = preview[:embedPreviewValues]
+		}
+		fmt.Printf("Returned:  %d values\n", len(values))
+		fmt.Printf("Preview:   %v\n", preview)
+		if len(values) != es.Dimension() {
+			fmt.Printf("WARNING: returned dimension %d does not match configured dimension %d\n", len(values), es.Dimension())
+		}
+		fmt.Println("Embedding provider is working.")
+		return nil
+	},
+}
+
+func init() {
+	embedCmd.AddCommand(embedTestCmd)
+	rootCmd.AddCommand(embedCmd)
+}
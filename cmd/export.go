@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"mimir/internal/services"
+)
+
+var (
+	exportFormat string
+	exportOutput string
+)
+
+// exportCmd groups commands that export stored data
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export stored data",
+}
+
+// exportEmbeddingsCmd streams all embeddings with their chunk text
+var exportEmbeddingsCmd = &cobra.Command{
+	Use:   "embeddings",
+	Short: "Export all embeddings with their chunk text",
+	Long: `Streams every stored embedding as JSON Lines, one object per chunk:
+{"id", "content_id", "chunk_index", "chunk_text", "vector", "metadata", "created_at"}.
+The output is independent of the vector backend, so it can back up embeddings
+or load them into another vector database without re-embedding.`,
+	Example: `  mimir export embeddings > embeddings.jsonl
+  mimir export embeddings --output embeddings.jsonl`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if exportFormat != services.EmbeddingExportFormatJSONL {
+			return fmt.Errorf("unsupported export format %q (supported: %s)", exportFormat, services.EmbeddingExportFormatJSONL)
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.VectorStore == nil {
+			return fmt.Errorf("vector store is not initialized in the application")
+		}
+
+		var out io.Writer = os.Stdout
+		if exportOutput != "" && exportOutput != "-" {
+			f, err := os.Create(exportOutput)
+			if err != nil {
+				return fmt.Errorf("create output file: %w", err)
+			}
+			defer f.Close()
+			out = f
+		}
+		bw := bufio.NewWriter(out)
+
+		count, err := services.ExportEmbeddingsJSONL(cmd.Context(), appInstance.VectorStore, bw)
+		if err != nil {
+			return err
+		}
+		if err := bw.Flush(); err != nil {
+			return fmt.Errorf("write export: %w", err)
+		}
+
+		// Keep stdout clean for the JSONL stream.
+		fmt.Fprintf(os.Stderr, "Exported %d embeddings\n", count)
+		return nil
+	},
+}
+
+func init() {
+	exportEmbeddingsCmd.Flags().StringVar(&exportFormat, "format", services.EmbeddingExportFormatJSONL, "Export format (jsonl)")
+	exportEmbeddingsCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write to this file instead of stdout")
+
+	exportCmd.AddCommand(exportEmbeddingsCmd)
+	rootCmd.AddCommand(exportCmd)
+}
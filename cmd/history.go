@@ -4,13 +4,15 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
 var (
-	historyLimit int
+	historyLimit     int
+	historyOlderThan string
 )
 
 // historyCmd represents the base command for search history operations
@@ -64,14 +66,51 @@ var listHistoryCmd = &cobra.Command{
 	},
 }
 
+var pruneHistoryCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old search history",
+	Long: `Deletes recorded search queries and their results older than --older-than
+(e.g. 90d or 720h), or search.history_retention_days when the flag is omitted.
+History of all owners is pruned. Run it periodically to enforce the retention.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		var olderThan time.Duration
+		switch {
+		case historyOlderThan != "":
+			if olderThan, err = parseAge(historyOlderThan); err != nil {
+				return err
+			}
+		case appInstance.Config.Search.HistoryRetentionDays > 0:
+			olderThan = time.Duration(appInstance.Config.Search.HistoryRetentionDays) * 24 * time.Hour
+		default:
+			return fmt.Errorf("no retention period: pass --older-than or set search.history_retention_days")
+		}
+
+		deleted, err := appInstance.SearchService.PruneSearchHistory(cmd.Context(), olderThan)
+		if err != nil {
+			return fmt.Errorf("error pruning search history: %w", err)
+		}
+		fmt.Printf("Deleted %d search queries older than %s.\n", deleted, olderThan)
+		return nil
+	},
+}
+
 func init() {
 	// Add historyCmd to root command in root.go's init
 
 	// Flags for list command
 	listHistoryCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Maximum number of history entries to show")
 
+	pruneHistoryCmd.Flags().StringVar(&historyOlderThan, "older-than", "", "Delete searches older than this age, e.g. 90d (default: search.history_retention_days)")
+
 	// Add subcommands to historyCmd
 	historyCmd.AddCommand(listHistoryCmd)
+	historyCmd.AddCommand(pruneHistoryCmd)
 
 	// Add historyCmd to the root command
 	rootCmd.AddCommand(historyCmd)
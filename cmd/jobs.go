@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var (
+	jobsDeadLimit  int
+	jobsDeadOffset int
+)
+
+// jobsCmd groups commands that manage background jobs
+var jobsCmd = &cobra.Command{
+	Use:   "jobs",
+	Short: "Manage background jobs",
+}
+
+// jobsDeadCmd lists jobs that exhausted their retries
+var jobsDeadCmd = &cobra.Command{
+	Use:   "dead",
+	Short: "List jobs that exhausted their retries",
+	Long: `Lists background jobs that failed worker.max_retry times (or failed without
+being retryable) and were moved to the dead queue, with the error of their last attempt.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.DeadLetterService == nil {
+			return fmt.Errorf("dead letter service is not initialized")
+		}
+
+		jobs, err := appInstance.DeadLetterService.ListDeadJobs(cmd.Context(), jobsDeadLimit, jobsDeadOffset)
+		if err != nil {
+			return fmt.Errorf("failed to list dead jobs: %w", err)
+		}
+		if len(jobs) == 0 {
+			fmt.Println("No dead jobs found.")
+			return nil
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"ID", "Task Type", "Queue", "Related", "Last Error", "Failed At"})
+		table.SetBorder(true)
+		table.SetRowLine(true)
+		for _, job := range jobs {
+			related := "N/A"
+			if job.RelatedEntityType != nil && job.RelatedEntityID != nil {
+				related = fmt.Sprintf("%s %d", *job.RelatedEntityType, *job.RelatedEntityID)
+			}
+			table.Append([]string{
+				strconv.FormatInt(job.ID, 10),
+				job.TaskType,
+				job.Queue,
+				related,
+				getStringPtrValue(job.LastError, "N/A"),
+				job.UpdatedAt.Format(time.RFC3339),
+			})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+// jobsRetryCmd requeues a dead job
+var jobsRetryCmd = &cobra.Command{
+	Use:   "retry <id>",
+	Short: "Requeue a dead job",
+	Long: `Requeues the dead job with the given ID (as shown by "mimir jobs dead") as a new
+task with the same type, payload and queue. Run it after fixing the cause of the failure.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid job ID %q: %w", args[0], err)
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.DeadLetterService == nil {
+			return fmt.Errorf("dead letter service is not initialized")
+		}
+
+		info, err := appInstance.DeadLetterService.RetryDeadJob(cmd.Context(), id)
+		if err != nil {
+			return fmt.Errorf("failed to retry job %d: %w", id, err)
+		}
+		fmt.Printf("Requeued job %d as task %s on queue %s\n", id, info.ID, info.Queue)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(jobsCmd)
+	jobsCmd.AddCommand(jobsDeadCmd)
+	jobsCmd.AddCommand(jobsRetryCmd)
+
+	jobsDeadCmd.Flags().IntVarP(&jobsDeadLimit, "limit", "n", 20, "Maximum number of dead jobs to list")
+	jobsDeadCmd.Flags().IntVarP(&jobsDeadOffset, "offset", "o", 0, "Number of dead jobs to skip")
+}
@@ -12,7 +12,10 @@ import (
 )
 
 var (
-	keywordTags string // New flag for tags
+	keywordTags   string // New flag for tags
+	keywordSortBy string
+	keywordLimit  int
+	keywordOffset int
 )
 
 var keywordCmd = &cobra.Command{
@@ -49,8 +52,9 @@ Provide the search terms as arguments.`,
 		params := services.KeywordSearchParams{
 			Query:      query,
 			FilterTags: filterTags,
-			Limit:      0, // Limit/Offset not implemented in command flags yet
-			Offset:     0,
+			Limit:      keywordLimit,
+			Offset:     keywordOffset,
+			SortBy:     keywordSortBy,
 		}
 		results, err := appInstance.SearchService.KeywordSearch(cmd.Context(), params)
 		if err != nil {
@@ -70,7 +74,7 @@ Provide the search terms as arguments.`,
 				log.Println("WARN: Skipping nil content in keyword search results")
 				continue
 			}
-			fmt.Printf("ID: %d\nTitle: %s\n", item.Content.ID, item.Content.Title)
+			fmt.Printf("Score: %.4f\nID: %d\nTitle: %s\n", item.Score, item.Content.ID, item.Content.Title)
 
 			// Print a snippet of the body
 			snippet := item.Content.Body
@@ -92,5 +96,7 @@ func init() {
 	rootCmd.AddCommand(keywordCmd)
 	// Add flags
 	keywordCmd.Flags().StringVarP(&keywordTags, "tags", "T", "", "Comma-separated list of tags to filter by (match any)")
-	// keywordCmd.Flags().IntP("limit", "l", 50, "Limit the number of search results") // Limit is currently hardcoded in store
+	keywordCmd.Flags().StringVar(&keywordSortBy, "sort-by", "relevance", "Order results by relevance, created_at or modified_at")
+	keywordCmd.Flags().IntVarP(&keywordLimit, "limit", "l", 0, "Maximum number of results (0 for all)")
+	keywordCmd.Flags().IntVar(&keywordOffset, "offset", 0, "Number of results to skip, for paging")
 }
@@ -16,6 +16,7 @@ var (
 	listSortBy    string
 	listSortOrder string
 	listTags      string // New flag for tags
+	listPinned    bool
 )
 
 // listCmd represents the list command
@@ -56,6 +57,9 @@ Supports pagination, sorting, and filtering by tags.`,
 			SortOrder:  listSortOrder,
 			FilterTags: filterTags,
 		}
+		if cmd.Flags().Changed("pinned") {
+			params.Pinned = &listPinned
+		}
 		results, err := appInstance.ContentService.ListContent(cmd.Context(), params)
 		if err != nil {
 			return fmt.Errorf("failed to list content: %w", err)
@@ -70,8 +74,12 @@ Supports pagination, sorting, and filtering by tags.`,
 		fmt.Println("Stored Content:")
 		fmt.Println("---------------")
 		for _, item := range results {
-			fmt.Printf("ID: %d\nTitle: %s\nCreated: %s\n",
-				item.Content.ID, item.Content.Title, item.Content.CreatedAt.Format("2006-01-02 15:04:05"))
+			pinMarker := ""
+			if item.Content.IsPinned {
+				pinMarker = " [pinned]"
+			}
+			fmt.Printf("ID: %d%s\nTitle: %s\nCreated: %s\n",
+				item.Content.ID, pinMarker, item.Content.Title, item.Content.CreatedAt.Format("2006-01-02 15:04:05"))
 
 			// Always display ModifiedAt, even if nil (show "N/A")
 			if item.Content.ModifiedAt != nil {
@@ -119,4 +127,5 @@ func init() {
 	listCmd.Flags().StringVar(&listSortBy, "sort-by", "c.created_at", "Column to sort by (c.id, c.title, c.created_at, c.updated_at)") // Prefix with 'c.'
 	listCmd.Flags().StringVar(&listSortOrder, "sort-order", "desc", "Sort order (asc, desc)")
 	listCmd.Flags().StringVarP(&listTags, "tags", "T", "", "Comma-separated list of tags to filter by (match any)")
+	listCmd.Flags().BoolVar(&listPinned, "pinned", false, "Only list pinned content (--pinned=false lists unpinned content)")
 }
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	mergeIDs   []int64
+	mergeTitle string
+)
+
+// mergeCmd represents the merge command
+var mergeCmd = &cobra.Command{
+	Use:   "merge --ids 1,2,3 [--title title]",
+	Short: "Merge several content items into one",
+	Long: `Combines several content items into a new one, joining their bodies in the
+given order with "---" separators. The new item gets the union of their tags
+and collections and is embedded; the originals and their embeddings are
+deleted. Without --title the first item's title is used.
+
+Merged content can be split again with: mimir split <id> --by delimiter --delimiter "---"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(mergeIDs) < 2 {
+			return fmt.Errorf("--ids must list at least two content IDs")
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.ContentService == nil {
+			return fmt.Errorf("content service is not initialized in the application")
+		}
+
+		merged, err := appInstance.ContentService.MergeContent(cmd.Context(), mergeIDs, mergeTitle, appInstance.VectorStore)
+		if err != nil {
+			return fmt.Errorf("failed to merge content %v: %w", mergeIDs, err)
+		}
+
+		fmt.Printf("Merged content %v into %d: %s (%d bytes)\n", mergeIDs, merged.ID, merged.Title, len(merged.Body))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+
+	mergeCmd.Flags().Int64SliceVar(&mergeIDs, "ids", nil, "Comma-separated IDs of the content to merge, in body order")
+	mergeCmd.Flags().StringVar(&mergeTitle, "title", "", "Title of the merged content (default: the first item's title)")
+}
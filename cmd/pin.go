@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var pinUnpin bool
+
+// pinCmd represents the pin command
+var pinCmd = &cobra.Command{
+	Use:   "pin [content_id]",
+	Short: "Pin or unpin a content item",
+	Long: `Marks a content item as pinned so it can be filtered with 'mimir list --pinned'.
+Use --unpin to clear the flag.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contentID, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid content ID provided: '%s'. Please provide a number.", args[0])
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.ContentService == nil {
+			return fmt.Errorf("content service is not initialized in the application")
+		}
+
+		content, err := appInstance.ContentService.PinContent(cmd.Context(), contentID, !pinUnpin)
+		if err != nil {
+			return fmt.Errorf("failed to update pin for content ID %d: %w", contentID, err)
+		}
+
+		if content.IsPinned {
+			fmt.Printf("Pinned content %d: %s\n", content.ID, content.Title)
+		} else {
+			fmt.Printf("Unpinned content %d: %s\n", content.ID, content.Title)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pinCmd)
+	pinCmd.Flags().BoolVar(&pinUnpin, "unpin", false, "Clear the pinned flag instead of setting it")
+}
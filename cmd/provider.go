@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"mimir/internal/services"
+)
+
+// providerCmd represents the base command for embedding provider inspection
+var providerCmd = &cobra.Command{
+	Use:   "provider",
+	Short: "Inspect embedding providers",
+}
+
+var providerStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the status of each configured embedding provider",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		fallback, ok := appInstance.EmbeddingService.(*services.FallbackEmbeddingService)
+		if !ok {
+			if appInstance.EmbeddingService == nil {
+				return fmt.Errorf("embedding service is not initialized")
+			}
+			es := appInstance.EmbeddingService
+			fmt.Printf("%s (%s): %s\n", es.Name(), es.ModelName(), es.Status())
+			return nil
+		}
+
+		infos := fallback.ProviderInfos()
+		if len(infos) == 0 {
+			fmt.Println("No embedding providers configured.")
+			return nil
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Provider", "Model", "Dimension", "Status", "Breaker", "Active"})
+		table.SetBorder(false)
+		table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+		for _, info := range infos {
+			active := ""
+			if info.Active {
+				active = "*"
+			}
+			table.Append([]string{
+				info.Name,
+				info.Model,
+				strconv.Itoa(info.Dimension),
+				info.Status.String(),
+				string(info.Breaker),
+				active,
+			})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+func init() {
+	providerCmd.AddCommand(providerStatusCmd)
+	rootCmd.AddCommand(providerCmd)
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"mimir/internal/services"
+)
+
+var rehashAll bool
+
+// rehashCmd recomputes content dedup hashes under the current hashing rules
+var rehashCmd = &cobra.Command{
+	Use:   "rehash [content_id...]",
+	Short: "Recompute content dedup hashes",
+	Long: `Recomputes the content_hash used to detect duplicate content from each body,
+using the current hashing rules. Run it after the hashing rules change so existing
+content is deduplicated against new content correctly.
+
+Content whose new hash already belongs to other content keeps its old hash and is
+listed as a duplicate.`,
+	Example: `  mimir rehash --all
+  mimir rehash 12 15`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if rehashAll == (len(args) > 0) {
+			return fmt.Errorf("specify content IDs or --all")
+		}
+		ids := make([]int64, len(args))
+		for i, arg := range args {
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid content ID %q: %w", arg, err)
+			}
+			ids[i] = id
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.ContentService == nil {
+			return fmt.Errorf("content service is not initialized in the application")
+		}
+
+		var result *services.RehashResult
+		if rehashAll {
+			result, err = appInstance.ContentService.RehashAllContent(cmd.Context())
+		} else {
+			result, err = appInstance.ContentService.RehashContent(cmd.Context(), ids)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to rehash content: %w", err)
+		}
+
+		fmt.Printf("Checked %d content items, %d hashes changed.\n", result.Checked, result.Changed)
+		if len(result.Duplicates) > 0 {
+			fmt.Printf("Kept old hash for %d items that now duplicate other content: %v\n", len(result.Duplicates), result.Duplicates)
+		}
+		if len(result.NotFound) > 0 {
+			fmt.Printf("Not found: %v\n", result.NotFound)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rehashCmd)
+	rehashCmd.Flags().BoolVar(&rehashAll, "all", false, "Rehash all content")
+}
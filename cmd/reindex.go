@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"mimir/internal/store"
+)
+
+var reindexStale bool
+
+// reindexCmd re-embeds all content and reports a run ID for progress tracking
+var reindexCmd = &cobra.Command{
+	Use:   "reindex",
+	Short: "Re-embed all content",
+	Long: `Enqueues an embedding job for every content item, e.g. after changing the embedding model.
+With --stale, only content whose body changed since it was embedded, or that was
+embedded from a previous embedding.input_template, is re-embedded (see 'mimir status stale').
+Progress is tracked in a reindex run; use 'mimir reindex status <run-id>' to follow it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		run, err := appInstance.ReindexService.StartReindex(cmd.Context(), reindexStale)
+		if err != nil {
+			return fmt.Errorf("error starting reindex: %w", err)
+		}
+
+		fmt.Printf("Started reindex run %d: %d item(s) enqueued.\n", run.ID, run.Total-run.Failed)
+		if run.Failed > 0 {
+			fmt.Printf("%d item(s) could not be enqueued.\n", run.Failed)
+		}
+		fmt.Printf("Check progress with: mimir reindex status %d\n", run.ID)
+		return nil
+	},
+}
+
+var reindexStatusCmd = &cobra.Command{
+	Use:   "status <run-id>",
+	Short: "Show progress of a reindex run",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runID, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid run ID '%s': %w", args[0], err)
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		run, err := appInstance.ReindexService.GetRun(cmd.Context(), runID)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return fmt.Errorf("reindex run %d not found", runID)
+			}
+			return fmt.Errorf("error getting reindex run: %w", err)
+		}
+
+		fmt.Printf("Run %d (%s): %d/%d complete, %d failed\n", run.ID, run.Status, run.Completed, run.Total, run.Failed)
+		fmt.Printf("Started: %s, last update: %s\n", run.CreatedAt.Format("2006-01-02 15:04:05"), run.UpdatedAt.Format("2006-01-02 15:04:05"))
+		return nil
+	},
+}
+
+func init() {
+	reindexCmd.Flags().BoolVar(&reindexStale, "stale", false, "Only re-embed content whose body or embedding input template changed since it was embedded")
+	reindexCmd.AddCommand(reindexStatusCmd)
+	rootCmd.AddCommand(reindexCmd)
+}
@@ -1,28 +1,25 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
-	"strings"
 
 	"github.com/spf13/cobra"
-	// "mimir/internal/app" // Removed unused import
-	// "mimir/internal/config" // Removed unused import
+	"mimir/internal/clix"
 	"mimir/internal/services"
+	"mimir/internal/store"
 )
 
-var (
-	relatedLimit int    // Variable to hold the limit flag value
-	relatedTags  string // Flag for filtering related items by tags
-)
+var relatedMinScore float64 // Minimum similarity for a neighbor to count as related
 
 var relatedCmd = &cobra.Command{
 	Use:   "related <content_id>",
 	Short: "Find content semantically similar to an existing item",
 	Long: `Finds content items that are semantically similar to the specified content item,
-based on vector embeddings. Requires the source content item to have been embedded.
-Results are ordered by similarity score.`,
+based on vector embeddings. Requires the source content item to have been embedded,
+so run the worker after adding content. Results are ordered by similarity score.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		contentIDStr := args[0]
@@ -31,22 +28,20 @@ Results are ordered by similarity score.`,
 			return fmt.Errorf("invalid source content ID provided: '%s'. Please provide a number.", contentIDStr)
 		}
 
-		var filterTags []string
-		if relatedTags != "" {
-			filterTags = strings.Split(relatedTags, ",")
-			for i := range filterTags {
-				filterTags[i] = strings.TrimSpace(filterTags[i])
-			}
-			var cleaned []string
-			for _, t := range filterTags {
-				if t != "" {
-					cleaned = append(cleaned, t)
-				}
-			}
-			filterTags = cleaned
+		if relatedMinScore < 0 || relatedMinScore > 1 {
+			return fmt.Errorf("--min-score must be between 0 and 1, got %g", relatedMinScore)
+		}
+
+		pagination, err := clix.ParsePagination(cmd.Flags())
+		if err != nil {
+			return err
+		}
+		filterTags, err := clix.ParseTags(cmd.Flags())
+		if err != nil {
+			return err
 		}
 
-		log.Printf("Finding content related to ID: %d (limit: %d, tags: %v)", sourceContentID, relatedLimit, filterTags)
+		log.Printf("Finding content related to ID: %d (limit: %d, tags: %v)", sourceContentID, pagination.Limit, filterTags)
 
 		// Retrieve the application instance from context
 		appInstance, err := GetAppFromContext(cmd.Context())
@@ -56,12 +51,19 @@ Results are ordered by similarity score.`,
 
 		params := services.RelatedContentParams{
 			SourceContentID: sourceContentID,
-			Limit:           relatedLimit,
+			Limit:           pagination.Limit,
 			FilterTags:      filterTags,
+			MinScore:        relatedMinScore,
 		}
 
 		results, err := appInstance.SearchService.FindRelatedContent(cmd.Context(), params)
 		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return fmt.Errorf("content %d not found", sourceContentID)
+			}
+			if errors.Is(err, services.ErrNotEmbedded) {
+				return fmt.Errorf("content %d has not been embedded yet; run 'mimir worker' to process pending embedding jobs, then try again", sourceContentID)
+			}
 			log.Printf("Error finding related content: %v", err)
 			return fmt.Errorf("failed to find content related to ID %d: %w", sourceContentID, err)
 		}
@@ -71,26 +73,7 @@ Results are ordered by similarity score.`,
 			return nil
 		}
 
-		fmt.Printf("Content Related to ID %d:\n", sourceContentID)
-		fmt.Println("---------------------------")
-		for _, item := range results {
-			fmt.Printf("Score: %.4f\nID:    %d\nTitle: %s\n", item.Score, item.Content.ID, item.Content.Title)
-
-			snippet := item.Content.Body
-			maxLen := 200
-			if len(snippet) > maxLen {
-				lastSpace := strings.LastIndex(snippet[:maxLen], " ")
-				if lastSpace > 0 {
-					snippet = snippet[:lastSpace] + "..."
-				} else {
-					snippet = snippet[:maxLen] + "..."
-				}
-			}
-			snippet = strings.ReplaceAll(snippet, "\n", " ")
-			fmt.Printf("Snippet: %s\n---\n", snippet)
-		}
-		fmt.Println("---------------------------")
-
+		printSemanticResults(fmt.Sprintf("Content Related to ID %d:", sourceContentID), results)
 		return nil
 	},
 }
@@ -99,6 +82,7 @@ func init() {
 	rootCmd.AddCommand(relatedCmd)
 
 	// Add flags
-	relatedCmd.Flags().IntVarP(&relatedLimit, "limit", "n", 10, "Limit the number of related items to find")
-	relatedCmd.Flags().StringVarP(&relatedTags, "tags", "T", "", "Comma-separated list of tags to filter related items by (match any)")
+	relatedCmd.Flags().IntP("limit", "n", 10, "Limit the number of related items to find")
+	relatedCmd.Flags().StringP("tags", "T", "", "Comma-separated list of tags to filter related items by (match any)")
+	relatedCmd.Flags().Float64Var(&relatedMinScore, "min-score", 0, "Minimum similarity (0-1; 1/(1+distance) for the l2 metric, 1-distance for cosine) for an item to count as related")
 }
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"mimir/internal/services"
+)
+
+var reprocessContentType string
+
+// reprocessCmd re-runs input processing on content from its original file or URL
+var reprocessCmd = &cobra.Command{
+	Use:   "reprocess [content_id...]",
+	Short: "Re-extract content bodies from their original files or URLs",
+	Long: `Reads content again from the file or URL it was added from and re-runs input
+processing, so items stored before an extraction improvement (such as PDF text
+extraction) get the better body. Items whose body changes are re-embedded.
+
+Content added as text, from stdin or by upload has no origin to read again and is
+skipped, as is content whose file or URL can no longer be read.`,
+	Example: `  mimir reprocess --content-type application/pdf
+  mimir reprocess 12 15`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if (reprocessContentType != "") == (len(args) > 0) {
+			return fmt.Errorf("specify content IDs or --content-type")
+		}
+		ids := make([]int64, len(args))
+		for i, arg := range args {
+			id, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid content ID %q: %w", arg, err)
+			}
+			ids[i] = id
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.ContentService == nil {
+			return fmt.Errorf("content service is not initialized in the application")
+		}
+
+		var result *services.ReprocessResult
+		if reprocessContentType != "" {
+			result, err = appInstance.ContentService.ReprocessContentByType(cmd.Context(), reprocessContentType, appInstance.VectorStore)
+		} else {
+			result, err = appInstance.ContentService.ReprocessContent(cmd.Context(), ids, appInstance.VectorStore)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to reprocess content: %w", err)
+		}
+
+		fmt.Printf("Checked %d content items, %d bodies changed and queued for re-embedding.\n", result.Checked, len(result.Updated))
+		if len(result.Updated) > 0 {
+			fmt.Printf("Updated: %v\n", result.Updated)
+		}
+		if len(result.Unreachable) > 0 {
+			fmt.Printf("Original file or URL no longer readable, left unchanged: %v\n", result.Unreachable)
+		}
+		if len(result.NoOrigin) > 0 {
+			fmt.Printf("No original file or URL recorded, skipped: %v\n", result.NoOrigin)
+		}
+		if len(result.Duplicates) > 0 {
+			fmt.Printf("New body duplicates other content, left unchanged: %v\n", result.Duplicates)
+		}
+		if len(result.NotFound) > 0 {
+			fmt.Printf("Not found: %v\n", result.NotFound)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reprocessCmd)
+	reprocessCmd.Flags().StringVar(&reprocessContentType, "content-type", "", "Reprocess all content of this type (e.g. application/pdf)")
+}
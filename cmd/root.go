@@ -25,6 +25,9 @@ var rootCmd = &cobra.Command{
 		if cmd.Name() == "help" || cmd.Name() == "version" { // Add other commands to skip if needed
 			return nil
 		}
+		if cmd.Annotations[skipAppInitAnnotation] == "true" {
+			return nil // The command loads what it needs itself (e.g. config validate)
+		}
 
 		// Load configuration once
 		cfg, err := config.LoadConfig()
@@ -62,7 +65,14 @@ var rootCmd = &cobra.Command{
 func Execute() {
 	// Initialization or setup can happen here before Execute() is called by main()
 
-	if err := rootCmd.Execute(); err != nil {
+	cmd, err := rootCmd.ExecuteC()
+	// Close the app even when the command failed, so buffered usage logs are written
+	if cmd != nil && cmd.Context() != nil {
+		if appInstance, ok := cmd.Context().Value(appKey).(*app.App); ok {
+			appInstance.Close()
+		}
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -73,6 +83,10 @@ type contextKey string
 
 const appKey contextKey = "app"
 
+// skipAppInitAnnotation marks commands that must run without initializing the
+// app, such as checks that should report a broken configuration rather than fail on it.
+const skipAppInitAnnotation = "skip_app_init"
+
 // Helper function to retrieve the app instance from context
 func GetAppFromContext(ctx context.Context) (*app.App, error) {
 	appInstance, ok := ctx.Value(appKey).(*app.App)
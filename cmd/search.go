@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
@@ -11,16 +12,25 @@ import (
 )
 
 var (
-	searchLimit   int
-	searchTags    string
-	searchKeyword bool
+	searchLimit     int
+	searchTags      string
+	searchKeyword   bool
+	searchBoost     string
+	searchExplain   bool
+	searchDiversity float64
+
+	searchHybrid         bool
+	searchKeywordWeight  float64
+	searchSemanticWeight float64
 )
 
 var searchCmd = &cobra.Command{
 	Use:   "search [query...]",
 	Short: "Search content using semantic embeddings or keyword search",
 	Long: `Performs a semantic search using vector embeddings by default.
-Use --keyword to perform a traditional keyword-based search.`,
+Use --keyword to perform a traditional keyword-based search.
+Use --hybrid to run both and fuse their rankings (Reciprocal Rank Fusion);
+--keyword-weight and --semantic-weight override search.hybrid in the config.`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		query := strings.Join(args, " ")
@@ -45,12 +55,28 @@ Use --keyword to perform a traditional keyword-based search.`,
 			return fmt.Errorf("search service is not initialized in the application")
 		}
 
+		if searchKeyword && searchHybrid {
+			return fmt.Errorf("--keyword and --hybrid cannot be used together")
+		}
+
+		if searchHybrid {
+			return runHybridSearch(cmd.Context(), appInstance.SearchService, services.HybridSearchParams{
+				Query:          query,
+				Limit:          pagination.Limit,
+				FilterTags:     filterTags,
+				KeywordWeight:  searchKeywordWeight,
+				SemanticWeight: searchSemanticWeight,
+				Explain:        searchExplain,
+			})
+		}
+
 		if searchKeyword {
 			params := services.KeywordSearchParams{
 				Query:      query,
 				FilterTags: filterTags,
 				Limit:      pagination.Limit,
 				Offset:     0,
+				Explain:    searchExplain,
 			}
 			results, err := appInstance.SearchService.KeywordSearch(cmd.Context(), params)
 			if err != nil {
@@ -86,7 +112,11 @@ Use --keyword to perform a traditional keyword-based search.`,
 					snippet = snippet[:maxSnippetLength] + "..."
 				}
 				snippet = strings.ReplaceAll(snippet, "\n", " ")
-				fmt.Printf("Snippet: %s\n---\n", snippet)
+				fmt.Printf("Snippet: %s\n", snippet)
+				if item.Explanation != nil {
+					fmt.Printf("Matched terms: %s\n", strings.Join(item.Explanation.MatchedTerms, ", "))
+				}
+				fmt.Println("---")
 			}
 			fmt.Println("------------------------")
 			return nil
@@ -96,6 +126,9 @@ Use --keyword to perform a traditional keyword-based search.`,
 			Query:      query,
 			Limit:      pagination.Limit,
 			FilterTags: filterTags,
+			Boost:      searchBoost,
+			Explain:    searchExplain,
+			Diversity:  searchDiversity,
 		}
 		results, err := appInstance.SearchService.SemanticSearch(cmd.Context(), params)
 		if err != nil {
@@ -108,41 +141,94 @@ Use --keyword to perform a traditional keyword-based search.`,
 			return nil
 		}
 
-		fmt.Println("Semantic Search Results:")
-		fmt.Println("------------------------")
-		for _, item := range results {
-			if item.Content == nil {
-				continue
-			}
-			fmt.Printf("Score: %.4f\nID:    %d\nTitle: %s\n", item.Score, item.Content.ID, item.Content.Title)
-			// Always display ModifiedAt, even if nil (show "N/A")
-			if item.Content.ModifiedAt != nil {
-				fmt.Printf("Modified: %s\n", item.Content.ModifiedAt.Format("2006-01-02 15:04:05"))
-			} else {
-				fmt.Printf("Modified: N/A\n")
+		printSemanticResults("Semantic Search Results:", results)
+		return nil
+	},
+}
+
+// printSemanticResults prints semantic search results under title, one block
+// per item with its score, distance, snippet and any matched chunks.
+func printSemanticResults(title string, results []services.SearchResultItem) {
+	fmt.Println(title)
+	fmt.Println("------------------------")
+	for _, item := range results {
+		if item.Content == nil {
+			continue
+		}
+		fmt.Printf("Score: %.4f (distance %.4f)\nID:    %d\nTitle: %s\n", item.Similarity(), item.Score, item.Content.ID, item.Content.Title)
+		// Always display ModifiedAt, even if nil (show "N/A")
+		if item.Content.ModifiedAt != nil {
+			fmt.Printf("Modified: %s\n", item.Content.ModifiedAt.Format("2006-01-02 15:04:05"))
+		} else {
+			fmt.Printf("Modified: N/A\n")
+		}
+		// Display summary if present
+		if item.Content.Summary != nil && *item.Content.Summary != "" {
+			fmt.Printf("Summary: %s\n", *item.Content.Summary)
+		}
+
+		// ChunkText and ChunkMetadata are no longer directly available on SearchResultItem
+		// Display a snippet from the body as a fallback
+		if item.Content.Body != "" {
+			snippet := item.Content.Body
+			maxSnippetLength := 200
+			if len(snippet) > maxSnippetLength {
+				snippet = snippet[:maxSnippetLength] + "..."
 			}
-			// Display summary if present
-			if item.Content.Summary != nil && *item.Content.Summary != "" {
-				fmt.Printf("Summary: %s\n", *item.Content.Summary)
+			snippet = strings.ReplaceAll(snippet, "\n", " ")
+			fmt.Printf("Snippet: %s\n", snippet)
+		} else {
+			fmt.Println("Snippet: (Body is empty)")
+		}
+		if item.Explanation != nil {
+			for _, chunk := range item.Explanation.Chunks {
+				text := strings.ReplaceAll(chunk.Text, "\n", " ")
+				if len(text) > 200 {
+					text = text[:200] + "..."
+				}
+				fmt.Printf("Matched chunk (score %.4f, distance %.4f): %s\n", chunk.Score, chunk.Distance, text)
 			}
+		}
+	}
+	fmt.Println("------------------------")
+}
 
-			// ChunkText and ChunkMetadata are no longer directly available on SearchResultItem
-			// Display a snippet from the body as a fallback
-			if item.Content.Body != "" {
-				snippet := item.Content.Body
-				maxSnippetLength := 200
-				if len(snippet) > maxSnippetLength {
-					snippet = snippet[:maxSnippetLength] + "..."
+// runHybridSearch runs a hybrid search and prints the fused results.
+func runHybridSearch(ctx context.Context, searchService *services.SearchService, params services.HybridSearchParams) error {
+	results, err := searchService.HybridSearch(ctx, params)
+	if err != nil {
+		log.Printf("Error during hybrid search: %v", err)
+		return fmt.Errorf("hybrid search failed: %w", err)
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No results found.")
+		return nil
+	}
+
+	fmt.Println("Hybrid Search Results:")
+	fmt.Println("------------------------")
+	for _, item := range results {
+		fmt.Printf("Score: %.4f (fused)\nID:    %d\nTitle: %s\n", item.Score, item.Content.ID, item.Content.Title)
+		snippet := strings.ReplaceAll(item.Content.Body, "\n", " ")
+		if len(snippet) > 200 {
+			snippet = snippet[:200] + "..."
+		}
+		fmt.Printf("Snippet: %s\n", snippet)
+		if item.Explanation != nil {
+			fmt.Printf("Matched terms: %s\n", strings.Join(item.Explanation.MatchedTerms, ", "))
+			for _, chunk := range item.Explanation.Chunks {
+				text := strings.ReplaceAll(chunk.Text, "\n", " ")
+				if len(text) > 200 {
+					text = text[:200] + "..."
 				}
-				snippet = strings.ReplaceAll(snippet, "\n", " ")
-				fmt.Printf("Snippet: %s\n", snippet)
-			} else {
-				fmt.Println("Snippet: (Body is empty)")
+				fmt.Printf("Matched chunk (score %.4f, distance %.4f): %s\n", chunk.Score, chunk.Distance, text)
 			}
 		}
-		fmt.Println("------------------------")
-		return nil
-	},
+		fmt.Println("---")
+	}
+	fmt.Println("------------------------")
+	return nil
 }
 
 func init() {
@@ -151,4 +237,10 @@ func init() {
 	searchCmd.Flags().IntVarP(&searchLimit, "limit", "l", 10, "Limit the number of search results")
 	searchCmd.Flags().StringVarP(&searchTags, "tags", "T", "", "Comma-separated list of tags to filter results by (match any)")
 	searchCmd.Flags().BoolVar(&searchKeyword, "keyword", false, "Use keyword-based search instead of semantic search")
+	searchCmd.Flags().StringVar(&searchBoost, "boost", "", "Boost semantic results by metadata: recency or source")
+	searchCmd.Flags().BoolVar(&searchHybrid, "hybrid", false, "Combine semantic and keyword search, fusing their rankings")
+	searchCmd.Flags().Float64Var(&searchKeywordWeight, "keyword-weight", 0, "Weight of keyword ranks with --hybrid (default search.hybrid.keyword_weight)")
+	searchCmd.Flags().Float64Var(&searchSemanticWeight, "semantic-weight", 0, "Weight of semantic ranks with --hybrid (default search.hybrid.semantic_weight)")
+	searchCmd.Flags().BoolVar(&searchExplain, "explain", false, "Show why each result matched: its best chunks (semantic) or matched terms (keyword)")
+	searchCmd.Flags().Float64Var(&searchDiversity, "diversity", 0, "Diversify semantic results (0-1): higher values favor results unlike those ranked above (MMR)")
 }
@@ -1,12 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"mimir/internal/apihandlers" // Import the new handlers package
 	// "mimir/internal/app" // Removed unused import
 	// "mimir/internal/config" // Removed unused import
-	"net/http" // Required for http constants
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
@@ -39,52 +45,104 @@ via a RESTful API. Allows interaction from other tools or UIs.`,
 
 		// Group routes under /api/v1 (optional, but good practice)
 		v1 := router.Group("/api/v1")
+		v1.Use(apihandlers.OwnerMiddleware(appInstance.Config.MultiTenant)) // Scope requests to the caller's data plus shared content
 		{
 			// Content Routes
 			contentGroup := v1.Group("/content")
 			{
 				contentGroup.POST("", apiHandler.AddContentHandler)
 				contentGroup.GET("", apiHandler.ListContentHandler)
+				contentGroup.POST("/tag-by-filter", apiHandler.TagByFilterHandler)        // Tag all content matching a query/filter
+				contentGroup.POST("/rehash", apiHandler.RehashContentHandler)             // Recompute dedup hashes after hashing rules change
+				contentGroup.POST("/batch-get", apiHandler.BatchGetContentHandler)        // Fetch several items with their tags
+				contentGroup.POST("/upload", apiHandler.UploadContentHandler)             // Add an uploaded file (multipart/form-data)
+				contentGroup.GET("/recent", apiHandler.RecentContentHandler)              // Recently viewed content with view counts
+				contentGroup.GET("/popular", apiHandler.PopularContentHandler)            // Most viewed content
+				contentGroup.GET("/diff", apiHandler.ContentDiffHandler)                  // Diff the bodies of two items
+				contentGroup.POST("/categorize/batch", apiHandler.BatchCategorizeHandler) // Suggest categories; apply=true applies them
 				contentGroup.GET("/:id", apiHandler.GetContentHandler)
-				// TODO: Add PUT /content/:id for editing later?
+				contentGroup.GET("/:id/render", apiHandler.RenderContentHandler)           // Body as sanitized HTML
+				contentGroup.GET("/:id/chunks", apiHandler.ContentChunksHandler)           // Embedded chunks, optionally with vectors
+				contentGroup.GET("/:id/tag-suggestions", apiHandler.TagSuggestionsHandler) // Tags drawn from similar content
+				contentGroup.GET("/:id/related", apiHandler.RelatedContentHandler)         // Semantically similar content
+				contentGroup.PATCH("/:id/source", apiHandler.ReassignSourceHandler)        // Move content to another source
+				contentGroup.POST("/:id/append", apiHandler.AppendContentHandler)          // Append text and re-embed
+				contentGroup.PATCH("/:id/pin", apiHandler.PinContentHandler)               // Pin or unpin content
+				contentGroup.PUT("/:id", apiHandler.UpdateContentHandler)                  // Edit title, body or metadata
+				contentGroup.PATCH("/:id", apiHandler.UpdateContentHandler)
 				// TODO: Add DELETE /content/:id later?
 			}
 
 			// Search Routes (Semantic)
 			searchGroup := v1.Group("/search")
 			{
-				searchGroup.GET("", apiHandler.SearchContentHandler) // Semantic search
+				searchGroup.GET("", apiHandler.SearchContentHandler)      // Semantic search
+				searchGroup.POST("/batch", apiHandler.BatchSearchHandler) // Several semantic searches, embedded in one batch
 			}
+			// Answer Routes (RAG)
+			v1.POST("/answer", apiHandler.AnswerHandler) // Answer a question from stored content, with sources
 			// Keyword Search Routes
 			keywordGroup := v1.Group("/keyword")
 			{
 				keywordGroup.GET("", apiHandler.KeywordSearchHandler) // Keyword search
 			}
 
-			// TODO: Add routes for tags, collections, related, history etc. later
-			// Example:
-			// tagGroup := v1.Group("/tags") { ... }
-			// collectionGroup := v1.Group("/collections") { ... }
+			// Tag Routes
+			tagGroup := v1.Group("/tags")
+			{
+				tagGroup.GET("/graph", apiHandler.TagGraphHandler)               // Tag co-occurrence graph
+				tagGroup.GET("/autocomplete", apiHandler.TagAutocompleteHandler) // Tags by name prefix, most used first
+			}
+
+			// Collection Routes
+			collectionGroup := v1.Group("/collections")
+			{
+				collectionGroup.GET("", apiHandler.ListCollectionsHandler)
+				collectionGroup.POST("/merge", apiHandler.MergeCollectionsHandler)              // Move all content of one collection into another
+				collectionGroup.DELETE("/:id", apiHandler.DeleteCollectionHandler)              // Delete a collection, keeping its content
+				collectionGroup.GET("/:id/search", apiHandler.SearchCollectionHandler)          // Semantic search within a collection
+				collectionGroup.POST("/:id/categorize", apiHandler.CategorizeCollectionHandler) // Re-run categorization over the members
+			}
+
+			// Stats Routes
+			v1.GET("/stats", apiHandler.StatsHandler) // Vector store size and dimension
+			// Worker Routes
+			v1.GET("/workers", apiHandler.ListWorkersHandler) // Workers with a recent heartbeat
+			// Cost Routes
+			v1.GET("/costs/summary", apiHandler.CostSummaryHandler) // AI spend per service type
+
+			// TODO: Add routes for related, history etc. later
 		}
 
-		// Simple health check endpoint
-		router.GET("/health", func(c *gin.Context) {
-			// TODO: Add checks for DB/Redis connectivity if needed
-			c.JSON(http.StatusOK, gin.H{"status": "ok"})
-		})
+		// Health check endpoint, including embedding provider circuit breaker states
+		router.GET("/health", apiHandler.HealthHandler)
 
 		// Start the server
 		listenAddr := fmt.Sprintf("%s:%s", serveAddr, servePort)
 		log.Printf("Starting Mimir API server on http://%s", listenAddr)
 
-		// router.Run blocks unless an error occurs
-		if err := router.Run(listenAddr); err != nil {
+		// Serve until SIGINT or SIGTERM, then shut down gracefully so the app is
+		// closed (flushing buffered usage logs) once the command returns
+		server := &http.Server{Addr: listenAddr, Handler: router}
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		serveErr := make(chan error, 1)
+		go func() { serveErr <- server.ListenAndServe() }()
+
+		select {
+		case err := <-serveErr:
 			// Log the error before returning it
 			log.Printf("ERROR: Failed to run API server: %v", err)
 			return fmt.Errorf("failed to run API server: %w", err)
+		case <-ctx.Done():
 		}
 
-		// This part is unlikely to be reached if router.Run starts successfully
+		log.Println("Shutdown signal received. Stopping API server...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shut down API server: %w", err)
+		}
 		log.Println("Mimir API server stopped.")
 		return nil
 	},
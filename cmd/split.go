@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"mimir/internal/services"
+)
+
+var (
+	splitBy                 string
+	splitDelimiter          string
+	splitLevel              int
+	splitInheritTags        bool
+	splitInheritCollections bool
+)
+
+// splitCmd represents the split command
+var splitCmd = &cobra.Command{
+	Use:   "split [content_id]",
+	Short: "Split a content item into separate items",
+	Long: `Creates a separate content item for each section of an existing one, for
+cleaning up large imports that hold many documents. Sections are cut at
+markdown headings (--by heading, the shallowest level unless --level is set)
+or at a literal delimiter (--by delimiter --delimiter "---").
+
+New items keep the original's source, owner and visibility, record it in
+their "split_from" metadata and are embedded on their own. The original is
+left unchanged; delete it once the split looks right.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contentID, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid content ID provided: '%s'. Please provide a number.", args[0])
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.ContentService == nil {
+			return fmt.Errorf("content service is not initialized in the application")
+		}
+
+		result, err := appInstance.ContentService.SplitContent(cmd.Context(), contentID, services.SplitContentParams{
+			By:                 splitBy,
+			Delimiter:          splitDelimiter,
+			Level:              splitLevel,
+			InheritTags:        splitInheritTags,
+			InheritCollections: splitInheritCollections,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to split content ID %d: %w", contentID, err)
+		}
+
+		fmt.Printf("Split content %d into %d new items:\n", contentID, len(result.Created))
+		for _, c := range result.Created {
+			fmt.Printf("  %d: %s (%d bytes)\n", c.ID, c.Title, len(c.Body))
+		}
+		if len(result.Existing) > 0 {
+			fmt.Printf("Skipped %d sections that already exist as content: %v\n", len(result.Existing), result.Existing)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(splitCmd)
+
+	splitCmd.Flags().StringVar(&splitBy, "by", services.SplitByHeading, "How to find sections: heading or delimiter")
+	splitCmd.Flags().StringVar(&splitDelimiter, "delimiter", "", "Delimiter separating sections when splitting by delimiter")
+	splitCmd.Flags().IntVar(&splitLevel, "level", 0, "Heading level to split at (1-6); 0 uses the shallowest heading level")
+	splitCmd.Flags().BoolVar(&splitInheritTags, "inherit-tags", false, "Apply the original's tags to the new items")
+	splitCmd.Flags().BoolVar(&splitInheritCollections, "inherit-collections", false, "Add the new items to the original's collections")
+}
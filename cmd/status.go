@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+// statusCmd represents the base command for knowledge base health checks
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Inspect the state of stored content",
+	Long:  `Reports on the health of stored content, such as embeddings that no longer match their content.`,
+}
+
+var statusStaleCmd = &cobra.Command{
+	Use:   "stale",
+	Short: "List content whose embedding is out of date",
+	Long: `Lists embedded content whose body changed after the embedding was generated.
+Run 'mimir reindex --stale' to re-embed these items.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		stale, err := appInstance.ContentService.ListStaleEmbeddings(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("error listing stale embeddings: %w", err)
+		}
+
+		if len(stale) == 0 {
+			fmt.Println("No stale embeddings found.")
+			return nil
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"ID", "Title", "Updated At"})
+		table.SetBorder(false)
+		table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+		for _, c := range stale {
+			table.Append([]string{
+				strconv.FormatInt(c.ID, 10),
+				c.Title,
+				c.UpdatedAt.Format("2006-01-02 15:04:05"),
+			})
+		}
+		table.Render()
+		fmt.Printf("%d item(s) have stale embeddings. Run 'mimir reindex --stale' to re-embed them.\n", len(stale))
+		return nil
+	},
+}
+
+var statusFailedLimit int
+
+var statusFailedCmd = &cobra.Command{
+	Use:   "failed",
+	Short: "List content whose embedding failed",
+	Long: `Lists unembedded content with a failed embedding job, with the error recorded
+when its embedding job exhausted its retries. Fix the content (e.g. shorten it) and
+requeue its job with 'mimir jobs retry'.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		failed, err := appInstance.ContentService.ListContent(cmd.Context(), services.ListContentParams{
+			Limit:           statusFailedLimit,
+			SortBy:          "c.updated_at",
+			SortOrder:       "desc",
+			EmbeddingStatus: store.EmbeddingStatusFailed,
+		})
+		if err != nil {
+			return fmt.Errorf("error listing content with failed embeddings: %w", err)
+		}
+
+		if len(failed) == 0 {
+			fmt.Println("No failed embeddings found.")
+			return nil
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"ID", "Title", "Updated At", "Error"})
+		table.SetBorder(false)
+		table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+
+		for _, item := range failed {
+			reason := services.EmbeddingError(&item.Content)
+			if reason == "" {
+				reason = "(embedding job failed; will be retried)"
+			}
+			table.Append([]string{
+				strconv.FormatInt(item.Content.ID, 10),
+				item.Content.Title,
+				item.Content.UpdatedAt.Format("2006-01-02 15:04:05"),
+				reason,
+			})
+		}
+		table.Render()
+		fmt.Printf("%d item(s) failed to embed.\n", len(failed))
+		return nil
+	},
+}
+
+func init() {
+	statusCmd.AddCommand(statusStaleCmd)
+	statusCmd.AddCommand(statusFailedCmd)
+	statusFailedCmd.Flags().IntVarP(&statusFailedLimit, "limit", "n", 50, "Maximum number of items to list")
+	rootCmd.AddCommand(statusCmd)
+}
@@ -6,6 +6,8 @@ import (
 	"strconv"
 	"strings"
 
+	"mimir/internal/services"
+
 	"github.com/spf13/cobra"
 )
 
@@ -60,7 +62,94 @@ Tags are case-insensitive and will be created if they don't exist.`,
 	},
 }
 
+// tagRemoveCmd removes a tag from a content item
+var tagRemoveCmd = &cobra.Command{
+	Use:   "remove <content_id> <tag_name>",
+	Short: "Remove a tag from a content item",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		contentID, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid content ID provided: '%s'. Please provide a number.", args[0])
+		}
+		tagName := strings.TrimSpace(args[1])
+		if tagName == "" {
+			return fmt.Errorf("no valid tag name provided")
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.TagService == nil {
+			return fmt.Errorf("tag service is not initialized in the application")
+		}
+
+		if err := appInstance.TagService.UntagContent(cmd.Context(), contentID, tagName); err != nil {
+			return fmt.Errorf("failed to remove tag %q from content ID %d: %w", tagName, contentID, err)
+		}
+
+		fmt.Printf("Removed tag %q from content ID %d\n", tagName, contentID)
+		return nil
+	},
+}
+
+var (
+	tagFilterQuery  string
+	tagFilterTags   []string
+	tagFilterPinned bool
+)
+
+// tagByFilterCmd tags every content item matching a query and/or filter
+var tagByFilterCmd = &cobra.Command{
+	Use:   "by-filter <tag_name...>",
+	Short: "Apply tags to all content matching a query or filter",
+	Long: `Applies one or more tags to every content item matching a full-text query
+(--query), existing tags (--with-tag) and/or pinned state (--pinned).
+At least one of these must be given.`,
+	Example: `  mimir tag by-filter k8s --query kubernetes
+  mimir tag by-filter reviewed --with-tag draft --pinned`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tagNames := make([]string, 0, len(args))
+		for _, t := range args {
+			if trimmed := strings.TrimSpace(t); trimmed != "" {
+				tagNames = append(tagNames, trimmed)
+			}
+		}
+		if len(tagNames) == 0 {
+			return fmt.Errorf("no valid tag names provided")
+		}
+
+		filter := services.ListContentParams{Query: tagFilterQuery, FilterTags: tagFilterTags}
+		if cmd.Flags().Changed("pinned") {
+			filter.Pinned = &tagFilterPinned
+		}
+
+		appInstance, err := GetAppFromContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if appInstance.TagService == nil {
+			return fmt.Errorf("tag service is not initialized in the application")
+		}
+
+		tagged, err := appInstance.TagService.TagContentByFilter(cmd.Context(), filter, tagNames)
+		if err != nil {
+			return fmt.Errorf("failed to tag content by filter: %w", err)
+		}
+
+		fmt.Printf("Applied tags %v to %d content item(s)\n", tagNames, tagged)
+		return nil
+	},
+}
+
 func init() {
+	tagByFilterCmd.Flags().StringVar(&tagFilterQuery, "query", "", "Full-text query matched against title and body")
+	tagByFilterCmd.Flags().StringSliceVar(&tagFilterTags, "with-tag", nil, "Only content carrying any of these tags (comma-separated or repeated)")
+	tagByFilterCmd.Flags().BoolVar(&tagFilterPinned, "pinned", false, "Only pinned content (--pinned=false selects unpinned content)")
+
+	tagCmd.AddCommand(tagRemoveCmd)
+	tagCmd.AddCommand(tagByFilterCmd)
 	rootCmd.AddCommand(tagCmd)
-	// No flags needed for this basic version
 }
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"    // Add fmt import
 	"log"
 	"os"        // For signal handling
@@ -11,6 +12,7 @@ import (
 	"github.com/hibiken/asynq"
 	"github.com/spf13/cobra"
 	"mimir/internal/app"
+	"mimir/internal/services"
 	"mimir/internal/tasks" // Add tasks import
 	"mimir/internal/worker" // Add worker import
 )
@@ -62,7 +64,16 @@ func runWorker(appInstance *app.App) error {
 			ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
 				log.Printf("ERROR: Asynq task failed: task_id=%s type=%s payload=%s err=%v",
 					task.ResultWriter().TaskID(), task.Type(), string(task.Payload()), err)
-				// Add more sophisticated error handling/reporting here
+				// Asynq archives tasks that will not be retried; record them for `mimir jobs dead`
+				if services.TaskExhausted(ctx, err) {
+					if recErr := appInstance.DeadLetterService.RecordDeadTask(ctx, task.ResultWriter().TaskID(), err); recErr != nil {
+						log.Printf("ERROR: Failed to record dead task %s: %v", task.ResultWriter().TaskID(), recErr)
+					}
+					// Mark the content failed with the reason, for `mimir status failed`
+					if recErr := appInstance.DeadLetterService.RecordEmbeddingFailure(ctx, task, err); recErr != nil {
+						log.Printf("ERROR: Failed to record embedding failure of task %s: %v", task.ResultWriter().TaskID(), recErr)
+					}
+				}
 			}),
 			// Logger: // Custom logger if needed
 		},
@@ -80,9 +91,14 @@ func runWorker(appInstance *app.App) error {
 		JobStore:      appInstance.JobStore,
 		BatchProvider: appInstance.BatchAPIProvider,
 		JobClient:     appInstance.JobClient,
-		MaxTokens:     cfg.Chunking.MaxTokens, // Pass config values
-		Overlap:       cfg.Chunking.Overlap,
+		MaxTokens:     cfg.Chunking.MaxTokens,       // Pass config values
+		Overlap:       appInstance.ChunkOverlap,     // Token count or fraction of MaxTokens (chunking.Overlap)
+		MaxChunks:     cfg.Chunking.MaxChunksPerDoc, // Caps chunks embedded per document (0 = no cap)
+		DedupChunks:   cfg.Chunking.DedupChunks,     // Skips exact-duplicate chunks within a document (chunking.DedupChunks); count goes in the job result
 		UseBatchAPI:   cfg.Embedding.UseBatchAPI,
+		Tags:          appInstance.TagStore,               // Denormalizes tag IDs into embedding metadata (services.ContentEmbeddingMetadata)
+		Sources:       appInstance.SourceStore,            // Source names for the embedding input template
+		InputTemplate: appInstance.EmbeddingInputTemplate, // Builds the embedded text (InputTemplate.Apply) and is recorded via SetEmbeddingInputVersion
 	}
 	// Register Embedding & Batch Check Handlers (using the new registration function)
 	worker.RegisterHandlers(mux, embeddingDeps, cfg)
@@ -104,14 +120,46 @@ func runWorker(appInstance *app.App) error {
 		log.Println("WARN: SummaryService is nil, skipping registration of summarization handler.")
 	}
 
+	// Register Embedding Metadata Update Handler (keeps tag filters in sync after tag changes)
+	log.Printf("Registering EmbeddingMetadataUpdateJob handler (%s)", tasks.TypeEmbeddingMetadataUpdateJob)
+	mux.HandleFunc(tasks.TypeEmbeddingMetadataUpdateJob, handleEmbeddingMetadataUpdate(appInstance.MetadataSyncer))
+
+	// Register Embedding Append Handler (embeds only text appended to already-embedded content)
+	log.Printf("Registering EmbeddingAppendJob handler (%s)", tasks.TypeEmbeddingAppendJob)
+	mux.HandleFunc(tasks.TypeEmbeddingAppendJob, handleEmbeddingAppend(appInstance.AppendEmbedder))
+
+	// Register Batch Embedding Handler (embeds bulk-added content with shared embedding API calls)
+	log.Printf("Registering BatchEmbeddingJob handler (%s)", tasks.TypeBatchEmbeddingJob)
+	mux.HandleFunc(tasks.TypeBatchEmbeddingJob, handleBatchEmbedding(appInstance.BatchEmbedder))
+
 	// Register other handlers here...
 
+	// Record liveness and in-flight task counts for GET /workers
+	heartbeater := appInstance.WorkerService.NewHeartbeater()
+	mux.Use(heartbeater.Middleware)
+
+	// Count reindex embedding jobs towards their run's progress (mimir reindex status)
+	mux.Use(appInstance.ReindexService.Middleware)
+
+	// Run one embedding job per content at a time, so concurrent jobs cannot duplicate chunks
+	if appInstance.EmbeddingJobGuard != nil {
+		mux.Use(appInstance.EmbeddingJobGuard.Middleware)
+	}
+
 	// --- Start Server & Handle Shutdown ---
 	log.Printf("Starting Asynq worker server (Concurrency: %d, Queues: %v)...", cfg.Worker.Concurrency, cfg.Worker.Queues)
 	if err := srv.Start(mux); err != nil {
 		return fmt.Errorf("failed to start Asynq server: %w", err)
 	}
 
+	heartbeatCtx, stopHeartbeat := context.WithCancel(context.Background())
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		heartbeater.Run(heartbeatCtx)
+	}()
+	log.Printf("Worker %s is sending heartbeats", heartbeater.ID())
+
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 	<-shutdown
@@ -121,10 +169,76 @@ func runWorker(appInstance *app.App) error {
 	// srv.ShutdownTimeout = 30 * time.Second // This field does not exist
 	srv.Stop()
 	srv.Shutdown()
+	stopHeartbeat()
+	<-heartbeatDone
 
-	// Optional: Add cleanup for appInstance resources if needed
-	// appInstance.Close()
+	// Execute closes appInstance once this returns, flushing buffered usage logs
 
 	log.Println("Worker shutdown complete.")
 	return nil
 }
+
+// handleEmbeddingMetadataUpdate refreshes the denormalized tag and source metadata
+// of the content named in the task payload.
+func handleEmbeddingMetadataUpdate(syncer *services.EmbeddingMetadataSyncer) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload struct {
+			ContentID int64 `json:"content_id"`
+		}
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			return fmt.Errorf("unmarshal embedding metadata update payload: %v: %w", err, asynq.SkipRetry)
+		}
+		if err := syncer.SyncContent(ctx, payload.ContentID); err != nil {
+			return fmt.Errorf("sync embedding metadata for content %d: %w", payload.ContentID, err)
+		}
+		return nil
+	}
+}
+
+// handleEmbeddingAppend embeds the appended text named in the task payload as
+// additional chunks of the content.
+func handleEmbeddingAppend(embedder *services.AppendEmbedder) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload struct {
+			ContentID int64  `json:"content_id"`
+			FromHash  string `json:"from_hash"`
+			ToHash    string `json:"to_hash"`
+			Text      string `json:"text"`
+		}
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			return fmt.Errorf("unmarshal embedding append payload: %v: %w", err, asynq.SkipRetry)
+		}
+		result, err := embedder.EmbedAppended(ctx, payload.ContentID, payload.FromHash, payload.ToHash, payload.Text)
+		if err != nil {
+			return fmt.Errorf("embed appended text for content %d: %w", payload.ContentID, err)
+		}
+		if res, err := json.Marshal(result); err == nil {
+			if _, err := t.ResultWriter().Write(res); err != nil {
+				log.Printf("WARN: Failed to write result for embedding append job of content %d: %v", payload.ContentID, err)
+			}
+		}
+		return nil
+	}
+}
+
+// handleBatchEmbedding chunks and embeds all content named in the task payload.
+func handleBatchEmbedding(embedder *services.BatchEmbedder) asynq.HandlerFunc {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var payload struct {
+			ContentIDs []int64 `json:"content_ids"`
+		}
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			return fmt.Errorf("unmarshal batch embedding payload: %v: %w", err, asynq.SkipRetry)
+		}
+		result, err := embedder.EmbedContents(ctx, payload.ContentIDs)
+		if res, jsonErr := json.Marshal(result); jsonErr == nil {
+			if _, writeErr := t.ResultWriter().Write(res); writeErr != nil {
+				log.Printf("WARN: Failed to write result for batch embedding job of %d content items: %v", len(payload.ContentIDs), writeErr)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("batch embed %d content items: %w", len(payload.ContentIDs), err)
+		}
+		return nil
+	}
+}
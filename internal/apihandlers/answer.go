@@ -1,73 +1,63 @@
 package apihandlers
 
 import (
+	"errors"
 	"fmt"
-	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"mimir/internal/services"
+	"mimir/internal/store"
 )
 
 // AnswerRequest defines the expected JSON body for the /answer endpoint.
 type AnswerRequest struct {
-	Query string `json:"query" binding:"required"`
+	Query        string   `json:"query" binding:"required"`
+	Limit        int      `json:"limit"`         // Content items retrieved as context; 0 uses the default search limit
+	Tags         []string `json:"tags"`          // Restricts the context to content carrying any of these tags
+	CollectionID int64    `json:"collection_id"` // Restricts the context to a collection
 }
 
-// AnswerResponse defines the JSON response for a successful answer generation.
-type AnswerResponse struct {
-	Answer string `json:"answer"`
-}
+// AnswerHandler handles requests to the /api/v1/answer endpoint: it answers
+// the query from stored content, returning the answer with its sources.
+func (h *APIHandler) AnswerHandler(c *gin.Context) {
+	if h.rejectKeywordOnly(c) {
+		return
+	}
+	if h.App.RAGService == nil {
+		NotImplemented(c, "RAG is not enabled: set rag.enabled and a completion provider in the configuration")
+		return
+	}
 
-// AnswerHandler handles requests to the /api/v1/answer endpoint.
-func AnswerHandler(c *gin.Context) {
 	var req AnswerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("ERROR binding answer request: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request body: %v", err)})
+		BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+	if req.Limit < 0 {
+		BadRequest(c, fmt.Sprintf("invalid limit: %d", req.Limit))
 		return
 	}
 
-	// // Retrieve the App instance from the Gin context (Commented out as RAGService is disabled)
-	// appInstanceAny, exists := c.Get("app")
-	// if !exists {
-	// 	log.Println("ERROR: App instance not found in Gin context")
-	// 	c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error: App context not found"})
-	// 	return
-	// }
-	//
-	// appInstance, ok := appInstanceAny.(*app.App)
-	// if !ok {
-	// 	log.Println("ERROR: App instance in Gin context is not of type *app.App")
-	// 	c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error: Invalid app context type"})
-	// 	return
-	// }
-
-	// --- RAG Service Integration (Currently Disabled/Commented Out in App) ---
-	// The RAGService field is commented out in internal/app/app.go.
-	// To re-enable this, uncomment the RAGService field and its initialization in app.go
-	// and ensure the services.RAGService type is defined.
-
-	// // Check if RAG service is available
-	// if appInstance.RAGService == nil {
-	// 	log.Println("WARN: RAG service requested via API but not initialized/enabled.")
-	// 	c.JSON(http.StatusNotImplemented, gin.H{"error": "RAG feature is not enabled or configured on the server"})
-	// 	return
-	// }
-	// // Call the RAG service
-	// answer, err := appInstance.RAGService.GenerateAnswer(c.Request.Context(), req.Query)
-	// --- End RAG Service Integration ---
-
-	// --- Temporary Error Response ---
-	// Return an error indicating the feature is not implemented/enabled yet.
-	c.JSON(http.StatusNotImplemented, gin.H{"error": "RAG functionality is currently disabled."})
-	return
-	// --- End Temporary Error Response ---
-	/*
-		if err != nil {
-			log.Printf("ERROR generating RAG answer via API: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to generate answer: %v", err)})
-			return
+	answer, err := h.App.RAGService.Query(c.Request.Context(), req.Query, services.RAGParams{
+		Limit:        h.clampLimit(c, req.Limit),
+		FilterTags:   req.Tags,
+		CollectionID: req.CollectionID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNoRAGContext):
+			NotFound(c, err.Error())
+		case errors.Is(err, store.ErrNotFound):
+			NotFound(c, fmt.Sprintf("Collection not found with ID: %d", req.CollectionID))
+		case errors.Is(err, services.ErrEmbeddingDisabled):
+			NotImplemented(c, err.Error())
+		default:
+			Internal(c, fmt.Sprintf("AnswerHandler: failed to generate answer: %v", err))
 		}
-		c.JSON(http.StatusOK, AnswerResponse{Answer: answer})
-	*/
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": answer})
 }
@@ -38,3 +38,11 @@ func Internal(ctx *gin.Context, msg string) {
 func Conflict(ctx *gin.Context, msg string) {
 	JSONError(ctx, http.StatusConflict, "conflict", msg)
 }
+
+func PayloadTooLarge(ctx *gin.Context, msg string) {
+	JSONError(ctx, http.StatusRequestEntityTooLarge, "payload_too_large", msg)
+}
+
+func NotImplemented(ctx *gin.Context, msg string) {
+	JSONError(ctx, http.StatusNotImplemented, "not_implemented", msg)
+}
@@ -1,14 +1,19 @@
 package apihandlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"mimir/internal/app"
+	"mimir/internal/config"
+	"mimir/internal/inputprocessor"
 	"mimir/internal/models"
+	"mimir/internal/render"
 	"mimir/internal/services"
 	"mimir/internal/store"
 
@@ -25,16 +30,30 @@ func (h *APIHandler) AddContentHandler(c *gin.Context) {
 		BadRequest(c, "Invalid request body: "+err.Error())
 		return
 	}
+	sourceType, err := h.resolveSourceType(req.SourceType)
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
 
 	params := services.AddContentParams{
 		SourceName: req.Source,
 		Title:      req.Title,
 		RawInput:   req.Input,
-		SourceType: "api",
+		SourceType: sourceType,
+		Visibility: req.Visibility,
 	}
 
 	content, existed, err := h.App.ContentService.AddContent(c.Request.Context(), params)
 	if err != nil {
+		if errors.Is(err, services.ErrContentTooLarge) {
+			PayloadTooLarge(c, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrInvalidVisibility) {
+			BadRequest(c, err.Error())
+			return
+		}
 		Internal(c, fmt.Sprintf("AddContentHandler: failed to add content: %v", err))
 		return
 	}
@@ -43,6 +62,87 @@ func (h *APIHandler) AddContentHandler(c *gin.Context) {
 	h.respondWithAddContentAndTags(c, content, existed, req.Source)
 }
 
+// UploadContentHandler handles multipart/form-data POST requests adding an
+// uploaded file as content, so clients need not share a filesystem with the
+// server. Form fields: file and source (required), title (defaults to the file
+// name), source_type and visibility. PDFs are reduced to their text.
+func (h *APIHandler) UploadContentHandler(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		BadRequest(c, "Invalid upload: missing file field: "+err.Error())
+		return
+	}
+	source := c.PostForm("source")
+	if source == "" {
+		BadRequest(c, "Invalid upload: missing required field: source")
+		return
+	}
+	title := c.PostForm("title")
+	if title == "" {
+		title = fileHeader.Filename
+	}
+	sourceType, err := h.resolveSourceType(c.PostForm("source_type"))
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		Internal(c, fmt.Sprintf("UploadContentHandler: failed to open upload: %v", err))
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		Internal(c, fmt.Sprintf("UploadContentHandler: failed to read upload: %v", err))
+		return
+	}
+
+	params := services.AddContentParams{
+		SourceName: source,
+		Title:      title,
+		SourceType: sourceType,
+		Visibility: c.PostForm("visibility"),
+		Upload:     data,
+		UploadName: fileHeader.Filename,
+	}
+	content, existed, err := h.App.ContentService.AddContent(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, services.ErrContentTooLarge) {
+			PayloadTooLarge(c, err.Error())
+			return
+		}
+		if errors.Is(err, services.ErrInvalidVisibility) || errors.Is(err, inputprocessor.ErrUnsupportedUpload) {
+			BadRequest(c, err.Error())
+			return
+		}
+		Internal(c, fmt.Sprintf("UploadContentHandler: failed to add content: %v", err))
+		return
+	}
+
+	h.respondWithAddContentAndTags(c, content, existed, source)
+}
+
+// resolveSourceType validates a client-supplied source type against
+// content.allowed_source_types, defaulting to "api" when it is empty.
+func (h *APIHandler) resolveSourceType(sourceType string) (string, error) {
+	sourceType = strings.TrimSpace(sourceType)
+	if sourceType == "" || sourceType == config.DefaultAPISourceType {
+		return config.DefaultAPISourceType, nil
+	}
+	allowed := h.App.Config.Content.AllowedSourceTypes
+	if len(allowed) == 0 {
+		allowed = config.DefaultAPISourceTypes
+	}
+	for _, t := range allowed {
+		if t == sourceType {
+			return sourceType, nil
+		}
+	}
+	return "", fmt.Errorf("invalid source_type %q; allowed: %s", sourceType, strings.Join(allowed, ", "))
+}
+
 // respondWithAddContentAndTags writes the AddContent response as JSON, including tags and summary if present.
 func (h *APIHandler) respondWithAddContentAndTags(c *gin.Context, content *models.Content, existed bool, source string) {
 	logMsg := fmt.Sprintf("API AddContent: content_id=%d, existed=%v, title=%q, source=%q", content.ID, existed, content.Title, source)
@@ -55,15 +155,17 @@ func (h *APIHandler) respondWithAddContentAndTags(c *gin.Context, content *model
 	}
 
 	resp := struct {
-		Content models.Content `json:"content"`
-		Existed bool           `json:"existed"`
-		Tags    []*models.Tag  `json:"tags,omitempty"`
-		Summary *string        `json:"summary,omitempty"`
+		Content     models.Content `json:"content"`
+		Existed     bool           `json:"existed"`
+		DuplicateOf *DuplicateRef  `json:"duplicate_of,omitempty"`
+		Tags        []*models.Tag  `json:"tags,omitempty"`
+		Summary     *string        `json:"summary,omitempty"`
 	}{
-		Content: *content,
-		Existed: existed,
-		Tags:    tags,
-		Summary: content.Summary,
+		Content:     *content,
+		Existed:     existed,
+		DuplicateOf: duplicateRef(content, existed),
+		Tags:        tags,
+		Summary:     content.Summary,
 	}
 
 	status := http.StatusCreated
@@ -79,8 +181,9 @@ func (h *APIHandler) respondWithAddContent(c *gin.Context, content *models.Conte
 	fmt.Println(logMsg)
 
 	resp := AddContentResponse{
-		Content: *content,
-		Existed: existed,
+		Content:     *content,
+		Existed:     existed,
+		DuplicateOf: duplicateRef(content, existed),
 	}
 
 	status := http.StatusCreated
@@ -120,7 +223,7 @@ func (h *APIHandler) ListContentHandler(c *gin.Context) {
 
 // parseAndValidateListContentParams parses and validates query parameters for listing content.
 func (h *APIHandler) parseAndValidateListContentParams(c *gin.Context) (services.ListContentParams, error) {
-	limit := 20
+	limit := h.App.Config.Defaults.PageLimit(0)
 	offset := 0
 	sortBy := c.DefaultQuery("sort_by", "created_at")
 	sortOrder := c.DefaultQuery("sort_order", "desc")
@@ -128,7 +231,7 @@ func (h *APIHandler) parseAndValidateListContentParams(c *gin.Context) (services
 
 	if l := c.Query("limit"); l != "" {
 		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
-			limit = parsed
+			limit = h.clampLimit(c, parsed)
 		} else {
 			return services.ListContentParams{}, fmt.Errorf("invalid limit: %s", l)
 		}
@@ -148,16 +251,43 @@ func (h *APIHandler) parseAndValidateListContentParams(c *gin.Context) (services
 			}
 		}
 	}
+	var pinned *bool
+	if p := c.Query("pinned"); p != "" {
+		parsed, err := strconv.ParseBool(p)
+		if err != nil {
+			return services.ListContentParams{}, fmt.Errorf("invalid pinned: %s", p)
+		}
+		pinned = &parsed
+	}
+	embeddingStatus := c.Query("embedding_status")
+	switch embeddingStatus {
+	case "", store.EmbeddingStatusEmbedded, store.EmbeddingStatusPending, store.EmbeddingStatusFailed:
+	default:
+		return services.ListContentParams{}, fmt.Errorf("invalid embedding_status: %s (want embedded, pending or failed)", embeddingStatus)
+	}
 
 	return services.ListContentParams{
-		Limit:      limit,
-		Offset:     offset,
-		SortBy:     sortBy,
-		SortOrder:  sortOrder,
-		FilterTags: filterTags,
+		Limit:           limit,
+		Offset:          offset,
+		SortBy:          sortBy,
+		SortOrder:       sortOrder,
+		FilterTags:      filterTags,
+		Pinned:          pinned,
+		EmbeddingStatus: embeddingStatus,
 	}, nil
 }
 
+// clampLimit caps a requested limit at the configured maximum page size.
+// When the limit is reduced, the applied value is reported in the
+// X-Limit-Clamped response header instead of failing the request.
+func (h *APIHandler) clampLimit(c *gin.Context, limit int) int {
+	if max := h.App.Config.Defaults.MaxLimit(); limit > max {
+		c.Header("X-Limit-Clamped", strconv.Itoa(max))
+		return max
+	}
+	return limit
+}
+
 // respondWithContentItems writes the content items as a JSON response.
 func (h *APIHandler) respondWithContentItems(c *gin.Context, items []services.ContentResultItem) {
 	c.JSON(http.StatusOK, gin.H{
@@ -179,12 +309,55 @@ func (h *APIHandler) GetContentHandler(c *gin.Context) {
 	}
 
 	resp := GetContentResponse{
-		Content: *content,
-		Tags:    tags,
+		Content:        *content,
+		Tags:           tags,
+		EmbeddingState: h.App.ContentService.EmbeddingState(c.Request.Context(), content),
 	}
 	c.JSON(http.StatusOK, gin.H{"data": resp})
 }
 
+// RenderContentResponse carries a content body rendered to sanitized HTML.
+// The raw body remains available from GET /content/:id.
+type RenderContentResponse struct {
+	ID          int64  `json:"id"`
+	ContentType string `json:"content_type"`
+	Format      string `json:"format"` // How the body was interpreted: markdown, html or text
+	HTML        string `json:"html"`
+}
+
+// RenderContentHandler handles GET /content/:id/render, returning the body as
+// sanitized HTML so clients can display it without their own XSS filtering.
+func (h *APIHandler) RenderContentHandler(c *gin.Context) {
+	id, err := parseContentIDFromRequest(c)
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	content, err := h.App.ContentService.GetContent(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			NotFound(c, fmt.Sprintf("Content not found with ID: %d", id))
+			return
+		}
+		Internal(c, fmt.Sprintf("RenderContentHandler: failed to retrieve content: %v", err))
+		return
+	}
+
+	safeHTML, format, err := render.HTML(content.ContentType, content.Body)
+	if err != nil {
+		Internal(c, fmt.Sprintf("RenderContentHandler: failed to render content %d: %v", id, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": RenderContentResponse{
+		ID:          content.ID,
+		ContentType: content.ContentType,
+		Format:      format,
+		HTML:        safeHTML,
+	}})
+}
+
 // fetchContentAndTagsForGet fetches content and tags for GetContentHandler, handling errors.
 func (h *APIHandler) fetchContentAndTagsForGet(c *gin.Context, id int64) (*models.Content, []*models.Tag, error) {
 	content, err := h.App.ContentService.GetContent(c.Request.Context(), id)
@@ -206,6 +379,9 @@ func (h *APIHandler) fetchContentAndTagsForGet(c *gin.Context, id int64) (*model
 }
 
 func (h *APIHandler) SearchContentHandler(c *gin.Context) {
+	if h.rejectKeywordOnly(c) {
+		return
+	}
 	params, err := h.parseAndValidateSearchContentParams(c)
 	if err != nil {
 		BadRequest(c, "Invalid query parameters: "+err.Error())
@@ -221,6 +397,48 @@ func (h *APIHandler) SearchContentHandler(c *gin.Context) {
 	h.respondWithSemanticSearchResults(c, results)
 }
 
+// SearchCollectionHandler handles GET requests for semantic search restricted to one collection.
+func (h *APIHandler) SearchCollectionHandler(c *gin.Context) {
+	if h.rejectKeywordOnly(c) {
+		return
+	}
+	collectionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		BadRequest(c, fmt.Sprintf("Invalid collection ID format: %s", c.Param("id")))
+		return
+	}
+
+	params, err := h.parseAndValidateSearchContentParams(c)
+	if err != nil {
+		BadRequest(c, "Invalid query parameters: "+err.Error())
+		return
+	}
+	params.CollectionID = collectionID
+
+	results, err := h.App.SearchService.SemanticSearch(c.Request.Context(), params)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			NotFound(c, fmt.Sprintf("Collection not found with ID: %d", collectionID))
+			return
+		}
+		Internal(c, fmt.Sprintf("SearchCollectionHandler: semantic search failed: %v", err))
+		return
+	}
+
+	h.respondWithSemanticSearchResults(c, results)
+}
+
+// rejectKeywordOnly responds 501 Not Implemented for endpoints that need
+// embeddings or the vector store when the app runs keyword-only, reporting
+// whether it did.
+func (h *APIHandler) rejectKeywordOnly(c *gin.Context) bool {
+	if !h.App.KeywordOnly {
+		return false
+	}
+	NotImplemented(c, services.ErrEmbeddingDisabled.Error())
+	return true
+}
+
 // parseAndValidateSearchContentParams parses and validates query parameters for semantic search.
 func (h *APIHandler) parseAndValidateSearchContentParams(c *gin.Context) (services.SemanticSearchParams, error) {
 	query := c.Query("query")
@@ -228,10 +446,10 @@ func (h *APIHandler) parseAndValidateSearchContentParams(c *gin.Context) (servic
 		return services.SemanticSearchParams{}, fmt.Errorf("missing required 'query' parameter")
 	}
 
-	limit := 10
+	limit := h.App.Config.Defaults.SearchLimitFor(0)
 	if l := c.Query("limit"); l != "" {
 		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
-			limit = parsed
+			limit = h.clampLimit(c, parsed)
 		} else {
 			return services.SemanticSearchParams{}, fmt.Errorf("invalid limit: %s", l)
 		}
@@ -247,30 +465,115 @@ func (h *APIHandler) parseAndValidateSearchContentParams(c *gin.Context) (servic
 		}
 	}
 
+	boost, err := services.ParseBoost(c.Query("boost"))
+	if err != nil {
+		return services.SemanticSearchParams{}, err
+	}
+
+	explain, err := parseExplain(c)
+	if err != nil {
+		return services.SemanticSearchParams{}, err
+	}
+
+	var diversity float64
+	if d := c.Query("diversity"); d != "" {
+		diversity, err = strconv.ParseFloat(d, 64)
+		if err != nil || diversity < 0 || diversity > 1 {
+			return services.SemanticSearchParams{}, fmt.Errorf("invalid diversity: %s (must be between 0 and 1)", d)
+		}
+	}
+
 	return services.SemanticSearchParams{
 		Query:      query,
 		Limit:      limit,
 		FilterTags: filterTags,
+		Boost:      boost,
+		Explain:    explain,
+		Diversity:  diversity,
 	}, nil
 }
 
+// parseExplain parses the optional explain query parameter of the search endpoints.
+func parseExplain(c *gin.Context) (bool, error) {
+	v := c.Query("explain")
+	if v == "" {
+		return false, nil
+	}
+	explain, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid explain: %s", v)
+	}
+	return explain, nil
+}
+
 // respondWithSemanticSearchResults writes the semantic search results as a JSON response.
 func (h *APIHandler) respondWithSemanticSearchResults(c *gin.Context, results []services.SearchResultItem) {
-	type searchResult struct {
-		Content *models.Content `json:"content"`
-		Score   float64         `json:"score"`
-	}
+	c.JSON(http.StatusOK, gin.H{
+		"results": toSemanticSearchResults(results),
+		"scoring": h.App.SearchService.ScoreInfo(),
+	})
+}
+
+// semanticSearchResult is the JSON shape of a single semantic search hit.
+type semanticSearchResult struct {
+	Content     *models.Content             `json:"content"`
+	Score       float64                     `json:"score"`    // Similarity, higher is better; see the scoring field
+	Distance    float64                     `json:"distance"` // Raw vector distance, lower is better
+	Explanation *services.SearchExplanation `json:"explanation,omitempty"`
+}
 
-	resp := make([]searchResult, len(results))
+func toSemanticSearchResults(results []services.SearchResultItem) []semanticSearchResult {
+	resp := make([]semanticSearchResult, len(results))
 	for i, r := range results {
-		resp[i] = searchResult{
-			Content: r.Content,
-			Score:   r.Score,
+		resp[i] = semanticSearchResult{
+			Content:     r.Content,
+			Score:       r.Similarity(),
+			Distance:    r.Score,
+			Explanation: r.Explanation,
 		}
 	}
+	return resp
+}
+
+// BatchSearchHandler handles POST requests running several semantic searches at once.
+func (h *APIHandler) BatchSearchHandler(c *gin.Context) {
+	if h.rejectKeywordOnly(c) {
+		return
+	}
+	var req BatchSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+	if len(req.Queries) == 0 {
+		BadRequest(c, "missing required field: queries")
+		return
+	}
+	if max := h.App.Config.Defaults.MaxLimit(); len(req.Queries) > max {
+		BadRequest(c, fmt.Sprintf("too many queries: %d (maximum %d)", len(req.Queries), max))
+		return
+	}
+	limit := h.App.Config.Defaults.SearchLimitFor(0)
+	if req.Limit < 0 {
+		BadRequest(c, fmt.Sprintf("invalid limit: %d", req.Limit))
+		return
+	} else if req.Limit > 0 {
+		limit = h.clampLimit(c, req.Limit)
+	}
+
+	results, err := h.App.SearchService.BatchSemanticSearch(c.Request.Context(), req.Queries, limit)
+	if err != nil {
+		Internal(c, fmt.Sprintf("BatchSearchHandler: batch semantic search failed: %v", err))
+		return
+	}
 
+	resp := make(map[string][]semanticSearchResult, len(results))
+	for query, items := range results {
+		resp[query] = toSemanticSearchResults(items)
+	}
 	c.JSON(http.StatusOK, gin.H{
 		"results": resp,
+		"scoring": h.App.SearchService.ScoreInfo(),
 	})
 }
 
@@ -282,11 +585,20 @@ func (h *APIHandler) KeywordSearchHandler(c *gin.Context) {
 		return
 	}
 
-	limit := 10 // Default limit for keyword search
+	limit := h.App.Config.Defaults.SearchLimitFor(0)
 	if l := c.Query("limit"); l != "" {
 		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
-			limit = parsed
+			limit = h.clampLimit(c, parsed)
+		}
+	}
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		parsed, err := strconv.Atoi(o)
+		if err != nil || parsed < 0 {
+			BadRequest(c, fmt.Sprintf("invalid offset: %s", o))
+			return
 		}
+		offset = parsed
 	}
 
 	filterTags := []string{}
@@ -299,10 +611,25 @@ func (h *APIHandler) KeywordSearchHandler(c *gin.Context) {
 		}
 	}
 
+	sortBy, err := services.ParseKeywordSort(c.Query("sort_by"))
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	explain, err := parseExplain(c)
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
 	results, err := h.App.SearchService.KeywordSearch(c.Request.Context(), services.KeywordSearchParams{
 		Query:      query,
 		FilterTags: filterTags,
-		Limit:      limit, // Note: KeywordSearch currently ignores limit/offset
+		Limit:      limit,
+		Offset:     offset,
+		SortBy:     sortBy,
+		Explain:    explain,
 	})
 	if err != nil {
 		Internal(c, fmt.Sprintf("KeywordSearchHandler: keyword search failed: %v", err))
@@ -315,110 +642,870 @@ func (h *APIHandler) KeywordSearchHandler(c *gin.Context) {
 	})
 }
 
-func (h *APIHandler) CategorizeContentHandler(c *gin.Context) {
-	contentID, err := parseContentIDFromRequest(c)
+// ReassignSourceHandler handles PATCH requests moving content to another source.
+func (h *APIHandler) ReassignSourceHandler(c *gin.Context) {
+	id, err := parseContentIDFromRequest(c)
 	if err != nil {
 		BadRequest(c, err.Error())
 		return
 	}
 
-	content, tags, err := h.fetchContentAndTagsForCategorization(c, contentID)
+	var req ReassignSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+	if req.Source == "" {
+		BadRequest(c, "missing required field: source")
+		return
+	}
+
+	content, err := h.App.ContentService.ReassignSource(c.Request.Context(), id, req.Source)
 	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			NotFound(c, fmt.Sprintf("Content not found with ID: %d", id))
+			return
+		}
+		Internal(c, fmt.Sprintf("ReassignSourceHandler: failed to reassign source: %v", err))
 		return
 	}
 
-	existingTagNames := make([]string, len(tags))
-	for i, t := range tags {
-		existingTagNames[i] = t.Name
+	c.JSON(http.StatusOK, gin.H{"data": content})
+}
+
+// AppendContentHandler handles POST requests appending text to existing content.
+func (h *APIHandler) AppendContentHandler(c *gin.Context) {
+	id, err := parseContentIDFromRequest(c)
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
 	}
 
-	if h.App.CategorizationService == nil {
-		Internal(c, "Categorization service is not configured or enabled")
+	var req AppendContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "Invalid request body: "+err.Error())
 		return
 	}
 
-	cats, err := h.App.CategorizationService.CategorizeContent(c.Request.Context(), content.Title, content.Body, existingTagNames)
+	content, err := h.App.ContentService.AppendToContent(c.Request.Context(), id, req.Text)
 	if err != nil {
-		Internal(c, "Categorization failed: "+err.Error())
+		switch {
+		case errors.Is(err, services.ErrEmptyAppend):
+			BadRequest(c, err.Error())
+		case errors.Is(err, store.ErrNotFound):
+			NotFound(c, fmt.Sprintf("Content not found with ID: %d", id))
+		case errors.Is(err, services.ErrContentTooLarge):
+			PayloadTooLarge(c, err.Error())
+		case errors.Is(err, store.ErrDuplicate):
+			Conflict(c, "appended body duplicates existing content")
+		default:
+			Internal(c, fmt.Sprintf("AppendContentHandler: failed to append content: %v", err))
+		}
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"content_id": contentID,
-		"tags":       cats.Tags,
-		"category":   cats.Category,
-		"confidence": cats.Confidence,
-	})
+	c.JSON(http.StatusOK, gin.H{"data": content})
 }
 
-// parseContentIDFromRequest parses the content ID from path or query.
-func parseContentIDFromRequest(c *gin.Context) (int64, error) {
-	idStr := c.Param("id")
-	if idStr == "" {
-		idStr = c.Query("id")
+// UpdateContentHandler handles PUT and PATCH requests editing a content item's
+// title, body or metadata, responding like GetContentHandler. Only a changed
+// body re-embeds the content.
+func (h *APIHandler) UpdateContentHandler(c *gin.Context) {
+	id, err := parseContentIDFromRequest(c)
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
 	}
-	if idStr == "" {
-		return 0, fmt.Errorf("Missing content ID parameter (expected in path /:id or query ?id=)")
+
+	var req UpdateContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "Invalid request body: "+err.Error())
+		return
 	}
-	contentID, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		return 0, fmt.Errorf("Invalid content ID format: %s", idStr)
+	if req.Title == nil && req.Body == nil && req.Metadata == nil {
+		BadRequest(c, "at least one of title, body or metadata is required")
+		return
 	}
-	return contentID, nil
-}
 
-// fetchContentAndTagsForCategorization fetches content and tags, and handles errors.
-func (h *APIHandler) fetchContentAndTagsForCategorization(c *gin.Context, contentID int64) (*models.Content, []*models.Tag, error) {
-	content, err := h.App.ContentService.GetContent(c.Request.Context(), contentID)
+	params := services.UpdateContentParams{Title: req.Title, Body: req.Body, Metadata: req.Metadata}
+	content, err := h.App.ContentService.UpdateContent(c.Request.Context(), id, params, h.App.VectorStore)
 	if err != nil {
-		if errors.Is(err, store.ErrNotFound) {
-			NotFound(c, fmt.Sprintf("Content not found with ID: %d", contentID))
-		} else {
-			Internal(c, fmt.Sprintf("fetchContentAndTagsForCategorization: failed to fetch content: %v", err))
+		switch {
+		case errors.Is(err, services.ErrEmptyBody):
+			BadRequest(c, err.Error())
+		case errors.Is(err, store.ErrNotFound):
+			NotFound(c, fmt.Sprintf("Content not found with ID: %d", id))
+		case errors.Is(err, services.ErrContentTooLarge):
+			PayloadTooLarge(c, err.Error())
+		case errors.Is(err, store.ErrDuplicate):
+			Conflict(c, "updated body duplicates existing content")
+		default:
+			Internal(c, fmt.Sprintf("UpdateContentHandler: failed to update content: %v", err))
 		}
-		return nil, nil, fmt.Errorf("fetchContentAndTagsForCategorization: %w", err)
+		return
 	}
 
-	tags, err := h.App.TagService.GetContentTags(c.Request.Context(), contentID)
+	tags, err := h.App.TagService.GetContentTags(c.Request.Context(), id)
 	if err != nil {
-		fmt.Printf("WARN: Failed to retrieve tags for content %d during categorization: %v\n", contentID, err)
+		fmt.Printf("WARN: Failed to retrieve tags for content %d: %v\n", id, err)
 		tags = []*models.Tag{}
 	}
-	return content, tags, nil
+	resp := GetContentResponse{
+		Content:        *content,
+		Tags:           tags,
+		EmbeddingState: h.App.ContentService.EmbeddingState(c.Request.Context(), content),
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
 }
 
-func (h *APIHandler) BatchCategorizeHandler(c *gin.Context) {
-	req, err := parseBatchCategorizeRequest(c)
+// ContentChunksHandler handles GET requests listing a content item's embedded
+// chunks. Vectors are omitted unless include_vector=true; precision rounds them.
+func (h *APIHandler) ContentChunksHandler(c *gin.Context) {
+	if h.rejectKeywordOnly(c) {
+		return
+	}
+	id, err := parseContentIDFromRequest(c)
 	if err != nil {
-		BadRequest(c, "Invalid request body: "+err.Error())
+		BadRequest(c, err.Error())
 		return
 	}
 
-	if h.App.CategorizationService == nil {
-		Internal(c, "Categorization service is not configured or enabled")
-		return
+	var opts services.ChunkListOptions
+	if v := c.Query("include_vector"); v != "" {
+		opts.IncludeVector, err = strconv.ParseBool(v)
+		if err != nil {
+			BadRequest(c, fmt.Sprintf("invalid include_vector: %s", v))
+			return
+		}
+	}
+	if p := c.Query("precision"); p != "" {
+		opts.Precision, err = strconv.Atoi(p)
+		if err != nil || opts.Precision < 1 || opts.Precision > services.MaxVectorPrecision {
+			BadRequest(c, fmt.Sprintf("invalid precision: %s (must be 1-%d)", p, services.MaxVectorPrecision))
+			return
+		}
 	}
 
-	resultsMap, err := h.App.CategorizationService.BatchCategorize(c.Request.Context(), req.ContentIDs)
+	chunks, err := h.App.ContentService.ListContentChunks(c.Request.Context(), id, h.App.VectorStore, opts)
 	if err != nil {
-		Internal(c, "Batch categorization failed: "+err.Error())
+		if errors.Is(err, store.ErrNotFound) {
+			NotFound(c, fmt.Sprintf("Content not found with ID: %d", id))
+			return
+		}
+		Internal(c, fmt.Sprintf("ContentChunksHandler: failed to list chunks: %v", err))
 		return
 	}
 
-	resp := make(map[string]interface{}, len(resultsMap))
-	for id, cats := range resultsMap {
-		resp[strconv.FormatInt(id, 10)] = gin.H{
-			"tags":       cats.Tags,
-			"category":   cats.Category,
-			"confidence": cats.Confidence,
+	c.JSON(http.StatusOK, gin.H{"data": chunks})
+}
+
+// ContentDiffHandler handles GET requests comparing the bodies of content a
+// and b, as a unified diff (mode=unified, the default) or word operations (mode=word).
+func (h *APIHandler) ContentDiffHandler(c *gin.Context) {
+	var ids [2]int64
+	for i, name := range []string{"a", "b"} {
+		v := c.Query(name)
+		if v == "" {
+			BadRequest(c, "missing required parameter: "+name)
+			return
+		}
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			BadRequest(c, fmt.Sprintf("invalid %s: %s", name, v))
+			return
+		}
+		ids[i] = id
+	}
+	mode, err := services.ParseDiffMode(c.Query("mode"))
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	diff, err := h.App.ContentService.DiffContent(c.Request.Context(), ids[0], ids[1], mode)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			NotFound(c, err.Error())
+			return
+		}
+		Internal(c, fmt.Sprintf("ContentDiffHandler: failed to diff content: %v", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": diff})
+}
+
+// PinContentHandler handles PATCH requests that pin or unpin content.
+func (h *APIHandler) PinContentHandler(c *gin.Context) {
+	id, err := parseContentIDFromRequest(c)
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	var req PinContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+	if req.Pinned == nil {
+		BadRequest(c, "missing required field: pinned")
+		return
+	}
+
+	content, err := h.App.ContentService.PinContent(c.Request.Context(), id, *req.Pinned)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			NotFound(c, fmt.Sprintf("Content not found with ID: %d", id))
+			return
+		}
+		Internal(c, fmt.Sprintf("PinContentHandler: failed to pin content: %v", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": content})
+}
+
+// TagByFilterHandler handles POST requests applying tags to all content
+// matching a query and/or filter.
+func (h *APIHandler) TagByFilterHandler(c *gin.Context) {
+	var req TagByFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+	tags := make([]string, 0, len(req.Tags))
+	for _, t := range req.Tags {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	if len(tags) == 0 {
+		BadRequest(c, "missing required field: tags")
+		return
+	}
+
+	filter := services.ListContentParams{Query: req.Query, FilterTags: req.FilterTags, Pinned: req.Pinned}
+	tagged, err := h.App.TagService.TagContentByFilter(c.Request.Context(), filter, tags)
+	if err != nil {
+		if errors.Is(err, services.ErrEmptyFilter) {
+			BadRequest(c, err.Error())
+			return
+		}
+		Internal(c, fmt.Sprintf("TagByFilterHandler: failed to tag content: %v", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"tagged": tagged, "tags": tags}})
+}
+
+// RehashContentHandler handles POST requests recomputing the dedup hash of the
+// given content, or of all content, under the current hashing rules.
+func (h *APIHandler) RehashContentHandler(c *gin.Context) {
+	var req RehashContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+	if req.All == (len(req.IDs) > 0) {
+		BadRequest(c, "exactly one of ids or all must be set")
+		return
+	}
+
+	var result *services.RehashResult
+	var err error
+	if req.All {
+		result, err = h.App.ContentService.RehashAllContent(c.Request.Context())
+	} else {
+		result, err = h.App.ContentService.RehashContent(c.Request.Context(), req.IDs)
+	}
+	if err != nil {
+		Internal(c, fmt.Sprintf("RehashContentHandler: failed to rehash content: %v", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
+// BatchGetContentHandler handles POST requests fetching several content items
+// with their tags. IDs that are not found are listed under not_found.
+func (h *APIHandler) BatchGetContentHandler(c *gin.Context) {
+	var req BatchGetContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+	if len(req.IDs) == 0 {
+		BadRequest(c, "missing required field: ids")
+		return
+	}
+	if max := h.App.Config.Defaults.MaxLimit(); len(req.IDs) > max {
+		BadRequest(c, fmt.Sprintf("too many ids: %d (maximum %d)", len(req.IDs), max))
+		return
+	}
+
+	items, err := h.App.ContentService.GetContentsWithTags(c.Request.Context(), req.IDs)
+	if err != nil {
+		Internal(c, fmt.Sprintf("BatchGetContentHandler: failed to get content: %v", err))
+		return
+	}
+
+	found := make(map[int64]bool, len(items))
+	for _, item := range items {
+		found[item.Content.ID] = true
+	}
+	notFound := []int64{}
+	for _, id := range req.IDs {
+		if !found[id] {
+			notFound = append(notFound, id)
+			found[id] = true // Report repeated IDs once
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items, "not_found": notFound})
+}
+
+// RecentContentHandler handles GET requests listing recently viewed content with
+// view counts, most recently viewed first.
+func (h *APIHandler) RecentContentHandler(c *gin.Context) {
+	h.listContentViews(c, "RecentContentHandler", h.App.ContentService.ListRecentlyViewed)
+}
+
+// PopularContentHandler handles GET requests listing viewed content by view
+// count, most viewed first.
+func (h *APIHandler) PopularContentHandler(c *gin.Context) {
+	h.listContentViews(c, "PopularContentHandler", h.App.ContentService.ListPopularContent)
+}
+
+// listContentViews parses the limit parameter and responds with the views returned by list.
+func (h *APIHandler) listContentViews(c *gin.Context, name string, list func(context.Context, int) ([]store.ContentView, error)) {
+	limit := h.App.Config.Defaults.PageLimit(0)
+	if l := c.Query("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed <= 0 {
+			BadRequest(c, fmt.Sprintf("invalid limit: %s", l))
+			return
+		}
+		limit = h.clampLimit(c, parsed)
+	}
+
+	views, err := list(c.Request.Context(), limit)
+	if err != nil {
+		Internal(c, fmt.Sprintf("%s: failed to list viewed content: %v", name, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": views})
+}
+
+// TagSuggestionsHandler handles GET requests for tag suggestions drawn from
+// semantically similar content.
+func (h *APIHandler) TagSuggestionsHandler(c *gin.Context) {
+	if h.rejectKeywordOnly(c) {
+		return
+	}
+	id, err := parseContentIDFromRequest(c)
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	k := h.App.Config.Defaults.SearchLimitFor(0)
+	if kParam := c.Query("k"); kParam != "" {
+		parsed, err := strconv.Atoi(kParam)
+		if err != nil || parsed <= 0 {
+			BadRequest(c, fmt.Sprintf("invalid k: %s", kParam))
+			return
+		}
+		k = h.clampLimit(c, parsed)
+	}
+
+	suggestions, err := h.App.TagService.SuggestTagsFromNeighbors(c.Request.Context(), id, k)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			NotFound(c, fmt.Sprintf("Content not found with ID: %d", id))
+		case errors.Is(err, services.ErrNotEmbedded):
+			Conflict(c, fmt.Sprintf("Content %d has not been embedded yet; tag suggestions are available once embedding completes", id))
+		default:
+			Internal(c, fmt.Sprintf("TagSuggestionsHandler: failed to suggest tags: %v", err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": suggestions})
+}
+
+// RelatedContentHandler handles GET requests for content semantically similar
+// to an existing item, using the item's own embedding as the query.
+func (h *APIHandler) RelatedContentHandler(c *gin.Context) {
+	if h.rejectKeywordOnly(c) {
+		return
+	}
+	id, err := parseContentIDFromRequest(c)
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	params := services.RelatedContentParams{
+		SourceContentID: id,
+		Limit:           h.App.Config.Defaults.SearchLimitFor(0),
+		FilterTags:      []string{},
+	}
+	if l := c.Query("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed <= 0 {
+			BadRequest(c, fmt.Sprintf("invalid limit: %s", l))
+			return
 		}
+		params.Limit = h.clampLimit(c, parsed)
+	}
+	if tagsParam := c.Query("tags"); tagsParam != "" {
+		for _, t := range strings.Split(tagsParam, ",") {
+			t = strings.TrimSpace(t)
+			if t != "" {
+				params.FilterTags = append(params.FilterTags, t)
+			}
+		}
+	}
+	if m := c.Query("min_score"); m != "" {
+		parsed, err := strconv.ParseFloat(m, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			BadRequest(c, fmt.Sprintf("invalid min_score: %s (must be between 0 and 1)", m))
+			return
+		}
+		params.MinScore = parsed
+	}
+
+	results, err := h.App.SearchService.FindRelatedContent(c.Request.Context(), params)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			NotFound(c, fmt.Sprintf("Content not found with ID: %d", id))
+		case errors.Is(err, services.ErrNotEmbedded):
+			Conflict(c, fmt.Sprintf("Content %d has not been embedded yet; related content is available once embedding completes", id))
+		default:
+			Internal(c, fmt.Sprintf("RelatedContentHandler: failed to find related content: %v", err))
+		}
+		return
+	}
+
+	h.respondWithSemanticSearchResults(c, results)
+}
+
+// ListCollectionsHandler handles GET requests to list collections.
+func (h *APIHandler) ListCollectionsHandler(c *gin.Context) {
+	limit := h.App.Config.Defaults.PageLimit(0)
+	if l := c.Query("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed <= 0 {
+			BadRequest(c, fmt.Sprintf("invalid limit: %s", l))
+			return
+		}
+		limit = h.clampLimit(c, parsed)
+	}
+
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		parsed, err := strconv.Atoi(o)
+		if err != nil || parsed < 0 {
+			BadRequest(c, fmt.Sprintf("invalid offset: %s", o))
+			return
+		}
+		offset = parsed
+	}
+
+	var pinned *bool
+	if p := c.Query("pinned"); p != "" {
+		parsed, err := strconv.ParseBool(p)
+		if err != nil {
+			BadRequest(c, fmt.Sprintf("invalid pinned: %s", p))
+			return
+		}
+		pinned = &parsed
+	}
+
+	collections, err := h.App.CollectionService.ListCollectionsPage(c.Request.Context(), limit, offset, pinned)
+	if err != nil {
+		Internal(c, fmt.Sprintf("ListCollectionsHandler: failed to list collections: %v", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": collections})
+}
+
+// DeleteCollectionHandler handles DELETE requests for a collection. Its content
+// items are kept; the response reports how many were removed from it.
+func (h *APIHandler) DeleteCollectionHandler(c *gin.Context) {
+	collectionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		BadRequest(c, fmt.Sprintf("Invalid collection ID format: %s", c.Param("id")))
+		return
+	}
+
+	removed, err := h.App.CollectionService.DeleteCollection(c.Request.Context(), collectionID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			NotFound(c, fmt.Sprintf("Collection not found with ID: %d", collectionID))
+			return
+		}
+		Internal(c, fmt.Sprintf("DeleteCollectionHandler: failed to delete collection %d: %v", collectionID, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{"id": collectionID, "content_removed": removed}})
+}
+
+// MergeCollectionsRequest defines the expected JSON body for merging collections.
+type MergeCollectionsRequest struct {
+	SourceID     int64 `json:"source_id" binding:"required"`
+	TargetID     int64 `json:"target_id" binding:"required"`
+	DeleteSource bool  `json:"delete_source"` // Delete the emptied source collection
+}
+
+// MergeCollectionsHandler handles POST requests moving all content of one
+// collection into another.
+func (h *APIHandler) MergeCollectionsHandler(c *gin.Context) {
+	var req MergeCollectionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	moved, err := h.App.CollectionService.MergeCollections(c.Request.Context(), req.SourceID, req.TargetID, req.DeleteSource)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrSameCollection):
+			BadRequest(c, err.Error())
+		case errors.Is(err, store.ErrNotFound):
+			NotFound(c, fmt.Sprintf("Collection not found with ID: %d or %d", req.SourceID, req.TargetID))
+		default:
+			Internal(c, fmt.Sprintf("MergeCollectionsHandler: failed to merge collection %d into %d: %v", req.SourceID, req.TargetID, err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"source_id":      req.SourceID,
+		"target_id":      req.TargetID,
+		"content_moved":  moved,
+		"source_deleted": req.DeleteSource,
+	}})
+}
+
+// ListWorkersHandler handles GET requests listing background workers with a
+// recent heartbeat.
+func (h *APIHandler) ListWorkersHandler(c *gin.Context) {
+	workers, err := h.App.WorkerService.ListActiveWorkers(c.Request.Context())
+	if err != nil {
+		Internal(c, fmt.Sprintf("ListWorkersHandler: failed to list workers: %v", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": workers})
+}
+
+// CostSummaryHandler handles GET requests for the AI cost and token totals of
+// the caller, broken down per service type. The optional from and to query
+// parameters take a date or RFC 3339 timestamp; a to date includes that day.
+func (h *APIHandler) CostSummaryHandler(c *gin.Context) {
+	var r store.UsageRange
+	var err error
+	if r.From, err = services.ParseUsageTime(c.Query("from"), false); err != nil {
+		BadRequest(c, "invalid from: "+err.Error())
+		return
+	}
+	if r.To, err = services.ParseUsageTime(c.Query("to"), true); err != nil {
+		BadRequest(c, "invalid to: "+err.Error())
+		return
+	}
+
+	summary, err := h.App.CostService.GetSummaryByService(c.Request.Context(), r)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidUsageRange) {
+			BadRequest(c, err.Error())
+			return
+		}
+		Internal(c, fmt.Sprintf("CostSummaryHandler: failed to get cost summary: %v", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": summary})
+}
+
+// StatsHandler handles GET requests for vector store statistics.
+// dimension_mismatch is true when the vector column dimension differs from
+// the active embedding model's dimension.
+func (h *APIHandler) StatsHandler(c *gin.Context) {
+	if h.rejectKeywordOnly(c) {
+		return
+	}
+	vectorStats, err := h.App.VectorStore.Stats(c.Request.Context())
+	if err != nil {
+		Internal(c, fmt.Sprintf("StatsHandler: failed to get vector store stats: %v", err))
+		return
+	}
+
+	modelDimension := 0
+	modelName := ""
+	if h.App.EmbeddingService != nil {
+		modelDimension = h.App.EmbeddingService.Dimension()
+		modelName = h.App.EmbeddingService.ModelName()
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"vector": vectorStats,
+		"embedding_model": gin.H{
+			"name":      modelName,
+			"dimension": modelDimension,
+		},
+		"dimension_mismatch":     vectorStats.Dimension > 0 && modelDimension > 0 && vectorStats.Dimension != modelDimension,
+		"estimated_vector_bytes": vectorStats.EmbeddingCount * int64(vectorStats.Dimension) * 4, // float32 components
+	}})
+}
+
+// HealthHandler handles GET /health. It reports each embedding provider with
+// its circuit breaker state; status is "degraded" while every breaker is open.
+// Keyword-only deployments report keyword_only instead of providers.
+func (h *APIHandler) HealthHandler(c *gin.Context) {
+	// TODO: Add checks for DB/Redis connectivity if needed
+	resp := gin.H{"status": "ok"}
+	if fallback, ok := h.App.EmbeddingService.(*services.FallbackEmbeddingService); ok {
+		infos := fallback.ProviderInfos()
+		providers := make([]gin.H, len(infos))
+		available := false
+		for i, info := range infos {
+			providers[i] = gin.H{
+				"name":    info.Name,
+				"model":   info.Model,
+				"status":  info.Status.String(),
+				"breaker": info.Breaker,
+				"active":  info.Active,
+			}
+			available = available || info.Breaker != services.BreakerOpen
+		}
+		resp["embedding_providers"] = providers
+		if len(infos) > 0 && !available {
+			resp["status"] = "degraded"
+		}
+	}
+	if h.App.KeywordOnly {
+		resp["keyword_only"] = true
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// TagGraphHandler handles GET requests for the tag co-occurrence graph.
+func (h *APIHandler) TagGraphHandler(c *gin.Context) {
+	minCount := 1
+	if m := c.Query("min_count"); m != "" {
+		parsed, err := strconv.Atoi(m)
+		if err != nil || parsed <= 0 {
+			BadRequest(c, fmt.Sprintf("Invalid min_count: %s", m))
+			return
+		}
+		minCount = parsed
+	}
+
+	graph, err := h.App.TagService.GetTagCooccurrence(c.Request.Context(), minCount)
+	if err != nil {
+		Internal(c, fmt.Sprintf("TagGraphHandler: failed to build tag graph: %v", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": graph})
+}
+
+// TagAutocompleteHandler handles GET requests for tags starting with a prefix, most used first.
+func (h *APIHandler) TagAutocompleteHandler(c *gin.Context) {
+	limit := 10
+	if l := c.Query("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed <= 0 {
+			BadRequest(c, fmt.Sprintf("Invalid limit: %s", l))
+			return
+		}
+		limit = h.clampLimit(c, parsed)
+	}
+
+	usages, err := h.App.TagService.AutocompleteTags(c.Request.Context(), c.Query("prefix"), limit)
+	if err != nil {
+		Internal(c, fmt.Sprintf("TagAutocompleteHandler: failed to search tags: %v", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": usages})
+}
+
+func (h *APIHandler) CategorizeContentHandler(c *gin.Context) {
+	contentID, err := parseContentIDFromRequest(c)
+	if err != nil {
+		BadRequest(c, err.Error())
+		return
+	}
+
+	content, tags, err := h.fetchContentAndTagsForCategorization(c, contentID)
+	if err != nil {
+		return
+	}
+
+	existingTagNames := make([]string, len(tags))
+	for i, t := range tags {
+		existingTagNames[i] = t.Name
+	}
+
+	if h.App.CategorizationService == nil {
+		Internal(c, "Categorization service is not configured or enabled")
+		return
+	}
+
+	cats, err := h.App.CategorizationService.CategorizeContent(c.Request.Context(), content.Title, content.Body, existingTagNames)
+	if err != nil {
+		Internal(c, "Categorization failed: "+err.Error())
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"results": resp,
+		"content_id": contentID,
+		"tags":       cats.Tags,
+		"category":   cats.Category,
+		"confidence": cats.Confidence,
 	})
 }
 
+// parseContentIDFromRequest parses the content ID from path or query.
+func parseContentIDFromRequest(c *gin.Context) (int64, error) {
+	idStr := c.Param("id")
+	if idStr == "" {
+		idStr = c.Query("id")
+	}
+	if idStr == "" {
+		return 0, fmt.Errorf("Missing content ID parameter (expected in path /:id or query ?id=)")
+	}
+	contentID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid content ID format: %s", idStr)
+	}
+	return contentID, nil
+}
+
+// fetchContentAndTagsForCategorization fetches content and tags, and handles errors.
+func (h *APIHandler) fetchContentAndTagsForCategorization(c *gin.Context, contentID int64) (*models.Content, []*models.Tag, error) {
+	content, err := h.App.ContentService.GetContent(c.Request.Context(), contentID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			NotFound(c, fmt.Sprintf("Content not found with ID: %d", contentID))
+		} else {
+			Internal(c, fmt.Sprintf("fetchContentAndTagsForCategorization: failed to fetch content: %v", err))
+		}
+		return nil, nil, fmt.Errorf("fetchContentAndTagsForCategorization: %w", err)
+	}
+
+	tags, err := h.App.TagService.GetContentTags(c.Request.Context(), contentID)
+	if err != nil {
+		fmt.Printf("WARN: Failed to retrieve tags for content %d during categorization: %v\n", contentID, err)
+		tags = []*models.Tag{}
+	}
+	return content, tags, nil
+}
+
+// BatchCategorizeHandler handles POST requests suggesting categories for several
+// content items; apply=true also applies the suggestions within the configured
+// confidence and tag limits.
+func (h *APIHandler) BatchCategorizeHandler(c *gin.Context) {
+	apply := false
+	if v := c.Query("apply"); v != "" {
+		var err error
+		if apply, err = strconv.ParseBool(v); err != nil {
+			BadRequest(c, fmt.Sprintf("invalid apply: %s", v))
+			return
+		}
+	}
+	req, err := parseBatchCategorizeRequest(c)
+	if err != nil {
+		BadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	if h.App.CategorizationService == nil {
+		Internal(c, "Categorization service is not configured or enabled")
+		return
+	}
+
+	resultsMap, err := h.App.CategorizationService.BatchCategorize(c.Request.Context(), req.ContentIDs)
+	if err != nil {
+		Internal(c, "Batch categorization failed: "+err.Error())
+		return
+	}
+
+	resp := gin.H{
+		"results": categorizationResults(resultsMap),
+		"applied": apply,
+	}
+	if apply {
+		applyResult, err := h.App.CategorizationService.ApplyBatchSuggestions(c.Request.Context(), resultsMap, true)
+		if err != nil {
+			Internal(c, "Applying categorization suggestions failed: "+err.Error())
+			return
+		}
+		resp["apply_result"] = applyResult
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// CategorizeCollectionHandler handles POST requests re-running categorization
+// over every member of a collection; auto_apply=true also applies the suggestions.
+func (h *APIHandler) CategorizeCollectionHandler(c *gin.Context) {
+	collectionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		BadRequest(c, fmt.Sprintf("Invalid collection ID format: %s", c.Param("id")))
+		return
+	}
+	autoApply := false
+	if v := c.Query("auto_apply"); v != "" {
+		if autoApply, err = strconv.ParseBool(v); err != nil {
+			BadRequest(c, fmt.Sprintf("invalid auto_apply: %s", v))
+			return
+		}
+	}
+
+	if h.App.CategorizationService == nil {
+		Internal(c, "Categorization service is not configured or enabled")
+		return
+	}
+
+	resultsMap, err := h.App.CategorizationService.CategorizeCollection(c.Request.Context(), collectionID, autoApply)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			NotFound(c, fmt.Sprintf("Collection not found with ID: %d", collectionID))
+			return
+		}
+		if errors.Is(err, services.ErrCollectionTooLarge) {
+			PayloadTooLarge(c, err.Error())
+			return
+		}
+		Internal(c, "Collection categorization failed: "+err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": categorizationResults(resultsMap),
+		"applied": autoApply,
+	})
+}
+
+// categorizationResults shapes batch categorization suggestions keyed by content ID.
+func categorizationResults(resultsMap map[int64]*services.ContentWithCategories) map[string]interface{} {
+	resp := make(map[string]interface{}, len(resultsMap))
+	for id, cats := range resultsMap {
+		resp[strconv.FormatInt(id, 10)] = gin.H{
+			"tags":       cats.Tags,
+			"category":   cats.Category,
+			"confidence": cats.Confidence,
+		}
+	}
+	return resp
+}
+
 // parseBatchCategorizeRequest parses and validates the batch categorize request.
 func parseBatchCategorizeRequest(c *gin.Context) (struct {
 	ContentIDs []int64 `json:"content_ids"`
@@ -440,19 +1527,88 @@ type AddContentRequest struct {
 	Source string `json:"source"` // Name of the source (e.g., "Web Upload", "API Import")
 	Title  string `json:"title"`  // Title of the content
 	Input  string `json:"input"`  // The raw input: file path, URL, or text content
+	// SourceType identifies the integration (e.g. "slack-bot"); it must be in
+	// content.allowed_source_types and defaults to "api". It is recorded when the source is created.
+	SourceType string `json:"source_type,omitempty"`
+	// Visibility is "private" (owner only) or "shared"; it defaults to content.default_visibility.
+	Visibility string `json:"visibility,omitempty"`
 	// ContentType is removed, it will be detected by the processor
 }
 
+// AppendContentRequest represents the JSON body to append text to content
+type AppendContentRequest struct {
+	Text string `json:"text"` // Appended on a new line
+}
+
+// UpdateContentRequest represents the JSON body to edit content; omitted fields are unchanged
+type UpdateContentRequest struct {
+	Title    *string                `json:"title"`
+	Body     *string                `json:"body"`     // Re-embeds the content when it changes
+	Metadata map[string]interface{} `json:"metadata"` // Replaces the stored metadata
+}
+
+// PinContentRequest represents the JSON body to pin or unpin content
+type PinContentRequest struct {
+	Pinned *bool `json:"pinned"` // Required; true pins, false unpins
+}
+
+// TagByFilterRequest represents the JSON body to tag all content matching a filter
+type TagByFilterRequest struct {
+	Query      string   `json:"query"`       // Full-text match on title and body
+	FilterTags []string `json:"filter_tags"` // Content carrying any of these tag names
+	Pinned     *bool    `json:"pinned"`      // Content with this pinned state
+	Tags       []string `json:"tags"`        // Required; tag names to apply
+}
+
+// RehashContentRequest represents the JSON body to recompute content hashes
+type RehashContentRequest struct {
+	IDs []int64 `json:"ids"` // Content to rehash
+	All bool    `json:"all"` // Rehash all content instead
+}
+
+// BatchGetContentRequest represents the JSON body to fetch several content items
+type BatchGetContentRequest struct {
+	IDs []int64 `json:"ids"` // Content to fetch; at most defaults.max_page_size
+}
+
+// ReassignSourceRequest represents the JSON body to move content to another source
+type ReassignSourceRequest struct {
+	Source string `json:"source"` // Name of the target source; created if it does not exist
+}
+
+// BatchSearchRequest represents the JSON body for a batch semantic search
+type BatchSearchRequest struct {
+	Queries []string `json:"queries"`
+	Limit   int      `json:"limit"` // Results per query; 0 uses the default
+}
+
 // AddContentResponse represents the JSON response after adding content
 type AddContentResponse struct {
-	Content models.Content `json:"content"`
-	Existed bool           `json:"existed"`
+	Content     models.Content `json:"content"`
+	Existed     bool           `json:"existed"`
+	DuplicateOf *DuplicateRef  `json:"duplicate_of,omitempty"`
+}
+
+// DuplicateRef identifies the existing content an add request was deduplicated against.
+type DuplicateRef struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+// duplicateRef returns the duplicate reference for an add response, or nil for new content.
+// When existed is true the service has already replaced content with the stored row.
+func duplicateRef(content *models.Content, existed bool) *DuplicateRef {
+	if !existed {
+		return nil
+	}
+	return &DuplicateRef{ID: content.ID, Title: content.Title}
 }
 
 // GetContentResponse represents the JSON response for a single content item
 type GetContentResponse struct {
-	Content models.Content `json:"content"`
-	Tags    []*models.Tag  `json:"tags"`
+	Content        models.Content `json:"content"`
+	Tags           []*models.Tag  `json:"tags"`
+	EmbeddingState string         `json:"embedding_state"` // embedded, pending or failed
 }
 
 type DummyContentService struct{}
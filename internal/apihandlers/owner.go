@@ -0,0 +1,28 @@
+package apihandlers
+
+import (
+	"mimir/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OwnerIDKey is the gin context key under which authentication middleware
+// stores the identity (user or API key) of the caller.
+const OwnerIDKey = "owner_id"
+
+// OwnerMiddleware scopes each request's context to the caller identified by
+// OwnerIDKey, so data is added under that owner and listings and searches see
+// only its data plus shared content. Unless multiTenant is set, or without an
+// identity (authentication disabled), requests act as the default "public" owner.
+func OwnerMiddleware(multiTenant bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !multiTenant {
+			c.Next()
+			return
+		}
+		if ownerID := c.GetString(OwnerIDKey); ownerID != "" {
+			c.Request = c.Request.WithContext(services.WithOwner(c.Request.Context(), ownerID))
+		}
+		c.Next()
+	}
+}
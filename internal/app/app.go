@@ -2,9 +2,12 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/sashabaranov/go-openai" // Add openai import
+	"mimir/internal/chunking"
 	"mimir/internal/config"             // Add config import
 	"mimir/internal/inputprocessor"     // Add inputprocessor import
 	"mimir/internal/costtracker"        // Add costtracker import
@@ -34,6 +37,11 @@ type App struct {
 	SearchHistoryStore store.SearchHistoryStore
 	JobStore           store.JobStore          // Add JobStore field
 	CostStore          store.CostTrackingStore // Add CostStore field
+	ReindexRunStore    store.ReindexRunStore
+	TxRunner           store.TxRunner
+	ContentAccessStore store.ContentAccessStore
+	HeartbeatStore     store.WorkerHeartbeatStore
+	ContentLocker      store.ContentLocker
 	CostTracker        costtracker.CostTracker // Add CostTracker field
 
 	CategorizationService *services.CategorizationService // Add CategorizationService field
@@ -47,9 +55,27 @@ type App struct {
 	BatchService      *services.BatchService    // Add BatchService field
 	BatchAPIProvider  services.BatchAPIProvider // Add BatchAPIProvider field
 	CostService       *services.CostService // Add CostService field
-	// RAGService        *services.RAGService      // Commented out - undefined
+	ReindexService    *services.ReindexService
+	MetadataSyncer    *services.EmbeddingMetadataSyncer // Handles embedding metadata update jobs
+	AppendEmbedder    *services.AppendEmbedder          // Handles incremental embedding of appended content
+	BatchEmbedder     *services.BatchEmbedder           // Embeds the content of batch embedding jobs together
+	CompactionService *services.CompactionService       // Archives embeddings of rarely used content
+	WorkerService     *services.WorkerService           // Worker heartbeats and liveness listing
+	DeadLetterService *services.DeadLetterService       // Jobs that exhausted their retries
+	EmbeddingJobGuard *services.EmbeddingJobGuard       // Runs one embedding job per content at a time
+	RAGService        *services.RAGService              // Answers questions from stored content; nil unless rag.enabled
 
 	SummaryService services.SummaryService // Expose summary service for worker registration
+	ChunkOverlap   chunking.Overlap        // Parsed chunking.overlap, for the embedding worker
+
+	EmbeddingInputTemplate *services.EmbeddingInputTemplate // Parsed embedding.input_template, for the embedding worker
+
+	// KeywordOnly is set in mode keyword-only, or when no embedding provider is
+	// available and embedding.required is false: embedding jobs are not
+	// enqueued and semantic search fails with services.ErrEmbeddingDisabled.
+	KeywordOnly bool
+
+	bufferedCosts *costtracker.BufferedStore // Wraps CostStore when cost.buffered_recording is set
 }
 
 func NewApp(cfg *config.Config, inputProc inputprocessor.Processor) (*App, error) {
@@ -91,10 +117,10 @@ func NewApp(cfg *config.Config, inputProc inputprocessor.Processor) (*App, error
 		app.cleanupPartialInit()
 		return nil, err
 	}
-	// if err := app.initRAGService(); err != nil { // Initialize RAG Service (after core services)
-	// 	app.cleanupPartialInit()
-	// 	return nil, err
-	// }
+	if err := app.initRAGService(); err != nil { // Initialize RAG Service (after core services)
+		app.cleanupPartialInit()
+		return nil, err
+	}
 
 	log.Println("Application initialization complete.")
 	return app, nil
@@ -103,10 +129,11 @@ func NewApp(cfg *config.Config, inputProc inputprocessor.Processor) (*App, error
 // --- Private Helper Methods ---
 
 func (a *App) initPrimaryStore(ctx context.Context) error {
-	ps, err := primary.NewPrimaryStore(ctx, a.Config.Database.Primary.DSN)
+	ps, err := primary.NewPrimaryStore(ctx, a.Config.Database.Primary.DSN, a.Config.Database.Primary.Pool.MaxConnections)
 	if err != nil {
 		return fmt.Errorf("init primary store: %w", err)
 	}
+	ps.SetKeywordUnaccent(a.Config.Database.KeywordUnaccent)
 	a.ContentStore = ps
 	a.TagStore = ps
 	a.SourceStore = ps
@@ -114,6 +141,15 @@ func (a *App) initPrimaryStore(ctx context.Context) error {
 	a.SearchHistoryStore = ps
 	a.JobStore = ps
 	a.CostStore = ps // StoreImpl implements CostTrackingStore
+	if a.Config.Cost.BufferedRecording {
+		a.bufferedCosts = costtracker.NewBufferedStore(ps, a.Config.Cost.FlushSize, a.Config.Cost.FlushInterval)
+		a.CostStore = a.bufferedCosts
+	}
+	a.ReindexRunStore = ps
+	a.TxRunner = ps
+	a.ContentAccessStore = ps
+	a.HeartbeatStore = ps
+	a.ContentLocker = ps
 	a.CostTracker = costtracker.New() // Initialize the cost tracker service
 	return nil
 }
@@ -123,6 +159,7 @@ func (a *App) initJobClient() error {
 	if err != nil {
 		return fmt.Errorf("init job client: %w", err)
 	}
+	jc.SetMaxRetry(a.Config.Worker.MaxRetry)
 	a.JobClient = jc
 	return nil
 }
@@ -131,6 +168,12 @@ func (a *App) initEmbeddingService() error {
 	var providers []services.EmbeddingProvider
 	cfg := a.Config
 
+	if cfg.KeywordOnly() {
+		log.Println("Running keyword-only (mode: keyword-only): embedding providers, the vector store and embedding jobs are disabled.")
+		a.useKeywordOnly()
+		return nil
+	}
+
 	// Initialize OpenAI provider if enabled
 	if cfg.Embedding.OpenaiApiKey != "" { // Check if API key is provided as indicator of enablement
 		if cfg.Embedding.OpenaiApiKey == "" { // Redundant check, but keeps structure
@@ -142,8 +185,12 @@ func (a *App) initEmbeddingService() error {
 			cfg.Embedding.Model, // Assuming a single model for now, adjust if needed
 			a.CostStore, // Pass CostStore
 			cfg.Pricing["openai"], // Pass OpenAI pricing map
+			embeddingModelOptions(cfg),
 		)
 		if err != nil {
+			if cfg.Embedding.StrictModel || errors.Is(err, services.ErrUnsupportedDimensions) {
+				return fmt.Errorf("init OpenAI embedding provider: %w", err)
+			}
 			log.Printf("WARN: Failed to initialize OpenAI provider: %v", err)
 			// Continue to try other providers
 		} else if openaiProvider != nil {
@@ -165,20 +212,52 @@ func (a *App) initEmbeddingService() error {
 	// if err == nil && localProvider != nil { providers = append(providers, localProvider) }
 
 	if len(providers) == 0 {
-		log.Println("WARN: No embedding providers were successfully initialized. Embedding generation will fail.")
-		// Optionally return an error if no providers are essential
-		// return fmt.Errorf("no embedding providers configured or initialized successfully")
+		if cfg.Embedding.Required {
+			return fmt.Errorf("no embedding provider is available: set embedding.openai_api_key (or OPENAI_API_KEY), or set embedding.required to false to run keyword-only")
+		}
+		log.Println("WARN: No embedding provider is available and embedding.required is false: running keyword-only. Content is not embedded and semantic search is unavailable.")
+		a.useKeywordOnly()
+		return nil
 	}
 
+	if cfg.Embedding.Strategy == "parallel" {
+		log.Println("WARN: embedding.strategy \"parallel\" is deprecated and behaves as \"fallback\"; set fallback or lowest_cost")
+	}
+	providers = services.OrderProviders(providers, services.ProviderOrder{
+		Order:    cfg.Embedding.ProviderOrder,
+		Primary:  cfg.Embedding.Primary,
+		Strategy: cfg.Embedding.Strategy,
+		Pricing:  cfg.Pricing,
+		Models:   embeddingModelOptions(cfg),
+	})
+
 	retryStrategy := &services.SimpleRetryStrategy{MaxAttempts: 3, BaseDelayMs: 200}
 	embeddingService, err := services.NewFallbackEmbeddingService(providers, retryStrategy)
 	if err != nil {
 		return fmt.Errorf("init embedding service: %w", err)
 	}
+	embeddingService.SetCircuitBreaker(cfg.Embedding.CircuitBreaker.FailureThreshold, cfg.Embedding.CircuitBreaker.Cooldown)
 	a.EmbeddingService = embeddingService
 	return nil
 }
 
+// useKeywordOnly replaces the embedding service with one failing with
+// services.ErrEmbeddingDisabled and stops embedding jobs from being enqueued.
+func (a *App) useKeywordOnly() {
+	a.EmbeddingService = services.NewNoopEmbeddingService(a.Config.Embedding.Dimension)
+	a.JobClient = store.WithoutEmbeddingJobs(a.JobClient)
+	a.KeywordOnly = true
+}
+
+// embeddingModelOptions builds the model dimension options passed to embedding providers.
+func embeddingModelOptions(cfg *config.Config) services.EmbeddingModelOptions {
+	return services.EmbeddingModelOptions{
+		Models:     cfg.Embedding.Models,
+		Strict:     cfg.Embedding.StrictModel,
+		Dimensions: cfg.Embedding.Dimensions,
+	}
+}
+
 func (a *App) initCompletionService() error {
 	cfg := a.Config
 	if !cfg.RAG.Enabled {
@@ -191,19 +270,33 @@ func (a *App) initCompletionService() error {
 
 	switch cfg.RAG.Provider {
 	case "gemini":
-		// Assuming GeminiProvider's New function signature is updated
 		// Note: Using Embedding API Key and Model Name for now. Consider separate config if needed.
-		completer, err = services.NewGeminiProvider( // Call with correct args
-			cfg.Embedding.GoogleApiKey, // Reuse embedding key for now
-			cfg.Embedding.GeminiModelName, // Embedding model // TODO: This field doesn't exist in current config (config.go:31), needs update in config or here
+		completionModel := cfg.RAG.Model
+		if completionModel == "" {
+			completionModel = services.DefaultGeminiCompletionModel
+		}
+		completer, err = services.NewGeminiProvider(
+			cfg.Embedding.GoogleApiKey,    // Reuse embedding key for now
+			cfg.Embedding.GeminiModelName, // Embedding model
+			completionModel,
+			embeddingModelOptions(cfg),
 		)
-		// The following arguments are not expected by NewGeminiProvider:
-		// cfg.RAG.Model, a.CostStore, cfg.Pricing
+		// Not yet passed to NewGeminiProvider: a.CostStore, cfg.Pricing
 
 		if err != nil {
 			return fmt.Errorf("failed to initialize Gemini completion provider: %w", err)
 		}
-	// case "openai": // Add OpenAI completion provider initialization here if needed
+	case "openai":
+		completionModel := cfg.RAG.Model
+		if completionModel == "" {
+			completionModel = services.DefaultOpenAICompletionModel
+		}
+		completer = services.NewOpenAICompletionProvider(
+			cfg.Embedding.OpenaiApiKey,
+			completionModel,
+			a.CostStore,
+			cfg.Pricing["openai"],
+		)
 	default:
 		return fmt.Errorf("unknown or unsupported RAG provider configured: %s", cfg.RAG.Provider)
 	}
@@ -217,7 +310,7 @@ func (a *App) initSummaryService() error {
 		switch cfg.Summarization.Provider {
 		case "openai":
 			// Load prompt using the new helper function
-			promptContent, err := config.LoadPromptContent(cfg.Summarization.Prompt, "summarize.txt")
+			promptContent, err := config.LoadPromptContent(cfg.Summarization.Prompt, config.DefaultSummarizationPrompt)
 			if err != nil {
 				log.Warnf("Failed to load summarization prompt: %v. Summarization might not work correctly.", err)
 				// Optionally return err here if prompt is mandatory: return fmt.Errorf("load summarization prompt: %w", err)
@@ -242,11 +335,15 @@ func (a *App) initSummaryService() error {
 }
 
 func (a *App) initBatchAPIProvider() error {
+	if a.Config.KeywordOnly() {
+		return nil // Batch embedding is not used in keyword-only mode
+	}
 	// Pass CostStore and Pricing info
 	batchAPIProvider, err := services.NewOpenAIBatchProvider(
 		a.Config.Embedding.OpenaiApiKey,
 		a.CostStore,
 		a.Config.Pricing["openai"], // Pass config.PricingInfo map
+		embeddingModelOptions(a.Config),
 	)
 	if err != nil {
 		return fmt.Errorf("init OpenAI Batch API provider: %w", err)
@@ -257,14 +354,17 @@ func (a *App) initBatchAPIProvider() error {
 
 func (a *App) initVectorStore(ctx context.Context) error {
 	cfg := a.Config
+	if cfg.KeywordOnly() {
+		return nil // No vector store in keyword-only mode; a.VectorStore stays nil
+	}
 	if cfg.Database.Vector.DSN == "" {
 		return fmt.Errorf("vector store DSN (Database.Vector.DSN) is required but not configured")
 	}
-	vectorStore, err := vector.NewStore(ctx, cfg.Database.Vector.DSN)
+	vectorStore, err := vector.NewStore(ctx, cfg.Database.Vector.DSN, cfg.Database.Vector.Metric)
 	if err != nil {
 		return fmt.Errorf("init postgres vector store: %w", err)
 	}
-	a.VectorStore = vectorStore
+	a.VectorStore = vector.WithRetry(vectorStore, cfg.Database.Vector.MaxRetries)
 	return nil
 }
 
@@ -279,7 +379,7 @@ func (a *App) initCategorizationService() error {
 			}
 			openaiClient := openai.NewClient(cfg.Embedding.OpenaiApiKey)
 			// Load prompt using the new helper function
-			promptContent, err := config.LoadPromptContent(cfg.Categorization.PromptTemplate, "categorize.txt")
+			promptContent, err := config.LoadPromptContent(cfg.Categorization.PromptTemplate, config.DefaultCategorizationPrompt)
 			if err != nil {
 				// Log the error and potentially disable categorization or return the error
 				log.Warnf("Failed to load categorization prompt: %v. LLM Categorization might not work correctly.", err)
@@ -299,6 +399,8 @@ func (a *App) initCategorizationService() error {
 	tagService := services.NewTagService(a.TagStore)
 	collectionService := services.NewCollectionService(a.CollectionStore, a.ContentStore, a.TagStore)
 	a.CategorizationService = services.NewCategorizationService(contentCategorizer, tagService, collectionService, a.ContentStore)
+	a.CategorizationService.SetApplyLimits(cfg.Categorization.MinConfidence, cfg.Categorization.MaxTags)
+	a.CategorizationService.SetMaxCollectionSize(cfg.Categorization.MaxCollectionSize)
 	return nil
 }
 
@@ -317,6 +419,10 @@ func (a *App) initCoreServices(inputProc inputprocessor.Processor) error {
 		TaggingService:        services.NewNoopTaggingService(),
 		CategorizationService: a.CategorizationService,
 		Config:                cfg,
+		TxRunner:              a.TxRunner,
+		AccessStore:           a.ContentAccessStore,
+		CollectionStore:       a.CollectionStore,
+		CompletionService:     a.CompletionService,
 	})
 	// Need the concrete primary store that implements KeywordSearcher
 	ps, ok := a.ContentStore.(*primary.StoreImpl) // Type assertion for KeywordSearcher
@@ -326,8 +432,52 @@ func (a *App) initCoreServices(inputProc inputprocessor.Processor) error {
 	}
 	// Pass the concrete store for both ContentStore and KeywordSearcher interfaces
 	a.SearchService = services.NewSearchService(ps, ps, a.VectorStore, a.EmbeddingService, a.SearchHistoryStore)
+	a.SearchService.SetDefaults(cfg.Defaults)
+	a.SearchService.SetDistanceMetric(cfg.Database.Vector.Metric)
+	a.SearchService.SetCollectionStore(a.CollectionStore)
+	a.TagService.SetRelatedContentFinder(a.SearchService)
+	a.TagService.SetJobClient(a.JobClient)
+	a.TagService.SetContentStore(a.ContentStore)
+	if cfg.Search.RerankEnabled {
+		a.SearchService.SetReranker(services.NewLexicalReranker(), cfg.Search.RerankCandidates)
+	}
+	a.SearchService.SetRecordHistory(cfg.Search.RecordHistory)
+	a.SearchService.SetBooster(services.NewScoreBooster(cfg.Search.Boost.RecencyHalfLife, cfg.Search.Boost.SourceWeights, a.SourceStore))
+	a.SearchService.SetTagStore(a.TagStore)
+	a.SearchService.SetHybridWeights(cfg.Search.Hybrid.KeywordWeight, cfg.Search.Hybrid.SemanticWeight)
 	a.BatchService = services.NewBatchService(a.JobStore)
 	a.CostService = services.NewCostService(a.CostStore) // Initialize CostService
+	a.ReindexService = services.NewReindexService(a.ContentStore, a.ReindexRunStore, a.JobClient)
+	a.MetadataSyncer = services.NewEmbeddingMetadataSyncer(a.ContentStore, a.TagStore, a.VectorStore)
+	a.CompactionService = services.NewCompactionService(a.ContentStore, a.VectorStore)
+	a.WorkerService = services.NewWorkerService(a.HeartbeatStore, cfg.Worker.HeartbeatInterval)
+	a.DeadLetterService = services.NewDeadLetterService(a.JobStore, a.JobClient)
+	a.DeadLetterService.SetContentStore(a.ContentStore)
+	overlap, err := chunking.ParseOverlap(cfg.Chunking.Overlap)
+	if err != nil {
+		return fmt.Errorf("chunking.overlap: %w", err)
+	}
+	a.ChunkOverlap = overlap
+	inputTemplate, err := services.NewEmbeddingInputTemplate(cfg.Embedding.InputTemplate)
+	if err != nil {
+		return fmt.Errorf("embedding.input_template: %w", err)
+	}
+	a.EmbeddingInputTemplate = inputTemplate
+	a.ReindexService.SetInputVersion(inputTemplate.Version())
+	a.AppendEmbedder = services.NewAppendEmbedder(a.ContentStore, a.TagStore, a.VectorStore, a.EmbeddingService, a.JobClient,
+		cfg.Chunking.MaxTokens, overlap)
+	a.AppendEmbedder.SetInputTemplate(inputTemplate)
+	a.AppendEmbedder.SetMaxChunks(cfg.Chunking.MaxChunksPerDoc)
+	a.AppendEmbedder.SetDedupChunks(cfg.Chunking.DedupChunks)
+	a.BatchEmbedder = services.NewBatchEmbedder(a.ContentStore, a.TagStore, a.VectorStore, a.EmbeddingService,
+		cfg.Chunking.MaxTokens, overlap)
+	a.BatchEmbedder.SetInputTemplate(inputTemplate, a.SourceStore)
+	a.BatchEmbedder.SetMaxChunks(cfg.Chunking.MaxChunksPerDoc)
+	a.BatchEmbedder.SetDedupChunks(cfg.Chunking.DedupChunks)
+	if a.ContentLocker != nil {
+		a.BatchEmbedder.SetLocker(a.ContentLocker)
+	}
+	a.EmbeddingJobGuard = services.NewEmbeddingJobGuard(a.ContentLocker, a.ContentStore)
 	return nil
 }
 
@@ -342,19 +492,32 @@ func (a *App) initRAGService() error {
 		return nil // Not a fatal error, but RAG won't work
 	}
 
-	// ragService, err := services.NewRAGService( // Keep commented out due to potential undefined: services.RAGService
-	// 	*a.SearchService,         // Pass initialized SearchService
-	// 	a.CompletionService,      // Pass initialized CompletionService
-	// 	config.LoadPromptContent, // Pass the prompt loader function
-	// 	cfg.RAG,                  // Pass the RAG config section
-	// )
-	// a.RAGService = ragService // Assign even if err != nil, caller checks RAGService field
-	// return err // Return the error from NewRAGService
-	return nil // Add missing return
-	return nil // Add missing return
+
+	promptContent, err := config.LoadPromptContent(cfg.RAG.Prompt, config.DefaultRAGPrompt)
+	if err != nil {
+		if cfg.RAG.Prompt != "" {
+			log.Warnf("Failed to load RAG prompt: %v. Using the built-in prompt.", err)
+		}
+		promptContent = "" // NewRAGService falls back to services.DefaultRAGPromptTemplate
+	}
+	a.RAGService = services.NewRAGService(a.SearchService, a.CompletionService, promptContent)
+	return nil
+}
+
+// Close writes buffered AI usage logs and releases the app's connections.
+// Commands call it once they finish, so no recorded usage is lost on exit.
+func (a *App) Close() {
+	a.cleanupPartialInit()
 }
 
 func (a *App) cleanupPartialInit() {
+	if a.bufferedCosts != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := a.bufferedCosts.Close(ctx); err != nil {
+			log.Printf("Error flushing buffered AI usage logs: %v", err)
+		}
+		cancel()
+	}
 	if a.JobClient != nil {
 		a.JobClient.Close()
 	}
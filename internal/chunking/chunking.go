@@ -2,6 +2,7 @@ package chunking
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"log"
 	"strings"
@@ -31,8 +32,9 @@ type Chunk struct {
 }
 
 // ContentAwareChunk selects the appropriate chunking strategy based on content type
-// and metadata overrides, then executes it.
-func ContentAwareChunk(content *models.Content, maxTokens, overlap int) []Chunk {
+// and metadata overrides, then executes it. maxChunks caps the number of chunks
+// returned (see CapChunks); 0 means no cap.
+func ContentAwareChunk(content *models.Content, maxTokens int, overlap Overlap, maxChunks int) []Chunk {
 	ctx := context.Background() // Use a background context for chunking logic
 
 	// Determine the target chunker type
@@ -110,11 +112,81 @@ func ContentAwareChunk(content *models.Content, maxTokens, overlap int) []Chunk
 
 	log.Printf("Successfully chunked content %d using '%s' strategy. Generated %d chunks.", content.ID, targetChunkerType, len(chunks))
 
-	// Ensure parser type and total chunks are set in metadata for all generated chunks
+	if maxChunks > 0 && len(chunks) > maxChunks {
+		log.Printf("WARN: Content %d produced %d chunks, over the limit of %d (chunking.max_chunks_per_doc). Dropping the remaining %d chunks.",
+			content.ID, len(chunks), maxChunks, len(chunks)-maxChunks)
+		chunks = CapChunks(chunks, maxChunks)
+	}
+
+	stampChunkMetadata(chunks, targetChunkerType)
+	return chunks
+}
+
+// CapChunks keeps the first maxChunks chunks, so pathological input (such as
+// thousands of one-word lines) cannot trigger an embedding call per chunk. The
+// tail is dropped rather than coalesced into one chunk, which would exceed the
+// embedding provider's input limit. maxChunks <= 0 returns chunks unchanged.
+func CapChunks(chunks []Chunk, maxChunks int) []Chunk {
+	if maxChunks <= 0 || len(chunks) <= maxChunks {
+		return chunks
+	}
+	return chunks[:maxChunks:maxChunks]
+}
+
+// DedupChunks drops chunks whose text exactly repeats an earlier chunk of the
+// same document, such as repeated boilerplate sections, keeping the first. The
+// kept chunks' chunk_index and total_chunks are renumbered to stay contiguous.
+// It returns the kept chunks and the number dropped.
+func DedupChunks(chunks []Chunk) ([]Chunk, int) {
+	return DedupChunksAgainst(chunks, nil)
+}
+
+// DedupChunksAgainst is DedupChunks that also drops chunks repeating one of
+// existing, such as the chunks already stored for a document that is appended to.
+func DedupChunksAgainst(chunks []Chunk, existing []string) ([]Chunk, int) {
+	seen := make(map[[sha256.Size]byte]bool, len(chunks)+len(existing))
+	for _, text := range existing {
+		seen[sha256.Sum256([]byte(text))] = true
+	}
+	kept := make([]Chunk, 0, len(chunks))
+	for _, c := range chunks {
+		sum := sha256.Sum256([]byte(c.Text))
+		if seen[sum] {
+			continue
+		}
+		seen[sum] = true
+		kept = append(kept, c)
+	}
+	skipped := len(chunks) - len(kept)
+	if skipped == 0 {
+		return chunks, 0
+	}
+	for i := range kept {
+		if _, ok := kept[i].Metadata["chunk_index"]; !ok {
+			continue
+		}
+		meta := make(map[string]interface{}, len(kept[i].Metadata))
+		for k, v := range kept[i].Metadata {
+			meta[k] = v
+		}
+		meta["chunk_index"] = i
+		meta["total_chunks"] = len(kept)
+		kept[i].Metadata = meta
+	}
+	return kept, skipped
+}
+
+// stampChunkMetadata sets the standard parser, chunk_index and total_chunks keys
+// on every chunk, whichever chunker or fallback path produced them, so that
+// chunk_index always runs from 0 to total_chunks-1 in order.
+func stampChunkMetadata(chunks []Chunk, parser string) {
 	totalChunks := len(chunks)
 	for i := range chunks {
-		chunks[i].Metadata["parser"] = targetChunkerType
+		if chunks[i].Metadata == nil {
+			chunks[i].Metadata = make(map[string]interface{})
+		}
+		chunks[i].Metadata["chunk_index"] = i
+		chunks[i].Metadata["parser"] = parser
 		chunks[i].Metadata["total_chunks"] = totalChunks
 	}
-	return chunks
 }
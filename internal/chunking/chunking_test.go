@@ -0,0 +1,105 @@
+package chunking
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+)
+
+func TestContentAwareChunk_StampsMetadataOnEveryChunk(t *testing.T) {
+	para := strings.Repeat("Kubernetes schedules pods onto nodes. ", 20)
+	cases := []struct {
+		name        string
+		contentType string
+		metadata    json.RawMessage
+		body        string
+		parser      string
+	}{
+		{"fallback", "text/plain", nil, para + "\n\n" + para + "\n\n" + para, "fallback"},
+		{"markdown", "text/markdown", nil, "## One\n\n" + para + "\n\n## Two\n\n" + para + para, "markdown"},
+		{"html", "text/html", nil, "<p>" + para + "</p><p>" + para + "</p><p>" + para + "</p>", "html"},
+		{"unknown override", "text/plain", json.RawMessage(`{"chunker": "pdf"}`), para + "\n\n" + para, "fallback"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			content := &models.Content{ID: 1, ContentType: tc.contentType, Metadata: tc.metadata, Body: tc.body}
+			chunks := ContentAwareChunk(content, 50, Overlap{}, 0)
+			require.NotEmpty(t, chunks)
+			for i, c := range chunks {
+				require.NotNil(t, c.Metadata, "chunk %d", i)
+				assert.Equal(t, tc.parser, c.Metadata["parser"], "chunk %d", i)
+				assert.Equal(t, len(chunks), c.Metadata["total_chunks"], "chunk %d", i)
+				assert.Equal(t, i, c.Metadata["chunk_index"], "chunk %d", i)
+			}
+		})
+	}
+}
+
+func TestStampChunkMetadata_FillsMissingMetadata(t *testing.T) {
+	chunks := []Chunk{{Text: "a"}, {Text: "b", Metadata: map[string]interface{}{"chunk_index": 7, "source_heading": "H"}}}
+	stampChunkMetadata(chunks, "fallback")
+
+	for i, c := range chunks {
+		assert.Equal(t, "fallback", c.Metadata["parser"])
+		assert.Equal(t, 2, c.Metadata["total_chunks"])
+		assert.Equal(t, i, c.Metadata["chunk_index"])
+	}
+	assert.Equal(t, "H", chunks[1].Metadata["source_heading"])
+}
+
+func TestContentAwareChunk_CapsChunksPerDocument(t *testing.T) {
+	// One long word per line: every line is its own paragraph-sized piece, so an
+	// uncapped chunker would emit thousands of chunks.
+	body := strings.Repeat(strings.Repeat("x", 4000)+"\n", 5000)
+	content := &models.Content{ID: 1, ContentType: "text/plain", Body: body}
+
+	uncapped := ContentAwareChunk(content, 1, Overlap{}, 0)
+	require.Greater(t, len(uncapped), 10)
+
+	chunks := ContentAwareChunk(content, 1, Overlap{}, 10)
+	require.Len(t, chunks, 10)
+	for i, c := range chunks {
+		assert.Equal(t, uncapped[i].Text, c.Text, "chunk %d", i)
+		assert.Equal(t, 10, c.Metadata["total_chunks"], "chunk %d", i)
+		assert.Equal(t, i, c.Metadata["chunk_index"], "chunk %d", i)
+	}
+}
+
+func TestCapChunks(t *testing.T) {
+	chunks := []Chunk{{Text: "a"}, {Text: "b"}, {Text: "c"}}
+	assert.Len(t, CapChunks(chunks, 0), 3)
+	assert.Len(t, CapChunks(chunks, 5), 3)
+	capped := CapChunks(chunks, 2)
+	assert.Equal(t, []Chunk{{Text: "a"}, {Text: "b"}}, capped)
+	assert.Equal(t, 2, cap(capped))
+}
+
+func TestDedupChunks(t *testing.T) {
+	chunks := []Chunk{
+		{Text: "Intro", Metadata: map[string]interface{}{"chunk_index": 0, "total_chunks": 4}},
+		{Text: "Boilerplate", Metadata: map[string]interface{}{"chunk_index": 1, "total_chunks": 4}},
+		{Text: "Boilerplate", Metadata: map[string]interface{}{"chunk_index": 2, "total_chunks": 4}},
+		{Text: "Outro", Metadata: map[string]interface{}{"chunk_index": 3, "total_chunks": 4}},
+	}
+	kept, skipped := DedupChunks(chunks)
+	assert.Equal(t, 1, skipped)
+	require.Len(t, kept, 3)
+	assert.Equal(t, []string{"Intro", "Boilerplate", "Outro"}, []string{kept[0].Text, kept[1].Text, kept[2].Text})
+	assert.Equal(t, 2, kept[2].Metadata["chunk_index"])
+	assert.Equal(t, 3, kept[2].Metadata["total_chunks"])
+	assert.Equal(t, 3, chunks[3].Metadata["chunk_index"], "input metadata is not modified")
+
+	unique := []Chunk{{Text: "a"}, {Text: "b"}}
+	kept, skipped = DedupChunks(unique)
+	assert.Zero(t, skipped)
+	assert.Equal(t, unique, kept)
+
+	kept, skipped = DedupChunksAgainst(unique, []string{"b", "c"})
+	assert.Equal(t, 1, skipped, "chunks repeating existing text are dropped")
+	assert.Equal(t, []Chunk{{Text: "a"}}, kept)
+}
@@ -21,7 +21,7 @@ func NewHTMLChunker() *htmlChunker { // Return concrete type *htmlChunker
 
 // Chunk splits HTML content into chunks based on block-level elements and token limits.
 // It now accepts context and models.Content.
-func (c *htmlChunker) Chunk(ctx context.Context, content *models.Content, maxTokens, overlap int) ([]Chunk, error) {
+func (c *htmlChunker) Chunk(ctx context.Context, content *models.Content, maxTokens int, overlapSpec Overlap) ([]Chunk, error) {
 	chunks := []Chunk{}
 	body := strings.TrimSpace(content.Body)
 	if body == "" {
@@ -30,6 +30,7 @@ func (c *htmlChunker) Chunk(ctx context.Context, content *models.Content, maxTok
 
 	// Validate and apply defaults for chunking parameters
 	if maxTokens <= 0 { maxTokens = DefaultMaxTokens }
+	overlap := overlapSpec.Tokens(maxTokens)
 	if overlap < 0 { overlap = DefaultOverlap }
 	if overlap >= maxTokens { overlap = maxTokens - 1 }
 
@@ -0,0 +1,60 @@
+package chunking
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Overlap is the number of tokens consecutive chunks share, given either as an
+// absolute count or as a fraction of the chunk size. A fractional overlap is
+// resolved against each chunker's effective maxTokens, so it scales with the
+// chunk size.
+type Overlap struct {
+	Count    int     // Absolute overlap in tokens; used when Fraction is zero
+	Fraction float64 // Overlap as a fraction of maxTokens, in [0, 1)
+}
+
+// TokenOverlap returns an absolute overlap of n tokens.
+func TokenOverlap(n int) Overlap {
+	return Overlap{Count: n}
+}
+
+// ParseOverlap parses a chunking.overlap setting: a token count ("50") or a
+// percentage of max_tokens ("10%"). An empty string means no overlap.
+func ParseOverlap(s string) (Overlap, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Overlap{}, nil
+	}
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		f, err := strconv.ParseFloat(strings.TrimSpace(pct), 64)
+		if err != nil || f < 0 || f >= 100 {
+			return Overlap{}, fmt.Errorf("invalid overlap %q: percentage must be a number from 0 up to 100", s)
+		}
+		return Overlap{Fraction: f / 100}, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return Overlap{}, fmt.Errorf("invalid overlap %q: must be a non-negative token count or a percentage like \"10%%\"", s)
+	}
+	return TokenOverlap(n), nil
+}
+
+// Tokens resolves the overlap for chunks of maxTokens tokens. The chunkers clamp
+// the result to less than maxTokens.
+func (o Overlap) Tokens(maxTokens int) int {
+	if o.Fraction > 0 {
+		return int(math.Round(o.Fraction * float64(maxTokens)))
+	}
+	return o.Count
+}
+
+// String formats the overlap the way ParseOverlap accepts it.
+func (o Overlap) String() string {
+	if o.Fraction > 0 {
+		return strconv.FormatFloat(o.Fraction*100, 'f', -1, 64) + "%"
+	}
+	return strconv.Itoa(o.Count)
+}
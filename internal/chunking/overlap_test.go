@@ -0,0 +1,30 @@
+package chunking
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOverlap(t *testing.T) {
+	o, err := ParseOverlap("50")
+	require.NoError(t, err)
+	assert.Equal(t, 50, o.Tokens(200))
+	assert.Equal(t, 50, o.Tokens(1000))
+
+	o, err = ParseOverlap("10%")
+	require.NoError(t, err)
+	assert.Equal(t, 20, o.Tokens(200))
+	assert.Equal(t, 100, o.Tokens(1000))
+	assert.Equal(t, "10%", o.String())
+
+	o, err = ParseOverlap("")
+	require.NoError(t, err)
+	assert.Equal(t, 0, o.Tokens(200))
+
+	for _, bad := range []string{"-5", "100%", "abc", "x%"} {
+		_, err := ParseOverlap(bad)
+		assert.Error(t, err, bad)
+	}
+}
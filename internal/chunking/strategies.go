@@ -13,7 +13,9 @@ import (
 
 // Chunker defines the interface for different chunking strategies.
 type Chunker interface {
-	Chunk(ctx context.Context, content *models.Content, maxTokens, overlap int) ([]Chunk, error) // Keep signature
+	// Chunk splits content into chunks of at most maxTokens tokens; a fractional
+	// overlap is resolved against the chunker's effective maxTokens.
+	Chunk(ctx context.Context, content *models.Content, maxTokens int, overlap Overlap) ([]Chunk, error)
 }
 
 // calculateSentenceOverlap finds sentences at the end of a text block
@@ -88,7 +90,7 @@ func estimateTokens(text string) int {
 
 // Chunk implements a fallback text splitting logic.
 // It prioritizes splitting by paragraphs (\n\n), then lines (\n), then words.
-func (c *FallbackChunker) Chunk(ctx context.Context, content *models.Content, maxTokens, overlap int) ([]Chunk, error) { // Keep signature
+func (c *FallbackChunker) Chunk(ctx context.Context, content *models.Content, maxTokens int, overlapSpec Overlap) ([]Chunk, error) {
 	log.Printf("Using FallbackChunker for content %d (Title: %s)", content.ID, content.Title)
 	var finalChunks []Chunk
 	text := strings.TrimSpace(content.Body)
@@ -103,6 +105,7 @@ func (c *FallbackChunker) Chunk(ctx context.Context, content *models.Content, ma
 		log.Printf("FallbackChunker: Invalid maxTokens (%d), using default %d for content %d", maxTokens, DefaultMaxTokens, content.ID)
 		maxTokens = DefaultMaxTokens
 	}
+	overlap := overlapSpec.Tokens(maxTokens)
 	if overlap < 0 {
 		log.Printf("FallbackChunker: Negative overlap (%d) is invalid, using default %d for content %d", overlap, DefaultOverlap, content.ID)
 		overlap = DefaultOverlap
@@ -227,7 +230,7 @@ func NewMarkdownChunker() *MarkdownChunker {
 
 // Chunk implements Markdown-specific chunking.
 // It splits the content by headings (##, ###, etc.) and then chunks each section.
-func (c *MarkdownChunker) Chunk(ctx context.Context, content *models.Content, maxTokens, overlap int) ([]Chunk, error) { // Keep signature
+func (c *MarkdownChunker) Chunk(ctx context.Context, content *models.Content, maxTokens int, overlapSpec Overlap) ([]Chunk, error) {
 	log.Printf("Using MarkdownChunker for content %d (Title: %s)", content.ID, content.Title)
 	var finalChunks []Chunk
 	text := strings.TrimSpace(content.Body)
@@ -242,6 +245,7 @@ func (c *MarkdownChunker) Chunk(ctx context.Context, content *models.Content, ma
 		log.Printf("MarkdownChunker: Invalid maxTokens (%d), using default %d for content %d", maxTokens, DefaultMaxTokens, content.ID)
 		maxTokens = DefaultMaxTokens
 	}
+	overlap := overlapSpec.Tokens(maxTokens)
 	if overlap < 0 {
 		log.Printf("MarkdownChunker: Negative overlap (%d) is invalid, using default %d for content %d", overlap, DefaultOverlap, content.ID)
 		overlap = DefaultOverlap
@@ -3,6 +3,8 @@ package clix
 import (
 	"strings"
 
+	"mimir/internal/config"
+
 	"github.com/spf13/pflag"
 )
 
@@ -15,7 +17,7 @@ func ParsePagination(flags *pflag.FlagSet) (PaginationParams, error) {
 	limit, _ := flags.GetInt("limit")
 	offset, _ := flags.GetInt("offset")
 	if limit <= 0 {
-		limit = 20
+		limit = config.DefaultPageSize
 	}
 	if offset < 0 {
 		offset = 0
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Default prompt file names, looked up in ~/.config/mimir/prompts when no path is configured.
+const (
+	DefaultSummarizationPrompt  = "summarize.txt"
+	DefaultCategorizationPrompt = "categorize.txt"
+	DefaultRAGPrompt            = "rag.txt"
+)
+
+// Provider names accepted by each feature. These mirror the providers the app
+// initializes (internal/app) and must be kept in sync with it.
+var (
+	knownEmbeddingProviders      = []string{"openai", "gemini"}
+	knownRAGProviders            = []string{"gemini", "openai"}
+	knownSummarizationProviders  = []string{"openai"}
+	knownCategorizationProviders = []string{"openai"}
+)
+
+// Check result statuses.
+const (
+	CheckPass = "PASS"
+	CheckWarn = "WARN" // The app starts, but a feature will not work as configured
+	CheckFail = "FAIL" // The app fails to start, or a configured feature is broken
+)
+
+// CheckResult is one line of a configuration check report.
+type CheckResult struct {
+	Name    string // The setting or area checked, e.g. "database.primary.DSN"
+	Status  string
+	Message string
+}
+
+// Check reports on the configuration without connecting to anything. Unlike
+// Validate, which stops at the first error, it runs every check so all
+// problems can be fixed in one pass: Validate's rules, required fields, API
+// keys for the providers in use, provider names and prompt files.
+func (c *Config) Check() []CheckResult {
+	var r checkReport
+
+	if err := c.Validate(); err != nil {
+		r.add("validation", CheckFail, "%v", err)
+	} else {
+		r.add("validation", CheckPass, "value ranges and dependent settings are valid")
+	}
+
+	r.required("database.primary.DSN", c.Database.Primary.DSN)
+	r.required("redis.address", c.Redis.Address)
+
+	if c.KeywordOnly() {
+		r.add("mode", CheckPass, "%s: embedding providers and the vector store are not used", ModeKeywordOnly)
+	} else {
+		r.required("database.vector.DSN", c.Database.Vector.DSN)
+		r.embedding(c)
+	}
+
+	if c.RAG.Enabled {
+		if r.provider("rag.provider", c.RAG.Provider, knownRAGProviders) {
+			r.apiKey(c, "rag", c.RAG.Provider)
+		}
+		// Without a configured prompt a built-in one is used if rag.txt is missing.
+		if c.RAG.Prompt != "" {
+			r.prompt("rag.prompt", c.RAG.Prompt, DefaultRAGPrompt)
+		}
+	}
+
+	if c.Summarization.Enabled {
+		if r.provider("summarization.provider", c.Summarization.Provider, knownSummarizationProviders) {
+			r.apiKey(c, "summarization", c.Summarization.Provider)
+		}
+		r.prompt("summarization.prompt", c.Summarization.Prompt, DefaultSummarizationPrompt)
+	}
+
+	if c.Categorization.Type == "llm" {
+		if r.provider("categorization.provider", c.Categorization.Provider, knownCategorizationProviders) {
+			r.apiKey(c, "categorization", c.Categorization.Provider)
+		}
+		r.prompt("categorization.prompt_template", c.Categorization.PromptTemplate, DefaultCategorizationPrompt)
+	}
+
+	return r
+}
+
+// checkReport collects the results of Config.Check.
+type checkReport []CheckResult
+
+func (r *checkReport) add(name, status, format string, args ...interface{}) {
+	*r = append(*r, CheckResult{Name: name, Status: status, Message: fmt.Sprintf(format, args...)})
+}
+
+// embedding checks the embedding provider settings.
+func (r *checkReport) embedding(c *Config) {
+	// Only OpenAI embeddings are initialized today.
+	switch {
+	case c.Embedding.OpenaiApiKey == "" && c.Embedding.Required:
+		r.add("embedding.openai_api_key", CheckFail, "not set: no embedding provider is configured (set embedding.required to false, or mode to keyword-only, to run keyword-only)")
+	case c.Embedding.OpenaiApiKey == "":
+		r.add("embedding.openai_api_key", CheckWarn, "not set: running keyword-only, semantic search is unavailable")
+	default:
+		r.add("embedding.openai_api_key", CheckPass, "set")
+	}
+	if c.Embedding.Strategy == "parallel" {
+		r.add("embedding.strategy", CheckWarn, "\"parallel\" is deprecated and behaves as \"fallback\"; set fallback or lowest_cost")
+	}
+	for i, name := range c.Embedding.ProviderOrder {
+		r.provider(fmt.Sprintf("embedding.provider_order[%d]", i), name, knownEmbeddingProviders)
+	}
+	if c.Embedding.Primary != "" {
+		r.provider("embedding.primary", c.Embedding.Primary, knownEmbeddingProviders)
+	}
+}
+
+// required checks that a setting is not empty.
+func (r *checkReport) required(setting, value string) {
+	if value == "" {
+		r.add(setting, CheckFail, "required but not set")
+	} else {
+		r.add(setting, CheckPass, "set")
+	}
+}
+
+// provider checks that name is one of known, reporting whether it is.
+func (r *checkReport) provider(setting, name string, known []string) bool {
+	for _, k := range known {
+		if name == k {
+			r.add(setting, CheckPass, "%s", name)
+			return true
+		}
+	}
+	if name == "" {
+		r.add(setting, CheckFail, "required but not set (known: %s)", strings.Join(known, ", "))
+	} else {
+		r.add(setting, CheckFail, "unknown provider %q (known: %s)", name, strings.Join(known, ", "))
+	}
+	return false
+}
+
+// apiKey checks that the API key provider needs for feature is set.
+func (r *checkReport) apiKey(c *Config, feature, provider string) {
+	setting, key := "embedding.openai_api_key", c.Embedding.OpenaiApiKey
+	if provider == "gemini" {
+		setting, key = "embedding.google_api_key", c.Embedding.GoogleApiKey
+	}
+	if key == "" {
+		r.add(setting, CheckFail, "required by %s (provider %s) but not set", feature, provider)
+	} else {
+		r.add(setting, CheckPass, "set for %s", feature)
+	}
+}
+
+// prompt checks that a prompt file exists, without creating the default
+// prompt directory as LoadPromptContent does.
+func (r *checkReport) prompt(setting, configuredPath, defaultFilename string) {
+	path, err := PromptPath(configuredPath, defaultFilename)
+	if err != nil {
+		r.add(setting, CheckFail, "%v", err)
+		return
+	}
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		r.add(setting, CheckFail, "prompt file %s: %v", path, err)
+	case info.IsDir():
+		r.add(setting, CheckFail, "prompt file %s is a directory", path)
+	default:
+		r.add(setting, CheckPass, "%s", path)
+	}
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validConfig() *Config {
+	c := &Config{}
+	c.Database.Primary.DSN = "postgres://primary"
+	c.Database.Vector.DSN = "postgres://vector"
+	c.Redis.Address = "localhost:6379"
+	c.Embedding.OpenaiApiKey = "sk-test"
+	c.Embedding.Dimension = 1536
+	c.Worker.Concurrency = 1
+	c.Worker.Queues = map[string]int{"default": 1}
+	c.Chunking.MaxTokens = 200
+	return c
+}
+
+func statuses(results []CheckResult) map[string]string {
+	m := make(map[string]string, len(results))
+	for _, r := range results {
+		if m[r.Name] != CheckFail { // A setting checked twice reports its worst status
+			m[r.Name] = r.Status
+		}
+	}
+	return m
+}
+
+func TestCheck_ValidConfigPasses(t *testing.T) {
+	for _, r := range validConfig().Check() {
+		assert.Equal(t, CheckPass, r.Status, "%s: %s", r.Name, r.Message)
+	}
+}
+
+func TestCheck_ReportsEveryProblem(t *testing.T) {
+	prompt := filepath.Join(t.TempDir(), "summarize.txt")
+	c := validConfig()
+	c.Database.Vector.DSN = ""
+	c.Embedding.OpenaiApiKey = ""
+	c.Embedding.ProviderOrder = []string{"openai", "anthropic"}
+	c.Embedding.Strategy = "parallel"
+	c.RAG.Enabled = true
+	c.RAG.Provider = "gemini" // No google_api_key
+	c.Summarization.Enabled = true
+	c.Summarization.Provider = "openai"
+	c.Summarization.Model = "gpt-4o-mini"
+	c.Summarization.Prompt = prompt // Does not exist yet
+	c.Categorization.Type = "llm"
+	c.Categorization.Provider = "claude"
+
+	got := statuses(c.Check())
+	assert.Equal(t, CheckFail, got["database.vector.DSN"])
+	assert.Equal(t, CheckPass, got["database.primary.DSN"])
+	assert.Equal(t, CheckPass, got["embedding.provider_order[0]"])
+	assert.Equal(t, CheckFail, got["embedding.provider_order[1]"])
+	assert.Equal(t, CheckWarn, got["embedding.strategy"], "parallel is a deprecated alias")
+	assert.Equal(t, CheckFail, got["embedding.google_api_key"], "RAG with gemini needs the Google key")
+	assert.Equal(t, CheckFail, got["embedding.openai_api_key"], "summarization with openai needs the OpenAI key")
+	assert.Equal(t, CheckFail, got["summarization.prompt"])
+	assert.Equal(t, CheckFail, got["categorization.provider"])
+
+	assert.NoError(t, os.WriteFile(prompt, []byte("Summarize: {{.Content}}"), 0o600))
+	assert.Equal(t, CheckPass, statuses(c.Check())["summarization.prompt"])
+}
+
+func TestCheck_KeywordOnlyNeedsNoEmbeddingSettings(t *testing.T) {
+	c := validConfig()
+	c.Mode = ModeKeywordOnly
+	c.Database.Vector.DSN = ""
+	c.Embedding.OpenaiApiKey = ""
+	c.Embedding.Dimension = 0
+	c.Embedding.Required = true // Ignored in keyword-only mode
+
+	assert.NoError(t, c.Validate())
+	for _, r := range c.Check() {
+		assert.Equal(t, CheckPass, r.Status, "%s: %s", r.Name, r.Message)
+	}
+
+	c.Mode = "semantic-only"
+	assert.Error(t, c.Validate())
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt" // Add fmt import for error wrapping
+	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/spf13/viper"
 )
 
@@ -10,17 +12,66 @@ import (
 type PricingInfo struct {
 	InputPerToken  float64 `mapstructure:"input_per_token"`
 	OutputPerToken float64 `mapstructure:"output_per_token"`
+	// Optional Batch API rates (OpenAI batch is 50% off); zero means the standard rate applies.
+	BatchInputPerToken  float64 `mapstructure:"batch_input_per_token"`
+	BatchOutputPerToken float64 `mapstructure:"batch_output_per_token"`
 }
 
+// BatchRates returns the per-token input and output rates for Batch API usage,
+// falling back to the standard rate for any batch rate that is not configured.
+func (p PricingInfo) BatchRates() (input, output float64) {
+	input, output = p.InputPerToken, p.OutputPerToken
+	if p.BatchInputPerToken > 0 {
+		input = p.BatchInputPerToken
+	}
+	if p.BatchOutputPerToken > 0 {
+		output = p.BatchOutputPerToken
+	}
+	return input, output
+}
+
+// EmbeddingModelConfig declares an embedding model's vector dimension and pricing.
+type EmbeddingModelConfig struct {
+	Name                string  `mapstructure:"name"`
+	Dimension           int     `mapstructure:"dimension"`
+	InputPerToken       float64 `mapstructure:"input_per_token"`
+	OutputPerToken      float64 `mapstructure:"output_per_token"`
+	BatchInputPerToken  float64 `mapstructure:"batch_input_per_token"`
+	BatchOutputPerToken float64 `mapstructure:"batch_output_per_token"`
+}
+
+// Run modes.
+const (
+	ModeFull        = "full"         // Keyword and semantic search (default)
+	ModeKeywordOnly = "keyword-only" // Keyword search only: no embeddings, vector store or embedding jobs
+)
+
 type Config struct {
+	// Mode is ModeFull (default when empty) or ModeKeywordOnly.
+	Mode string `mapstructure:"mode"`
+
+	// MultiTenant isolates each owner's content, collections, tags, search history
+	// and AI usage. When false every request acts as the single "public" owner.
+	MultiTenant bool `mapstructure:"multi_tenant"`
+
 	Database struct {
 		Primary struct {
-			DSN string
+			DSN  string
+			Pool struct {
+				// MaxConnections caps the connection pool; 0 keeps pgx's default
+				// (pool_max_conns in the DSN, or the larger of 4 and the CPU count).
+				MaxConnections int `mapstructure:"max_connections"`
+			} `mapstructure:"pool"`
 		}
 		// Vector struct definition (Postgres only)
 		Vector struct {
-			DSN string `mapstructure:"DSN"` // DSN for Postgres vector store
+			DSN        string `mapstructure:"DSN"`         // DSN for Postgres vector store
+			MaxRetries int    `mapstructure:"max_retries"` // Retries on transient connection errors; 0 disables
+			Metric     string `mapstructure:"metric"`      // Search distance: "l2" (default), "cosine" or "inner_product"
 		}
+		// KeywordUnaccent makes keyword search accent-insensitive; requires
+		// migration 011 (the unaccent extension).
+		KeywordUnaccent bool `mapstructure:"keyword_unaccent"`
 	}
 	Embedding struct {
 		Model           string `mapstructure:"model"`
@@ -28,15 +79,84 @@ type Config struct {
 		GoogleApiKey    string `mapstructure:"google_api_key"`
 		GeminiModelName string `mapstructure:"gemini_model_name"`
 		Dimension       int    `mapstructure:"dimension"`
+		Dimensions      int    `mapstructure:"dimensions"`    // Shortened output size for models that support it (text-embedding-3-*); 0 keeps the native size
 		UseBatchAPI     bool   `mapstructure:"use_batch_api"` // Add field for batch API toggle
+		// Required makes startup fail when no embedding provider is available
+		// (default true). False allows keyword-only deployments.
+		Required bool `mapstructure:"required"`
+
+		StrictModel bool                   `mapstructure:"strict_model"` // Fail at startup for embedding models with unknown dimensions
+		Models      []EmbeddingModelConfig `mapstructure:"models"`       // Known models; extends/overrides the built-in defaults
+
+		Strategy      string   `mapstructure:"strategy"`       // "fallback" (default) or "lowest_cost"; "parallel" is a deprecated alias for "fallback"
+		ProviderOrder []string `mapstructure:"provider_order"` // Provider names in the order they are tried
+		Primary       string   `mapstructure:"primary"`        // Provider always tried first
+
+		// InputTemplate is a Go text/template building the text that is chunked and
+		// embedded, with .Title, .Body, .Source and .Tags; empty embeds the body only.
+		InputTemplate string `mapstructure:"input_template"`
+
+		// BatchJob controls batch embedding jobs for bulk adds (directory adds):
+		// once at least MinItems items are added, they are embedded Size items per
+		// job instead of one job per item. MinItems 0 disables batch jobs.
+		BatchJob struct {
+			MinItems int `mapstructure:"min_items"`
+			Size     int `mapstructure:"size"` // Items per batch job; 0 uses 100
+		} `mapstructure:"batch_job"`
+
+		CircuitBreaker struct {
+			FailureThreshold int           `mapstructure:"failure_threshold"` // Consecutive failures that open a provider's breaker; 0 uses 5
+			Cooldown         time.Duration `mapstructure:"cooldown"`          // How long an open provider is skipped before a probe; 0 uses 30s
+		} `mapstructure:"circuit_breaker"`
 	}
+	Defaults DefaultsConfig `mapstructure:"defaults"` // Default and maximum page sizes
+
 	Search struct {
-		DefaultLimit int
+		RerankEnabled    bool `mapstructure:"rerank_enabled"`    // Rerank semantic search candidates before trimming to the limit
+		RerankCandidates int  `mapstructure:"rerank_candidates"` // Number of candidates fetched from the vector store when reranking
+
+		RecordHistory        bool `mapstructure:"record_history"`         // Record searches in search history; defaults to true
+		HistoryRetentionDays int  `mapstructure:"history_retention_days"` // Age at which 'mimir history prune' deletes searches; 0 keeps them
+
+		Boost struct {
+			RecencyHalfLife time.Duration      `mapstructure:"recency_half_life"` // Age at which ?boost=recency halves a score; 0 uses 30 days
+			SourceWeights   map[string]float64 `mapstructure:"source_weights"`    // Score multipliers for ?boost=source, keyed by source name
+		} `mapstructure:"boost"`
+
+		// Hybrid sets the default weights with which hybrid search fuses keyword
+		// and semantic rankings; 0 weighs a ranking 1.
+		Hybrid struct {
+			KeywordWeight  float64 `mapstructure:"keyword_weight"`
+			SemanticWeight float64 `mapstructure:"semantic_weight"`
+		} `mapstructure:"hybrid"`
 	}
 
+	Content struct {
+		MaxBodyLength  int    `mapstructure:"max_body_length"` // Maximum body size in bytes after input processing; 0 disables the limit
+		OversizePolicy string `mapstructure:"oversize_policy"` // "reject" (default) or "truncate"
+
+		// AllowedSourceTypes lists the source_type values API clients may set when adding content.
+		// Empty uses DefaultAPISourceTypes; "api" is always accepted.
+		AllowedSourceTypes []string `mapstructure:"allowed_source_types"`
+
+		// DefaultVisibility applies to content added without a visibility:
+		// "private" (default, owner only) or "shared" (all owners).
+		DefaultVisibility string `mapstructure:"default_visibility"`
+
+		// AutoTitle generates a title for content added without one, using the
+		// RAG completion provider when enabled and the body's first heading or line otherwise.
+		AutoTitle bool `mapstructure:"auto_title"`
+	} `mapstructure:"content"`
+
 	Chunking struct { // Add Chunking struct
-		MaxTokens int `mapstructure:"max_tokens"`
-		Overlap   int `mapstructure:"overlap"`
+		MaxTokens int    `mapstructure:"max_tokens"`
+		Overlap   string `mapstructure:"overlap"` // Token count ("50") or percentage of max_tokens ("10%"); see chunking.ParseOverlap
+		// MaxChunksPerDoc caps the chunks (and so embedding calls) per document;
+		// chunks beyond it are dropped with a warning. 0 means no cap.
+		MaxChunksPerDoc int `mapstructure:"max_chunks_per_doc"`
+		// DedupChunks skips embedding chunks whose text exactly repeats an earlier
+		// chunk of the same document (see chunking.DedupChunks).
+		DedupChunks bool `mapstructure:"dedup_chunks"`
 	} `mapstructure:"chunking"` // Add mapstructure tag
 
 	// Add Categorization struct back
@@ -46,6 +166,13 @@ type Config struct {
 		Model          string `mapstructure:"model"`           // Model name for the provider
 		PromptTemplate string `mapstructure:"prompt_template"` // Path to prompt template file or the template itself
 		AutoApplyTags  bool   `mapstructure:"auto_apply_tags"` // Add this line
+		// MinConfidence skips applying suggestions below this confidence (0 applies all).
+		MinConfidence float64 `mapstructure:"min_confidence"`
+		// MaxTags caps the number of suggested tags applied per item (0 means no limit).
+		MaxTags int `mapstructure:"max_tags"`
+		// MaxCollectionSize is the largest collection POST /collections/{id}/categorize
+		// accepts (0 uses the default of 100).
+		MaxCollectionSize int `mapstructure:"max_collection_size"`
 	}
 
 	Summarization struct {
@@ -69,12 +196,71 @@ type Config struct {
 	}
 
 	Worker struct {
-		Concurrency int            `mapstructure:"concurrency"`
-		Queues      map[string]int `mapstructure:"queues"`
+		Concurrency       int                  `mapstructure:"concurrency"`
+		Queues            map[string]int       `mapstructure:"queues"`
+		HeartbeatInterval time.Duration        `mapstructure:"heartbeat_interval"` // How often workers record liveness; 0 uses the 15s default
+		MaxRetry          int                  `mapstructure:"max_retry"`          // Attempts before a task is moved to the dead queue; 0 uses asynq's default (25)
+		Jobs              map[string]JobConfig `mapstructure:"jobs"`               // Per job type overrides, e.g. "summarization"
 	}
 
 	// Pricing: map[provider][model] = struct{input_per_token, output_per_token}
 	Pricing map[string]map[string]PricingInfo `mapstructure:"pricing"`
+
+	Cost struct {
+		// BufferedRecording queues AI usage logs in memory and inserts them in
+		// batches, instead of one insert per AI call. Queued logs are flushed on
+		// shutdown and before usage is read.
+		BufferedRecording bool          `mapstructure:"buffered_recording"`
+		FlushInterval     time.Duration `mapstructure:"flush_interval"` // Longest a log waits in the buffer; 0 uses the 5s default
+		FlushSize         int           `mapstructure:"flush_size"`     // Buffered logs that trigger a flush; 0 uses the default of 100
+	} `mapstructure:"cost"`
+}
+
+// JobConfig tunes the retries and timeout of one background job type.
+type JobConfig struct {
+	MaxRetry *int          `mapstructure:"max_retry"` // Unset uses the job's default; 0 disables retries
+	Timeout  time.Duration `mapstructure:"timeout"`   // 0 uses the job's default
+}
+
+// MaxRetryOr returns the configured retry count, or def when unset.
+func (j JobConfig) MaxRetryOr(def int) int {
+	if j.MaxRetry != nil {
+		return *j.MaxRetry
+	}
+	return def
+}
+
+// TimeoutOr returns the configured timeout, or def when unset.
+func (j JobConfig) TimeoutOr(def time.Duration) time.Duration {
+	if j.Timeout > 0 {
+		return j.Timeout
+	}
+	return def
+}
+
+// KeywordOnly reports whether mimir runs without embedding infrastructure.
+func (c *Config) KeywordOnly() bool {
+	return c.Mode == ModeKeywordOnly
+}
+
+// PrimaryPoolSize returns the size of the primary database connection pool as
+// primary.NewPrimaryStore sets it up: pool.max_connections when set, else
+// pool_max_conns from the DSN or pgx's default.
+func (c *Config) PrimaryPoolSize() (int, error) {
+	if n := c.Database.Primary.Pool.MaxConnections; n > 0 {
+		return n, nil
+	}
+	poolConfig, err := pgxpool.ParseConfig(c.Database.Primary.DSN)
+	if err != nil {
+		return 0, fmt.Errorf("parse database.primary.DSN: %w", err)
+	}
+	return int(poolConfig.MaxConns), nil
+}
+
+// FileUsed returns the path of the config file read by LoadConfig, or "" when
+// none was found and the configuration came from defaults and the environment.
+func FileUsed() string {
+	return viper.ConfigFileUsed()
 }
 
 func LoadConfig() (*Config, error) {
@@ -95,6 +281,10 @@ func LoadConfig() (*Config, error) {
 	viper.BindEnv("embedding.openai_api_key", "OPENAI_API_KEY")
 	// --- End Environment Variable Binding ---
 
+	viper.SetDefault("search.record_history", true)
+	viper.SetDefault("embedding.required", true)
+	viper.SetDefault("embedding.batch_job.min_items", 20)
+
 	if err := viper.ReadInConfig(); err != nil {
 		// It's okay if the config file doesn't exist, Viper might rely solely on env vars
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
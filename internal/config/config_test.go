@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobConfig_FallsBackToDefaults(t *testing.T) {
+	var unset JobConfig
+	assert.Equal(t, 3, unset.MaxRetryOr(3))
+	assert.Equal(t, 5*time.Minute, unset.TimeoutOr(5*time.Minute))
+
+	retries := 8
+	set := JobConfig{MaxRetry: &retries, Timeout: time.Minute}
+	assert.Equal(t, 8, set.MaxRetryOr(3))
+	assert.Equal(t, time.Minute, set.TimeoutOr(5*time.Minute))
+
+	none := 0
+	disabled := JobConfig{MaxRetry: &none}
+	assert.Equal(t, 0, disabled.MaxRetryOr(3), "0 disables retries rather than meaning unset")
+}
+
+func TestValidate_RejectsNegativeJobSettings(t *testing.T) {
+	c := validConfig()
+	retries := 5
+	c.Worker.Jobs = map[string]JobConfig{"summarization": {MaxRetry: &retries, Timeout: 10 * time.Minute}}
+	assert.NoError(t, c.Validate())
+
+	c.Worker.Jobs["summarization"] = JobConfig{Timeout: -time.Second}
+	assert.ErrorContains(t, c.Validate(), "worker.jobs.summarization")
+}
+
+func TestValidate_RequiresConnectionsBeyondWorkerConcurrency(t *testing.T) {
+	c := validConfig()
+	c.Worker.Concurrency = 10
+	c.Database.Primary.Pool.MaxConnections = 11
+	assert.NoError(t, c.Validate())
+
+	c.Database.Primary.Pool.MaxConnections = 10
+	assert.ErrorContains(t, c.Validate(), "worker.concurrency")
+
+	// Without max_connections the pool size comes from the DSN or pgx's default.
+	c.Database.Primary.Pool.MaxConnections = 0
+	c.Database.Primary.DSN = "postgres://primary?pool_max_conns=10"
+	assert.ErrorContains(t, c.Validate(), "worker.concurrency", "pool_max_conns limits the pool")
+	c.Database.Primary.DSN = "postgres://primary?pool_max_conns=11"
+	assert.NoError(t, c.Validate())
+
+	c.Database.Primary.DSN = "postgres://primary"
+	size, err := c.PrimaryPoolSize()
+	require.NoError(t, err)
+	c.Worker.Concurrency = size
+	assert.ErrorContains(t, c.Validate(), "worker.concurrency", "the default pool must exceed concurrency as well")
+}
@@ -0,0 +1,63 @@
+package config
+
+// Built-in pagination defaults, used whenever the corresponding
+// defaults.* config value is unset. Stores fall back to these directly.
+const (
+	DefaultPageSize    = 20  // Items per page for list operations
+	DefaultSearchLimit = 10  // Results returned by search operations
+	DefaultMaxPageSize = 200 // Upper bound for any requested page or result count
+)
+
+// DefaultAPISourceType is the source type of API-added content when the client
+// does not name one.
+const DefaultAPISourceType = "api"
+
+// DefaultAPISourceTypes are the source types API clients may set when
+// content.allowed_source_types is unset.
+var DefaultAPISourceTypes = []string{DefaultAPISourceType, "slack-bot", "email-ingest", "web-clip"}
+
+// DefaultsConfig holds the default and maximum page sizes shared by the API,
+// CLI and services.
+type DefaultsConfig struct {
+	PageSize    int `mapstructure:"page_size"`     // Default items per page for list operations
+	SearchLimit int `mapstructure:"search_limit"`  // Default number of search results
+	MaxPageSize int `mapstructure:"max_page_size"` // Maximum items per page or search results
+}
+
+// PageLimit resolves a requested list page size: non-positive values fall back
+// to the configured default, and oversized values are capped at MaxPageSize.
+func (d DefaultsConfig) PageLimit(limit int) int {
+	if limit <= 0 {
+		limit = d.PageSize
+		if limit <= 0 {
+			limit = DefaultPageSize
+		}
+	}
+	return d.clamp(limit)
+}
+
+// SearchLimitFor resolves a requested search result count in the same way as PageLimit.
+func (d DefaultsConfig) SearchLimitFor(limit int) int {
+	if limit <= 0 {
+		limit = d.SearchLimit
+		if limit <= 0 {
+			limit = DefaultSearchLimit
+		}
+	}
+	return d.clamp(limit)
+}
+
+// MaxLimit returns the effective maximum page size.
+func (d DefaultsConfig) MaxLimit() int {
+	if d.MaxPageSize <= 0 {
+		return DefaultMaxPageSize
+	}
+	return d.MaxPageSize
+}
+
+func (d DefaultsConfig) clamp(limit int) int {
+	if max := d.MaxLimit(); limit > max {
+		return max
+	}
+	return limit
+}
@@ -9,26 +9,32 @@ import (
 // defaultPromptDir is the subdirectory within the user's config directory.
 const defaultPromptDir = ".config/mimir/prompts"
 
-// LoadPromptContent resolves the path for a prompt template and reads its content.
+// PromptPath resolves the path of a prompt template.
 // If configuredPath is absolute, it's used directly.
 // If configuredPath is relative or empty, it's treated as a filename within ~/.config/mimir/prompts/.
-func LoadPromptContent(configuredPath, defaultFilename string) (string, error) {
-	finalPath := configuredPath
+func PromptPath(configuredPath, defaultFilename string) (string, error) {
+	if filepath.IsAbs(configuredPath) {
+		return configuredPath, nil
+	}
 
-	// If the path is relative or empty, resolve it against the default user config directory.
-	if !filepath.IsAbs(configuredPath) {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("failed to get user home directory: %w", err)
-		}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
 
-		// Use defaultFilename if configuredPath is empty, otherwise use configuredPath as the filename.
-		filename := configuredPath
-		if filename == "" {
-			filename = defaultFilename
-		}
+	// Use defaultFilename if configuredPath is empty, otherwise use configuredPath as the filename.
+	filename := configuredPath
+	if filename == "" {
+		filename = defaultFilename
+	}
+	return filepath.Join(homeDir, defaultPromptDir, filename), nil
+}
 
-		finalPath = filepath.Join(homeDir, defaultPromptDir, filename)
+// LoadPromptContent resolves the path for a prompt template (see PromptPath) and reads its content.
+func LoadPromptContent(configuredPath, defaultFilename string) (string, error) {
+	finalPath, err := PromptPath(configuredPath, defaultFilename)
+	if err != nil {
+		return "", err
 	}
 
 	// Ensure the directory exists if we are using the default path logic
@@ -3,6 +3,9 @@ package config
 import (
 	"errors"
 	"fmt"
+
+	"mimir/internal/chunking"
+	"mimir/internal/store"
 )
 
 /*
@@ -19,13 +22,28 @@ especially for enabled providers/features. This covers:
 */
 
 func (c *Config) Validate() error {
+	switch c.Mode {
+	case "", ModeFull, ModeKeywordOnly:
+	default:
+		return fmt.Errorf("mode must be %q or %q, got %q", ModeFull, ModeKeywordOnly, c.Mode)
+	}
+
 	// Database config
 	if c.Database.Primary.DSN == "" {
 		return errors.New("database.primary.DSN is required")
 	}
-	if c.Database.Vector.DSN == "" {
+	if c.Database.Primary.Pool.MaxConnections < 0 {
+		return errors.New("database.primary.pool.max_connections must not be negative")
+	}
+	if c.Database.Vector.DSN == "" && !c.KeywordOnly() {
 		return errors.New("database.vector.DSN is required")
 	}
+	if c.Database.Vector.MaxRetries < 0 {
+		return errors.New("database.vector.max_retries must be non-negative")
+	}
+	if _, err := store.ParseDistanceMetric(c.Database.Vector.Metric); err != nil {
+		return fmt.Errorf("database.vector.metric: %w", err)
+	}
 
 	// Embedding config validation based on the actual struct fields
 	// Note: The current Config.Embedding struct seems simplified.
@@ -40,9 +58,92 @@ func (c *Config) Validate() error {
 		return errors.New("embedding.google_api_key is required when embedding.gemini_model_name is set")
 	}
 
-	if c.Embedding.Dimension <= 0 {
+	if c.Embedding.Dimension <= 0 && !c.KeywordOnly() {
 		return errors.New("embedding.dimension must be a positive integer")
 	}
+	if c.Embedding.Dimensions < 0 {
+		return errors.New("embedding.dimensions must not be negative")
+	}
+	if c.Embedding.CircuitBreaker.FailureThreshold < 0 {
+		return errors.New("embedding.circuit_breaker.failure_threshold must be non-negative")
+	}
+	if c.Embedding.CircuitBreaker.Cooldown < 0 {
+		return errors.New("embedding.circuit_breaker.cooldown must be non-negative")
+	}
+	if c.Embedding.BatchJob.MinItems < 0 || c.Embedding.BatchJob.Size < 0 {
+		return errors.New("embedding.batch_job min_items and size must be non-negative")
+	}
+	switch c.Embedding.Strategy {
+	case "", "fallback", "lowest_cost", "parallel": // parallel is a deprecated alias for fallback
+	default:
+		return fmt.Errorf("embedding.strategy must be \"fallback\" or \"lowest_cost\", got %q", c.Embedding.Strategy)
+	}
+	for i, name := range c.Embedding.ProviderOrder {
+		if name == "" {
+			return fmt.Errorf("embedding.provider_order[%d] must not be empty", i)
+		}
+	}
+	for i, m := range c.Embedding.Models {
+		if m.Name == "" {
+			return fmt.Errorf("embedding.models[%d].name is required", i)
+		}
+		if m.Dimension <= 0 {
+			return fmt.Errorf("embedding.models[%d] (%s): dimension must be a positive integer", i, m.Name)
+		}
+		if m.InputPerToken < 0 || m.OutputPerToken < 0 || m.BatchInputPerToken < 0 || m.BatchOutputPerToken < 0 {
+			return fmt.Errorf("embedding.models[%d] (%s): token prices must not be negative", i, m.Name)
+		}
+	}
+
+	// Defaults config
+	if c.Defaults.PageSize < 0 || c.Defaults.SearchLimit < 0 || c.Defaults.MaxPageSize < 0 {
+		return errors.New("defaults.page_size, defaults.search_limit and defaults.max_page_size must not be negative")
+	}
+	if max := c.Defaults.MaxLimit(); c.Defaults.PageSize > max || c.Defaults.SearchLimit > max {
+		return fmt.Errorf("defaults.page_size and defaults.search_limit must not exceed defaults.max_page_size (%d)", max)
+	}
+
+	// Search config
+	if c.Search.RerankCandidates < 0 {
+		return errors.New("search.rerank_candidates must not be negative")
+	}
+	if c.Search.HistoryRetentionDays < 0 {
+		return errors.New("search.history_retention_days must not be negative")
+	}
+	if c.Search.Boost.RecencyHalfLife < 0 {
+		return errors.New("search.boost.recency_half_life must not be negative")
+	}
+	for name, w := range c.Search.Boost.SourceWeights {
+		if w <= 0 {
+			return fmt.Errorf("search.boost.source_weights.%s must be positive", name)
+		}
+	}
+	if c.Search.Hybrid.KeywordWeight < 0 || c.Search.Hybrid.SemanticWeight < 0 {
+		return errors.New("search.hybrid weights must not be negative")
+	}
+
+	// Cost config
+	if c.Cost.FlushInterval < 0 {
+		return errors.New("cost.flush_interval must not be negative")
+	}
+	if c.Cost.FlushSize < 0 {
+		return errors.New("cost.flush_size must not be negative")
+	}
+
+	// Content config
+	if c.Content.MaxBodyLength < 0 {
+		return errors.New("content.max_body_length must not be negative")
+	}
+	switch c.Content.OversizePolicy {
+	case "", "reject", "truncate":
+	default:
+		return fmt.Errorf("content.oversize_policy must be 'reject' or 'truncate', got '%s'", c.Content.OversizePolicy)
+	}
+	switch c.Content.DefaultVisibility {
+	case "", "private", "shared":
+	default:
+		return fmt.Errorf("content.default_visibility must be 'private' or 'shared', got '%s'", c.Content.DefaultVisibility)
+	}
 
 	// Redis config
 	if c.Redis.Address == "" {
@@ -53,9 +154,29 @@ func (c *Config) Validate() error {
 	if c.Worker.Concurrency <= 0 {
 		return errors.New("worker.concurrency must be a positive integer")
 	}
+	// Each running embedding job holds a pooled connection for its content lock
+	// (see primary.LockContent) and needs at least one more for its own queries.
+	poolSize, err := c.PrimaryPoolSize()
+	if err != nil {
+		return err
+	}
+	if c.Worker.Concurrency >= poolSize {
+		return fmt.Errorf("worker.concurrency (%d) must be less than the primary database pool size (%d, database.primary.pool.max_connections or the DSN's pool_max_conns)", c.Worker.Concurrency, poolSize)
+	}
 	if len(c.Worker.Queues) == 0 {
 		return errors.New("worker.queues must define at least one queue")
 	}
+	if c.Worker.HeartbeatInterval < 0 {
+		return errors.New("worker.heartbeat_interval must be non-negative")
+	}
+	if c.Worker.MaxRetry < 0 {
+		return errors.New("worker.max_retry must be non-negative")
+	}
+	for name, job := range c.Worker.Jobs {
+		if (job.MaxRetry != nil && *job.MaxRetry < 0) || job.Timeout < 0 {
+			return fmt.Errorf("worker.jobs.%s max_retry and timeout must be non-negative", name)
+		}
+	}
 	for name, priority := range c.Worker.Queues {
 		if name == "" {
 			return errors.New("worker.queues contains an empty queue name")
@@ -69,11 +190,24 @@ func (c *Config) Validate() error {
 	if c.Chunking.MaxTokens <= 0 {
 		return errors.New("chunking.max_tokens must be positive")
 	}
-	if c.Chunking.Overlap < 0 || c.Chunking.Overlap >= c.Chunking.MaxTokens {
-		return fmt.Errorf("chunking.overlap (%d) must be non-negative and less than max_tokens (%d)", c.Chunking.Overlap, c.Chunking.MaxTokens)
+	overlap, err := chunking.ParseOverlap(c.Chunking.Overlap)
+	if err != nil {
+		return fmt.Errorf("chunking.overlap: %w", err)
+	}
+	if overlap.Tokens(c.Chunking.MaxTokens) >= c.Chunking.MaxTokens {
+		return fmt.Errorf("chunking.overlap (%s) must be less than max_tokens (%d)", overlap, c.Chunking.MaxTokens)
+	}
+	if c.Chunking.MaxChunksPerDoc < 0 {
+		return errors.New("chunking.max_chunks_per_doc must not be negative")
 	}
 
 	// Categorization config
+	if c.Categorization.MinConfidence < 0 || c.Categorization.MinConfidence > 1 {
+		return errors.New("categorization.min_confidence must be between 0 and 1")
+	}
+	if c.Categorization.MaxTags < 0 {
+		return errors.New("categorization.max_tags must not be negative")
+	}
 	if c.Categorization.AutoApplyTags {
 		if c.Categorization.Provider == "" {
 			return errors.New("categorization.provider is required when auto_apply_tags is true")
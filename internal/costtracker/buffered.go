@@ -0,0 +1,235 @@
+package costtracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+const (
+	// DefaultFlushInterval is the longest a usage log waits in a BufferedStore.
+	DefaultFlushInterval = 5 * time.Second
+	// DefaultFlushSize is the number of buffered usage logs that triggers a flush.
+	DefaultFlushSize = 100
+	// maxBufferedBatches caps the queue at this many flush sizes; past it the
+	// oldest logs are dropped, so an unreachable store cannot exhaust memory.
+	maxBufferedBatches = 10
+)
+
+// BufferedStore is a store.CostTrackingStore that queues RecordUsage calls in
+// memory and writes them to the wrapped store with RecordUsageBatch, taking the
+// insert off the path of every AI call. Logs are flushed every interval, as soon
+// as size logs are queued, before any usage query and on Close. When a batch
+// insert fails the logs are written one at a time: logs the database rejects are
+// dropped and logged, and logs that could not be written because the store is
+// unreachable stay queued for the next flush. At most maxBufferedBatches times
+// size logs are queued; the oldest are dropped beyond that.
+//
+// Buffered logs get their ID and owner when they are flushed, not when
+// RecordUsage returns.
+type BufferedStore struct {
+	store.CostTrackingStore
+
+	size    int
+	maxSize int
+
+	mu     sync.Mutex
+	buf    []*models.AIUsageLog
+	closed bool
+
+	flushMu   sync.Mutex // Serializes flushes so logs are written in the order recorded
+	full      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewBufferedStore wraps next in a BufferedStore and starts its flush loop.
+// A size or interval of 0 uses DefaultFlushSize or DefaultFlushInterval.
+// Close must be called to write the logs still queued.
+func NewBufferedStore(next store.CostTrackingStore, size int, interval time.Duration) *BufferedStore {
+	if size <= 0 {
+		size = DefaultFlushSize
+	}
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	b := &BufferedStore{
+		CostTrackingStore: next,
+		size:              size,
+		maxSize:           size * maxBufferedBatches,
+		full:              make(chan struct{}, 1),
+		stop:              make(chan struct{}),
+		done:              make(chan struct{}),
+	}
+	go b.run(interval)
+	return b
+}
+
+// RecordUsage queues log for the next flush. After Close it records log directly.
+func (b *BufferedStore) RecordUsage(ctx context.Context, log *models.AIUsageLog) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return b.CostTrackingStore.RecordUsage(ctx, log)
+	}
+	if log.Timestamp.IsZero() {
+		log.Timestamp = time.Now() // The time of the call, not of the flush
+	}
+	b.buf = append(b.buf, log)
+	b.trimLocked()
+	full := len(b.buf) >= b.size
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.full <- struct{}{}:
+		default: // A flush is already pending
+		}
+	}
+	return nil
+}
+
+// Flush writes the queued logs to the wrapped store. If the batch insert fails
+// the logs are inserted one at a time; on a failure that is not the database
+// rejecting the log, the unwritten logs stay queued, ahead of any recorded since.
+func (b *BufferedStore) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	logs := b.buf
+	b.buf = nil
+	b.mu.Unlock()
+	if len(logs) == 0 {
+		return nil
+	}
+
+	batchErr := b.CostTrackingStore.RecordUsageBatch(ctx, logs)
+	if batchErr == nil {
+		return nil
+	}
+	for i, usage := range logs {
+		err := b.CostTrackingStore.RecordUsage(ctx, usage)
+		if err == nil {
+			continue
+		}
+		if !rejected(err) {
+			b.mu.Lock()
+			b.buf = append(logs[i:], b.buf...)
+			b.trimLocked()
+			b.mu.Unlock()
+			return fmt.Errorf("flush %d usage logs: %w", len(logs)-i, errors.Join(batchErr, err))
+		}
+		log.Printf("WARN: Dropping AI usage log for %s/%s the database rejected: %v", usage.ProviderName, usage.ModelName, err)
+	}
+	return nil
+}
+
+// trimLocked drops the oldest queued logs beyond maxSize. b.mu must be held.
+func (b *BufferedStore) trimLocked() {
+	if over := len(b.buf) - b.maxSize; over > 0 {
+		log.Printf("WARN: AI usage log buffer is full, dropping the %d oldest logs", over)
+		b.buf = append([]*models.AIUsageLog(nil), b.buf[over:]...)
+	}
+}
+
+// rejected reports whether err is the database refusing the log itself, such as
+// a constraint violation, which retrying cannot fix.
+func rejected(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	switch {
+	case len(pgErr.Code) == 5 && pgErr.Code[:2] == "08": // connection_exception class
+		return false
+	case pgErr.Code == "57P01", pgErr.Code == "57P02", pgErr.Code == "57P03": // admin/crash shutdown, cannot_connect_now
+		return false
+	}
+	return true
+}
+
+// Close stops the flush loop and writes the queued logs. Logs recorded after
+// Close are written directly.
+func (b *BufferedStore) Close(ctx context.Context) error {
+	b.closeOnce.Do(func() {
+		close(b.stop)
+		<-b.done
+		b.mu.Lock()
+		b.closed = true
+		b.mu.Unlock()
+	})
+	return b.Flush(ctx)
+}
+
+func (b *BufferedStore) run(interval time.Duration) {
+	defer close(b.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+		case <-b.full:
+		}
+		if err := b.Flush(context.Background()); err != nil {
+			log.Printf("WARN: Failed to flush buffered AI usage logs, retrying later: %v", err)
+		}
+	}
+}
+
+// The usage queries flush first, so they include every log recorded so far.
+
+func (b *BufferedStore) ListUsage(ctx context.Context, ownerID string, limit, offset int) ([]*models.AIUsageLog, error) {
+	if err := b.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return b.CostTrackingStore.ListUsage(ctx, ownerID, limit, offset)
+}
+
+func (b *BufferedStore) GetUsageSummary(ctx context.Context, ownerID string) (float64, int64, int64, error) {
+	if err := b.Flush(ctx); err != nil {
+		return 0, 0, 0, err
+	}
+	return b.CostTrackingStore.GetUsageSummary(ctx, ownerID)
+}
+
+func (b *BufferedStore) GetUsageByOwner(ctx context.Context) ([]store.OwnerUsage, error) {
+	if err := b.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return b.CostTrackingStore.GetUsageByOwner(ctx)
+}
+
+func (b *BufferedStore) GetUsageSummaryByService(ctx context.Context, ownerID string, r store.UsageRange) ([]store.ServiceUsage, error) {
+	if err := b.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return b.CostTrackingStore.GetUsageSummaryByService(ctx, ownerID, r)
+}
+
+func (b *BufferedStore) StreamUsage(ctx context.Context, ownerID string, r store.UsageRange, fn func(log *models.AIUsageLog) error) error {
+	if err := b.Flush(ctx); err != nil {
+		return err
+	}
+	return b.CostTrackingStore.StreamUsage(ctx, ownerID, r, fn)
+}
+
+func (b *BufferedStore) GetUsageGroups(ctx context.Context, ownerID string, r store.UsageRange, byDay, byModel bool) ([]store.UsageGroup, error) {
+	if err := b.Flush(ctx); err != nil {
+		return nil, err
+	}
+	return b.CostTrackingStore.GetUsageGroups(ctx, ownerID, r, byDay, byModel)
+}
+
+var _ store.CostTrackingStore = (*BufferedStore)(nil)
@@ -0,0 +1,139 @@
+package costtracker_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/costtracker"
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// batchCostStore records the logs written with RecordUsageBatch and
+// RecordUsage, failing while err is set. Logs whose model is in reject fail
+// with a constraint violation, failing any batch holding them.
+type batchCostStore struct {
+	store.CostTrackingStore
+	mu      sync.Mutex
+	err     error
+	reject  map[string]bool
+	batches [][]*models.AIUsageLog
+}
+
+func (s *batchCostStore) RecordUsageBatch(ctx context.Context, logs []*models.AIUsageLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	for _, l := range logs {
+		if s.reject[l.ModelName] {
+			return &pgconn.PgError{Code: "23503"}
+		}
+	}
+	s.batches = append(s.batches, logs)
+	return nil
+}
+
+func (s *batchCostStore) RecordUsage(ctx context.Context, log *models.AIUsageLog) error {
+	return s.RecordUsageBatch(ctx, []*models.AIUsageLog{log})
+}
+
+func (s *batchCostStore) ListUsage(ctx context.Context, ownerID string, limit, offset int) ([]*models.AIUsageLog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var logs []*models.AIUsageLog
+	for _, batch := range s.batches {
+		logs = append(logs, batch...)
+	}
+	return logs, nil
+}
+
+func (s *batchCostStore) batchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.batches)
+}
+
+func TestBufferedStore_FlushesAtSizeThreshold(t *testing.T) {
+	next := &batchCostStore{}
+	b := costtracker.NewBufferedStore(next, 2, time.Hour)
+	defer b.Close(context.Background())
+
+	require.NoError(t, b.RecordUsage(context.Background(), &models.AIUsageLog{ModelName: "a"}))
+	assert.Equal(t, 0, next.batchCount(), "one log is below the threshold")
+	require.NoError(t, b.RecordUsage(context.Background(), &models.AIUsageLog{ModelName: "b"}))
+
+	assert.Eventually(t, func() bool { return next.batchCount() == 1 }, time.Second, 5*time.Millisecond)
+	assert.Len(t, next.batches[0], 2)
+}
+
+func TestBufferedStore_CloseFlushesAndRecordsDirectlyAfter(t *testing.T) {
+	next := &batchCostStore{}
+	b := costtracker.NewBufferedStore(next, 100, time.Hour)
+
+	require.NoError(t, b.RecordUsage(context.Background(), &models.AIUsageLog{ModelName: "a"}))
+	require.NoError(t, b.Close(context.Background()))
+	require.Equal(t, 1, next.batchCount())
+	assert.False(t, next.batches[0][0].Timestamp.IsZero(), "the log is stamped when recorded")
+}
+
+func TestBufferedStore_FailedFlushKeepsLogsInOrder(t *testing.T) {
+	next := &batchCostStore{err: errors.New("db down")}
+	b := costtracker.NewBufferedStore(next, 100, time.Hour)
+	defer b.Close(context.Background())
+
+	require.NoError(t, b.RecordUsage(context.Background(), &models.AIUsageLog{ModelName: "a"}))
+	require.Error(t, b.Flush(context.Background()))
+	require.NoError(t, b.RecordUsage(context.Background(), &models.AIUsageLog{ModelName: "b"}))
+
+	next.mu.Lock()
+	next.err = nil
+	next.mu.Unlock()
+	logs, err := b.ListUsage(context.Background(), "", 10, 0) // Reads flush first
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, "a", logs[0].ModelName)
+	assert.Equal(t, "b", logs[1].ModelName)
+}
+
+func TestBufferedStore_FailedBatchFallsBackToSingleInserts(t *testing.T) {
+	next := &batchCostStore{reject: map[string]bool{"bad": true}}
+	b := costtracker.NewBufferedStore(next, 100, time.Hour)
+	defer b.Close(context.Background())
+
+	for _, model := range []string{"a", "bad", "b"} {
+		require.NoError(t, b.RecordUsage(context.Background(), &models.AIUsageLog{ModelName: model}))
+	}
+	require.NoError(t, b.Flush(context.Background()), "the rejected log is dropped")
+
+	logs, err := b.ListUsage(context.Background(), "", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, "a", logs[0].ModelName)
+	assert.Equal(t, "b", logs[1].ModelName)
+}
+
+func TestBufferedStore_CapsQueuedLogs(t *testing.T) {
+	next := &batchCostStore{err: errors.New("db down")}
+	b := costtracker.NewBufferedStore(next, 1, time.Hour)
+	defer b.Close(context.Background())
+
+	for i := 0; i < 25; i++ {
+		require.NoError(t, b.RecordUsage(context.Background(), &models.AIUsageLog{InputTokens: i}))
+	}
+	next.mu.Lock()
+	next.err = nil
+	next.mu.Unlock()
+	logs, err := b.ListUsage(context.Background(), "", 100, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 10, "at most 10 flush sizes are queued")
+	assert.Equal(t, 15, logs[0].InputTokens, "the oldest logs are dropped")
+}
@@ -1,9 +1,11 @@
 package fileingest
 
 import (
+	"bufio"
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 )
 
@@ -70,3 +72,30 @@ func ExtractFileMeta(path string) (FileMeta, error) {
 		ModTime: info.ModTime(),
 	}, nil
 }
+
+/*
+ReadInputList reads a list file containing one input (path or URL) per line.
+
+Blank lines and lines starting with '#' are skipped; surrounding whitespace is trimmed.
+*/
+func ReadInputList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var inputs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		inputs = append(inputs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return inputs, nil
+}
@@ -0,0 +1,44 @@
+package fileingest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadInputList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.txt")
+	data := "# imports\nhttps://example.com/a\n\n  notes/b.md  \n#skipped.md\n"
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	inputs, err := ReadInputList(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/a", "notes/b.md"}, inputs)
+}
+
+func TestReadJSONL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.jsonl")
+	data := `{"content": "First record", "title": "One", "tags": ["a", " b "], "lang": "en"}
+
+{"content": "Second", "tags": "x, y"}
+{"title": "no body"}
+not json
+`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	records, err := ReadJSONL(path, JSONLFields{Text: "content", Title: "title", Tags: "tags"})
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+
+	assert.Equal(t, JSONLRecord{Line: 1, Title: "One", Body: "First record", Tags: []string{"a", "b"},
+		Metadata: map[string]interface{}{"lang": "en"}}, records[0])
+	assert.Equal(t, 3, records[1].Line)
+	assert.Equal(t, []string{"x", "y"}, records[1].Tags)
+	assert.Nil(t, records[1].Metadata)
+	assert.Error(t, records[2].Err)
+	assert.Equal(t, 5, records[3].Line)
+	assert.Error(t, records[3].Err)
+}
@@ -0,0 +1,147 @@
+package fileingest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxJSONLLineBytes bounds a single JSONL record; larger lines fail the read.
+const maxJSONLLineBytes = 16 * 1024 * 1024
+
+// JSONLFields names the record fields mapped onto content. Fields other than
+// Text, Title and Tags are kept as content metadata.
+type JSONLFields struct {
+	Text  string // Required; becomes the body
+	Title string // Optional; becomes the title
+	Tags  string // Optional; an array of strings or a comma-separated string
+}
+
+// JSONLRecord is one parsed line of a JSONL file. Err is set when the line
+// could not be mapped, so callers can report it and continue with the rest.
+type JSONLRecord struct {
+	Line     int
+	Title    string
+	Body     string
+	Tags     []string
+	Metadata map[string]interface{}
+	Err      error
+}
+
+// IsJSONLPath reports whether path has a .jsonl or .ndjson extension.
+func IsJSONLPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return true
+	}
+	return false
+}
+
+/*
+ReadJSONL reads a JSONL/NDJSON file with one JSON object per line.
+
+Blank lines are skipped. Lines that are not objects, or lack a non-empty string
+text field, are returned with Err set.
+*/
+func ReadJSONL(path string, fields JSONLFields) ([]JSONLRecord, error) {
+	if fields.Text == "" {
+		return nil, fmt.Errorf("text field name is required")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []JSONLRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLLineBytes)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		rec := parseJSONLRecord(line, fields)
+		rec.Line = lineNo
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", lineNo+1, err)
+	}
+	return records, nil
+}
+
+func parseJSONLRecord(line []byte, fields JSONLFields) JSONLRecord {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(line, &obj); err != nil {
+		return JSONLRecord{Err: fmt.Errorf("invalid JSON object: %w", err)}
+	}
+
+	body, ok := obj[fields.Text].(string)
+	if !ok || strings.TrimSpace(body) == "" {
+		return JSONLRecord{Err: fmt.Errorf("missing or empty string field %q", fields.Text)}
+	}
+	rec := JSONLRecord{Body: body}
+	delete(obj, fields.Text)
+
+	if fields.Title != "" {
+		if v, present := obj[fields.Title]; present {
+			title, ok := v.(string)
+			if !ok {
+				return JSONLRecord{Err: fmt.Errorf("field %q must be a string", fields.Title)}
+			}
+			rec.Title = title
+			delete(obj, fields.Title)
+		}
+	}
+
+	if fields.Tags != "" {
+		if v, present := obj[fields.Tags]; present {
+			tags, err := jsonlTags(v)
+			if err != nil {
+				return JSONLRecord{Err: fmt.Errorf("field %q: %w", fields.Tags, err)}
+			}
+			rec.Tags = tags
+			delete(obj, fields.Tags)
+		}
+	}
+
+	if len(obj) > 0 {
+		rec.Metadata = obj
+	}
+	return rec
+}
+
+// jsonlTags accepts an array of strings or a comma-separated string.
+func jsonlTags(v interface{}) ([]string, error) {
+	var raw []string
+	switch t := v.(type) {
+	case string:
+		raw = strings.Split(t, ",")
+	case []interface{}:
+		for _, item := range t {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("tags must be strings")
+			}
+			raw = append(raw, s)
+		}
+	case nil:
+	default:
+		return nil, fmt.Errorf("must be an array of strings or a comma-separated string")
+	}
+
+	var tags []string
+	for _, s := range raw {
+		if s = strings.TrimSpace(s); s != "" {
+			tags = append(tags, s)
+		}
+	}
+	return tags, nil
+}
@@ -77,8 +77,14 @@ func (p *defaultProcessor) Process(ctx context.Context, input string) (Result, e
 			fileSize := fi.Size()
 			mtime := fi.ModTime() // Get modification time
 
-			res.Body = string(data)
-			res.ContentType = ct // Store pointer to mtime
+			if isPDF(data) {
+				if err := setPDFBody(&res, data); err != nil {
+					return res, fmt.Errorf("failed to read PDF '%s': %w", input, err)
+				}
+			} else {
+				res.Body = string(data)
+				res.ContentType = ct
+			}
 			res.FilePath = &absPath  // Store pointer to absolute path
 			res.FileSize = &fileSize // Store pointer to size
 			res.Mtime = &mtime       // Store pointer to mtime
@@ -130,10 +136,17 @@ func (p *defaultProcessor) Process(ctx context.Context, input string) (Result, e
 		}
 
 		urlStr := parsedURL.String() // Get the cleaned URL string
-		res.Body = string(bodyBytes)
-		res.ContentType = ct
+		if isPDF(bodyBytes) {
+			if err := setPDFBody(&res, bodyBytes); err != nil {
+				return res, fmt.Errorf("failed to read PDF from URL '%s': %w", input, err)
+			}
+		} else {
+			res.Body = string(bodyBytes)
+			res.ContentType = ct
+		}
 		res.URL = &urlStr // Store pointer to URL string
 		res.Metadata["input_type"] = "url"
+		res.Metadata["source_url"] = urlStr // Kept in content metadata so the URL can be fetched again
 		return res, nil
 	}
 	// Not a valid URL or scheme not http/https
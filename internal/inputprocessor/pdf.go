@@ -0,0 +1,61 @@
+package inputprocessor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// pdfContentType is the content type stored for extracted PDF inputs.
+const pdfContentType = "application/pdf"
+
+// isPDF reports whether data starts with the PDF magic bytes.
+func isPDF(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("%PDF"))
+}
+
+// extractPDFText returns the plain text of a PDF and its page count. It fails
+// rather than returning binary data when the document cannot be parsed or has
+// no extractable text (e.g. scanned pages without OCR).
+func extractPDFText(data []byte) (text string, pages int, err error) {
+	// The parser panics on some malformed documents.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to parse PDF: %v", r)
+		}
+	}()
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to parse PDF: %w", err)
+	}
+	plain, err := reader.GetPlainText()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to extract PDF text: %w", err)
+	}
+	buf, err := io.ReadAll(plain)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to extract PDF text: %w", err)
+	}
+	text = strings.TrimSpace(string(buf))
+	if text == "" {
+		return "", 0, errors.New("PDF contains no extractable text")
+	}
+	return text, reader.NumPage(), nil
+}
+
+// setPDFBody extracts the text of a PDF into res, recording the page count.
+func setPDFBody(res *Result, data []byte) error {
+	text, pages, err := extractPDFText(data)
+	if err != nil {
+		return err
+	}
+	res.Body = text
+	res.ContentType = pdfContentType
+	res.Metadata["page_count"] = pages
+	return nil
+}
@@ -0,0 +1,75 @@
+package inputprocessor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// minimalPDF builds a single-page PDF showing text, with a valid xref table.
+func minimalPDF(text string) []byte {
+	stream := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func TestProcess_PDFFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doc.pdf")
+	require.NoError(t, os.WriteFile(path, minimalPDF("Hello PDF"), 0o644))
+
+	res, err := New().Process(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", res.ContentType)
+	assert.Contains(t, res.Body, "Hello PDF")
+	assert.Equal(t, 1, res.Metadata["page_count"])
+}
+
+func TestProcess_UnreadablePDFFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.pdf")
+	require.NoError(t, os.WriteFile(path, []byte("%PDF-1.4\n\x00\x01\x02 not really a pdf"), 0o644))
+
+	res, err := New().Process(context.Background(), path)
+	require.Error(t, err)
+	assert.Empty(t, res.Body)
+}
+
+func TestProcessUpload(t *testing.T) {
+	res, err := ProcessUpload(minimalPDF("Uploaded PDF"), "doc.pdf")
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", res.ContentType)
+	assert.Contains(t, res.Body, "Uploaded PDF")
+	assert.Equal(t, "doc.pdf", res.Metadata["filename"])
+
+	res, err = ProcessUpload([]byte("<html><body>Hi</body></html>"), "page.html")
+	require.NoError(t, err)
+	assert.Contains(t, res.ContentType, "text/html")
+
+	_, err = ProcessUpload([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\xff\xfe"), "image.png")
+	assert.ErrorIs(t, err, ErrUnsupportedUpload)
+}
@@ -0,0 +1,40 @@
+package inputprocessor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedUpload is returned by ProcessUpload for files that are neither
+// text nor PDF, such as images, whose bytes would be meaningless as a body.
+var ErrUnsupportedUpload = errors.New("unsupported file type")
+
+// ProcessUpload extracts the body of an uploaded file the way Process does for
+// a file on disk: PDFs are reduced to their text and other files are detected
+// by content (HTML, plain text). filename is recorded in the metadata only;
+// nothing is read from the filesystem.
+func ProcessUpload(data []byte, filename string) (Result, error) {
+	res := Result{Metadata: map[string]interface{}{}}
+
+	if isPDF(data) {
+		if err := setPDFBody(&res, data); err != nil {
+			return res, fmt.Errorf("failed to read uploaded PDF '%s': %w", filename, err)
+		}
+	} else {
+		if !utf8.Valid(data) {
+			return res, fmt.Errorf("%w: '%s' (%s)", ErrUnsupportedUpload, filename, http.DetectContentType(data))
+		}
+		res.Body = string(data)
+		res.ContentType = http.DetectContentType(data)
+	}
+
+	size := int64(len(data))
+	res.FileSize = &size
+	res.Metadata["input_type"] = "upload"
+	if filename != "" {
+		res.Metadata["filename"] = filename
+	}
+	return res, nil
+}
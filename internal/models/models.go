@@ -20,6 +20,7 @@ type AIUsageLog struct {
 	Cost            float64    `db:"cost"`
 	RelatedContentID *int64    `db:"related_content_id"` // nullable
 	RelatedJobID    *uuid.UUID `db:"related_job_id"`     // nullable UUID
+	OwnerID         string     `db:"owner_id"`           // Owner billed for the call
 }
 
 
@@ -45,9 +46,15 @@ type Content struct {
 	Metadata       json.RawMessage `db:"metadata"`
 	EmbeddingID    *uuid.UUID      `db:"embedding_id"`
 	IsEmbedded     bool            `db:"is_embedded"`
-	LastAccessedAt *time.Time      `db:"last_accessed_at"`
+	IsPinned       bool            `db:"is_pinned"`
+	EmbeddedHash   *string         `db:"embedded_hash"` // content_hash at the time the embedding was stored
+	InputVersion   *string         `db:"embedding_input_version"` // embedding.input_template version of the embeddings; nil is the default
+	LastAccessedAt *time.Time      `db:"last_accessed_at"` // Last time the content appeared in search results
+	ArchivedAt     *time.Time      `db:"archived_at"`      // Set when compaction removed the content's embeddings
 	ModifiedAt     *time.Time      `db:"modified_at"` // File modification time (nullable)
 	Summary        *string         `db:"summary"`     // Added for summarization
+	OwnerID        string          `db:"owner_id"`    // User or API key that added the content
+	Visibility     string          `db:"visibility"`  // "private" (owner only) or "shared"
 	CreatedAt      time.Time       `db:"created_at"`
 	UpdatedAt      time.Time       `db:"updated_at"`
 }
@@ -56,15 +63,51 @@ type Tag struct {
 	ID        int64     `db:"id"`
 	Name      string    `db:"name"`
 	Slug      string    `db:"slug"`
+	OwnerID   string    `db:"owner_id"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 }
 
+// TagUsage is a tag with the number of content items it is applied to.
+type TagUsage struct {
+	Tag   Tag `db:"tag" json:"tag"`
+	Count int `db:"count" json:"count"`
+}
+
+// TagCooccurrence counts the content items that carry both TagA and TagB.
+type TagCooccurrence struct {
+	TagA  Tag `db:"tag_a"`
+	TagB  Tag `db:"tag_b"`
+	Count int `db:"count"`
+}
+
+// ReindexRun tracks the progress of a bulk re-embedding operation.
+type ReindexRun struct {
+	ID        int64     `db:"id"`
+	Total     int       `db:"total"`
+	Completed int       `db:"completed"`
+	Failed    int       `db:"failed"`
+	Status    string    `db:"status"` // "running" or "finished"
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// WorkerHeartbeat is the last liveness report of a background worker process.
+type WorkerHeartbeat struct {
+	WorkerID   string    `db:"worker_id"`
+	Hostname   string    `db:"hostname"`
+	PID        int       `db:"pid"`
+	InFlight   int       `db:"in_flight"` // Tasks being processed at the last heartbeat
+	StartedAt  time.Time `db:"started_at"`
+	LastSeenAt time.Time `db:"last_seen_at"`
+}
+
 type Collection struct {
 	ID          int64     `db:"id"`
 	Name        string    `db:"name"`
 	Description *string   `db:"description"`
 	IsPinned    bool      `db:"is_pinned"`
+	OwnerID     string    `db:"owner_id"`
 	CreatedAt   time.Time `db:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at"`
 }
@@ -73,6 +116,7 @@ type SearchQuery struct {
 	ID           int64     `db:"id"`
 	Query        string    `db:"query"`
 	ResultsCount int       `db:"results_count"`
+	OwnerID      string    `db:"owner_id"`
 	ExecutedAt   time.Time `db:"executed_at"`
 	CreatedAt    time.Time `db:"created_at"`
 	UpdatedAt    time.Time `db:"updated_at"`
@@ -86,6 +130,14 @@ type SearchResult struct {
 	Rank           int       `db:"rank"`
 	CreatedAt      time.Time `db:"created_at"`
 	UpdatedAt      time.Time `db:"updated_at"`
+
+	// ChunkText is the text of the matched chunk. It is set by vector
+	// similarity search and not recorded in search history.
+	ChunkText string `db:"-"`
+	// Distance is the raw vector distance of a similarity search result, lower
+	// is closer; RelevanceScore then holds its similarity, higher is closer
+	// (store.Similarity: 1-distance for cosine, 1/(1+distance) for L2).
+	Distance float64 `db:"-"`
 }
 
 type EmbeddingEntry struct {
@@ -111,6 +163,7 @@ type BackgroundJob struct {
 	BatchInputFileID  *string         `db:"batch_input_file_id"`  // Use pointer for NULLable
 	BatchOutputFileID *string         `db:"batch_output_file_id"` // Use pointer for NULLable
 	JobData           json.RawMessage `db:"job_data"`             // Add field for job data (e.g., chunks)
+	LastError         *string         `db:"last_error"`           // Final error of a dead job
 	// Summary field removed - belongs to Content model
 	CreatedAt         time.Time       `db:"created_at"`
 	UpdatedAt         time.Time       `db:"updated_at"`
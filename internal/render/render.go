@@ -0,0 +1,61 @@
+// Package render converts stored content bodies into HTML that is safe to embed in a page.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"mime"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Source formats reported by HTML.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatText     = "text" // Non-text bodies, shown escaped and preformatted
+)
+
+var (
+	markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+	policy   = bluemonday.UGCPolicy() // Policies are safe for concurrent use once built
+)
+
+// HTML renders body according to contentType and returns sanitized HTML along
+// with the format it was treated as. HTML bodies are sanitized as-is; textual
+// bodies (markdown, and plain text, which renders the same way) go through
+// markdown first; anything else is escaped inside a <pre> block.
+func HTML(contentType, body string) (string, string, error) {
+	switch format := detectFormat(contentType); format {
+	case FormatHTML:
+		return policy.Sanitize(body), format, nil
+	case FormatMarkdown:
+		var buf bytes.Buffer
+		if err := markdown.Convert([]byte(body), &buf); err != nil {
+			return "", format, fmt.Errorf("render markdown: %w", err)
+		}
+		return string(policy.SanitizeBytes(buf.Bytes())), format, nil
+	default:
+		return "<pre>" + html.EscapeString(body) + "</pre>", format, nil
+	}
+}
+
+// detectFormat maps a MIME type (parameters such as charset are ignored) to a source format.
+func detectFormat(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return FormatHTML
+	case mediaType == "" || strings.HasPrefix(mediaType, "text/"):
+		return FormatMarkdown
+	default:
+		return FormatText
+	}
+}
@@ -0,0 +1,46 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name, contentType, body string
+		wantFormat              string
+		contains, excludes      []string
+	}{
+		{
+			name: "markdown", contentType: "text/markdown", body: "# Title\n\n*hi* <script>alert(1)</script>",
+			wantFormat: FormatMarkdown, contains: []string{"<h1", "<em>hi</em>"}, excludes: []string{"<script"},
+		},
+		{
+			name: "plain text renders as markdown", contentType: "text/plain; charset=utf-8", body: "some **bold** text",
+			wantFormat: FormatMarkdown, contains: []string{"<strong>bold</strong>"},
+		},
+		{
+			name: "html is sanitized", contentType: "text/html; charset=utf-8", body: `<p onclick="x()">ok</p><script>alert(1)</script><a href="javascript:alert(1)">l</a>`,
+			wantFormat: FormatHTML, contains: []string{"<p>ok</p>"}, excludes: []string{"<script", "onclick", "javascript:"},
+		},
+		{
+			name: "binary is escaped", contentType: "application/octet-stream", body: "<b>x</b>",
+			wantFormat: FormatText, contains: []string{"<pre>&lt;b&gt;x&lt;/b&gt;</pre>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, format, err := HTML(tt.contentType, tt.body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFormat, format)
+			for _, s := range tt.contains {
+				assert.Contains(t, out, s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, out, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+	"mimir/internal/chunking"
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// AppendEmbedder embeds text appended to already-embedded content as additional
+// chunks, continuing the content's chunk_index sequence. Existing chunk embeddings
+// keep their vectors, so embedding cost grows with the appended text rather than
+// the whole document; only their total_chunks metadata is updated.
+type AppendEmbedder struct {
+	contents  store.ContentStore
+	tags      store.TagStore
+	vector    store.VectorStore
+	embedder  store.EmbeddingService
+	jobs      store.JobClient
+	maxTokens int
+	overlap   chunking.Overlap
+	maxChunks int
+	dedup     bool
+	input     *EmbeddingInputTemplate
+}
+
+// AppendResult reports what an EmbedAppended call did; the worker stores it as
+// the task result.
+type AppendResult struct {
+	ChunksEmbedded         int  `json:"chunks_embedded"`
+	DuplicateChunksSkipped int  `json:"duplicate_chunks_skipped"` // Repeats of stored or earlier appended chunks; see SetDedupChunks
+	Reembedded             bool `json:"reembedded"`               // A full embedding job was enqueued instead
+}
+
+// NewAppendEmbedder creates an AppendEmbedder. jobs is used to fall back to a full
+// re-embed when the appended text cannot be embedded incrementally.
+func NewAppendEmbedder(contents store.ContentStore, tags store.TagStore, vector store.VectorStore, embedder store.EmbeddingService, jobs store.JobClient, maxTokens int, overlap chunking.Overlap) *AppendEmbedder {
+	return &AppendEmbedder{
+		contents:  contents,
+		tags:      tags,
+		vector:    vector,
+		embedder:  embedder,
+		jobs:      jobs,
+		maxTokens: maxTokens,
+		overlap:   overlap,
+	}
+}
+
+// SetMaxChunks caps the chunks stored per document, counting the chunks embedded
+// before the append; 0 means no cap.
+func (e *AppendEmbedder) SetMaxChunks(maxChunks int) {
+	e.maxChunks = maxChunks
+}
+
+// SetDedupChunks skips embedding appended chunks whose text repeats a chunk
+// already stored for the content or an earlier chunk of the same appended text.
+func (e *AppendEmbedder) SetDedupChunks(dedup bool) {
+	e.dedup = dedup
+}
+
+// SetInputTemplate sets the current embedding input template. Content embedded
+// from another template version is re-embedded in full instead of incrementally.
+func (e *AppendEmbedder) SetInputTemplate(t *EmbeddingInputTemplate) {
+	e.input = t
+}
+
+// EmbedAppended embeds text that was appended to the content, changing its hash
+// from fromHash to toHash. When the stored embeddings do not cover the fromHash
+// version (never embedded, stale, or another append got there first), the whole
+// content is re-embedded instead. Appended chunks record toHash as their
+// "appended_hash" metadata, and any left by an earlier, failed attempt at the
+// same append are deleted first, so a retried job does not duplicate them.
+func (e *AppendEmbedder) EmbedAppended(ctx context.Context, contentID int64, fromHash, toHash, text string) (AppendResult, error) {
+	var result AppendResult
+	content, err := e.contents.GetContent(ctx, contentID)
+	if err != nil {
+		return result, fmt.Errorf("get content %d: %w", contentID, err)
+	}
+
+	if !content.IsEmbedded || content.EmbeddedHash == nil || *content.EmbeddedHash != fromHash {
+		log.Printf("INFO: Embeddings for content %d do not match the pre-append version, re-embedding all chunks", contentID)
+		return e.reembed(ctx, contentID)
+	}
+	if e.input != nil && inputVersionOf(content) != e.input.Version() {
+		log.Printf("INFO: Embeddings for content %d were built from another embedding input template, re-embedding all chunks", contentID)
+		return e.reembed(ctx, contentID)
+	}
+
+	if err := e.vector.DeleteAppendedChunks(ctx, contentID, toHash); err != nil {
+		return result, err
+	}
+	last, err := e.vector.LastChunkIndex(ctx, contentID)
+	if err != nil {
+		return result, err
+	}
+	if last < 0 {
+		log.Printf("INFO: Content %d has no chunk embeddings, re-embedding all chunks", contentID)
+		return e.reembed(ctx, contentID)
+	}
+
+	appended := *content
+	appended.Body = text
+	chunks := embeddableChunks(chunking.ContentAwareChunk(&appended, e.maxTokens, e.overlap, 0))
+	if e.dedup {
+		stored, err := e.vector.ListEmbeddingsByContentID(ctx, contentID)
+		if err != nil {
+			return result, fmt.Errorf("list stored chunks of content %d: %w", contentID, err)
+		}
+		existing := make([]string, len(stored))
+		for i, s := range stored {
+			existing[i] = s.ChunkText
+		}
+		chunks, result.DuplicateChunksSkipped = chunking.DedupChunksAgainst(chunks, existing)
+		if result.DuplicateChunksSkipped > 0 {
+			log.Printf("INFO: Skipping %d duplicate chunks appended to content %d", result.DuplicateChunksSkipped, contentID)
+		}
+	}
+	if e.maxChunks > 0 {
+		room := e.maxChunks - (last + 1)
+		if room < 0 {
+			room = 0
+		}
+		if len(chunks) > room {
+			log.Printf("WARN: Content %d would have %d chunks after the append, over the limit of %d (chunking.max_chunks_per_doc). Dropping the remaining %d appended chunks.",
+				contentID, last+1+len(chunks), e.maxChunks, len(chunks)-room)
+			chunks = chunks[:room]
+		}
+	}
+
+	var vectors []pgvector.Vector
+	if len(chunks) > 0 {
+		texts := make([]string, len(chunks))
+		for i, c := range chunks {
+			texts[i] = c.Text
+		}
+		vectors, err = e.embedder.GenerateEmbeddings(ctx, texts)
+		if err != nil {
+			return result, fmt.Errorf("generate embeddings for appended text of content %d: %w", contentID, err)
+		}
+		if len(vectors) != len(chunks) {
+			return result, fmt.Errorf("generate embeddings for appended text of content %d: got %d vectors for %d chunks", contentID, len(vectors), len(chunks))
+		}
+		chunks, vectors = dropZeroVectors(contentID, chunks, vectors)
+	}
+
+	result.ChunksEmbedded = len(chunks)
+	total := last + 1 + len(chunks)
+	var firstID uuid.UUID
+	for i, c := range chunks {
+		chunkMeta := make(map[string]interface{}, len(c.Metadata)+3)
+		for k, v := range c.Metadata {
+			chunkMeta[k] = v
+		}
+		chunkMeta["chunk_index"] = last + 1 + i
+		chunkMeta["total_chunks"] = total
+		chunkMeta["appended_hash"] = toHash
+
+		meta, err := ContentEmbeddingMetadata(ctx, e.tags, content, chunkMeta)
+		if err != nil {
+			return result, err
+		}
+		entry := &models.EmbeddingEntry{
+			ID:        uuid.New(),
+			ContentID: contentID,
+			ChunkText: c.Text,
+			Vector:    vectors[i],
+			Metadata:  meta,
+		}
+		if err := e.vector.AddEmbedding(ctx, entry); err != nil {
+			return result, fmt.Errorf("store appended chunk %d of content %d: %w", last+1+i, contentID, err)
+		}
+		if i == 0 {
+			firstID = entry.ID
+		}
+	}
+
+	if len(chunks) > 0 {
+		if err := e.vector.SetTotalChunks(ctx, contentID, total); err != nil {
+			return result, err
+		}
+	}
+
+	// A later append changed the body again; leave embedded_hash behind so that
+	// append's job sees the mismatch and re-embeds everything.
+	if content.ContentHash != toHash {
+		return result, nil
+	}
+	embeddingID := firstID
+	if content.EmbeddingID != nil {
+		embeddingID = *content.EmbeddingID
+	}
+	if embeddingID == uuid.Nil {
+		return result, nil
+	}
+	if err := e.contents.UpdateContentEmbeddingStatus(ctx, contentID, embeddingID, true); err != nil {
+		return result, fmt.Errorf("mark content %d embedded after append: %w", contentID, err)
+	}
+	return result, nil
+}
+
+// embeddableChunks drops chunks with no non-whitespace text; embedding them
+// would only store meaningless vectors.
+func embeddableChunks(chunks []chunking.Chunk) []chunking.Chunk {
+	kept := chunks[:0]
+	for _, c := range chunks {
+		if strings.TrimSpace(c.Text) != "" {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// dropZeroVectors removes chunks whose embedding came back all zeros, which
+// would match nothing (or everything) in similarity search.
+func dropZeroVectors(contentID int64, chunks []chunking.Chunk, vectors []pgvector.Vector) ([]chunking.Chunk, []pgvector.Vector) {
+	keptChunks := chunks[:0]
+	keptVectors := vectors[:0]
+	for i, v := range vectors {
+		if store.IsZeroVector(v) {
+			log.Printf("WARN: Skipping zero embedding for a chunk of content %d", contentID)
+			continue
+		}
+		keptChunks = append(keptChunks, chunks[i])
+		keptVectors = append(keptVectors, v)
+	}
+	return keptChunks, keptVectors
+}
+
+// reembed falls back to a full embedding job for the content.
+func (e *AppendEmbedder) reembed(ctx context.Context, contentID int64) (AppendResult, error) {
+	if e.jobs == nil {
+		return AppendResult{}, fmt.Errorf("job client is not initialized")
+	}
+	return AppendResult{Reembedded: true}, e.jobs.EnqueueEmbeddingJob(ctx, contentID)
+}
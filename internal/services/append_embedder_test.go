@@ -0,0 +1,243 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/chunking"
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+type appendTagStore struct{ store.TagStore }
+
+func (appendTagStore) GetContentTags(ctx context.Context, contentID int64) ([]*models.Tag, error) {
+	return []*models.Tag{{ID: 7}}, nil
+}
+
+// appendVectorStore holds chunks 0 to last plus the added entries.
+type appendVectorStore struct {
+	store.VectorStore
+	last   int
+	stored []string // Text of the chunks 0 to last, if set
+	added  []*models.EmbeddingEntry
+	total  int // Set by SetTotalChunks
+}
+
+func (v *appendVectorStore) ListEmbeddingsByContentID(ctx context.Context, contentID int64) ([]*models.EmbeddingEntry, error) {
+	entries := make([]*models.EmbeddingEntry, 0, len(v.stored)+len(v.added))
+	for _, text := range v.stored {
+		entries = append(entries, &models.EmbeddingEntry{ContentID: contentID, ChunkText: text})
+	}
+	return append(entries, v.added...), nil
+}
+
+func (v *appendVectorStore) LastChunkIndex(ctx context.Context, contentID int64) (int, error) {
+	last := v.last
+	for _, e := range v.added {
+		var meta map[string]interface{}
+		if err := json.Unmarshal(e.Metadata, &meta); err != nil {
+			return 0, err
+		}
+		if i, ok := meta["chunk_index"].(float64); ok && int(i) > last {
+			last = int(i)
+		}
+	}
+	return last, nil
+}
+
+func (v *appendVectorStore) DeleteAppendedChunks(ctx context.Context, contentID int64, appendedHash string) error {
+	kept := v.added[:0]
+	for _, e := range v.added {
+		var meta map[string]interface{}
+		if err := json.Unmarshal(e.Metadata, &meta); err != nil {
+			return err
+		}
+		if meta["appended_hash"] != appendedHash {
+			kept = append(kept, e)
+		}
+	}
+	v.added = kept
+	return nil
+}
+
+func (v *appendVectorStore) SetTotalChunks(ctx context.Context, contentID int64, total int) error {
+	v.total = total
+	return nil
+}
+
+func (v *appendVectorStore) AddEmbedding(ctx context.Context, entry *models.EmbeddingEntry) error {
+	v.added = append(v.added, entry)
+	return nil
+}
+
+type appendEmbeddingService struct{ store.EmbeddingService }
+
+func (appendEmbeddingService) GenerateEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	out := make([]pgvector.Vector, len(texts))
+	for i := range texts {
+		out[i] = pgvector.NewVector([]float32{1, 0})
+	}
+	return out, nil
+}
+
+func TestAppendEmbedder_ContinuesChunkIndex(t *testing.T) {
+	embeddedHash := "old"
+	contents := newMemContentStore(&models.Content{
+		ID: 1, SourceID: 3, ContentHash: "new", IsEmbedded: true, EmbeddedHash: &embeddedHash,
+	})
+	vectors := &appendVectorStore{last: 2}
+	jobs := &recordingJobClient{}
+	e := services.NewAppendEmbedder(contents, appendTagStore{}, vectors, appendEmbeddingService{}, jobs, 200, chunking.Overlap{})
+
+	_, err := e.EmbedAppended(context.Background(), 1, "old", "new", "A new journal entry.")
+	require.NoError(t, err)
+
+	require.Len(t, vectors.added, 1)
+	var meta map[string]interface{}
+	require.NoError(t, json.Unmarshal(vectors.added[0].Metadata, &meta))
+	assert.EqualValues(t, 3, meta["chunk_index"])
+	assert.EqualValues(t, 4, meta["total_chunks"])
+	assert.EqualValues(t, 3, meta[store.FilterSourceID])
+	assert.Equal(t, 4, vectors.total, "existing chunks get the new total")
+	assert.Equal(t, []int64{1}, contents.marked)
+	assert.Empty(t, jobs.embedded)
+}
+
+func TestAppendEmbedder_RetryReplacesPartialAppend(t *testing.T) {
+	embeddedHash := "old"
+	contents := newMemContentStore(&models.Content{
+		ID: 1, ContentHash: "new", IsEmbedded: true, EmbeddedHash: &embeddedHash,
+	})
+	vectors := &appendVectorStore{last: 2}
+	e := services.NewAppendEmbedder(contents, appendTagStore{}, vectors, appendEmbeddingService{}, &recordingJobClient{}, 200, chunking.Overlap{})
+
+	for attempt := 0; attempt < 2; attempt++ {
+		_, err := e.EmbedAppended(context.Background(), 1, "old", "new", "A new journal entry.")
+		require.NoError(t, err)
+	}
+
+	require.Len(t, vectors.added, 1, "the retry replaces the chunks of the first attempt")
+	var meta map[string]interface{}
+	require.NoError(t, json.Unmarshal(vectors.added[0].Metadata, &meta))
+	assert.EqualValues(t, 3, meta["chunk_index"])
+	assert.Equal(t, 4, vectors.total)
+}
+
+func TestAppendEmbedder_FallsBackWhenEmbeddingsStale(t *testing.T) {
+	embeddedHash := "older"
+	contents := newMemContentStore(&models.Content{
+		ID: 1, ContentHash: "new", IsEmbedded: true, EmbeddedHash: &embeddedHash,
+	})
+	vectors := &appendVectorStore{last: 2}
+	jobs := &recordingJobClient{}
+	e := services.NewAppendEmbedder(contents, appendTagStore{}, vectors, appendEmbeddingService{}, jobs, 200, chunking.Overlap{})
+
+	_, err := e.EmbedAppended(context.Background(), 1, "old", "new", "More text.")
+	require.NoError(t, err)
+
+	assert.Empty(t, vectors.added)
+	assert.Equal(t, []int64{1}, jobs.embedded)
+}
+
+// zeroForBlankEmbeddingService mimics providers that return a zero vector for
+// text they treat as empty.
+type zeroForBlankEmbeddingService struct{ store.EmbeddingService }
+
+func (zeroForBlankEmbeddingService) GenerateEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	out := make([]pgvector.Vector, len(texts))
+	for i, t := range texts {
+		if strings.Trim(t, " \t\n.") == "" {
+			out[i] = pgvector.NewVector([]float32{0, 0})
+		} else {
+			out[i] = pgvector.NewVector([]float32{1, 0})
+		}
+	}
+	return out, nil
+}
+
+func TestAppendEmbedder_SkipsEmptyText(t *testing.T) {
+	embeddedHash := "old"
+	embeddingID := uuid.New()
+	for _, text := range []string{"", "   \n\t  ", "..."} {
+		contents := newMemContentStore(&models.Content{
+			ID: 1, ContentHash: "new", IsEmbedded: true, EmbeddedHash: &embeddedHash, EmbeddingID: &embeddingID,
+		})
+		vectors := &appendVectorStore{last: 2}
+		jobs := &recordingJobClient{}
+		e := services.NewAppendEmbedder(contents, appendTagStore{}, vectors, zeroForBlankEmbeddingService{}, jobs, 200, chunking.Overlap{})
+
+		_, err := e.EmbedAppended(context.Background(), 1, "old", "new", text)
+		require.NoError(t, err)
+
+		assert.Empty(t, vectors.added, "text %q", text)
+		assert.Equal(t, []int64{1}, contents.marked, "text %q", text)
+		assert.Empty(t, jobs.embedded)
+	}
+}
+
+func TestAppendEmbedder_DedupChunks(t *testing.T) {
+	embeddedHash := "old"
+	contents := newMemContentStore(&models.Content{
+		ID: 1, ContentHash: "new", IsEmbedded: true, EmbeddedHash: &embeddedHash,
+	})
+	vectors := &appendVectorStore{last: 2}
+	e := services.NewAppendEmbedder(contents, appendTagStore{}, vectors, appendEmbeddingService{}, &recordingJobClient{}, 3, chunking.Overlap{})
+	e.SetDedupChunks(true)
+
+	result, err := e.EmbedAppended(context.Background(), 1, "old", "new", "see you soon see you soon goodbye for now")
+	require.NoError(t, err)
+
+	assert.Equal(t, services.AppendResult{ChunksEmbedded: 2, DuplicateChunksSkipped: 1}, result)
+	require.Len(t, vectors.added, 2)
+	assert.Equal(t, "goodbye for now", strings.TrimSpace(vectors.added[1].ChunkText))
+}
+
+func TestAppendEmbedder_MaxChunksCapsDocument(t *testing.T) {
+	embeddedHash := "h0"
+	contents := newMemContentStore(&models.Content{
+		ID: 1, ContentHash: "h1", IsEmbedded: true, EmbeddedHash: &embeddedHash,
+	})
+	vectors := &appendVectorStore{last: 2}
+	e := services.NewAppendEmbedder(contents, appendTagStore{}, vectors, appendEmbeddingService{}, &recordingJobClient{}, 200, chunking.Overlap{})
+	e.SetMaxChunks(5)
+
+	var embedded []int
+	for i := 1; i <= 3; i++ {
+		from, to := fmt.Sprintf("h%d", i-1), fmt.Sprintf("h%d", i)
+		contents.contents[1].ContentHash = to
+		result, err := e.EmbedAppended(context.Background(), 1, from, to, fmt.Sprintf("Journal entry %d.", i))
+		require.NoError(t, err)
+		embedded = append(embedded, result.ChunksEmbedded)
+	}
+
+	assert.Equal(t, []int{1, 1, 0}, embedded, "appends stop once the document holds max chunks")
+	assert.Len(t, vectors.added, 2)
+	assert.Equal(t, 5, vectors.total)
+	assert.Equal(t, []int64{1, 1, 1}, contents.marked, "capped appends still leave the content embedded")
+}
+
+func TestAppendEmbedder_DedupChunksAgainstStoredChunks(t *testing.T) {
+	embeddedHash := "old"
+	contents := newMemContentStore(&models.Content{
+		ID: 1, ContentHash: "new", IsEmbedded: true, EmbeddedHash: &embeddedHash,
+	})
+	vectors := &appendVectorStore{last: 1, stored: []string{"Entry one.", "Sent from my phone"}}
+	e := services.NewAppendEmbedder(contents, appendTagStore{}, vectors, appendEmbeddingService{}, &recordingJobClient{}, 200, chunking.Overlap{})
+	e.SetDedupChunks(true)
+
+	result, err := e.EmbedAppended(context.Background(), 1, "old", "new", "Sent from my phone")
+	require.NoError(t, err)
+
+	assert.Equal(t, services.AppendResult{DuplicateChunksSkipped: 1}, result, "appended boilerplate matching a stored chunk is not embedded again")
+	assert.Empty(t, vectors.added)
+}
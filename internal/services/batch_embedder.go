@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+	"mimir/internal/chunking"
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// maxTextsPerEmbeddingCall caps the chunk texts sent in one GenerateEmbeddings
+// call by BatchEmbedder, keeping requests well under provider input limits.
+const maxTextsPerEmbeddingCall = 256
+
+// BatchEmbedder embeds many content items together: the chunks of all items
+// are embedded with as few GenerateEmbeddings calls as possible instead of one
+// embedding job, and at least one API call, per item. It backs the batch
+// embedding job enqueued by JobClient.EnqueueBatchEmbeddingJob.
+type BatchEmbedder struct {
+	contents  store.ContentStore
+	tags      store.TagStore
+	sources   store.SourceStore
+	vector    store.VectorStore
+	embedder  store.EmbeddingService
+	locker    store.ContentLocker
+	maxTokens int
+	overlap   chunking.Overlap
+	maxChunks int
+	dedup     bool
+	input     *EmbeddingInputTemplate
+}
+
+// BatchEmbedResult reports what an EmbedContents call did; the worker stores it
+// as the task result.
+type BatchEmbedResult struct {
+	Embedded       []int64          `json:"embedded"`
+	Skipped        []int64          `json:"skipped"` // Already embedded, not found or without embeddable text
+	Failed         map[int64]string `json:"failed,omitempty"`
+	ChunksEmbedded int              `json:"chunks_embedded"`
+	EmbeddingCalls int              `json:"embedding_calls"`
+}
+
+// batchEmbedItem is a content item prepared for embedding.
+type batchEmbedItem struct {
+	content *models.Content
+	chunks  []chunking.Chunk
+	vectors []pgvector.Vector
+}
+
+// NewBatchEmbedder creates a BatchEmbedder.
+func NewBatchEmbedder(contents store.ContentStore, tags store.TagStore, vector store.VectorStore, embedder store.EmbeddingService, maxTokens int, overlap chunking.Overlap) *BatchEmbedder {
+	return &BatchEmbedder{
+		contents:  contents,
+		tags:      tags,
+		vector:    vector,
+		embedder:  embedder,
+		maxTokens: maxTokens,
+		overlap:   overlap,
+	}
+}
+
+// SetMaxChunks caps the chunks embedded per content item; 0 means no cap.
+func (e *BatchEmbedder) SetMaxChunks(maxChunks int) {
+	e.maxChunks = maxChunks
+}
+
+// SetDedupChunks skips embedding chunks whose text repeats an earlier chunk of
+// the same content item.
+func (e *BatchEmbedder) SetDedupChunks(dedup bool) {
+	e.dedup = dedup
+}
+
+// SetInputTemplate sets the embedding input template, and the source store it
+// reads source names from. The template's version is recorded with the embeddings.
+func (e *BatchEmbedder) SetInputTemplate(t *EmbeddingInputTemplate, sources store.SourceStore) {
+	e.input = t
+	e.sources = sources
+}
+
+// SetLocker makes EmbedContents hold each item's content lock while storing its
+// embeddings, as EmbeddingJobGuard does for single-item embedding jobs. Only one
+// item is locked at a time, so a batch job holds at most one pooled connection
+// for locking however many items it has.
+func (e *BatchEmbedder) SetLocker(locker store.ContentLocker) {
+	e.locker = locker
+}
+
+// EmbedContents chunks and embeds the content items, replacing any embeddings
+// they had. Items whose embeddings already cover their current body are
+// skipped, so a retried job only embeds what is left. Items that cannot be
+// stored are reported in the result and make the call return an error once
+// the other items are done; a failed embedding API call fails the whole call
+// before anything is stored.
+func (e *BatchEmbedder) EmbedContents(ctx context.Context, contentIDs []int64) (BatchEmbedResult, error) {
+	result := BatchEmbedResult{Embedded: []int64{}, Skipped: []int64{}}
+	ids := uniqueIDs(contentIDs)
+	if len(ids) == 0 {
+		return result, nil
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	contents, err := e.contents.GetContentsByIDs(ctx, ids)
+	if err != nil {
+		return result, fmt.Errorf("get %d content items for batch embedding: %w", len(ids), err)
+	}
+	found := make(map[int64]bool, len(contents))
+	var items []*batchEmbedItem
+	for _, content := range contents {
+		found[content.ID] = true
+		if e.upToDate(content) {
+			result.Skipped = append(result.Skipped, content.ID)
+			continue
+		}
+		item, err := e.prepare(ctx, content)
+		if err != nil {
+			result.fail(content.ID, err)
+			continue
+		}
+		if len(item.chunks) == 0 {
+			log.Printf("INFO: Content %d has no embeddable text, skipping it in batch embedding", content.ID)
+			result.Skipped = append(result.Skipped, content.ID)
+			continue
+		}
+		items = append(items, item)
+	}
+	for _, id := range ids {
+		if !found[id] {
+			log.Printf("WARN: Content %d not found for batch embedding, skipping it", id)
+			result.Skipped = append(result.Skipped, id)
+		}
+	}
+
+	if err := e.generate(ctx, items, &result); err != nil {
+		return result, err
+	}
+
+	for _, item := range items {
+		chunks, stored, err := e.storeLocked(ctx, item)
+		if err != nil {
+			result.fail(item.content.ID, err)
+			continue
+		}
+		if !stored {
+			result.Skipped = append(result.Skipped, item.content.ID)
+			continue
+		}
+		result.Embedded = append(result.Embedded, item.content.ID)
+		result.ChunksEmbedded += chunks
+	}
+	sort.Slice(result.Skipped, func(i, j int) bool { return result.Skipped[i] < result.Skipped[j] })
+
+	if len(result.Failed) > 0 {
+		return result, fmt.Errorf("batch embedding failed for %d of %d content items", len(result.Failed), len(ids))
+	}
+	return result, nil
+}
+
+// upToDate reports whether the content's embeddings cover its current body and
+// input template.
+func (e *BatchEmbedder) upToDate(content *models.Content) bool {
+	if !content.IsEmbedded || content.EmbeddedHash == nil || *content.EmbeddedHash != content.ContentHash {
+		return false
+	}
+	return e.input == nil || inputVersionOf(content) == e.input.Version()
+}
+
+// prepare builds the embedding input of the content and chunks it.
+func (e *BatchEmbedder) prepare(ctx context.Context, content *models.Content) (*batchEmbedItem, error) {
+	input := content
+	if e.input != nil {
+		var err error
+		if input, err = e.input.Apply(ctx, e.sources, e.tags, content); err != nil {
+			return nil, err
+		}
+	}
+	chunks := embeddableChunks(chunking.ContentAwareChunk(input, e.maxTokens, e.overlap, e.maxChunks))
+	if e.dedup {
+		var skipped int
+		chunks, skipped = chunking.DedupChunks(chunks)
+		if skipped > 0 {
+			log.Printf("INFO: Skipping %d duplicate chunks of content %d", skipped, content.ID)
+		}
+	}
+	return &batchEmbedItem{content: content, chunks: chunks}, nil
+}
+
+// generate embeds the chunks of all items, at most maxTextsPerEmbeddingCall
+// texts per call, and hands each item its vectors.
+func (e *BatchEmbedder) generate(ctx context.Context, items []*batchEmbedItem, result *BatchEmbedResult) error {
+	var texts []string
+	for _, item := range items {
+		for _, c := range item.chunks {
+			texts = append(texts, c.Text)
+		}
+	}
+
+	vectors := make([]pgvector.Vector, 0, len(texts))
+	for start := 0; start < len(texts); start += maxTextsPerEmbeddingCall {
+		end := start + maxTextsPerEmbeddingCall
+		if end > len(texts) {
+			end = len(texts)
+		}
+		batch, err := e.embedder.GenerateEmbeddings(ctx, texts[start:end])
+		result.EmbeddingCalls++
+		if err != nil {
+			return fmt.Errorf("generate embeddings for %d chunks: %w", end-start, err)
+		}
+		if len(batch) != end-start {
+			return fmt.Errorf("generate embeddings: got %d vectors for %d chunks", len(batch), end-start)
+		}
+		vectors = append(vectors, batch...)
+	}
+
+	next := 0
+	for _, item := range items {
+		item.vectors = vectors[next : next+len(item.chunks)]
+		next += len(item.chunks)
+	}
+	return nil
+}
+
+// storeLocked stores the item's embeddings while holding its content lock.
+// Content whose body changed since it was chunked is not stored (stored is
+// false); the change enqueued its own embedding job.
+func (e *BatchEmbedder) storeLocked(ctx context.Context, item *batchEmbedItem) (chunks int, stored bool, err error) {
+	if e.locker != nil {
+		unlock, err := e.locker.LockContent(ctx, item.content.ID)
+		if err != nil {
+			return 0, false, fmt.Errorf("lock content %d for embedding: %w", item.content.ID, err)
+		}
+		defer unlock()
+	}
+	current, err := e.contents.GetContent(ctx, item.content.ID)
+	if err != nil {
+		return 0, false, fmt.Errorf("get content %d: %w", item.content.ID, err)
+	}
+	if current.ContentHash != item.content.ContentHash {
+		log.Printf("INFO: Content %d changed during batch embedding, leaving it to its own embedding job", item.content.ID)
+		return 0, false, nil
+	}
+	chunks, err = e.store(ctx, item)
+	return chunks, err == nil, err
+}
+
+// store replaces the item's embeddings with its new chunk embeddings and marks
+// the content embedded. It returns the number of chunks stored.
+func (e *BatchEmbedder) store(ctx context.Context, item *batchEmbedItem) (int, error) {
+	content := item.content
+	chunks, vectors := dropZeroVectors(content.ID, item.chunks, item.vectors)
+	if len(chunks) == 0 {
+		return 0, fmt.Errorf("every chunk embedding of content %d was a zero vector", content.ID)
+	}
+
+	if err := e.vector.DeleteEmbeddingsByContentID(ctx, content.ID); err != nil {
+		return 0, fmt.Errorf("delete old embeddings of content %d: %w", content.ID, err)
+	}
+	var firstID uuid.UUID
+	for i, c := range chunks {
+		chunkMeta := make(map[string]interface{}, len(c.Metadata)+2)
+		for k, v := range c.Metadata {
+			chunkMeta[k] = v
+		}
+		chunkMeta["chunk_index"] = i
+		chunkMeta["total_chunks"] = len(chunks)
+
+		meta, err := ContentEmbeddingMetadata(ctx, e.tags, content, chunkMeta)
+		if err != nil {
+			return 0, err
+		}
+		entry := &models.EmbeddingEntry{
+			ID:        uuid.New(),
+			ContentID: content.ID,
+			ChunkText: c.Text,
+			Vector:    vectors[i],
+			Metadata:  meta,
+		}
+		if err := e.vector.AddEmbedding(ctx, entry); err != nil {
+			return 0, fmt.Errorf("store chunk %d of content %d: %w", i, content.ID, err)
+		}
+		if i == 0 {
+			firstID = entry.ID
+		}
+	}
+
+	if err := e.contents.UpdateContentEmbeddingStatus(ctx, content.ID, firstID, true); err != nil {
+		return 0, fmt.Errorf("mark content %d embedded: %w", content.ID, err)
+	}
+	if e.input != nil {
+		if err := e.contents.SetEmbeddingInputVersion(ctx, content.ID, e.input.Version()); err != nil {
+			return 0, fmt.Errorf("record embedding input version of content %d: %w", content.ID, err)
+		}
+	}
+	return len(chunks), nil
+}
+
+// fail records that embedding the content failed.
+func (r *BatchEmbedResult) fail(contentID int64, err error) {
+	log.Printf("WARN: Batch embedding of content %d failed: %v", contentID, err)
+	if r.Failed == nil {
+		r.Failed = make(map[int64]string)
+	}
+	r.Failed[contentID] = err.Error()
+}
@@ -0,0 +1,135 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/chunking"
+	"mimir/internal/config"
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+type batchEmbedVectorStore struct {
+	appendVectorStore
+	deleted []int64
+}
+
+func (v *batchEmbedVectorStore) DeleteEmbeddingsByContentID(ctx context.Context, contentID int64) error {
+	v.deleted = append(v.deleted, contentID)
+	return nil
+}
+
+type countingEmbeddingService struct {
+	appendEmbeddingService
+	calls int
+}
+
+func (s *countingEmbeddingService) GenerateEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	s.calls++
+	return s.appendEmbeddingService.GenerateEmbeddings(ctx, texts)
+}
+
+func TestBatchEmbedder_EmbedContentsSharesEmbeddingCalls(t *testing.T) {
+	hash := "h2"
+	contents := &memContentStore{contents: map[int64]*models.Content{
+		1: {ID: 1, Body: "First note.", ContentHash: "h1"},
+		2: {ID: 2, Body: "Already embedded.", ContentHash: "h2", IsEmbedded: true, EmbeddedHash: &hash},
+		3: {ID: 3, Body: "Third note.", ContentHash: "h3"},
+		4: {ID: 4, Body: "   ", ContentHash: "h4"},
+	}}
+	vectors := &batchEmbedVectorStore{}
+	embedder := &countingEmbeddingService{}
+	e := services.NewBatchEmbedder(contents, appendTagStore{}, vectors, embedder, 200, chunking.Overlap{})
+
+	result, err := e.EmbedContents(context.Background(), []int64{3, 1, 2, 4, 9, 1})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, embedder.calls, "all chunks go in one embedding call")
+	assert.Equal(t, []int64{1, 3}, result.Embedded)
+	assert.Equal(t, []int64{2, 4, 9}, result.Skipped, "embedded, blank and missing content is skipped")
+	assert.Equal(t, 2, result.ChunksEmbedded)
+	assert.Equal(t, []int64{1, 3}, vectors.deleted)
+	assert.Equal(t, []int64{1, 3}, contents.marked)
+	require.Len(t, vectors.added, 2)
+	assert.Equal(t, int64(1), vectors.added[0].ContentID)
+	assert.Equal(t, "Third note.", vectors.added[1].ChunkText)
+}
+
+// poolLimitedLocker fails like an exhausted connection pool once more than max
+// locks are held at the same time.
+type poolLimitedLocker struct {
+	max, held, peak int
+}
+
+func (l *poolLimitedLocker) LockContent(ctx context.Context, contentID int64) (func(), error) {
+	if l.held == l.max {
+		return nil, errors.New("connection pool exhausted")
+	}
+	l.held++
+	if l.held > l.peak {
+		l.peak = l.held
+	}
+	return func() { l.held-- }, nil
+}
+
+func TestBatchEmbedder_LocksOneItemAtATime(t *testing.T) {
+	contents := &memContentStore{contents: map[int64]*models.Content{}}
+	ids := make([]int64, 50)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+		contents.contents[ids[i]] = &models.Content{ID: ids[i], Body: "Note.", ContentHash: "h"}
+	}
+	locker := &poolLimitedLocker{max: 1}
+	e := services.NewBatchEmbedder(contents, appendTagStore{}, &batchEmbedVectorStore{}, &countingEmbeddingService{}, 200, chunking.Overlap{})
+	e.SetLocker(locker)
+
+	result, err := e.EmbedContents(context.Background(), ids)
+	require.NoError(t, err)
+	assert.Len(t, result.Embedded, 50)
+	assert.Equal(t, 1, locker.peak)
+	assert.Zero(t, locker.held, "every lock is released")
+}
+
+type batchJobClient struct {
+	recordingJobClient
+	batches [][]int64
+}
+
+func (j *batchJobClient) EnqueueBatchEmbeddingJob(ctx context.Context, contentIDs []int64) error {
+	j.batches = append(j.batches, contentIDs)
+	return nil
+}
+
+func TestEmbeddingBatcher(t *testing.T) {
+	newBatcher := func(jobs store.JobClient) *services.EmbeddingBatcher {
+		cfg := &config.Config{}
+		cfg.Embedding.BatchJob.MinItems = 3
+		cfg.Embedding.BatchJob.Size = 4
+		return services.NewContentService(services.ContentServiceDeps{JobClient: jobs, Config: cfg}).NewEmbeddingBatcher()
+	}
+	ctx := context.Background()
+
+	few := &batchJobClient{}
+	b := newBatcher(few)
+	require.NoError(t, b.Add(ctx, 1))
+	require.NoError(t, b.Add(ctx, 2))
+	require.NoError(t, b.Flush(ctx))
+	assert.Equal(t, []int64{1, 2}, few.embedded, "below min_items each item gets its own job")
+	assert.Empty(t, few.batches)
+
+	many := &batchJobClient{}
+	b = newBatcher(many)
+	for id := int64(1); id <= 5; id++ {
+		require.NoError(t, b.Add(ctx, id))
+	}
+	require.NoError(t, b.Flush(ctx))
+	assert.Equal(t, [][]int64{{1, 2, 3, 4}, {5}}, many.batches, "once batching starts the remainder is batched too")
+	assert.Empty(t, many.embedded)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"mimir/internal/config"
 	"mimir/internal/models"
 	"mimir/internal/store"
 )
@@ -23,7 +24,7 @@ func NewBatchService(js store.JobStore) *BatchService {
 // ListBatches retrieves a list of background jobs associated with Batch API calls.
 func (s *BatchService) ListBatches(ctx context.Context, limit, offset int) ([]*models.BackgroundJob, error) {
 	if limit <= 0 {
-		limit = 20 // Default limit
+		limit = config.DefaultPageSize
 	}
 	if offset < 0 {
 		offset = 0
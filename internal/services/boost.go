@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"mimir/internal/store"
+)
+
+// Score boosts applied to semantic search candidates before trimming to the limit.
+const (
+	BoostNone    = ""
+	BoostRecency = "recency" // Decay by age, halving every search.boost.recency_half_life
+	BoostSource  = "source"  // Weight by search.boost.source_weights
+)
+
+// DefaultRecencyHalfLife is used when search.boost.recency_half_life is unset.
+const DefaultRecencyHalfLife = 30 * 24 * time.Hour
+
+// boostOverfetchFactor multiplies the limit when boosting, so content pushed
+// down by a boost can be replaced by candidates from beyond the top-k.
+const boostOverfetchFactor = 3
+
+// minBoostFactor keeps very old content at a finite score.
+const minBoostFactor = 1e-6
+
+// ParseBoost validates a semantic search boost, accepting an empty value for none.
+func ParseBoost(boost string) (string, error) {
+	switch boost {
+	case BoostNone, BoostRecency, BoostSource:
+		return boost, nil
+	}
+	return "", fmt.Errorf("invalid boost %q: must be %s or %s", boost, BoostRecency, BoostSource)
+}
+
+// ScoreBooster adjusts semantic search scores by content metadata.
+type ScoreBooster struct {
+	halfLife      time.Duration
+	sourceWeights map[string]float64 // Keyed by lower-cased source name; missing sources weigh 1
+	sources       store.SourceStore
+	now           func() time.Time
+}
+
+// NewScoreBooster creates a booster. halfLife <= 0 uses DefaultRecencyHalfLife;
+// sources resolves source names for source weights and may be nil when no
+// weights are configured.
+func NewScoreBooster(halfLife time.Duration, sourceWeights map[string]float64, sources store.SourceStore) *ScoreBooster {
+	if halfLife <= 0 {
+		halfLife = DefaultRecencyHalfLife
+	}
+	weights := make(map[string]float64, len(sourceWeights))
+	for name, w := range sourceWeights {
+		weights[strings.ToLower(name)] = w
+	}
+	return &ScoreBooster{halfLife: halfLife, sourceWeights: weights, sources: sources, now: time.Now}
+}
+
+// Boost multiplies each item's similarity by its boost factor and sorts the
+// items by the boosted score. Scores stay distances (lower is closer): the
+// boosted similarity is mapped back through store.DistanceFromSimilarity.
+func (b *ScoreBooster) Boost(ctx context.Context, boost string, items []SearchResultItem) []SearchResultItem {
+	if boost == BoostNone || len(items) == 0 {
+		return items
+	}
+
+	var factor func(item SearchResultItem) float64
+	switch boost {
+	case BoostRecency:
+		now := b.now()
+		factor = func(item SearchResultItem) float64 { return b.recencyFactor(now, item) }
+	case BoostSource:
+		weights := b.sourceWeightsByID(ctx, items)
+		factor = func(item SearchResultItem) float64 {
+			if w, ok := weights[item.Content.SourceID]; ok {
+				return w
+			}
+			return 1
+		}
+	default:
+		return items
+	}
+
+	boosted := make([]SearchResultItem, len(items))
+	for i, item := range items {
+		boosted[i] = item
+		if item.Content == nil {
+			continue
+		}
+		similarity := item.Similarity() * math.Max(factor(item), minBoostFactor)
+		boosted[i].Score = store.DistanceFromSimilarity(item.metric, similarity)
+	}
+	sort.SliceStable(boosted, func(i, j int) bool {
+		return boosted[i].Score < boosted[j].Score
+	})
+	return boosted
+}
+
+// recencyFactor halves every half-life since the content was last modified,
+// falling back to when it was added.
+func (b *ScoreBooster) recencyFactor(now time.Time, item SearchResultItem) float64 {
+	at := item.Content.CreatedAt
+	if item.Content.ModifiedAt != nil {
+		at = *item.Content.ModifiedAt
+	}
+	age := now.Sub(at)
+	if age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(b.halfLife))
+}
+
+// sourceWeightsByID resolves the configured weights for the items' sources.
+// Sources that cannot be loaded keep a weight of 1.
+func (b *ScoreBooster) sourceWeightsByID(ctx context.Context, items []SearchResultItem) map[int64]float64 {
+	weights := make(map[int64]float64)
+	if b.sources == nil || len(b.sourceWeights) == 0 {
+		return weights
+	}
+	seen := make(map[int64]bool)
+	for _, item := range items {
+		if item.Content == nil || seen[item.Content.SourceID] {
+			continue
+		}
+		seen[item.Content.SourceID] = true
+		source, err := b.sources.GetSource(ctx, item.Content.SourceID)
+		if err != nil {
+			log.Printf("WARN: Failed to load source %d for score boosting: %v", item.Content.SourceID, err)
+			continue
+		}
+		if w, ok := b.sourceWeights[strings.ToLower(source.Name)]; ok {
+			weights[source.ID] = w
+		}
+	}
+	return weights
+}
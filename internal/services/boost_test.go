@@ -0,0 +1,61 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreBooster_Recency(t *testing.T) {
+	old := time.Now().Add(-90 * 24 * time.Hour)
+	items := []services.SearchResultItem{
+		{Content: &models.Content{ID: 1, ModifiedAt: &old}, Score: 0.1},
+		{Content: &models.Content{ID: 2, CreatedAt: time.Now()}, Score: 0.3},
+	}
+
+	boosted := services.NewScoreBooster(30*24*time.Hour, nil, nil).Boost(context.Background(), services.BoostRecency, items)
+	require.Len(t, boosted, 2)
+
+	assert.Equal(t, int64(2), boosted[0].Content.ID)
+	assert.Equal(t, int64(1), boosted[1].Content.ID)
+	assert.InDelta(t, 0.3, boosted[0].Score, 0.001, "fresh content keeps its score")
+	assert.Equal(t, 0.1, items[0].Score, "input items must not be modified")
+}
+
+type boostSourceStore struct {
+	store.SourceStore
+}
+
+func (s boostSourceStore) GetSource(ctx context.Context, id int64) (*models.Source, error) {
+	names := map[int64]string{1: "Clippings", 2: "notes"}
+	return &models.Source{ID: id, Name: names[id]}, nil
+}
+
+func TestScoreBooster_SourceWeights(t *testing.T) {
+	items := []services.SearchResultItem{
+		{Content: &models.Content{ID: 1, SourceID: 1}, Score: 0.1},
+		{Content: &models.Content{ID: 2, SourceID: 2}, Score: 0.2},
+	}
+	weights := map[string]float64{"clippings": 0.5, "notes": 2}
+
+	boosted := services.NewScoreBooster(0, weights, boostSourceStore{}).Boost(context.Background(), services.BoostSource, items)
+	require.Len(t, boosted, 2)
+
+	assert.Equal(t, int64(2), boosted[0].Content.ID)
+	assert.Equal(t, int64(1), boosted[1].Content.ID)
+}
+
+func TestParseBoost(t *testing.T) {
+	_, err := services.ParseBoost("popularity")
+	assert.Error(t, err)
+	boost, err := services.ParseBoost("")
+	require.NoError(t, err)
+	assert.Equal(t, services.BoostNone, boost)
+}
@@ -2,14 +2,24 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
 
 	"mimir/internal/models" // Add models import
 	"mimir/internal/store"
 	categorizer "mimir/pkg/categorizer"
 )
 
+// DefaultMaxCollectionCategorize is the largest collection CategorizeCollection
+// accepts when categorization.max_collection_size is not set.
+const DefaultMaxCollectionCategorize = 100
+
+// ErrCollectionTooLarge is returned by CategorizeCollection when the collection
+// has more members than it categorizes in one request.
+var ErrCollectionTooLarge = errors.New("collection has too many members to categorize in one request")
+
 type ContentWithCategories struct {
 	Tags       []string
 	Category   string
@@ -21,6 +31,17 @@ type CategorizationService struct {
 	TagService        *TagService
 	CollectionService *CollectionService
 	contentStore      store.ContentStore
+	minConfidence     float64
+	maxTags           int
+	maxCollectionSize int
+}
+
+// BatchApplyResult reports what ApplyBatchSuggestions did with each suggestion.
+// IDs are in ascending order.
+type BatchApplyResult struct {
+	Applied              []int64          `json:"applied"`
+	SkippedLowConfidence []int64          `json:"skipped_low_confidence"`
+	Failed               map[int64]string `json:"failed,omitempty"`
 }
 
 func NewCategorizationService(cat categorizer.ContentCategorizer, ts *TagService, cs *CollectionService, contentStore store.ContentStore) *CategorizationService {
@@ -29,7 +50,25 @@ func NewCategorizationService(cat categorizer.ContentCategorizer, ts *TagService
 		TagService:        ts,
 		CollectionService: cs,
 		contentStore:      contentStore,
+		maxCollectionSize: DefaultMaxCollectionCategorize,
+	}
+}
+
+// SetMaxCollectionSize sets the most members CategorizeCollection categorizes
+// in one call; n <= 0 uses DefaultMaxCollectionCategorize.
+func (s *CategorizationService) SetMaxCollectionSize(n int) {
+	if n <= 0 {
+		n = DefaultMaxCollectionCategorize
 	}
+	s.maxCollectionSize = n
+}
+
+// SetApplyLimits sets the limits ApplyBatchSuggestions applies suggestions
+// under: suggestions below minConfidence are skipped and at most maxTags
+// suggested tags are applied per item. Zero disables either limit.
+func (s *CategorizationService) SetApplyLimits(minConfidence float64, maxTags int) {
+	s.minConfidence = minConfidence
+	s.maxTags = maxTags
 }
 
 func (s *CategorizationService) CategorizeContent(ctx context.Context, title, body string, existingTags []string) (*ContentWithCategories, error) {
@@ -93,6 +132,77 @@ func (s *CategorizationService) BatchCategorize(ctx context.Context, contentIDs
 	return results, nil
 }
 
+// CategorizeCollection runs BatchCategorize over every member of a collection.
+// With autoApply the suggestions are applied as by ApplyBatchSuggestions.
+// Collections larger than the limit set by SetMaxCollectionSize are rejected
+// with ErrCollectionTooLarge before any categorization call is made.
+func (s *CategorizationService) CategorizeCollection(ctx context.Context, collectionID int64, autoApply bool) (map[int64]*ContentWithCategories, error) {
+	if s.CollectionService == nil {
+		return nil, fmt.Errorf("collection service is not initialized")
+	}
+	ids, err := s.CollectionService.ListContentIDs(ctx, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) > s.maxCollectionSize {
+		return nil, fmt.Errorf("%w: %d members (limit %d)", ErrCollectionTooLarge, len(ids), s.maxCollectionSize)
+	}
+	results, err := s.BatchCategorize(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.ApplyBatchSuggestions(ctx, results, autoApply); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ApplyBatchSuggestions applies BatchCategorize results to their content items
+// as by ApplyCategories, within the limits set by SetApplyLimits: suggestions
+// below the confidence threshold are skipped and only the first maxTags
+// suggested tags are applied. The suggestions themselves are not modified.
+// Items that fail to apply are reported in the result, not returned as an
+// error; only cancellation of ctx stops the batch early. If autoApply is false
+// nothing is applied and the result is empty.
+func (s *CategorizationService) ApplyBatchSuggestions(ctx context.Context, suggestions map[int64]*ContentWithCategories, autoApply bool) (*BatchApplyResult, error) {
+	result := &BatchApplyResult{Applied: []int64{}, SkippedLowConfidence: []int64{}}
+	if !autoApply {
+		return result, nil
+	}
+
+	ids := make([]int64, 0, len(suggestions))
+	for id := range suggestions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		cats := suggestions[id]
+		if cats != nil && cats.Confidence < s.minConfidence {
+			result.SkippedLowConfidence = append(result.SkippedLowConfidence, id)
+			continue
+		}
+		if cats != nil && s.maxTags > 0 && len(cats.Tags) > s.maxTags {
+			capped := *cats
+			capped.Tags = cats.Tags[:s.maxTags]
+			cats = &capped
+		}
+		if err := s.ApplyCategories(ctx, id, cats, true); err != nil {
+			log.Printf("WARN: Failed to apply categories to content %d: %v", id, err)
+			if result.Failed == nil {
+				result.Failed = make(map[int64]string)
+			}
+			result.Failed[id] = err.Error()
+			continue
+		}
+		result.Applied = append(result.Applied, id)
+	}
+	return result, nil
+}
+
 // ApplyCategories applies the suggested tags and category (collection) to a content item.
 // If autoApply is false, it currently does nothing (future: persist suggestion).
 
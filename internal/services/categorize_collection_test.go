@@ -0,0 +1,92 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+type categorizeCollectionStore struct {
+	store.CollectionStore
+	members map[int64][]int64
+}
+
+func (s categorizeCollectionStore) GetCollection(ctx context.Context, ownerID string, id int64) (*models.Collection, error) {
+	if _, ok := s.members[id]; !ok {
+		return nil, store.ErrNotFound
+	}
+	return &models.Collection{ID: id, OwnerID: ownerID}, nil
+}
+
+func (s categorizeCollectionStore) ListCollectionContentIDs(ctx context.Context, collectionID int64) ([]int64, error) {
+	return s.members[collectionID], nil
+}
+
+func TestCategorizationService_CategorizeCollection(t *testing.T) {
+	collections := categorizeCollectionStore{members: map[int64][]int64{5: {1, 2}}}
+	contents := &memContentStore{contents: map[int64]*models.Content{
+		1: {ID: 1, Title: "a"},
+		2: {ID: 2, Title: "b"},
+		3: {ID: 3, Title: "not a member"},
+	}}
+	svc := services.NewCategorizationService(
+		staticCategorizer{tags: []string{"go"}},
+		services.NewTagService(&batchGetTagStore{}),
+		services.NewCollectionService(collections, contents, nil),
+		contents,
+	)
+
+	results, err := svc.CategorizeCollection(context.Background(), 5, false)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, []string{"go"}, results[1].Tags)
+	assert.Contains(t, results, int64(2))
+
+	_, err = svc.CategorizeCollection(context.Background(), 6, false)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	svc.SetMaxCollectionSize(1)
+	_, err = svc.CategorizeCollection(context.Background(), 5, false)
+	assert.ErrorIs(t, err, services.ErrCollectionTooLarge)
+}
+
+type applyTagStore struct {
+	batchTagStore
+	applied map[int64][]int64
+}
+
+func (s *applyTagStore) AddTagsToContent(ctx context.Context, contentID int64, tagIDs []int64) error {
+	s.applied[contentID] = tagIDs
+	return nil
+}
+
+func TestCategorizationService_ApplyBatchSuggestions(t *testing.T) {
+	tags := &applyTagStore{applied: map[int64][]int64{}}
+	svc := services.NewCategorizationService(nil, services.NewTagService(tags), nil, nil)
+	svc.SetApplyLimits(0.5, 2)
+	suggestions := map[int64]*services.ContentWithCategories{
+		3: {Tags: []string{"go", "db", "sql"}, Confidence: 0.9},
+		1: {Tags: []string{"go"}, Confidence: 0.5},
+		2: {Tags: []string{"noise"}, Confidence: 0.2},
+		4: nil,
+	}
+
+	result, err := svc.ApplyBatchSuggestions(context.Background(), suggestions, true)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 3}, result.Applied)
+	assert.Equal(t, []int64{2}, result.SkippedLowConfidence)
+	assert.Contains(t, result.Failed, int64(4))
+	assert.Len(t, tags.applied[3], 2, "tags are capped at max_tags")
+	assert.Len(t, suggestions[3].Tags, 3, "suggestions are left unmodified")
+	assert.NotContains(t, tags.applied, int64(2))
+
+	result, err = svc.ApplyBatchSuggestions(context.Background(), suggestions, false)
+	require.NoError(t, err)
+	assert.Empty(t, result.Applied)
+}
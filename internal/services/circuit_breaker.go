@@ -0,0 +1,112 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a provider's circuit breaker.
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // Requests flow normally
+	BreakerOpen     BreakerState = "open"      // Provider is skipped until the cooldown ends
+	BreakerHalfOpen BreakerState = "half_open" // Cooldown ended; one probe request tests recovery
+)
+
+// Defaults used when embedding.circuit_breaker is unset.
+const (
+	DefaultBreakerFailureThreshold = 5
+	DefaultBreakerCooldown         = 30 * time.Second
+)
+
+// CircuitBreaker stops requests to a provider after consecutive failures.
+// It opens after threshold failures in a row, rejects requests for cooldown,
+// then lets a single probe through: success closes it, failure reopens it.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu           sync.Mutex
+	state        BreakerState
+	failures     int       // Consecutive failures while closed
+	openedAt     time.Time // When the breaker last opened
+	probeStarted time.Time // Start of the in-flight half-open probe; zero when none
+}
+
+// NewCircuitBreaker creates a closed breaker. threshold <= 0 and cooldown <= 0
+// use DefaultBreakerFailureThreshold and DefaultBreakerCooldown.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = DefaultBreakerFailureThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown, now: time.Now, state: BreakerClosed}
+}
+
+// Allow reports whether a request may be sent. In the half-open state only one
+// probe is allowed at a time; a probe that never reports back is replaced after
+// another cooldown.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	switch b.state {
+	case BreakerOpen:
+		if now.Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probeStarted = now
+		return true
+	case BreakerHalfOpen:
+		if !b.probeStarted.IsZero() && now.Sub(b.probeStarted) < b.cooldown {
+			return false
+		}
+		b.probeStarted = now
+		return true
+	default:
+		return true
+	}
+}
+
+// Success records a successful request, closing the breaker.
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probeStarted = time.Time{}
+}
+
+// Failure records a failed request. A failed probe reopens the breaker at once.
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerClosed {
+		b.failures++
+		if b.failures < b.threshold {
+			return
+		}
+	}
+	b.state = BreakerOpen
+	b.openedAt = b.now()
+	b.failures = 0
+	b.probeStarted = time.Time{}
+}
+
+// State returns the breaker's state. An open breaker whose cooldown has ended
+// is reported as half-open, since the next request will probe the provider.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"log"
 
+	"mimir/internal/config"
 	"mimir/internal/models"
 	"mimir/internal/store"
 )
 
+// ErrSameCollection is returned when merging a collection into itself.
+var ErrSameCollection = errors.New("source and target collections must differ")
+
 type CollectionService struct {
 	collections store.CollectionStore
 	contents    store.ContentStore
@@ -36,6 +40,7 @@ func (cs *CollectionService) CreateCollection(ctx context.Context, name string,
 		Name:        name,
 		Description: description,
 		IsPinned:    pinned,
+		OwnerID:     OwnerFromContext(ctx),
 	}
 	if err := cs.collections.CreateCollection(ctx, c); err != nil {
 		return nil, fmt.Errorf("could not create collection: %w", err)
@@ -49,7 +54,7 @@ func (cs *CollectionService) GetOrCreateCollection(ctx context.Context, name str
 		return nil, fmt.Errorf("collection name cannot be empty")
 	}
 
-	existingColl, err := cs.collections.GetCollectionByName(ctx, name)
+	existingColl, err := cs.collections.GetCollectionByName(ctx, OwnerFromContext(ctx), name)
 	if err == nil {
 		// Collection found, return it
 		return existingColl, nil
@@ -68,18 +73,45 @@ func (cs *CollectionService) GetOrCreateCollection(ctx context.Context, name str
 }
 
 func (cs *CollectionService) ListCollections(ctx context.Context) ([]*models.Collection, error) {
-	return cs.collections.ListCollections(ctx, 100, 0, nil)
+	return cs.collections.ListCollections(ctx, OwnerFromContext(ctx), config.DefaultMaxPageSize, 0, nil)
+}
+
+// ListCollectionsPage retrieves one page of collections, optionally filtering by pinned status.
+func (cs *CollectionService) ListCollectionsPage(ctx context.Context, limit, offset int, pinned *bool) ([]*models.Collection, error) {
+	collections, err := cs.collections.ListCollections(ctx, OwnerFromContext(ctx), limit, offset, pinned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	return collections, nil
+}
+
+// checkOwned fails with store.ErrNotFound unless the collection belongs to the
+// owner in ctx.
+func (cs *CollectionService) checkOwned(ctx context.Context, collectionID int64) error {
+	if _, err := cs.collections.GetCollection(ctx, OwnerFromContext(ctx), collectionID); err != nil {
+		return fmt.Errorf("get collection %d: %w", collectionID, err)
+	}
+	return nil
 }
 
 func (cs *CollectionService) AddContent(ctx context.Context, contentID, collectionID int64) error {
+	if err := cs.checkOwned(ctx, collectionID); err != nil {
+		return err
+	}
 	return cs.collections.AddContentToCollection(ctx, collectionID, contentID)
 }
 
 func (cs *CollectionService) RemoveContent(ctx context.Context, contentID, collectionID int64) error {
+	if err := cs.checkOwned(ctx, collectionID); err != nil {
+		return err
+	}
 	return cs.collections.RemoveContentFromCollection(ctx, collectionID, contentID)
 }
 
 func (cs *CollectionService) ListContent(ctx context.Context, collectionID int64, limit, offset int, sortBy, sortOrder string) ([]ContentResultItem, error) {
+	if err := cs.checkOwned(ctx, collectionID); err != nil {
+		return nil, err
+	}
 	contents, err := cs.collections.ListContentByCollection(ctx, collectionID, limit, offset, sortBy, sortOrder)
 	if err != nil {
 		return nil, err
@@ -96,83 +128,109 @@ func (cs *CollectionService) ListContent(ctx context.Context, collectionID int64
 	return result, nil
 }
 
-// GetCollection retrieves a single collection by its ID.
-// --- REMOVING DUPLICATE METHOD DEFINITIONS BELOW ---
-/*
-func (cs *CollectionService) GetCollection(ctx context.Context, id int64) (*models.Collection, error) {
-	coll, err := cs.collections.GetCollection(ctx, id)
+// ListContentIDs returns the IDs of all content in the collection.
+func (cs *CollectionService) ListContentIDs(ctx context.Context, collectionID int64) ([]int64, error) {
+	if err := cs.checkOwned(ctx, collectionID); err != nil {
+		return nil, err
+	}
+	ids, err := cs.collections.ListCollectionContentIDs(ctx, collectionID)
 	if err != nil {
-		// Wrap error, potentially check for store.ErrNotFound
-		return nil, fmt.Errorf("failed to get collection %d: %w", id, err)
+		return nil, fmt.Errorf("list content of collection %d: %w", collectionID, err)
 	}
-	return coll, nil
+	return ids, nil
 }
 
-// ListCollections retrieves a list of collections, optionally filtering by pinned status.
-func (cs *CollectionService) ListCollections(ctx context.Context, limit, offset int, pinned *bool) ([]*models.Collection, error) {
-	if limit <= 0 {
-		limit = 20 // Default limit
+// DeleteCollection removes a collection owned by the owner in ctx and returns
+// how many content items it held; the content itself is kept. Fails with
+// store.ErrNotFound when there is no such collection.
+func (cs *CollectionService) DeleteCollection(ctx context.Context, id int64) (int64, error) {
+	removed, err := cs.collections.DeleteCollection(ctx, OwnerFromContext(ctx), id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete collection %d: %w", id, err)
 	}
-	if offset < 0 {
-		offset = 0
+	return removed, nil
+}
+
+// MergeCollections moves every content item of collection sourceID into
+// targetID, keeping a single membership for items already in both, and deletes
+// the source when deleteSource is set. Both collections must belong to the
+// owner in ctx, else it fails with store.ErrNotFound. It returns how many items
+// were added to the target.
+func (cs *CollectionService) MergeCollections(ctx context.Context, sourceID, targetID int64, deleteSource bool) (int64, error) {
+	if sourceID == targetID {
+		return 0, ErrSameCollection
 	}
-	collections, err := cs.collections.ListCollections(ctx, limit, offset, pinned)
+	moved, err := cs.collections.MergeCollections(ctx, OwnerFromContext(ctx), sourceID, targetID, deleteSource)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list collections: %w", err)
+		return 0, fmt.Errorf("failed to merge collection %d into %d: %w", sourceID, targetID, err)
 	}
-	return collections, nil
+	return moved, nil
 }
 
-// UpdateCollection updates an existing collection's details.
-// Pass nil for fields that should not be updated.
-func (cs *CollectionService) UpdateCollection(ctx context.Context, id int64, name *string, description *string, isPinned *bool) (*models.Collection, error) {
-	// Fetch the existing collection first to ensure it exists
-	existingColl, err := cs.collections.GetCollection(ctx, id)
+// UpdateCollection applies the non-nil fields to a collection owned by the
+// owner in ctx and returns it; an empty description clears it. Fails with
+// store.ErrNotFound for an unknown collection and store.ErrDuplicate when
+// the new name is taken.
+func (cs *CollectionService) UpdateCollection(ctx context.Context, id int64, name, description *string, isPinned *bool) (*models.Collection, error) {
+	existing, err := cs.collections.GetCollection(ctx, OwnerFromContext(ctx), id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get collection %d for update: %w", id, err)
 	}
 
-	// Apply updates if provided
 	updated := false
-	if name != nil && *name != "" && *name != existingColl.Name {
-		existingColl.Name = *name
-		updated = true
-	}
-	// Handle nil description carefully
-	// If new description is provided and different from existing (or existing was nil)
-	if description != nil && (existingColl.Description == nil || *description != *existingColl.Description) {
-		existingColl.Description = description
+	if name != nil && *name != existing.Name {
+		if *name == "" {
+			return nil, fmt.Errorf("collection name cannot be empty")
+		}
+		existing.Name = *name
 		updated = true
 	}
-	// If new description is explicitly nil and existing was not nil
-	if description == nil && existingColl.Description != nil {
-		existingColl.Description = nil
+	if description != nil {
+		existing.Description = nil
+		if *description != "" {
+			existing.Description = description
+		}
 		updated = true
 	}
-
-	if isPinned != nil && *isPinned != existingColl.IsPinned {
-		existingColl.IsPinned = *isPinned
+	if isPinned != nil && *isPinned != existing.IsPinned {
+		existing.IsPinned = *isPinned
 		updated = true
 	}
 
 	// Only call update if something actually changed
 	if updated {
-		err = cs.collections.UpdateCollection(ctx, existingColl)
-		if err != nil {
+		if err := cs.collections.UpdateCollection(ctx, existing); err != nil {
 			return nil, fmt.Errorf("failed to update collection %d: %w", id, err)
 		}
 	}
+	return existing, nil
+}
 
-	return existingColl, nil
+// GetCollection retrieves a single collection by its ID.
+// --- REMOVING DUPLICATE METHOD DEFINITIONS BELOW ---
+/*
+func (cs *CollectionService) GetCollection(ctx context.Context, id int64) (*models.Collection, error) {
+	coll, err := cs.collections.GetCollection(ctx, id)
+	if err != nil {
+		// Wrap error, potentially check for store.ErrNotFound
+		return nil, fmt.Errorf("failed to get collection %d: %w", id, err)
+	}
+	return coll, nil
 }
 
-// DeleteCollection removes a collection.
-func (cs *CollectionService) DeleteCollection(ctx context.Context, id int64) error {
-	err := cs.collections.DeleteCollection(ctx, id)
+// ListCollections retrieves a list of collections, optionally filtering by pinned status.
+func (cs *CollectionService) ListCollections(ctx context.Context, limit, offset int, pinned *bool) ([]*models.Collection, error) {
+	if limit <= 0 {
+		limit = 20 // Default limit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	collections, err := cs.collections.ListCollections(ctx, limit, offset, pinned)
 	if err != nil {
-		return fmt.Errorf("failed to delete collection %d: %w", id, err)
+		return nil, fmt.Errorf("failed to list collections: %w", err)
 	}
-	return nil
+	return collections, nil
 }
 */
 // --- END REMOVED DUPLICATE METHODS ---
@@ -0,0 +1,137 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+type deletingCollectionStore struct {
+	store.CollectionStore
+	members map[int64][]int64
+}
+
+func (s deletingCollectionStore) DeleteCollection(ctx context.Context, ownerID string, id int64) (int64, error) {
+	members, ok := s.members[id]
+	if !ok {
+		return 0, store.ErrNotFound
+	}
+	delete(s.members, id)
+	return int64(len(members)), nil
+}
+
+func TestCollectionService_DeleteCollection(t *testing.T) {
+	collections := deletingCollectionStore{members: map[int64][]int64{5: {1, 2}}}
+	svc := services.NewCollectionService(collections, nil, nil)
+
+	removed, err := svc.DeleteCollection(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), removed)
+
+	_, err = svc.DeleteCollection(context.Background(), 5)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+type updatingCollectionStore struct {
+	store.CollectionStore
+	collections map[int64]*models.Collection
+}
+
+func (s updatingCollectionStore) GetCollection(ctx context.Context, ownerID string, id int64) (*models.Collection, error) {
+	c, ok := s.collections[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	copied := *c
+	return &copied, nil
+}
+
+func (s updatingCollectionStore) UpdateCollection(ctx context.Context, collection *models.Collection) error {
+	for id, c := range s.collections {
+		if id != collection.ID && c.Name == collection.Name {
+			return store.ErrDuplicate
+		}
+	}
+	copied := *collection
+	s.collections[collection.ID] = &copied
+	return nil
+}
+
+func TestCollectionService_UpdateCollection(t *testing.T) {
+	desc := "Papers to read"
+	collections := updatingCollectionStore{collections: map[int64]*models.Collection{
+		1: {ID: 1, Name: "reading", Description: &desc},
+		2: {ID: 2, Name: "archive"},
+	}}
+	svc := services.NewCollectionService(collections, nil, nil)
+
+	pinned := true
+	updated, err := svc.UpdateCollection(context.Background(), 1, nil, nil, &pinned)
+	require.NoError(t, err)
+	assert.True(t, updated.IsPinned)
+	assert.Equal(t, "reading", collections.collections[1].Name, "unset fields are unchanged")
+	require.NotNil(t, collections.collections[1].Description)
+
+	name := "archive"
+	_, err = svc.UpdateCollection(context.Background(), 1, &name, nil, nil)
+	assert.ErrorIs(t, err, store.ErrDuplicate)
+
+	_, err = svc.UpdateCollection(context.Background(), 3, &name, nil, nil)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+type mergingCollectionStore struct {
+	store.CollectionStore
+	members map[int64][]int64
+}
+
+func (s mergingCollectionStore) MergeCollections(ctx context.Context, ownerID string, sourceID, targetID int64, deleteSource bool) (int64, error) {
+	source, ok := s.members[sourceID]
+	if _, targetOK := s.members[targetID]; !ok || !targetOK {
+		return 0, store.ErrNotFound
+	}
+	var moved int64
+	for _, id := range source {
+		if !containsID(s.members[targetID], id) {
+			s.members[targetID] = append(s.members[targetID], id)
+			moved++
+		}
+	}
+	s.members[sourceID] = nil
+	if deleteSource {
+		delete(s.members, sourceID)
+	}
+	return moved, nil
+}
+
+func containsID(ids []int64, id int64) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCollectionService_MergeCollections(t *testing.T) {
+	collections := mergingCollectionStore{members: map[int64][]int64{1: {10, 11}, 2: {11, 12}}}
+	svc := services.NewCollectionService(collections, nil, nil)
+
+	_, err := svc.MergeCollections(context.Background(), 1, 1, true)
+	assert.ErrorIs(t, err, services.ErrSameCollection)
+
+	moved, err := svc.MergeCollections(context.Background(), 1, 2, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), moved, "content already in the target is not counted")
+	assert.ElementsMatch(t, []int64{10, 11, 12}, collections.members[2])
+	assert.NotContains(t, collections.members, int64(1))
+
+	_, err = svc.MergeCollections(context.Background(), 1, 2, false)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
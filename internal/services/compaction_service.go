@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// Compaction policies deciding which timestamp must be older than the cutoff.
+const (
+	CompactPolicyLastAccessed = "last_accessed" // Last search access, falling back to creation time
+	CompactPolicyAge          = "age"           // Creation time
+)
+
+// CompactParams configures a compaction run.
+type CompactParams struct {
+	OlderThan time.Duration
+	Policy    string // CompactPolicyLastAccessed (default) or CompactPolicyAge
+	DryRun    bool   // List the content that would be archived without changing anything
+}
+
+// CompactResult reports the content archived (or, for a dry run, selected) by a
+// compaction run, and the content whose embeddings could not be removed.
+type CompactResult struct {
+	Archived []*models.Content
+	Failed   []int64
+}
+
+// CompactionService removes the embeddings of old, rarely used content from the
+// vector store to save space. Archived content keeps its row, so it remains
+// available to keyword search, and is re-embedded by a reindex.
+type CompactionService struct {
+	contents store.ContentStore
+	vector   store.VectorStore
+}
+
+// NewCompactionService creates a new CompactionService.
+func NewCompactionService(contents store.ContentStore, vector store.VectorStore) *CompactionService {
+	return &CompactionService{contents: contents, vector: vector}
+}
+
+// Compact archives embedded content not used within params.OlderThan. Pinned
+// content is never archived. Items that fail are logged and reported, and do
+// not stop the run.
+func (s *CompactionService) Compact(ctx context.Context, params CompactParams) (*CompactResult, error) {
+	if s.vector == nil {
+		return nil, fmt.Errorf("vector store is not initialized: %w", ErrEmbeddingDisabled)
+	}
+	if params.OlderThan <= 0 {
+		return nil, fmt.Errorf("older-than must be positive")
+	}
+	var byAge bool
+	switch params.Policy {
+	case "", CompactPolicyLastAccessed:
+	case CompactPolicyAge:
+		byAge = true
+	default:
+		return nil, fmt.Errorf("invalid compaction policy %q: must be %s or %s", params.Policy, CompactPolicyLastAccessed, CompactPolicyAge)
+	}
+
+	candidates, err := s.contents.ListArchivableContent(ctx, time.Now().Add(-params.OlderThan), byAge)
+	if err != nil {
+		return nil, fmt.Errorf("list archivable content: %w", err)
+	}
+	if params.DryRun {
+		return &CompactResult{Archived: candidates}, nil
+	}
+
+	result := &CompactResult{}
+	for _, c := range candidates {
+		if err := s.vector.DeleteEmbeddingsByContentID(ctx, c.ID); err != nil {
+			log.Printf("ERROR: Compaction: delete embeddings for content %d: %v", c.ID, err)
+			result.Failed = append(result.Failed, c.ID)
+			continue
+		}
+		if err := s.contents.MarkContentArchived(ctx, c.ID); err != nil {
+			log.Printf("ERROR: Compaction: mark content %d archived: %v", c.ID, err)
+			result.Failed = append(result.Failed, c.ID)
+			continue
+		}
+		result.Archived = append(result.Archived, c)
+	}
+	return result, nil
+}
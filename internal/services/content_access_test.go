@@ -0,0 +1,81 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type viewContentStore struct{ store.ContentStore }
+
+func (viewContentStore) GetContent(ctx context.Context, id int64) (*models.Content, error) {
+	return &models.Content{ID: id, Title: "doc", OwnerID: store.DefaultOwnerID, Visibility: store.VisibilityPrivate}, nil
+}
+
+func (viewContentStore) SetContentPinned(ctx context.Context, contentID int64, pinned bool) error {
+	return nil
+}
+
+// recordingAccessStore embeds store.ContentAccessStore so only RecordContentView
+// needs implementing.
+type recordingAccessStore struct {
+	store.ContentAccessStore
+	views chan int64
+}
+
+func (s *recordingAccessStore) RecordContentView(ctx context.Context, contentID int64) error {
+	s.views <- contentID
+	return nil
+}
+
+func TestGetContentRecordsView(t *testing.T) {
+	access := &recordingAccessStore{views: make(chan int64, 2)}
+	cs := services.NewContentService(services.ContentServiceDeps{
+		ContentStore: viewContentStore{},
+		AccessStore:  access,
+	})
+
+	_, err := cs.GetContent(context.Background(), 42)
+	require.NoError(t, err)
+	select {
+	case id := <-access.views:
+		assert.Equal(t, int64(42), id)
+	case <-time.After(time.Second):
+		t.Fatal("view was not recorded")
+	}
+
+	// Reads the service makes for its own purposes are not views.
+	_, err = cs.PinContent(context.Background(), 42, true)
+	require.NoError(t, err)
+	select {
+	case id := <-access.views:
+		t.Fatalf("unexpected view of content %d", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestListRecentlyViewedWithoutAccessStore(t *testing.T) {
+	cs := services.NewContentService(services.ContentServiceDeps{ContentStore: viewContentStore{}})
+	_, err := cs.ListRecentlyViewed(context.Background(), 10)
+	assert.ErrorIs(t, err, services.ErrAccessTrackingDisabled)
+}
+
+func TestContentIsolatedByOwner(t *testing.T) {
+	cs := services.NewContentService(services.ContentServiceDeps{ContentStore: viewContentStore{}})
+	other := services.WithOwner(context.Background(), "team-b")
+
+	_, err := cs.GetContent(other, 42)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	_, err = cs.PinContent(other, 42, true)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+
+	_, err = cs.PinContent(context.Background(), 42, true)
+	assert.NoError(t, err)
+}
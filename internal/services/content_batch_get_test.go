@@ -0,0 +1,46 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+type batchGetTagStore struct {
+	store.TagStore
+	calls int
+}
+
+func (s *batchGetTagStore) GetTagsForContents(ctx context.Context, contentIDs []int64) (map[int64][]*models.Tag, error) {
+	s.calls++
+	return map[int64][]*models.Tag{2: {{Name: "go"}}}, nil
+}
+
+func TestContentService_GetContentsWithTags(t *testing.T) {
+	contents := &memContentStore{contents: map[int64]*models.Content{
+		1: {ID: 1, OwnerID: store.DefaultOwnerID, Visibility: store.VisibilityPrivate},
+		2: {ID: 2, OwnerID: store.DefaultOwnerID, Visibility: store.VisibilityPrivate, IsEmbedded: true},
+		3: {ID: 3, OwnerID: "alice", Visibility: store.VisibilityPrivate},
+	}, failed: map[int64]bool{1: true}}
+	tags := &batchGetTagStore{}
+	cs := services.NewContentService(services.ContentServiceDeps{ContentStore: contents, TagStore: tags})
+
+	items, err := cs.GetContentsWithTags(context.Background(), []int64{2, 3, 9, 1, 2})
+	require.NoError(t, err)
+	require.Len(t, items, 2, "missing, invisible and repeated IDs are skipped")
+
+	assert.Equal(t, int64(2), items[0].Content.ID)
+	assert.Equal(t, "go", items[0].Tags[0].Name)
+	assert.Equal(t, int64(1), items[1].Content.ID)
+	assert.Empty(t, items[1].Tags)
+	assert.Equal(t, store.EmbeddingStatusEmbedded, items[0].EmbeddingState)
+	assert.Equal(t, store.EmbeddingStatusFailed, items[1].EmbeddingState)
+	assert.Equal(t, 1, contents.batchGets)
+	assert.Equal(t, 1, tags.calls)
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"mimir/internal/store"
+)
+
+// MaxVectorPrecision is the largest number of decimal places vectors can be
+// rounded to; float32 values carry no more significant digits than this.
+const MaxVectorPrecision = 8
+
+// ChunkListOptions controls how ListContentChunks reports vectors. Vectors
+// are large, so they are omitted unless IncludeVector is set.
+type ChunkListOptions struct {
+	IncludeVector bool
+	Precision     int // Decimal places vector values are rounded to; 0 keeps full precision
+}
+
+// ContentChunk is one embedded chunk of a content item.
+type ContentChunk struct {
+	ID         uuid.UUID       `json:"id"`
+	ChunkIndex *int            `json:"chunk_index"` // From metadata; null for embeddings stored without one
+	ChunkText  string          `json:"chunk_text"`
+	Dimension  int             `json:"dimension"`
+	Vector     []float32       `json:"vector,omitempty"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// ListContentChunks returns the embedded chunks of a content item, for
+// inspecting how it was chunked and embedded.
+func (cs *ContentService) ListContentChunks(ctx context.Context, contentID int64, vs store.VectorStore, opts ChunkListOptions) ([]ContentChunk, error) {
+	if vs == nil {
+		return nil, fmt.Errorf("vector store is not initialized: %w", ErrEmbeddingDisabled)
+	}
+	if opts.Precision < 0 || opts.Precision > MaxVectorPrecision {
+		return nil, fmt.Errorf("precision must be between 0 and %d", MaxVectorPrecision)
+	}
+	if _, err := cs.getContent(ctx, contentID); err != nil {
+		return nil, err
+	}
+
+	entries, err := vs.ListEmbeddingsByContentID(ctx, contentID)
+	if err != nil {
+		return nil, fmt.Errorf("list chunks of content %d: %w", contentID, err)
+	}
+
+	chunks := make([]ContentChunk, len(entries))
+	for i, entry := range entries {
+		vector := entry.Vector.Slice()
+		chunks[i] = ContentChunk{
+			ID:         entry.ID,
+			ChunkIndex: chunkIndexOf(entry.Metadata),
+			ChunkText:  entry.ChunkText,
+			Dimension:  len(vector),
+			CreatedAt:  entry.CreatedAt,
+		}
+		if len(entry.Metadata) > 0 {
+			chunks[i].Metadata = entry.Metadata
+		}
+		if opts.IncludeVector {
+			chunks[i].Vector = roundVector(vector, opts.Precision)
+		}
+	}
+	return chunks, nil
+}
+
+// roundVector rounds each value to precision decimal places; 0 returns v unchanged.
+func roundVector(v []float32, precision int) []float32 {
+	if precision == 0 {
+		return v
+	}
+	scale := math.Pow(10, float64(precision))
+	rounded := make([]float32, len(v))
+	for i, x := range v {
+		rounded[i] = float32(math.Round(float64(x)*scale) / scale)
+	}
+	return rounded
+}
@@ -0,0 +1,47 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+type chunkVectorStore struct {
+	store.VectorStore
+	entries []*models.EmbeddingEntry
+}
+
+func (v chunkVectorStore) ListEmbeddingsByContentID(ctx context.Context, contentID int64) ([]*models.EmbeddingEntry, error) {
+	return v.entries, nil
+}
+
+func TestContentService_ListContentChunks(t *testing.T) {
+	content := &models.Content{ID: 1, OwnerID: store.DefaultOwnerID}
+	cs := services.NewContentService(services.ContentServiceDeps{ContentStore: newMemContentStore(content)})
+	vs := chunkVectorStore{entries: []*models.EmbeddingEntry{{
+		ContentID: 1,
+		ChunkText: "hello",
+		Vector:    pgvector.NewVector([]float32{0.123456, -0.98765}),
+		Metadata:  json.RawMessage(`{"chunk_index": 0}`),
+	}}}
+
+	chunks, err := cs.ListContentChunks(context.Background(), 1, vs, services.ChunkListOptions{})
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	assert.Nil(t, chunks[0].Vector, "vectors are omitted by default")
+	assert.Equal(t, 2, chunks[0].Dimension)
+	require.NotNil(t, chunks[0].ChunkIndex)
+	assert.Equal(t, 0, *chunks[0].ChunkIndex)
+
+	chunks, err = cs.ListContentChunks(context.Background(), 1, vs, services.ChunkListOptions{IncludeVector: true, Precision: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []float32{0.12, -0.99}, chunks[0].Vector)
+}
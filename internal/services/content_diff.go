@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Ways of diffing two content bodies.
+const (
+	DiffUnified = "unified" // Line-based unified diff text
+	DiffWords   = "word"    // Word-level insert/delete/equal operations
+)
+
+// diffContextLines is the number of unchanged lines around each unified diff hunk.
+const diffContextLines = 3
+
+// Word diff operations.
+const (
+	DiffOpEqual  = "equal"
+	DiffOpInsert = "insert"
+	DiffOpDelete = "delete"
+)
+
+// DiffOp is one run of a word-level diff. Concatenating the equal and delete
+// runs gives the first body; the equal and insert runs give the second.
+type DiffOp struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// ContentDiff is the difference between the bodies of two content items.
+type ContentDiff struct {
+	A         int64    `json:"a"`
+	B         int64    `json:"b"`
+	Mode      string   `json:"mode"`
+	Identical bool     `json:"identical"`
+	Unified   string   `json:"unified,omitempty"` // For DiffUnified
+	Ops       []DiffOp `json:"ops,omitempty"`     // For DiffWords
+}
+
+// ParseDiffMode validates a diff mode, defaulting an empty value to DiffUnified.
+func ParseDiffMode(mode string) (string, error) {
+	switch mode {
+	case "":
+		return DiffUnified, nil
+	case DiffUnified, DiffWords:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid diff mode %q: must be %s or %s", mode, DiffUnified, DiffWords)
+}
+
+// DiffContent compares the bodies of two content items, for deciding which of
+// two near-duplicates to keep. Rendering the diff is left to the client.
+func (cs *ContentService) DiffContent(ctx context.Context, a, b int64, mode string) (*ContentDiff, error) {
+	mode, err := ParseDiffMode(mode)
+	if err != nil {
+		return nil, err
+	}
+	contentA, err := cs.getContent(ctx, a)
+	if err != nil {
+		return nil, err
+	}
+	contentB, err := cs.getContent(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &ContentDiff{A: a, B: b, Mode: mode, Identical: contentA.Body == contentB.Body}
+	if diff.Identical {
+		return diff, nil
+	}
+	switch mode {
+	case DiffUnified:
+		diff.Unified, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(contentA.Body),
+			B:        difflib.SplitLines(contentB.Body),
+			FromFile: fmt.Sprintf("content/%d", a),
+			ToFile:   fmt.Sprintf("content/%d", b),
+			Context:  diffContextLines,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("diff content %d and %d: %w", a, b, err)
+		}
+	case DiffWords:
+		diff.Ops = diffWords(contentA.Body, contentB.Body)
+	}
+	return diff, nil
+}
+
+// diffToken matches a run of whitespace or a word, so tokens join back into the original text.
+var diffToken = regexp.MustCompile(`\s+|\S+`)
+
+// diffWords returns the word-level operations turning a into b. Adjacent
+// operations of the same kind are merged.
+func diffWords(a, b string) []DiffOp {
+	wordsA := diffToken.FindAllString(a, -1)
+	wordsB := diffToken.FindAllString(b, -1)
+	// Without autojunk, common tokens such as single spaces still anchor matches.
+	matcher := difflib.NewMatcherWithJunk(wordsA, wordsB, false, nil)
+
+	var ops []DiffOp
+	add := func(op string, words []string) {
+		if len(words) == 0 {
+			return
+		}
+		text := strings.Join(words, "")
+		if n := len(ops); n > 0 && ops[n-1].Op == op {
+			ops[n-1].Text += text
+			return
+		}
+		ops = append(ops, DiffOp{Op: op, Text: text})
+	}
+	for _, oc := range matcher.GetOpCodes() {
+		switch oc.Tag {
+		case 'e':
+			add(DiffOpEqual, wordsA[oc.I1:oc.I2])
+		case 'd':
+			add(DiffOpDelete, wordsA[oc.I1:oc.I2])
+		case 'i':
+			add(DiffOpInsert, wordsB[oc.J1:oc.J2])
+		case 'r':
+			add(DiffOpDelete, wordsA[oc.I1:oc.I2])
+			add(DiffOpInsert, wordsB[oc.J1:oc.J2])
+		}
+	}
+	return ops
+}
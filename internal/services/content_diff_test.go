@@ -0,0 +1,63 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+func TestContentService_DiffContent(t *testing.T) {
+	a := &models.Content{ID: 1, OwnerID: store.DefaultOwnerID, Body: "Pods run on nodes.\nThe scheduler picks a node.\n"}
+	b := &models.Content{ID: 2, OwnerID: store.DefaultOwnerID, Body: "Pods run on nodes.\nThe kube scheduler picks the node.\n"}
+	cs := services.NewContentService(services.ContentServiceDeps{
+		ContentStore: &memContentStore{contents: map[int64]*models.Content{1: a, 2: b, 3: {ID: 3, OwnerID: store.DefaultOwnerID, Body: a.Body}}},
+	})
+	ctx := context.Background()
+
+	unified, err := cs.DiffContent(ctx, 1, 2, "")
+	require.NoError(t, err)
+	assert.Equal(t, services.DiffUnified, unified.Mode)
+	assert.False(t, unified.Identical)
+	assert.Contains(t, unified.Unified, "--- content/1")
+	assert.Contains(t, unified.Unified, "-The scheduler picks a node.")
+	assert.Contains(t, unified.Unified, "+The kube scheduler picks the node.")
+
+	words, err := cs.DiffContent(ctx, 1, 2, services.DiffWords)
+	require.NoError(t, err)
+	var before, after strings.Builder
+	var inserted, deleted []string
+	for _, op := range words.Ops {
+		switch op.Op {
+		case services.DiffOpEqual:
+			before.WriteString(op.Text)
+			after.WriteString(op.Text)
+		case services.DiffOpDelete:
+			before.WriteString(op.Text)
+			deleted = append(deleted, strings.TrimSpace(op.Text))
+		case services.DiffOpInsert:
+			after.WriteString(op.Text)
+			inserted = append(inserted, strings.TrimSpace(op.Text))
+		}
+	}
+	assert.Equal(t, a.Body, before.String())
+	assert.Equal(t, b.Body, after.String())
+	assert.Equal(t, []string{"a"}, deleted)
+	assert.Equal(t, []string{"kube", "the"}, inserted)
+
+	same, err := cs.DiffContent(ctx, 1, 3, services.DiffWords)
+	require.NoError(t, err)
+	assert.True(t, same.Identical)
+	assert.Empty(t, same.Ops)
+
+	_, err = cs.DiffContent(ctx, 1, 9, "")
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	_, err = cs.DiffContent(ctx, 1, 2, "chars")
+	assert.Error(t, err)
+}
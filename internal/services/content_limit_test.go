@@ -0,0 +1,35 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"mimir/internal/config"
+	"mimir/internal/inputprocessor"
+	"mimir/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticProcessor struct{ body string }
+
+func (p staticProcessor) Process(ctx context.Context, input string) (inputprocessor.Result, error) {
+	return inputprocessor.Result{Body: p.body, ContentType: "text/plain"}, nil
+}
+
+func TestContentService_AddContent_RejectsOversizedBody(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Content.MaxBodyLength = 10
+	cfg.Content.OversizePolicy = "reject"
+
+	cs := services.NewContentService(services.ContentServiceDeps{
+		Processor: staticProcessor{body: strings.Repeat("x", 11)},
+		Config:    cfg,
+	})
+
+	_, _, err := cs.AddContent(context.Background(), services.AddContentParams{SourceName: "test", RawInput: "input"})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, services.ErrContentTooLarge)
+}
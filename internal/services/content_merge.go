@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// MergeSeparator joins the bodies of merged content. Merged content can be
+// split again with SplitByDelimiter and the delimiter "---".
+const MergeSeparator = "\n\n---\n\n"
+
+// MergeContent combines several content items into a new one: bodies are
+// joined in the given order with MergeSeparator, tags and collections are
+// unioned, and the originals are deleted. The new item records the originals
+// in its "merged_from" metadata. An empty title uses the first item's title.
+// All database writes share one transaction; once it commits the originals'
+// embeddings are removed from vs (when non-nil) and the new item is embedded.
+func (cs *ContentService) MergeContent(ctx context.Context, ids []int64, title string, vs store.VectorStore) (*models.Content, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) < 2 {
+		return nil, fmt.Errorf("at least two distinct content IDs are required to merge")
+	}
+
+	originals := make([]*models.Content, len(ids))
+	bodies := make([]string, len(ids))
+	for i, id := range ids {
+		content, err := cs.getOwnedContent(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		originals[i] = content
+		bodies[i] = strings.TrimSpace(content.Body)
+	}
+
+	body := strings.Join(bodies, MergeSeparator)
+	if err := cs.checkBodyLength(len(body)); err != nil {
+		return nil, err
+	}
+
+	tagNames, err := cs.unionTagNames(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	collectionIDs, err := cs.unionCollectionIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := mergedContent(originals, title, body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cs.runInTx(ctx, func(tx store.ContentTx) error {
+		existed, err := tx.CreateContentIfNotExists(ctx, merged)
+		if err != nil {
+			return fmt.Errorf("create merged content: %w", err)
+		}
+		if existed {
+			return fmt.Errorf("merged body duplicates content %d: %w", merged.ID, store.ErrDuplicate)
+		}
+		if err := cs.applyTags(ctx, tx, merged.ID, tagNames); err != nil {
+			return err
+		}
+		for _, collectionID := range collectionIDs {
+			if err := tx.AddContentToCollection(ctx, collectionID, merged.ID); err != nil {
+				return fmt.Errorf("add merged content to collection %d: %w", collectionID, err)
+			}
+		}
+		for _, id := range ids {
+			if err := tx.DeleteContent(ctx, id); err != nil {
+				return fmt.Errorf("delete merged content %d: %w", id, err)
+			}
+		}
+		tx.AfterCommit(func() {
+			for _, id := range ids {
+				// The content is gone either way; leftover embeddings only
+				// waste space, so failures are logged rather than returned.
+				if err := cs.deleteEmbeddingsIfPresent(ctx, id, vs); err != nil {
+					log.Printf("WARN: Failed to delete embeddings of merged content %d: %v", id, err)
+				}
+			}
+			cs.enqueueEmbeddingJobIfPossible(ctx, merged)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("MergeContent: content_id=%d, merged=%v", merged.ID, ids)
+	return merged, nil
+}
+
+// mergedContent builds the content item combining originals. It keeps the
+// first item's source and type, the latest modification time, and is shared
+// only when every original is shared.
+func mergedContent(originals []*models.Content, title, body string) (*models.Content, error) {
+	first := originals[0]
+	if title == "" {
+		title = first.Title
+	}
+	ids := make([]int64, len(originals))
+	visibility := store.VisibilityShared
+	modifiedAt := first.ModifiedAt
+	for i, c := range originals {
+		ids[i] = c.ID
+		if c.Visibility != store.VisibilityShared {
+			visibility = store.VisibilityPrivate
+		}
+		if c.ModifiedAt != nil && (modifiedAt == nil || c.ModifiedAt.After(*modifiedAt)) {
+			modifiedAt = c.ModifiedAt
+		}
+	}
+	meta, err := json.Marshal(map[string]interface{}{"merged_from": ids})
+	if err != nil {
+		return nil, fmt.Errorf("marshal merge metadata: %w", err)
+	}
+	return &models.Content{
+		SourceID:    first.SourceID,
+		Title:       title,
+		Body:        body,
+		ContentType: first.ContentType,
+		ModifiedAt:  modifiedAt,
+		Metadata:    meta,
+		OwnerID:     first.OwnerID,
+		Visibility:  visibility,
+	}, nil
+}
+
+// unionTagNames returns the names of the tags on any of the content, in first-seen order.
+func (cs *ContentService) unionTagNames(ctx context.Context, ids []int64) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		tags, err := cs.tags.GetContentTags(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("get tags of content %d: %w", id, err)
+		}
+		for _, tag := range tags {
+			if !seen[tag.Name] {
+				seen[tag.Name] = true
+				names = append(names, tag.Name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// unionCollectionIDs returns the caller's collections containing any of the
+// content. Without a collection store no collections are carried over.
+func (cs *ContentService) unionCollectionIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	if cs.deps.CollectionStore == nil {
+		return nil, nil
+	}
+	var collectionIDs []int64
+	seen := make(map[int64]bool)
+	for _, id := range ids {
+		found, err := cs.deps.CollectionStore.ListContentCollectionIDs(ctx, OwnerFromContext(ctx), id)
+		if err != nil {
+			return nil, fmt.Errorf("list collections of content %d: %w", id, err)
+		}
+		for _, collectionID := range found {
+			if !seen[collectionID] {
+				seen[collectionID] = true
+				collectionIDs = append(collectionIDs, collectionID)
+			}
+		}
+	}
+	return collectionIDs, nil
+}
+
+// uniqueIDs drops repeated IDs, keeping the first occurrence.
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,76 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+type mergeTagStore struct {
+	store.TagStore
+	tags map[int64][]string
+}
+
+func (s mergeTagStore) GetContentTags(ctx context.Context, contentID int64) ([]*models.Tag, error) {
+	var tags []*models.Tag
+	for _, name := range s.tags[contentID] {
+		tags = append(tags, &models.Tag{Name: name})
+	}
+	return tags, nil
+}
+
+type mergeVectorStore struct {
+	store.VectorStore
+	deleted []int64
+}
+
+func (v *mergeVectorStore) DeleteEmbeddingsByContentID(ctx context.Context, contentID int64) error {
+	v.deleted = append(v.deleted, contentID)
+	return nil
+}
+
+func TestContentService_MergeContent(t *testing.T) {
+	contents := &memContentStore{contents: map[int64]*models.Content{
+		10: {ID: 10, SourceID: 3, Title: "Monday", Body: "first note\n", OwnerID: store.DefaultOwnerID, Visibility: store.VisibilityShared},
+		20: {ID: 20, SourceID: 4, Title: "Tuesday", Body: "second note", OwnerID: store.DefaultOwnerID, Visibility: store.VisibilityPrivate},
+	}}
+	runner := &fakeTxRunner{tx: &fakeTx{}}
+	jobs := &recordingJobClient{}
+	vs := &mergeVectorStore{}
+	cs := services.NewContentService(services.ContentServiceDeps{
+		ContentStore: contents,
+		TagStore:     mergeTagStore{tags: map[int64][]string{10: {"go", "notes"}, 20: {"notes"}}},
+		JobClient:    jobs,
+		TxRunner:     runner,
+	})
+
+	merged, err := cs.MergeContent(context.Background(), []int64{10, 20, 10}, "", vs)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Monday", merged.Title)
+	assert.Equal(t, "first note"+services.MergeSeparator+"second note", merged.Body)
+	assert.Equal(t, int64(3), merged.SourceID)
+	assert.Equal(t, store.VisibilityPrivate, merged.Visibility, "merged content is shared only if every original is")
+
+	var meta map[string][]int64
+	require.NoError(t, json.Unmarshal(merged.Metadata, &meta))
+	assert.Equal(t, []int64{10, 20}, meta["merged_from"])
+
+	assert.Equal(t, []int64{10, 20}, runner.tx.deleted)
+	assert.Equal(t, []int64{10, 20}, vs.deleted)
+	assert.Equal(t, []int64{merged.ID}, jobs.embedded)
+}
+
+func TestContentService_MergeContent_RequiresTwoItems(t *testing.T) {
+	cs := services.NewContentService(services.ContentServiceDeps{TxRunner: &fakeTxRunner{tx: &fakeTx{}}})
+
+	_, err := cs.MergeContent(context.Background(), []int64{5, 5}, "t", nil)
+	assert.Error(t, err)
+}
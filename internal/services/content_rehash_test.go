@@ -0,0 +1,48 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"mimir/internal/services"
+	"mimir/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rehashContentStore reports content 1 changed, 2 unchanged, 3 a duplicate and 4 missing.
+type rehashContentStore struct{ store.ContentStore }
+
+func (rehashContentStore) RehashContent(ctx context.Context, id int64) (bool, error) {
+	switch id {
+	case 1:
+		return true, nil
+	case 2:
+		return false, nil
+	case 3:
+		return false, store.ErrDuplicate
+	default:
+		return false, store.ErrNotFound
+	}
+}
+
+func (rehashContentStore) ListContentIDs(ctx context.Context, query string, filterTags []string, pinned *bool) ([]int64, error) {
+	return []int64{1, 2, 3}, nil
+}
+
+func TestRehashContent(t *testing.T) {
+	cs := services.NewContentService(services.ContentServiceDeps{ContentStore: rehashContentStore{}})
+
+	result, err := cs.RehashContent(context.Background(), []int64{1, 2, 3, 4})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Checked)
+	assert.Equal(t, 1, result.Changed)
+	assert.Equal(t, []int64{3}, result.Duplicates)
+	assert.Equal(t, []int64{4}, result.NotFound)
+
+	result, err = cs.RehashAllContent(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Checked)
+	assert.Equal(t, 1, result.Changed)
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// ReprocessResult reports the outcome of a reprocess run.
+type ReprocessResult struct {
+	Checked     int     `json:"checked"`
+	Updated     []int64 `json:"updated"`     // Body changed; re-embedding enqueued
+	NoOrigin    []int64 `json:"no_origin"`   // Added as text, from stdin or by upload, or from a URL before URLs were recorded
+	Unreachable []int64 `json:"unreachable"` // The file or URL can no longer be read or processed; left unchanged
+	Duplicates  []int64 `json:"duplicates"`  // The new body already belongs to other content; left unchanged
+	NotFound    []int64 `json:"not_found"`
+}
+
+// contentOrigin returns the file path or URL the content was read from and the
+// processor input type ("file" or "url") it should come back as; origin is
+// empty when neither was recorded.
+func contentOrigin(content *models.Content) (origin, inputType string) {
+	if content.FilePath != nil && *content.FilePath != "" {
+		return *content.FilePath, "file"
+	}
+	var meta map[string]interface{}
+	if len(content.Metadata) > 0 && json.Unmarshal(content.Metadata, &meta) == nil {
+		if u, ok := meta["source_url"].(string); ok && u != "" {
+			return u, "url"
+		}
+	}
+	return "", ""
+}
+
+// ReprocessContent reads the given content again from the file or URL it was
+// added from and re-runs the input processor, so items stored before an
+// extraction improvement (PDF text, content type detection) get the better
+// body. Items whose body changes are re-embedded (see replaceEmbeddings).
+// Items without a recorded origin, or whose origin can no longer be read, are
+// left unchanged and reported.
+func (cs *ContentService) ReprocessContent(ctx context.Context, ids []int64, vs store.VectorStore) (*ReprocessResult, error) {
+	if cs.processor == nil {
+		return nil, fmt.Errorf("input processor is not initialized")
+	}
+	result := &ReprocessResult{Updated: []int64{}, NoOrigin: []int64{}, Unreachable: []int64{}, Duplicates: []int64{}, NotFound: []int64{}}
+	for _, id := range ids {
+		content, err := cs.contents.GetContent(ctx, id)
+		if errors.Is(err, store.ErrNotFound) {
+			result.NotFound = append(result.NotFound, id)
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("get content %d: %w", id, err)
+		}
+		result.Checked++
+
+		origin, inputType := contentOrigin(content)
+		if origin == "" {
+			result.NoOrigin = append(result.NoOrigin, id)
+			continue
+		}
+		processed, err := cs.processor.Process(ctx, origin)
+		if err == nil && processed.Metadata["input_type"] != inputType {
+			// The processor takes a path that no longer exists for raw text.
+			err = fmt.Errorf("%s no longer exists", inputType)
+		}
+		if err == nil {
+			err = cs.enforceBodyLimit(&processed)
+		}
+		if err == nil && strings.TrimSpace(processed.Body) == "" {
+			err = ErrEmptyBody
+		}
+		if err != nil {
+			log.Printf("WARN: Cannot reprocess content %d from %s: %v", id, origin, err)
+			result.Unreachable = append(result.Unreachable, id)
+			continue
+		}
+
+		oldHash := content.ContentHash
+		content.Body = processed.Body
+		content.ContentType = processed.ContentType
+		if processed.FileSize != nil {
+			content.FileSize = processed.FileSize
+		}
+		if processed.Mtime != nil {
+			content.ModifiedAt = processed.Mtime
+		}
+		if pages, ok := processed.Metadata["page_count"]; ok {
+			meta := map[string]interface{}{}
+			if len(content.Metadata) > 0 {
+				if err := json.Unmarshal(content.Metadata, &meta); err != nil {
+					return result, fmt.Errorf("unmarshal metadata of content %d: %w", id, err)
+				}
+			}
+			meta["page_count"] = pages
+			if content.Metadata, err = json.Marshal(meta); err != nil {
+				return result, fmt.Errorf("marshal metadata of content %d: %w", id, err)
+			}
+		}
+
+		err = cs.contents.UpdateContent(ctx, content)
+		if errors.Is(err, store.ErrDuplicate) {
+			log.Printf("WARN: Reprocessed content %d duplicates existing content, leaving it unchanged", id)
+			result.Duplicates = append(result.Duplicates, id)
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("update content %d: %w", id, err)
+		}
+		if content.ContentHash == oldHash {
+			continue
+		}
+		if err := cs.replaceEmbeddings(ctx, content, vs); err != nil {
+			return result, err
+		}
+		result.Updated = append(result.Updated, id)
+	}
+	return result, nil
+}
+
+// ReprocessContentByType reprocesses every content item of the given content
+// type, e.g. "application/pdf"; parameters such as charset are ignored.
+func (cs *ContentService) ReprocessContentByType(ctx context.Context, contentType string, vs store.VectorStore) (*ReprocessResult, error) {
+	ids, err := cs.contents.ListContentIDsByContentType(ctx, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("list content for reprocessing: %w", err)
+	}
+	return cs.ReprocessContent(ctx, ids, vs)
+}
@@ -0,0 +1,54 @@
+package services_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/inputprocessor"
+	"mimir/internal/models"
+	"mimir/internal/services"
+)
+
+func TestReprocessContent(t *testing.T) {
+	dir := t.TempDir()
+	changed := filepath.Join(dir, "changed.txt")
+	require.NoError(t, os.WriteFile(changed, []byte("better extracted text"), 0o644))
+	same := filepath.Join(dir, "same.txt")
+	require.NoError(t, os.WriteFile(same, []byte("unchanged"), 0o644))
+	missing := filepath.Join(dir, "missing.txt")
+
+	contents := &memContentStore{contents: map[int64]*models.Content{
+		1: {ID: 1, Body: "garbled", FilePath: &changed, IsEmbedded: true},
+		2: {ID: 2, Body: "unchanged", FilePath: &same, IsEmbedded: true},
+		3: {ID: 3, Body: "gone", FilePath: &missing, IsEmbedded: true},
+		4: {ID: 4, Body: "typed in", IsEmbedded: true},
+	}}
+	for _, c := range contents.contents {
+		require.NoError(t, contents.UpdateContent(context.Background(), c))
+	}
+	jobs := &recordingJobClient{}
+	cs := services.NewContentService(services.ContentServiceDeps{
+		ContentStore: contents, JobClient: jobs, Processor: inputprocessor.New(),
+	})
+	vs := &deletingVectorStore{}
+
+	result, err := cs.ReprocessContent(context.Background(), []int64{1, 2, 3, 4, 5}, vs)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, result.Checked)
+	assert.Equal(t, []int64{1}, result.Updated)
+	assert.Equal(t, []int64{3}, result.Unreachable)
+	assert.Equal(t, []int64{4}, result.NoOrigin)
+	assert.Equal(t, []int64{5}, result.NotFound)
+
+	assert.Equal(t, "better extracted text", contents.contents[1].Body)
+	assert.False(t, contents.contents[1].IsEmbedded)
+	assert.Equal(t, "gone", contents.contents[3].Body, "unreachable content is left unchanged")
+	assert.Equal(t, []int64{1}, jobs.embedded)
+	assert.Equal(t, []int64{1}, vs.deleted)
+}
@@ -3,9 +3,12 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/hibiken/asynq"
 	"mimir/internal/config"
@@ -15,6 +18,35 @@ import (
 	"mimir/internal/store"
 )
 
+// ErrContentTooLarge is returned by AddContent when the processed body exceeds
+// content.max_body_length and the oversize policy is "reject", and by
+// AppendToContent and UpdateContent whenever the new body would exceed it.
+var ErrContentTooLarge = errors.New("content body exceeds maximum length")
+
+// ErrEmptyAppend is returned by AppendToContent when there is no text to append.
+var ErrEmptyAppend = errors.New("append text cannot be empty")
+
+// ErrEmptyBody is returned by UpdateContent when the new body is blank.
+var ErrEmptyBody = errors.New("content body cannot be empty")
+
+// ErrAccessTrackingDisabled is returned by the view listing methods when no
+// ContentAccessStore is configured.
+var ErrAccessTrackingDisabled = errors.New("content access tracking is not configured")
+
+// ErrInvalidVisibility is returned by AddContent for a visibility other than
+// "private" or "shared".
+var ErrInvalidVisibility = errors.New("visibility must be 'private' or 'shared'")
+
+// viewRecordTimeout bounds how long a background view record may take.
+const viewRecordTimeout = 5 * time.Second
+
+// Summarization job settings, overridable under worker.jobs.summarization.
+const (
+	summarizationJobName         = "summarization"
+	defaultSummarizationMaxRetry = 3
+	defaultSummarizationTimeout  = 5 * time.Minute
+)
+
 // ContentInputResult holds extracted content details
 // Note: Field names and types updated to match PrepareContentInput assignments.
 type ContentInputResult struct {
@@ -30,8 +62,9 @@ type ContentInputResult struct {
 }
 
 type ContentResultItem struct {
-	Content models.Content
-	Tags    []*models.Tag
+	Content        models.Content
+	Tags           []*models.Tag
+	EmbeddingState string `json:"embedding_state"` // store.EmbeddingStatus* value: embedded, pending or failed
 }
 
 type CollectionResultItem struct {
@@ -40,8 +73,9 @@ type CollectionResultItem struct {
 }
 
 type KeywordResultItem struct {
-	Content *models.Content
-	Score   float64
+	Content     *models.Content
+	Score       float64
+	Explanation *SearchExplanation `json:"explanation,omitempty"` // Matched terms; set when KeywordSearchParams.Explain is
 }
 
 type ContentService struct {
@@ -64,6 +98,10 @@ type ListContentParams struct {
 	SortBy     string
 	SortOrder  string
 	FilterTags []string
+	Pinned     *bool  // When non-nil, only content with this pinned state is listed
+	Query      string // Full-text match on title and body; honoured by TagService.TagContentByFilter
+
+	EmbeddingStatus string // store.EmbeddingStatus* value; empty lists content of any status
 }
 
 // Update constructor signature to accept inputprocessor.Processor
@@ -75,8 +113,12 @@ type ContentServiceDeps struct {
 	Processor             inputprocessor.Processor
 	SummaryService        SummaryService
 	TaggingService        TaggingService
-	CategorizationService *CategorizationService // Add this line
-	Config                *config.Config         // Add config reference
+	CategorizationService *CategorizationService   // Add this line
+	Config                *config.Config           // Add config reference
+	TxRunner              store.TxRunner           // Optional; without it AddContent's writes are not atomic
+	AccessStore           store.ContentAccessStore // Optional; without it GetContent records no views
+	CollectionStore       store.CollectionStore    // Optional; required to inherit collections when splitting content
+	CompletionService     CompletionService        // Optional; generates titles for content.auto_title, which otherwise uses the body
 }
 
 func NewContentService(deps ContentServiceDeps) *ContentService {
@@ -102,10 +144,54 @@ type AddContentParams struct {
 	Title      string
 	RawInput   string // Input string (file path, URL, or raw text)
 	SourceType string // Type of the source (e.g., "cli", "web")
+
+	// Body, when set, is stored as-is and RawInput is not processed. Used for
+	// pre-extracted records such as JSONL imports and text read from stdin.
+	Body        string
+	ContentType string                 // Content type of Body; empty means text/plain
+	Metadata    map[string]interface{} // Optional content metadata
+	Tags        []string               // Optional tag names applied with the content
+
+	// Upload, when set, holds the bytes of an uploaded file, extracted with
+	// inputprocessor.ProcessUpload instead of processing RawInput.
+	Upload     []byte
+	UploadName string // File name of Upload, recorded as the "filename" metadata
+
+	// Visibility is "private" or "shared"; empty uses content.default_visibility.
+	// The owner is taken from the context (see WithOwner).
+	Visibility string
+
+	// DeferEmbedding skips enqueueing the embedding job of new content; the
+	// caller enqueues it instead, e.g. through an EmbeddingBatcher.
+	DeferEmbedding bool
 }
 
 func (cs *ContentService) AddContent(ctx context.Context, params AddContentParams) (*models.Content, bool, error) {
-	inputResult, err := cs.processInput(ctx, params.RawInput)
+	var inputResult inputprocessor.Result
+	if params.Body != "" {
+		contentType := params.ContentType
+		if contentType == "" {
+			contentType = "text/plain"
+		}
+		inputResult = inputprocessor.Result{Body: params.Body, ContentType: contentType}
+	} else if params.Upload != nil {
+		var err error
+		inputResult, err = inputprocessor.ProcessUpload(params.Upload, params.UploadName)
+		if err != nil {
+			return nil, false, err
+		}
+	} else {
+		var err error
+		inputResult, err = cs.processInput(ctx, params.RawInput)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	if err := cs.enforceBodyLimit(&inputResult); err != nil {
+		return nil, false, err
+	}
+
+	visibility, err := cs.resolveVisibility(params.Visibility)
 	if err != nil {
 		return nil, false, err
 	}
@@ -116,73 +202,75 @@ func (cs *ContentService) AddContent(ctx context.Context, params AddContentParam
 	}
 
 	content := cs.buildContentModel(source.ID, params.Title, inputResult)
+	content.OwnerID = OwnerFromContext(ctx)
+	content.Visibility = visibility
+
+	// A duplicate is returned as stored, so look it up before the title and
+	// categorization calls, which are billed.
+	existing, err := cs.contents.FindContentByHash(ctx, content.OwnerID, store.ContentHash(content.Body))
+	if err == nil {
+		log.Printf("AddContent: content_id=%d, existed=true, title=%q, source=%q", existing.ID, existing.Title, params.SourceName)
+		return existing, true, nil
+	}
+	if !errors.Is(err, store.ErrNotFound) {
+		return nil, false, fmt.Errorf("find content by hash: %w", err)
+	}
 
-	existed, err := cs.contents.CreateContentIfNotExists(ctx, content)
-	if err != nil {
-		return nil, false, fmt.Errorf("create content: %w", err)
+	if content.Title == "" && cs.deps.Config != nil && cs.deps.Config.Content.AutoTitle {
+		content.Title = cs.generateTitle(ctx, inputResult.Body)
 	}
 
-	if !existed {
-		cs.enqueueEmbeddingJobIfPossible(ctx, content)
-		// Auto-tagging: If enabled, apply tags using CategorizationService
-		if cs.deps.Config != nil && cs.deps.Config.Categorization.AutoApplyTags && cs.deps.CategorizationService != nil {
-			res, err := cs.deps.CategorizationService.CategorizeContent(ctx, content.Title, content.Body, nil)
-			if err == nil && res != nil && len(res.Tags) > 0 {
-				tagObjs, err := cs.tags.GetOrCreateTagsByName(ctx, res.Tags)
-				if err == nil && len(tagObjs) > 0 {
-					tagIDs := make([]int64, len(tagObjs))
-					for i, t := range tagObjs {
-						tagIDs[i] = t.ID
-					}
-					_ = cs.tags.AddTagsToContent(ctx, content.ID, tagIDs)
-				} else if err != nil {
-					log.Printf("WARN: Failed to get/create tags (%v) for content %d: %v", res.Tags, content.ID, err)
-				}
-			} else if err != nil {
-				log.Printf("WARN: Failed to categorize content %d: %v", content.ID, err)
-			}
+	// Details extracted from the input are kept unless the caller sets the same keys.
+	metadata := make(map[string]interface{}, len(params.Metadata)+2)
+	for _, key := range []string{"page_count", "filename", "source_url"} {
+		if v, ok := inputResult.Metadata[key]; ok {
+			metadata[key] = v
 		}
-		// Summarization: If enabled, enqueue summarization job asynchronously
-		if cs.deps.Config != nil && cs.deps.Config.Summarization.Enabled && cs.jobs != nil {
-			// Enqueue summarization job
-			// Define the payload structure locally for marshalling.
-			// The worker will define and unmarshal its own expected payload.
-			type summarizationPayload struct {
-				ContentID int64 `json:"ContentID"`
-			}
-			payload := summarizationPayload{ContentID: content.ID}
-			payloadBytes, err := json.Marshal(payload)
-			if err != nil {
-				log.Printf("ERROR: Failed to marshal summarization payload for content %d: %v", content.ID, err)
-				// Decide if this should prevent adding content or just log
-			} else {
-				queueName := "default" // Default queue
-				if q, ok := cs.deps.Config.Worker.Queues["summarization"]; ok {
-					queueName = "summarization" // Use specific queue if defined
-					log.Printf("Using 'summarization' queue (priority %d) for summarization job.", q)
-				} else if q, ok := cs.deps.Config.Worker.Queues["default"]; ok {
-					log.Printf("Using 'default' queue (priority %d) for summarization job.", q)
-				}
-
-				task := asynq.NewTask(tasks.TypeSummarizationJob, payloadBytes)
-				// Enqueue with appropriate options (e.g., queue name from config)
-				_, err := cs.jobs.Enqueue(ctx, task,
-					"content", // relatedEntityType
-					content.ID, // relatedEntityID
-					asynq.Queue(queueName),
-					asynq.MaxRetry(3),             // Example retry count
-					asynq.Timeout(5*time.Minute), // Example timeout
-				)
-				if err != nil {
-					log.Printf("ERROR: Failed to enqueue summarization job for content %d: %v", content.ID, err)
-					// Potentially return error or just log
-				} else {
-					log.Printf("Successfully enqueued summarization job for content %d", content.ID)
-				}
-			}
-		} else if cs.deps.Config != nil && cs.deps.Config.Summarization.Enabled && cs.jobs == nil {
-			log.Printf("WARN: Summarization enabled but JobClient (cs.jobs) is nil. Cannot enqueue summarization job for content %d.", content.ID)
+	}
+	for k, v := range params.Metadata {
+		metadata[k] = v
+	}
+	if len(metadata) > 0 {
+		meta, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, false, fmt.Errorf("marshal content metadata: %w", err)
+		}
+		content.Metadata = meta
+	}
+
+	// Categorization calls the LLM, so it runs before the transaction rather
+	// than holding a connection open for the call.
+	autoTags := cs.suggestAutoTags(ctx, content)
+
+	// Content creation and synchronous tagging commit or roll back together.
+	var existed bool
+	err = cs.runInTx(ctx, func(tx store.ContentTx) error {
+		var err error
+		existed, err = tx.CreateContentIfNotExists(ctx, content)
+		if err != nil {
+			return fmt.Errorf("create content: %w", err)
+		}
+		if existed {
+			return nil
+		}
+		if err := cs.applyTags(ctx, tx, content.ID, params.Tags); err != nil {
+			return err
 		}
+		if err := cs.applyTags(ctx, tx, content.ID, autoTags); err != nil {
+			return err
+		}
+		// Jobs fire only once the content is durably committed, so workers never
+		// receive IDs of rolled-back content.
+		tx.AfterCommit(func() {
+			if !params.DeferEmbedding {
+				cs.enqueueEmbeddingJobIfPossible(ctx, content)
+			}
+			cs.enqueueSummarizationJobIfEnabled(ctx, content)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
 	}
 
 	log.Printf("AddContent: content_id=%d, existed=%v, title=%q, source=%q", content.ID, existed, content.Title, params.SourceName)
@@ -204,7 +292,7 @@ func (cs *ContentService) summarizeAndTagContent(ctx context.Context, content *m
 	// Tagging (no-op for now)
 	tags, err := cs.taggingService.SuggestTags(ctx, content.Body)
 	if err == nil && len(tags) > 0 {
-		tagObjs, err := cs.tags.GetOrCreateTagsByName(ctx, tags)
+		tagObjs, err := cs.tags.GetOrCreateTagsByName(ctx, content.OwnerID, tags)
 		if err == nil && len(tagObjs) > 0 {
 			tagIDs := make([]int64, len(tagObjs))
 			for i, t := range tagObjs {
@@ -215,6 +303,22 @@ func (cs *ContentService) summarizeAndTagContent(ctx context.Context, content *m
 	}
 }
 
+// resolveVisibility validates a requested visibility, defaulting an empty one
+// to content.default_visibility.
+func (cs *ContentService) resolveVisibility(visibility string) (string, error) {
+	if visibility == "" && cs.deps.Config != nil {
+		visibility = cs.deps.Config.Content.DefaultVisibility
+	}
+	switch visibility {
+	case "":
+		return store.VisibilityPrivate, nil
+	case store.VisibilityPrivate, store.VisibilityShared:
+		return visibility, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidVisibility, visibility)
+	}
+}
+
 // processInput handles input processing and error wrapping.
 func (cs *ContentService) processInput(ctx context.Context, rawInput string) (inputprocessor.Result, error) {
 	inputResult, err := cs.processor.Process(ctx, rawInput)
@@ -224,6 +328,142 @@ func (cs *ContentService) processInput(ctx context.Context, rawInput string) (in
 	return inputResult, nil
 }
 
+// enforceBodyLimit applies content.max_body_length to the processed body,
+// rejecting or truncating it according to content.oversize_policy.
+func (cs *ContentService) enforceBodyLimit(inputResult *inputprocessor.Result) error {
+	if cs.deps.Config == nil || cs.deps.Config.Content.MaxBodyLength <= 0 {
+		return nil
+	}
+	maxLen := cs.deps.Config.Content.MaxBodyLength
+	if len(inputResult.Body) <= maxLen {
+		return nil
+	}
+
+	if cs.deps.Config.Content.OversizePolicy != "truncate" {
+		return cs.checkBodyLength(len(inputResult.Body))
+	}
+
+	// Cut on a rune boundary so the stored body stays valid UTF-8.
+	cut := maxLen
+	for cut > 0 && !utf8.RuneStart(inputResult.Body[cut]) {
+		cut--
+	}
+	log.Printf("WARN: Truncating content body from %d to %d bytes (content.max_body_length)", len(inputResult.Body), cut)
+	inputResult.Body = inputResult.Body[:cut]
+	return nil
+}
+
+// checkBodyLength returns ErrContentTooLarge when a body of n bytes exceeds
+// content.max_body_length.
+func (cs *ContentService) checkBodyLength(n int) error {
+	if cs.deps.Config == nil || cs.deps.Config.Content.MaxBodyLength <= 0 || n <= cs.deps.Config.Content.MaxBodyLength {
+		return nil
+	}
+	return fmt.Errorf("%w: %d bytes (limit %d)", ErrContentTooLarge, n, cs.deps.Config.Content.MaxBodyLength)
+}
+
+// runInTx runs fn in a transaction when a TxRunner is configured, and directly
+// against the content, tag and collection stores otherwise.
+func (cs *ContentService) runInTx(ctx context.Context, fn func(tx store.ContentTx) error) error {
+	if cs.deps.TxRunner != nil {
+		return cs.deps.TxRunner.RunInTx(ctx, fn)
+	}
+	return fn(contentTagStores{cs.contents, cs.tags, cs.deps.CollectionStore})
+}
+
+// contentTagStores adapts separate content, tag and collection stores to store.ContentTx.
+type contentTagStores struct {
+	store.ContentStore
+	store.TagStore
+	store.CollectionStore
+}
+
+// AfterCommit runs fn immediately; there is no transaction to wait for.
+func (contentTagStores) AfterCommit(fn func()) { fn() }
+
+// suggestAutoTags categorizes new content and returns the tags to apply when
+// categorization.auto_apply_tags is enabled. Categorization failures are logged
+// and yield no tags.
+func (cs *ContentService) suggestAutoTags(ctx context.Context, content *models.Content) []string {
+	if cs.deps.Config == nil || !cs.deps.Config.Categorization.AutoApplyTags || cs.deps.CategorizationService == nil {
+		return nil
+	}
+	res, err := cs.deps.CategorizationService.CategorizeContent(ctx, content.Title, content.Body, nil)
+	if err != nil {
+		log.Printf("WARN: Failed to categorize content %q: %v", content.Title, err)
+		return nil
+	}
+	if res == nil {
+		return nil
+	}
+	return res.Tags
+}
+
+// applyTags creates any missing tags of the owner in ctx by name and attaches
+// them to the content.
+func (cs *ContentService) applyTags(ctx context.Context, tx store.ContentTx, contentID int64, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	tagObjs, err := tx.GetOrCreateTagsByName(ctx, OwnerFromContext(ctx), names)
+	if err != nil {
+		return fmt.Errorf("get/create tags %v for content %d: %w", names, contentID, err)
+	}
+	tagIDs := make([]int64, len(tagObjs))
+	for i, t := range tagObjs {
+		tagIDs[i] = t.ID
+	}
+	if err := tx.AddTagsToContent(ctx, contentID, tagIDs); err != nil {
+		return fmt.Errorf("apply tags to content %d: %w", contentID, err)
+	}
+	return nil
+}
+
+// enqueueSummarizationJobIfEnabled enqueues an asynchronous summarization job when summarization is enabled.
+func (cs *ContentService) enqueueSummarizationJobIfEnabled(ctx context.Context, content *models.Content) {
+	if cs.deps.Config != nil && cs.deps.Config.Summarization.Enabled && cs.jobs != nil {
+		// Enqueue summarization job
+		// Define the payload structure locally for marshalling.
+		// The worker will define and unmarshal its own expected payload.
+		type summarizationPayload struct {
+			ContentID int64 `json:"ContentID"`
+		}
+		payload := summarizationPayload{ContentID: content.ID}
+		payloadBytes, err := json.Marshal(payload)
+		if err != nil {
+			log.Printf("ERROR: Failed to marshal summarization payload for content %d: %v", content.ID, err)
+			// Decide if this should prevent adding content or just log
+		} else {
+			queueName := "default" // Default queue
+			if q, ok := cs.deps.Config.Worker.Queues["summarization"]; ok {
+				queueName = "summarization" // Use specific queue if defined
+				log.Printf("Using 'summarization' queue (priority %d) for summarization job.", q)
+			} else if q, ok := cs.deps.Config.Worker.Queues["default"]; ok {
+				log.Printf("Using 'default' queue (priority %d) for summarization job.", q)
+			}
+
+			task := asynq.NewTask(tasks.TypeSummarizationJob, payloadBytes)
+			jobCfg := cs.deps.Config.Worker.Jobs[summarizationJobName]
+			// Enqueue with appropriate options (e.g., queue name from config)
+			_, err := cs.jobs.Enqueue(ctx, task,
+				"content", // relatedEntityType
+				content.ID, // relatedEntityID
+				asynq.Queue(queueName),
+				asynq.MaxRetry(jobCfg.MaxRetryOr(defaultSummarizationMaxRetry)),
+				asynq.Timeout(jobCfg.TimeoutOr(defaultSummarizationTimeout)),
+			)
+			if err != nil {
+				log.Printf("ERROR: Failed to enqueue summarization job for content %d: %v", content.ID, err)
+				// Potentially return error or just log
+			} else {
+				log.Printf("Successfully enqueued summarization job for content %d", content.ID)
+			}
+		}
+	} else if cs.deps.Config != nil && cs.deps.Config.Summarization.Enabled && cs.jobs == nil {
+		log.Printf("WARN: Summarization enabled but JobClient (cs.jobs) is nil. Cannot enqueue summarization job for content %d.", content.ID)
+	}
+}
+
 // getOrCreateSource wraps source creation and error handling.
 func (cs *ContentService) getOrCreateSource(ctx context.Context, sourceName, sourceType string, inputResult inputprocessor.Result) (*models.Source, error) {
 	sourceURL := getSourceURLFromInputResult(inputResult)
@@ -297,7 +537,13 @@ func (cs *ContentService) enqueueEmbeddingJobIfPossible(ctx context.Context, con
 }
 
 func (cs *ContentService) ListContent(ctx context.Context, params ListContentParams) ([]ContentResultItem, error) {
-	contents, err := cs.contents.ListContent(ctx, params.Limit, params.Offset, params.SortBy, params.SortOrder, params.FilterTags)
+	var defaults config.DefaultsConfig
+	if cs.deps.Config != nil {
+		defaults = cs.deps.Config.Defaults
+	}
+	params.Limit = defaults.PageLimit(params.Limit)
+
+	contents, err := cs.contents.ListContent(ctx, params.Limit, params.Offset, params.SortBy, params.SortOrder, params.FilterTags, params.Pinned, OwnerFromContext(ctx), params.EmbeddingStatus)
 	if err != nil {
 		return nil, fmt.Errorf("list content: %w", err)
 	}
@@ -305,21 +551,91 @@ func (cs *ContentService) ListContent(ctx context.Context, params ListContentPar
 	return cs.attachTagsToContents(ctx, contents)
 }
 
-// attachTagsToContents attaches tags to each content item and returns ContentResultItem slices.
+// attachTagsToContents attaches tags to each content item with a single batch
+// query and returns ContentResultItem slices.
 func (cs *ContentService) attachTagsToContents(ctx context.Context, contents []*models.Content) ([]ContentResultItem, error) {
+	ids := make([]int64, len(contents))
+	for i, c := range contents {
+		ids[i] = c.ID
+	}
+	tagsByID, err := cs.tags.GetTagsForContents(ctx, ids)
+	if err != nil {
+		log.Printf("WARN: fetch tags for %d content items: %v", len(ids), err)
+		tagsByID = map[int64][]*models.Tag{}
+	}
+
+	states := cs.embeddingStates(ctx, contents)
+
 	result := make([]ContentResultItem, len(contents))
 	for i, c := range contents {
-		tags, err := cs.tags.GetContentTags(ctx, c.ID)
-		if err != nil {
-			log.Printf("WARN: fetch tags for content %d: %v", c.ID, err)
+		tags := tagsByID[c.ID]
+		if tags == nil {
 			tags = []*models.Tag{}
 		}
-		result[i] = ContentResultItem{Content: *c, Tags: tags}
+		result[i] = ContentResultItem{Content: *c, Tags: tags, EmbeddingState: states[c.ID]}
 	}
 	return result, nil
 }
 
+// EmbeddingState returns the embedding state of content: embedded, failed when
+// it is not embedded and an embedding job failed, and pending otherwise.
+func (cs *ContentService) EmbeddingState(ctx context.Context, content *models.Content) string {
+	return cs.embeddingStates(ctx, []*models.Content{content})[content.ID]
+}
+
+// embeddingStates returns the embedding state (store.EmbeddingStatus*) of each
+// content item by ID, looking up failed jobs for unembedded items in one query.
+// When that lookup fails they are reported as pending.
+func (cs *ContentService) embeddingStates(ctx context.Context, contents []*models.Content) map[int64]string {
+	states := make(map[int64]string, len(contents))
+	var unembedded []int64
+	for _, c := range contents {
+		if c.IsEmbedded {
+			states[c.ID] = store.EmbeddingStatusEmbedded
+			continue
+		}
+		states[c.ID] = store.EmbeddingStatusPending
+		unembedded = append(unembedded, c.ID)
+	}
+	if len(unembedded) == 0 {
+		return states
+	}
+
+	failed, err := cs.contents.ListFailedEmbeddingContentIDs(ctx, unembedded)
+	if err != nil {
+		log.Printf("WARN: look up failed embedding jobs for %d content items: %v", len(unembedded), err)
+		return states
+	}
+	for _, id := range failed {
+		states[id] = store.EmbeddingStatusFailed
+	}
+	return states
+}
+
+// GetContentsWithTags returns the content with the given IDs and their tags in
+// two queries, in the order of ids. IDs that do not exist or are not visible to
+// the owner in ctx are skipped, as are repeated IDs.
+func (cs *ContentService) GetContentsWithTags(ctx context.Context, ids []int64) ([]ContentResultItem, error) {
+	ids = uniqueIDs(ids)
+	contents, err := cs.contents.GetContentsByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get content by IDs: %w", err)
+	}
+
+	ownerID := OwnerFromContext(ctx)
+	found := make([]*models.Content, 0, len(contents))
+	for _, c := range contents {
+		if c != nil && store.VisibleTo(c, ownerID) {
+			found = append(found, c)
+		}
+	}
+	return cs.attachTagsToContents(ctx, found)
+}
+
 func (cs *ContentService) DeleteContent(ctx context.Context, contentID int64, vs store.VectorStore) error {
+	if _, err := cs.getOwnedContent(ctx, contentID); err != nil {
+		return fmt.Errorf("DeleteContent: %w", err)
+	}
 	if err := cs.deleteEmbeddingsIfPresent(ctx, contentID, vs); err != nil {
 		return fmt.Errorf("DeleteContent: %w", err)
 	}
@@ -351,12 +667,293 @@ func (cs *ContentService) deleteContentFromStore(ctx context.Context, contentID
 	return nil
 }
 
-// GetContent retrieves a single content item by its ID.
+// GetContent retrieves a single content item by its ID and records a view of it
+// in the background.
 func (cs *ContentService) GetContent(ctx context.Context, id int64) (*models.Content, error) {
+	content, err := cs.getContent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	cs.recordView(id)
+	return content, nil
+}
+
+// getContent retrieves content without counting it as a view; used when the
+// service reads content for its own purposes. Content not visible to the owner
+// in ctx is reported as store.ErrNotFound.
+func (cs *ContentService) getContent(ctx context.Context, id int64) (*models.Content, error) {
 	content, err := cs.contents.GetContent(ctx, id)
 	if err != nil {
 		// Wrap the error for context, potentially checking for store.ErrNotFound
 		return nil, fmt.Errorf("GetContent: failed to get content with ID %d from store: %w", id, err)
 	}
+	if !store.VisibleTo(content, OwnerFromContext(ctx)) {
+		return nil, fmt.Errorf("GetContent: content %d: %w", id, store.ErrNotFound)
+	}
+	return content, nil
+}
+
+// getOwnedContent is getContent for changes: shared content of other owners is
+// visible but cannot be changed, and is reported as store.ErrNotFound.
+func (cs *ContentService) getOwnedContent(ctx context.Context, id int64) (*models.Content, error) {
+	content, err := cs.getContent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if content.OwnerID != OwnerFromContext(ctx) {
+		return nil, fmt.Errorf("content %d is not owned by the caller: %w", id, store.ErrNotFound)
+	}
+	return content, nil
+}
+
+// recordView records a view of the content without blocking the read. Failures
+// are logged and otherwise ignored.
+func (cs *ContentService) recordView(id int64) {
+	access := cs.deps.AccessStore
+	if access == nil {
+		return
+	}
+	go func() {
+		// Detached from the request context, which is cancelled once the response is written.
+		ctx, cancel := context.WithTimeout(context.Background(), viewRecordTimeout)
+		defer cancel()
+		if err := access.RecordContentView(ctx, id); err != nil {
+			log.Printf("WARN: %v", err)
+		}
+	}()
+}
+
+// ListRecentlyViewed returns viewed content, most recently viewed first, with
+// each item's view count.
+func (cs *ContentService) ListRecentlyViewed(ctx context.Context, limit int) ([]store.ContentView, error) {
+	if cs.deps.AccessStore == nil {
+		return nil, ErrAccessTrackingDisabled
+	}
+	views, err := cs.deps.AccessStore.ListRecentlyViewed(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list recently viewed content: %w", err)
+	}
+	return views, nil
+}
+
+// ListPopularContent returns viewed content, most viewed first.
+func (cs *ContentService) ListPopularContent(ctx context.Context, limit int) ([]store.ContentView, error) {
+	if cs.deps.AccessStore == nil {
+		return nil, ErrAccessTrackingDisabled
+	}
+	views, err := cs.deps.AccessStore.ListPopularContent(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list popular content: %w", err)
+	}
+	return views, nil
+}
+
+// ReassignSource moves content to the source named newSourceName, creating the
+// source if needed. The body, hash and embeddings are left unchanged.
+func (cs *ContentService) ReassignSource(ctx context.Context, contentID int64, newSourceName string) (*models.Content, error) {
+	if newSourceName == "" {
+		return nil, fmt.Errorf("source name cannot be empty")
+	}
+
+	content, err := cs.getOwnedContent(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := cs.ss.GetOrCreateSource(ctx, GetOrCreateSourceParams{Name: newSourceName, Type: "manual"})
+	if err != nil {
+		return nil, fmt.Errorf("get/create source '%s': %w", newSourceName, err)
+	}
+	if source.ID == content.SourceID {
+		return content, nil
+	}
+
+	if err := cs.contents.UpdateContentSource(ctx, contentID, source.ID); err != nil {
+		return nil, fmt.Errorf("reassign content %d to source '%s': %w", contentID, newSourceName, err)
+	}
+	content.SourceID = source.ID
+	// Embedding metadata carries the source ID filtered on by semantic search.
+	// The source change itself has already been applied, so failures are logged.
+	if cs.jobs != nil {
+		if err := cs.jobs.EnqueueEmbeddingMetadataUpdateJob(ctx, contentID); err != nil {
+			log.Printf("WARN: Failed to enqueue embedding metadata update for content %d: %v", contentID, err)
+		}
+	}
+	return content, nil
+}
+
+// AppendToContent appends text to a content item's body on a new line,
+// recalculates its hash and re-embeds it. The item keeps its ID, tags and
+// collections, so growing documents do not turn into duplicates. Content that
+// is already embedded only has the appended text embedded (see AppendEmbedder).
+func (cs *ContentService) AppendToContent(ctx context.Context, contentID int64, text string) (*models.Content, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, ErrEmptyAppend
+	}
+
+	content, err := cs.getOwnedContent(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := content.Body
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	body += text
+	if err := cs.checkBodyLength(len(body)); err != nil {
+		return nil, err
+	}
+
+	fromHash := content.ContentHash
+	content.Body = body
+	if err := cs.contents.UpdateContent(ctx, content); err != nil {
+		return nil, fmt.Errorf("append to content %d: %w", contentID, err)
+	}
+
+	cs.enqueueAppendEmbedding(ctx, content, fromHash, text)
+	return content, nil
+}
+
+// UpdateContentParams holds the fields of an edit. Nil fields are left unchanged.
+type UpdateContentParams struct {
+	Title    *string
+	Body     *string
+	Metadata map[string]interface{} // Replaces the stored metadata when non-nil
+}
+
+// UpdateContent edits a content item. Only when the body's hash changed is the
+// content marked not embedded, its embeddings deleted and an embedding job
+// enqueued (see replaceEmbeddings), so title and metadata edits leave the
+// embeddings in place even when the embedding input template includes the
+// title. vs may be nil to leave stored vectors to the embedding job.
+func (cs *ContentService) UpdateContent(ctx context.Context, contentID int64, params UpdateContentParams, vs store.VectorStore) (*models.Content, error) {
+	content, err := cs.getOwnedContent(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Title != nil {
+		content.Title = *params.Title
+	}
+	if params.Body != nil {
+		if strings.TrimSpace(*params.Body) == "" {
+			return nil, ErrEmptyBody
+		}
+		if err := cs.checkBodyLength(len(*params.Body)); err != nil {
+			return nil, err
+		}
+		content.Body = *params.Body
+	}
+	if params.Metadata != nil {
+		meta, err := json.Marshal(params.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("marshal content metadata: %w", err)
+		}
+		content.Metadata = meta
+	}
+
+	oldHash := content.ContentHash
+	if err := cs.contents.UpdateContent(ctx, content); err != nil {
+		return nil, fmt.Errorf("update content %d: %w", contentID, err)
+	}
+	if content.ContentHash == oldHash {
+		return content, nil
+	}
+	if err := cs.replaceEmbeddings(ctx, content, vs); err != nil {
+		return nil, err
+	}
 	return content, nil
 }
+
+// replaceEmbeddings marks content whose body changed as not embedded, deletes
+// its outdated embeddings and enqueues an embedding job. The embedding ID is
+// cleared before the vectors go, so FindRelatedContent never looks up a
+// deleted embedding.
+func (cs *ContentService) replaceEmbeddings(ctx context.Context, content *models.Content, vs store.VectorStore) error {
+	if err := cs.contents.ClearContentEmbedding(ctx, content.ID); err != nil {
+		return fmt.Errorf("clear outdated embedding of content %d: %w", content.ID, err)
+	}
+	content.IsEmbedded = false
+	content.EmbeddingID = nil
+	content.EmbeddedHash = nil
+	if err := cs.deleteEmbeddingsIfPresent(ctx, content.ID, vs); err != nil {
+		log.Printf("WARN: Failed to delete outdated embeddings of content %d: %v", content.ID, err)
+	}
+	cs.enqueueEmbeddingJobIfPossible(ctx, content)
+	return nil
+}
+
+// enqueueAppendEmbedding enqueues incremental embedding of appended text when the
+// content's embeddings are current, and a full embedding job otherwise.
+func (cs *ContentService) enqueueAppendEmbedding(ctx context.Context, content *models.Content, fromHash, text string) {
+	if cs.jobs == nil || !content.IsEmbedded || content.EmbeddedHash == nil || *content.EmbeddedHash != fromHash {
+		cs.enqueueEmbeddingJobIfPossible(ctx, content)
+		return
+	}
+	if err := cs.jobs.EnqueueEmbeddingAppendJob(ctx, content.ID, fromHash, content.ContentHash, text); err != nil {
+		log.Printf("ERROR: %v", err)
+	}
+}
+
+// PinContent sets or clears the pinned flag on a content item and returns
+// the updated content.
+func (cs *ContentService) PinContent(ctx context.Context, contentID int64, pinned bool) (*models.Content, error) {
+	if _, err := cs.getOwnedContent(ctx, contentID); err != nil {
+		return nil, err
+	}
+	if err := cs.contents.SetContentPinned(ctx, contentID, pinned); err != nil {
+		return nil, fmt.Errorf("pin content %d: %w", contentID, err)
+	}
+	return cs.getContent(ctx, contentID)
+}
+
+// ListStaleEmbeddings returns content whose body changed after it was embedded.
+func (cs *ContentService) ListStaleEmbeddings(ctx context.Context) ([]*models.Content, error) {
+	contents, err := cs.contents.ListStaleEmbeddings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list stale embeddings: %w", err)
+	}
+	return contents, nil
+}
+
+// RehashResult reports the outcome of a rehash run.
+type RehashResult struct {
+	Checked    int     `json:"checked"`
+	Changed    int     `json:"changed"`
+	Duplicates []int64 `json:"duplicates"` // Content whose new hash already belongs to other content; left unchanged
+	NotFound   []int64 `json:"not_found"`
+}
+
+// RehashContent recomputes the dedup hash of the given content under the
+// current hashing rules, so content stored before the rules changed is
+// deduplicated against new content correctly.
+func (cs *ContentService) RehashContent(ctx context.Context, ids []int64) (*RehashResult, error) {
+	result := &RehashResult{Duplicates: []int64{}, NotFound: []int64{}}
+	for _, id := range ids {
+		changed, err := cs.contents.RehashContent(ctx, id)
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			result.NotFound = append(result.NotFound, id)
+			continue
+		case errors.Is(err, store.ErrDuplicate):
+			log.Printf("WARN: Rehashed content %d duplicates existing content, keeping its old hash", id)
+			result.Duplicates = append(result.Duplicates, id)
+		case err != nil:
+			return result, fmt.Errorf("rehash content %d: %w", id, err)
+		case changed:
+			result.Changed++
+		}
+		result.Checked++
+	}
+	return result, nil
+}
+
+// RehashAllContent recomputes the dedup hash of every content item.
+func (cs *ContentService) RehashAllContent(ctx context.Context) (*RehashResult, error) {
+	ids, err := cs.contents.ListContentIDs(ctx, "", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list content for rehash: %w", err)
+	}
+	return cs.RehashContent(ctx, ids)
+}
@@ -0,0 +1,33 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+func TestContentService_ReassignSource_UpdatesEmbeddingMetadata(t *testing.T) {
+	contents := newMemContentStore(&models.Content{ID: 4, SourceID: 2, OwnerID: store.DefaultOwnerID, IsEmbedded: true})
+	jobs := &recordingJobClient{}
+	cs := services.NewContentService(services.ContentServiceDeps{
+		ContentStore:  contents,
+		JobClient:     jobs,
+		SourceService: services.NewSourceService(fakeSourceStore{}),
+	})
+
+	content, err := cs.ReassignSource(context.Background(), 4, "notes")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), content.SourceID)
+	assert.Equal(t, int64(1), contents.contents[4].SourceID)
+	assert.Equal(t, []int64{4}, jobs.metadataUpdates, "embedding metadata carries the new source ID")
+
+	_, err = cs.ReassignSource(context.Background(), 4, "notes")
+	require.NoError(t, err)
+	assert.Len(t, jobs.metadataUpdates, 1, "an unchanged source needs no update")
+}
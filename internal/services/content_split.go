@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// Ways of splitting content into sections.
+const (
+	SplitByHeading   = "heading"   // Markdown ATX headings (#, ##, ...)
+	SplitByDelimiter = "delimiter" // A literal delimiter string
+)
+
+// ErrNothingToSplit is returned by SplitContent when the body has fewer than two sections.
+var ErrNothingToSplit = errors.New("content has fewer than two sections to split")
+
+// SplitContentParams controls how SplitContent divides a content item.
+type SplitContentParams struct {
+	By        string // SplitByHeading (default) or SplitByDelimiter
+	Delimiter string // Required for SplitByDelimiter
+	// Level is the heading level to split at for SplitByHeading; 0 uses the
+	// shallowest level in the body. Deeper headings stay inside their section.
+	Level int
+
+	InheritTags        bool // Apply the parent's tags to the new items
+	InheritCollections bool // Add the new items to the parent's collections
+}
+
+// SplitContentResult reports the items created by SplitContent.
+type SplitContentResult struct {
+	Parent   *models.Content
+	Created  []*models.Content
+	Existing []int64 // Sections whose body already exists as content; left unchanged
+}
+
+// contentSection is one part of a body being split.
+type contentSection struct {
+	Title string
+	Body  string
+}
+
+// markdownHeading matches an ATX heading line, capturing its level and text.
+var markdownHeading = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// SplitContent creates a content item for each section of an existing item,
+// so one large import can be searched and organized as separate documents. New
+// items keep the parent's source, owner and visibility, record the parent in
+// their "split_from" metadata and are embedded on their own. The parent itself
+// is left unchanged; delete it once the split looks right.
+func (cs *ContentService) SplitContent(ctx context.Context, contentID int64, params SplitContentParams) (*SplitContentResult, error) {
+	if params.InheritCollections && cs.deps.CollectionStore == nil {
+		return nil, fmt.Errorf("collection store is not initialized")
+	}
+	parent, err := cs.getOwnedContent(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []contentSection
+	switch params.By {
+	case "", SplitByHeading:
+		sections = splitByHeading(parent.Body, params.Level)
+	case SplitByDelimiter:
+		if params.Delimiter == "" {
+			return nil, fmt.Errorf("a delimiter is required to split by delimiter")
+		}
+		sections = splitByDelimiter(parent.Body, params.Delimiter)
+	default:
+		return nil, fmt.Errorf("invalid split mode %q: must be %s or %s", params.By, SplitByHeading, SplitByDelimiter)
+	}
+	if len(sections) < 2 {
+		return nil, fmt.Errorf("content %d: %w", contentID, ErrNothingToSplit)
+	}
+
+	var tagNames []string
+	if params.InheritTags {
+		tags, err := cs.tags.GetContentTags(ctx, parent.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get tags of content %d: %w", parent.ID, err)
+		}
+		for _, tag := range tags {
+			tagNames = append(tagNames, tag.Name)
+		}
+	}
+
+	var collectionIDs []int64
+	if params.InheritCollections {
+		collectionIDs, err = cs.deps.CollectionStore.ListContentCollectionIDs(ctx, OwnerFromContext(ctx), parent.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list collections of content %d: %w", parent.ID, err)
+		}
+	}
+
+	result := &SplitContentResult{Parent: parent, Created: []*models.Content{}, Existing: []int64{}}
+	err = cs.runInTx(ctx, func(tx store.ContentTx) error {
+		for i, section := range sections {
+			child, err := splitChild(parent, section, i, len(sections))
+			if err != nil {
+				return err
+			}
+			existed, err := tx.CreateContentIfNotExists(ctx, child)
+			if err != nil {
+				return fmt.Errorf("create section %d of content %d: %w", i+1, parent.ID, err)
+			}
+			if existed {
+				result.Existing = append(result.Existing, child.ID)
+				continue
+			}
+			if err := cs.applyTags(ctx, tx, child.ID, tagNames); err != nil {
+				return err
+			}
+			for _, collectionID := range collectionIDs {
+				if err := tx.AddContentToCollection(ctx, collectionID, child.ID); err != nil {
+					return fmt.Errorf("add content %d to collection %d: %w", child.ID, collectionID, err)
+				}
+			}
+			result.Created = append(result.Created, child)
+		}
+		tx.AfterCommit(func() {
+			for _, child := range result.Created {
+				cs.enqueueEmbeddingJobIfPossible(ctx, child)
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("SplitContent: content_id=%d, created=%d, existing=%d", parent.ID, len(result.Created), len(result.Existing))
+	return result, nil
+}
+
+// splitChild builds the content item for section i of n.
+func splitChild(parent *models.Content, section contentSection, i, n int) (*models.Content, error) {
+	meta, err := json.Marshal(map[string]interface{}{"split_from": parent.ID, "split_index": i})
+	if err != nil {
+		return nil, fmt.Errorf("marshal split metadata: %w", err)
+	}
+	title := section.Title
+	if title == "" {
+		title = fmt.Sprintf("%s (%d/%d)", parent.Title, i+1, n)
+	}
+	return &models.Content{
+		SourceID:    parent.SourceID,
+		Title:       title,
+		Body:        section.Body,
+		ContentType: parent.ContentType,
+		ModifiedAt:  parent.ModifiedAt,
+		Metadata:    meta,
+		OwnerID:     parent.OwnerID,
+		Visibility:  parent.Visibility,
+	}, nil
+}
+
+// splitByHeading splits a markdown body before each heading of the given level
+// (or the shallowest level present when level is 0). Text before the first
+// heading becomes an untitled section; headings inside fenced code blocks are
+// ignored. Each section's body starts with its heading line.
+func splitByHeading(body string, level int) []contentSection {
+	lines := strings.Split(body, "\n")
+
+	// Find heading lines outside code fences.
+	headings := make(map[int]int) // line index -> heading level
+	inFence := false
+	shallowest := 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if m := markdownHeading.FindStringSubmatch(line); m != nil {
+			headings[i] = len(m[1])
+			if shallowest == 0 || len(m[1]) < shallowest {
+				shallowest = len(m[1])
+			}
+		}
+	}
+	if level <= 0 {
+		level = shallowest
+	}
+
+	var sections []contentSection
+	current := contentSection{}
+	var buf []string
+	flush := func() {
+		text := strings.TrimSpace(strings.Join(buf, "\n"))
+		if text != "" {
+			current.Body = text
+			sections = append(sections, current)
+		}
+	}
+	for i, line := range lines {
+		if l, ok := headings[i]; ok && l == level {
+			flush()
+			current = contentSection{Title: markdownHeading.FindStringSubmatch(line)[2]}
+			buf = nil
+		}
+		buf = append(buf, line)
+	}
+	flush()
+	return sections
+}
+
+// splitByDelimiter splits body on every occurrence of delimiter, dropping empty sections.
+func splitByDelimiter(body, delimiter string) []contentSection {
+	var sections []contentSection
+	for _, part := range strings.Split(body, delimiter) {
+		if text := strings.TrimSpace(part); text != "" {
+			sections = append(sections, contentSection{Body: text})
+		}
+	}
+	return sections
+}
@@ -0,0 +1,55 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+func TestContentService_SplitContent_ByHeading(t *testing.T) {
+	body := "Intro text\n\n# First\nalpha\n## Detail\nmore alpha\n```\n# not a heading\n```\n# Second\nbeta"
+	parent := &models.Content{ID: 100, SourceID: 7, Title: "Notes", Body: body, OwnerID: store.DefaultOwnerID, Visibility: store.VisibilityPrivate}
+	runner := &fakeTxRunner{tx: &fakeTx{}}
+	jobs := &recordingJobClient{}
+	cs := services.NewContentService(services.ContentServiceDeps{
+		ContentStore: newMemContentStore(parent),
+		JobClient:    jobs,
+		TxRunner:     runner,
+	})
+
+	result, err := cs.SplitContent(context.Background(), parent.ID, services.SplitContentParams{})
+	require.NoError(t, err)
+	require.Len(t, result.Created, 3)
+
+	assert.Equal(t, "Notes (1/3)", result.Created[0].Title)
+	assert.Equal(t, "Intro text", result.Created[0].Body)
+	assert.Equal(t, "First", result.Created[1].Title)
+	assert.Contains(t, result.Created[1].Body, "## Detail")
+	assert.Contains(t, result.Created[1].Body, "# not a heading")
+	assert.Equal(t, "# Second\nbeta", result.Created[2].Body)
+	assert.Equal(t, int64(7), result.Created[2].SourceID)
+
+	var meta map[string]interface{}
+	require.NoError(t, json.Unmarshal(result.Created[1].Metadata, &meta))
+	assert.Equal(t, float64(100), meta["split_from"])
+	assert.Len(t, jobs.embedded, 3, "each new item is embedded")
+}
+
+func TestContentService_SplitContent_NothingToSplit(t *testing.T) {
+	parent := &models.Content{ID: 1, Body: "one---", OwnerID: store.DefaultOwnerID}
+	cs := services.NewContentService(services.ContentServiceDeps{
+		ContentStore: newMemContentStore(parent),
+		TxRunner:     &fakeTxRunner{tx: &fakeTx{}},
+	})
+
+	_, err := cs.SplitContent(context.Background(), 1, services.SplitContentParams{By: services.SplitByDelimiter, Delimiter: "---"})
+	assert.True(t, errors.Is(err, services.ErrNothingToSplit))
+}
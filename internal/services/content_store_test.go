@@ -0,0 +1,132 @@
+package services_test
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// memContentStore is an in-memory store.ContentStore shared by the service
+// tests. It implements the methods below; any other panics through the nil
+// embedded interface. Like the primary store, reads return copies and
+// UpdateContent recalculates the content hash.
+type memContentStore struct {
+	store.ContentStore
+	mu        sync.Mutex
+	contents  map[int64]*models.Content
+	failed    map[int64]bool // Reported by ListFailedEmbeddingContentIDs
+	batchGets int            // GetContentsByIDs calls
+	marked    []int64        // Content marked embedded, in order
+}
+
+func newMemContentStore(contents ...*models.Content) *memContentStore {
+	s := &memContentStore{contents: make(map[int64]*models.Content, len(contents))}
+	for _, c := range contents {
+		s.contents[c.ID] = c
+	}
+	return s
+}
+
+func (s *memContentStore) GetContent(ctx context.Context, id int64) (*models.Content, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.contents[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	copied := *c
+	return &copied, nil
+}
+
+// GetContentsByIDs returns the stored items among ids, in the order of ids.
+func (s *memContentStore) GetContentsByIDs(ctx context.Context, ids []int64) ([]*models.Content, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batchGets++
+	var out []*models.Content
+	for _, id := range ids {
+		if c, ok := s.contents[id]; ok {
+			copied := *c
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (s *memContentStore) FindContentByHash(ctx context.Context, ownerID, hash string) (*models.Content, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.contents {
+		if c.OwnerID == ownerID && c.ContentHash == hash {
+			copied := *c
+			return &copied, nil
+		}
+	}
+	return nil, store.ErrNotFound
+}
+
+func (s *memContentStore) UpdateContent(ctx context.Context, content *models.Content) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content.ContentHash = store.ContentHash(content.Body)
+	copied := *content
+	s.contents[content.ID] = &copied
+	return nil
+}
+
+func (s *memContentStore) UpdateContentSource(ctx context.Context, contentID, sourceID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.contents[contentID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	c.SourceID = sourceID
+	return nil
+}
+
+func (s *memContentStore) ClearContentEmbedding(ctx context.Context, contentID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.contents[contentID]; ok {
+		c.IsEmbedded = false
+		c.EmbeddingID = nil
+		c.EmbeddedHash = nil
+	}
+	return nil
+}
+
+// UpdateContentEmbeddingStatus records the embedding, marking the current
+// body as the embedded one.
+func (s *memContentStore) UpdateContentEmbeddingStatus(ctx context.Context, contentID int64, embeddingID uuid.UUID, isEmbedded bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.contents[contentID]
+	if !ok {
+		return store.ErrNotFound
+	}
+	c.IsEmbedded = isEmbedded
+	if isEmbedded {
+		hash := c.ContentHash
+		c.EmbeddingID = &embeddingID
+		c.EmbeddedHash = &hash
+		s.marked = append(s.marked, contentID)
+	}
+	return nil
+}
+
+func (s *memContentStore) ListFailedEmbeddingContentIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	var failed []int64
+	for _, id := range ids {
+		if s.failed[id] {
+			failed = append(failed, id)
+		}
+	}
+	return failed, nil
+}
+
+func (s *memContentStore) TouchContentAccessed(ctx context.Context, ids []int64) error { return nil }
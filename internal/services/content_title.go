@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxTitleRunes caps generated titles, which come from a model or the body.
+	maxTitleRunes = 120
+	// titlePromptRunes caps the body excerpt sent to the completion service.
+	titlePromptRunes = 4000
+)
+
+const titleSystemPrompt = "You write titles for documents in a personal knowledge base. " +
+	"Reply with only a concise, descriptive title of at most 12 words, without quotes or trailing punctuation."
+
+// generateTitle returns a title for body, for content added without one when
+// content.auto_title is set. It asks the completion service when one is
+// configured and falls back to FallbackTitle when there is none or it fails.
+func (cs *ContentService) generateTitle(ctx context.Context, body string) string {
+	if completer := cs.deps.CompletionService; completer != nil {
+		excerpt := body
+		if utf8.RuneCountInString(excerpt) > titlePromptRunes {
+			excerpt = string([]rune(excerpt)[:titlePromptRunes])
+		}
+		reply, err := completer.GenerateChatCompletion(ctx, []ChatMessage{
+			{Role: ChatMessageRoleSystem, Content: titleSystemPrompt},
+			{Role: ChatMessageRoleUser, Content: excerpt},
+		})
+		if err != nil {
+			log.Printf("WARN: Generating title with %s failed, using the body instead: %v", completer.Name(), err)
+		} else if title := cleanTitle(reply); title != "" {
+			return title
+		}
+	}
+	return FallbackTitle(body)
+}
+
+// FallbackTitle derives a title from body without a model: the first Markdown
+// heading, or else the first non-blank line, truncated to a readable length.
+func FallbackTitle(body string) string {
+	var firstLine string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if heading := strings.TrimLeft(line, "#"); heading != line && strings.HasPrefix(heading, " ") {
+			if title := cleanTitle(heading); title != "" {
+				return title
+			}
+		}
+		if firstLine == "" {
+			firstLine = line
+		}
+	}
+	return cleanTitle(firstLine)
+}
+
+// cleanTitle reduces a model reply or line of text to a single-line title:
+// surrounding quotes, a "Title:" label and trailing periods are removed, and
+// long titles are cut at a word boundary.
+func cleanTitle(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	if len(s) >= len("title:") && strings.EqualFold(s[:len("title:")], "title:") {
+		s = strings.TrimSpace(s[len("title:"):])
+	}
+	s = strings.Trim(s, "\"'`*")
+	s = strings.TrimRight(strings.TrimSpace(s), ".")
+
+	if utf8.RuneCountInString(s) <= maxTitleRunes {
+		return s
+	}
+	cut := string([]rune(s)[:maxTitleRunes])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
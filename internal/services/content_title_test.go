@@ -0,0 +1,111 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"mimir/internal/config"
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubCompleter returns reply, or err when set.
+type stubCompleter struct {
+	reply string
+	err   error
+}
+
+func (c stubCompleter) GenerateChatCompletion(ctx context.Context, messages []services.ChatMessage) (string, error) {
+	return c.reply, c.err
+}
+func (stubCompleter) Status() store.ProviderStatus { return store.ProviderStatusActive }
+func (stubCompleter) Name() string                 { return "stub" }
+func (stubCompleter) ModelName() string            { return "stub-model" }
+
+func addUntitled(t *testing.T, completer services.CompletionService) string {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Content.AutoTitle = true
+	cs := services.NewContentService(services.ContentServiceDeps{
+		ContentStore:      newMemContentStore(),
+		JobClient:         &recordingJobClient{},
+		SourceService:     services.NewSourceService(fakeSourceStore{}),
+		Processor:         staticProcessor{body: "# Release Notes\n\nfixes"},
+		Config:            cfg,
+		TxRunner:          &fakeTxRunner{tx: &fakeTx{}},
+		CompletionService: completer,
+	})
+	content, _, err := cs.AddContent(context.Background(), services.AddContentParams{SourceName: "test", RawInput: "input"})
+	require.NoError(t, err)
+	return content.Title
+}
+
+func TestContentService_AddContent_AutoTitle(t *testing.T) {
+	assert.Equal(t, "Mimir 2.0 Release Notes", addUntitled(t, stubCompleter{reply: "Title: \"Mimir 2.0 Release Notes.\"\n"}))
+	assert.Equal(t, "Release Notes", addUntitled(t, stubCompleter{err: errors.New("rate limited")}), "falls back to the body when completion fails")
+	assert.Equal(t, "Release Notes", addUntitled(t, nil), "uses the body without a completion service")
+}
+
+// countingCompleter counts its calls.
+type countingCompleter struct {
+	stubCompleter
+	calls *int
+}
+
+func (c countingCompleter) GenerateChatCompletion(ctx context.Context, messages []services.ChatMessage) (string, error) {
+	*c.calls++
+	return c.stubCompleter.GenerateChatCompletion(ctx, messages)
+}
+
+func TestContentService_AddContent_NoTitleForDuplicates(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Content.AutoTitle = true
+	body := "# Release Notes\n\nfixes"
+	var calls int
+	cs := services.NewContentService(services.ContentServiceDeps{
+		ContentStore:      newMemContentStore(&models.Content{ID: 3, Title: "Release Notes", Body: body, ContentHash: store.ContentHash(body), OwnerID: store.DefaultOwnerID}),
+		JobClient:         &recordingJobClient{},
+		SourceService:     services.NewSourceService(fakeSourceStore{}),
+		Processor:         staticProcessor{body: body},
+		Config:            cfg,
+		TxRunner:          &fakeTxRunner{tx: &fakeTx{}},
+		CompletionService: countingCompleter{stubCompleter: stubCompleter{reply: "Other"}, calls: &calls},
+	})
+
+	content, existed, err := cs.AddContent(context.Background(), services.AddContentParams{SourceName: "test", RawInput: "input"})
+	require.NoError(t, err)
+	assert.True(t, existed)
+	assert.Equal(t, "Release Notes", content.Title)
+	assert.Zero(t, calls, "no title is generated for existing content")
+}
+
+func TestFallbackTitle(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"first heading", "intro line\n\n## Setup Guide\n\ntext", "Setup Guide"},
+		{"first line", "\n\n  Meeting notes from Monday.  \nmore", "Meeting notes from Monday"},
+		{"hashtag is not a heading", "#golang tips\nbody", "#golang tips"},
+		{"empty body", "  \n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, services.FallbackTitle(tt.body))
+		})
+	}
+}
+
+func TestFallbackTitle_TruncatesLongLines(t *testing.T) {
+	title := services.FallbackTitle(strings.Repeat("word ", 100))
+	assert.True(t, strings.HasSuffix(title, "…"))
+	assert.LessOrEqual(t, len([]rune(title)), 121)
+	assert.False(t, strings.HasSuffix(strings.TrimSuffix(title, "…"), " "))
+}
@@ -0,0 +1,227 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/config"
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+	"mimir/pkg/categorizer"
+)
+
+// fakeTx records writes and fails AddTagsToContent when tagErr is set.
+type fakeTx struct {
+	created []*models.Content
+	deleted []int64
+	tagErr  error
+	hooks   []func()
+}
+
+func (f *fakeTx) CreateContentIfNotExists(ctx context.Context, content *models.Content) (bool, error) {
+	content.ID = int64(len(f.created) + 1)
+	f.created = append(f.created, content)
+	return false, nil
+}
+
+func (f *fakeTx) GetOrCreateTagsByName(ctx context.Context, ownerID string, names []string) ([]*models.Tag, error) {
+	tags := make([]*models.Tag, len(names))
+	for i, n := range names {
+		tags[i] = &models.Tag{ID: int64(i + 1), Name: n}
+	}
+	return tags, nil
+}
+
+func (f *fakeTx) AddTagsToContent(ctx context.Context, contentID int64, tagIDs []int64) error {
+	return f.tagErr
+}
+
+func (f *fakeTx) AddContentToCollection(ctx context.Context, collectionID, contentID int64) error {
+	return nil
+}
+
+func (f *fakeTx) DeleteContent(ctx context.Context, id int64) error {
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func (f *fakeTx) AfterCommit(fn func()) { f.hooks = append(f.hooks, fn) }
+
+// fakeTxRunner discards the transaction's writes and hooks when fn fails,
+// and runs the post-commit hooks otherwise.
+type fakeTxRunner struct {
+	tx           *fakeTx
+	committed    bool
+	rolledBack   bool
+	open         bool   // Set while fn runs
+	beforeCommit func() // Optional; called after fn succeeds, before the hooks run
+}
+
+func (r *fakeTxRunner) RunInTx(ctx context.Context, fn func(tx store.ContentTx) error) error {
+	r.open = true
+	err := fn(r.tx)
+	r.open = false
+	if err != nil {
+		r.tx.created = nil
+		r.tx.deleted = nil
+		r.tx.hooks = nil
+		r.rolledBack = true
+		return err
+	}
+	if r.beforeCommit != nil {
+		r.beforeCommit()
+	}
+	r.committed = true
+	for _, hook := range r.tx.hooks {
+		hook()
+	}
+	return nil
+}
+
+type fakeSourceStore struct{}
+
+func (fakeSourceStore) CreateSource(ctx context.Context, source *models.Source) error { return nil }
+func (fakeSourceStore) GetSource(ctx context.Context, id int64) (*models.Source, error) {
+	return &models.Source{ID: id}, nil
+}
+func (fakeSourceStore) GetSourceByName(ctx context.Context, name string) (*models.Source, error) {
+	return &models.Source{ID: 1, Name: name}, nil
+}
+func (fakeSourceStore) ListSources(ctx context.Context, limit, offset int) ([]*models.Source, error) {
+	return nil, nil
+}
+
+type recordingJobClient struct {
+	embedded        []int64
+	metadataUpdates []int64
+}
+
+func (j *recordingJobClient) Enqueue(ctx context.Context, task *asynq.Task, relatedEntityType string, relatedEntityID int64, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	return &asynq.TaskInfo{}, nil
+}
+func (j *recordingJobClient) EnqueueEmbeddingJob(ctx context.Context, contentID int64) error {
+	j.embedded = append(j.embedded, contentID)
+	return nil
+}
+func (j *recordingJobClient) EnqueueReindexEmbeddingJob(ctx context.Context, contentID, runID int64) error {
+	return nil
+}
+func (j *recordingJobClient) EnqueueEmbeddingMetadataUpdateJob(ctx context.Context, contentID int64) error {
+	j.metadataUpdates = append(j.metadataUpdates, contentID)
+	return nil
+}
+func (j *recordingJobClient) EnqueueEmbeddingAppendJob(ctx context.Context, contentID int64, fromHash, toHash, text string) error {
+	return nil
+}
+func (j *recordingJobClient) EnqueueBatchEmbeddingJob(ctx context.Context, contentIDs []int64) error {
+	return nil
+}
+func (j *recordingJobClient) Close() error { return nil }
+
+type staticCategorizer struct{ tags []string }
+
+func (c staticCategorizer) Categorize(ctx context.Context, req categorizer.CategorizationRequest) (categorizer.CategorizationResult, error) {
+	return categorizer.CategorizationResult{SuggestedTags: c.tags}, nil
+}
+
+// countingCategorizer counts its calls.
+type countingCategorizer struct{ calls *int }
+
+func (c countingCategorizer) Categorize(ctx context.Context, req categorizer.CategorizationRequest) (categorizer.CategorizationResult, error) {
+	*c.calls++
+	return categorizer.CategorizationResult{SuggestedTags: []string{"go"}}, nil
+}
+
+// txCheckingCategorizer records whether it was called inside runner's transaction.
+type txCheckingCategorizer struct {
+	runner *fakeTxRunner
+	inTx   *bool
+}
+
+func (c txCheckingCategorizer) Categorize(ctx context.Context, req categorizer.CategorizationRequest) (categorizer.CategorizationResult, error) {
+	*c.inTx = c.runner.open
+	return categorizer.CategorizationResult{SuggestedTags: []string{"go"}}, nil
+}
+
+func newTxContentService(runner *fakeTxRunner, jobs *recordingJobClient) *services.ContentService {
+	return newTxContentServiceWith(runner, jobs, staticCategorizer{tags: []string{"go"}})
+}
+
+func newTxContentServiceWith(runner *fakeTxRunner, jobs *recordingJobClient, c categorizer.ContentCategorizer, contents ...*models.Content) *services.ContentService {
+	cfg := &config.Config{}
+	cfg.Categorization.AutoApplyTags = true
+	return services.NewContentService(services.ContentServiceDeps{
+		ContentStore:          newMemContentStore(contents...),
+		JobClient:             jobs,
+		SourceService:         services.NewSourceService(fakeSourceStore{}),
+		Processor:             staticProcessor{body: "body"},
+		CategorizationService: services.NewCategorizationService(c, nil, nil, nil),
+		Config:                cfg,
+		TxRunner:              runner,
+	})
+}
+
+func TestContentService_AddContent_RollsBackOnTagFailure(t *testing.T) {
+	runner := &fakeTxRunner{tx: &fakeTx{tagErr: errors.New("tag insert failed")}}
+	jobs := &recordingJobClient{}
+	cs := newTxContentService(runner, jobs)
+
+	_, _, err := cs.AddContent(context.Background(), services.AddContentParams{SourceName: "test", Title: "t", RawInput: "input"})
+	require.Error(t, err)
+
+	assert.True(t, runner.rolledBack)
+	assert.False(t, runner.committed)
+	assert.Empty(t, runner.tx.created, "content insert must be rolled back")
+	assert.Empty(t, jobs.embedded, "no job may be enqueued for rolled-back content")
+}
+
+func TestContentService_AddContent_EnqueuesAfterCommit(t *testing.T) {
+	runner := &fakeTxRunner{tx: &fakeTx{}}
+	jobs := &recordingJobClient{}
+	runner.beforeCommit = func() {
+		assert.Empty(t, jobs.embedded, "jobs must not be enqueued before commit")
+	}
+	cs := newTxContentService(runner, jobs)
+
+	content, existed, err := cs.AddContent(context.Background(), services.AddContentParams{SourceName: "test", Title: "t", RawInput: "input"})
+	require.NoError(t, err)
+
+	assert.False(t, existed)
+	assert.True(t, runner.committed)
+	assert.Equal(t, []int64{content.ID}, jobs.embedded)
+}
+
+func TestContentService_AddContent_CategorizesOutsideTx(t *testing.T) {
+	runner := &fakeTxRunner{tx: &fakeTx{}}
+	inTx := true
+	cs := newTxContentServiceWith(runner, &recordingJobClient{}, txCheckingCategorizer{runner: runner, inTx: &inTx})
+
+	_, _, err := cs.AddContent(context.Background(), services.AddContentParams{SourceName: "test", Title: "t", RawInput: "input"})
+	require.NoError(t, err)
+	assert.False(t, inTx, "the LLM call must not hold the transaction open")
+}
+
+func TestContentService_AddContent_SkipsCategorizationForDuplicates(t *testing.T) {
+	runner := &fakeTxRunner{tx: &fakeTx{}}
+	var calls int
+	existing := &models.Content{ID: 7, Title: "stored", Body: "body", ContentHash: store.ContentHash("body"), OwnerID: store.DefaultOwnerID}
+	cs := newTxContentServiceWith(runner, &recordingJobClient{}, countingCategorizer{calls: &calls}, existing)
+
+	content, existed, err := cs.AddContent(context.Background(), services.AddContentParams{SourceName: "test", Title: "t", RawInput: "input"})
+	require.NoError(t, err)
+	assert.True(t, existed)
+	assert.Equal(t, int64(7), content.ID)
+	assert.Zero(t, calls, "existing content must not be categorized again")
+	assert.Empty(t, runner.tx.created)
+
+	_, existed, err = cs.AddContent(services.WithOwner(context.Background(), "team-b"), services.AddContentParams{SourceName: "test", Title: "t", RawInput: "input"})
+	require.NoError(t, err)
+	assert.False(t, existed, "another owner's identical body is new content")
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,88 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+type deletingVectorStore struct {
+	store.VectorStore
+	deleted []int64
+}
+
+func (v *deletingVectorStore) DeleteEmbeddingsByContentID(ctx context.Context, contentID int64) error {
+	v.deleted = append(v.deleted, contentID)
+	return nil
+}
+
+func newUpdateTestService(t *testing.T) (*services.ContentService, *memContentStore, *recordingJobClient) {
+	t.Helper()
+	contents := newMemContentStore()
+	embeddingID := uuid.New()
+	require.NoError(t, contents.UpdateContent(context.Background(), &models.Content{
+		ID: 1, Title: "Old", Body: "body", OwnerID: store.DefaultOwnerID, IsEmbedded: true, EmbeddingID: &embeddingID,
+	}))
+	jobs := &recordingJobClient{}
+	cs := services.NewContentService(services.ContentServiceDeps{ContentStore: contents, JobClient: jobs})
+	return cs, contents, jobs
+}
+
+func TestUpdateContent_TitleOnlySkipsReembedding(t *testing.T) {
+	cs, contents, jobs := newUpdateTestService(t)
+	vs := &deletingVectorStore{}
+	title := "New"
+
+	content, err := cs.UpdateContent(context.Background(), 1, services.UpdateContentParams{Title: &title}, vs)
+	require.NoError(t, err)
+
+	assert.Equal(t, "New", content.Title)
+	assert.Equal(t, "New", contents.contents[1].Title)
+	assert.Empty(t, jobs.embedded, "title-only edit must not enqueue embedding")
+	assert.Empty(t, vs.deleted, "title-only edit must keep embeddings")
+	assert.True(t, contents.contents[1].IsEmbedded)
+	assert.NotNil(t, contents.contents[1].EmbeddingID)
+}
+
+func TestUpdateContent_BodyChangeReembeds(t *testing.T) {
+	cs, contents, jobs := newUpdateTestService(t)
+	vs := &deletingVectorStore{}
+	body := "new body"
+
+	content, err := cs.UpdateContent(context.Background(), 1, services.UpdateContentParams{Body: &body}, vs)
+	require.NoError(t, err)
+
+	assert.Equal(t, "new body", content.Body)
+	assert.Equal(t, []int64{1}, jobs.embedded)
+	assert.Equal(t, []int64{1}, vs.deleted)
+	assert.False(t, content.IsEmbedded)
+	assert.False(t, contents.contents[1].IsEmbedded, "content is pending until the new embedding job finishes")
+	assert.Nil(t, contents.contents[1].EmbeddingID, "related-content lookups must not use the deleted embedding")
+}
+
+func TestUpdateContent_SameBodySkipsReembedding(t *testing.T) {
+	cs, _, jobs := newUpdateTestService(t)
+	vs := &deletingVectorStore{}
+	body := "body"
+
+	_, err := cs.UpdateContent(context.Background(), 1, services.UpdateContentParams{Body: &body}, vs)
+	require.NoError(t, err)
+
+	assert.Empty(t, jobs.embedded)
+	assert.Empty(t, vs.deleted)
+}
+
+func TestUpdateContent_EmptyBodyRejected(t *testing.T) {
+	cs, _, _ := newUpdateTestService(t)
+	body := "  "
+
+	_, err := cs.UpdateContent(context.Background(), 1, services.UpdateContentParams{Body: &body}, nil)
+	assert.ErrorIs(t, err, services.ErrEmptyBody)
+}
@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"mimir/internal/models"
 	"mimir/internal/store"
@@ -18,20 +21,140 @@ func NewCostService(store store.CostTrackingStore) *CostService {
 	return &CostService{store: store}
 }
 
-// ListUsage retrieves a paginated list of AI usage logs.
+// ListUsage retrieves a paginated list of the AI usage logs billed to the owner in ctx.
 func (s *CostService) ListUsage(ctx context.Context, limit, offset int) ([]*models.AIUsageLog, error) {
-	logs, err := s.store.ListUsage(ctx, limit, offset)
+	logs, err := s.store.ListUsage(ctx, OwnerFromContext(ctx), limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list usage logs from store: %w", err)
 	}
 	return logs, nil
 }
 
-// GetSummary retrieves the total cost and token usage summary.
+// GetSummary retrieves the total cost and token usage billed to the owner in ctx.
 func (s *CostService) GetSummary(ctx context.Context) (totalCost float64, totalInputTokens, totalOutputTokens int64, err error) {
-	totalCost, totalInputTokens, totalOutputTokens, err = s.store.GetUsageSummary(ctx)
+	totalCost, totalInputTokens, totalOutputTokens, err = s.store.GetUsageSummary(ctx, OwnerFromContext(ctx))
 	if err != nil {
 		return 0, 0, 0, fmt.Errorf("failed to get usage summary from store: %w", err)
 	}
 	return totalCost, totalInputTokens, totalOutputTokens, nil
 }
+
+// GetUsageByOwner retrieves the cost and token usage of every owner, for billing
+// in multi-tenant deployments. It is not limited to the owner in ctx.
+func (s *CostService) GetUsageByOwner(ctx context.Context) ([]store.OwnerUsage, error) {
+	usage, err := s.store.GetUsageByOwner(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage by owner from store: %w", err)
+	}
+	return usage, nil
+}
+
+// CostSummary is the AI usage in a range, in total and per service type.
+type CostSummary struct {
+	TotalCost         float64              `json:"total_cost"`
+	TotalCalls        int64                `json:"total_calls"`
+	TotalInputTokens  int64                `json:"total_input_tokens"`
+	TotalOutputTokens int64                `json:"total_output_tokens"`
+	ByService         []store.ServiceUsage `json:"by_service"` // Highest cost first
+}
+
+// GetSummaryByService totals the usage in r billed to the owner in ctx per
+// service type (embedding, categorization, summarization, RAG completion, ...);
+// the totals are the sums of the breakdown.
+func (s *CostService) GetSummaryByService(ctx context.Context, r store.UsageRange) (*CostSummary, error) {
+	if err := validateUsageRange(r); err != nil {
+		return nil, err
+	}
+	byService, err := s.store.GetUsageSummaryByService(ctx, OwnerFromContext(ctx), r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get usage by service from store: %w", err)
+	}
+	summary := &CostSummary{ByService: byService}
+	for _, u := range byService {
+		summary.TotalCost += u.Cost
+		summary.TotalCalls += u.Calls
+		summary.TotalInputTokens += u.InputTokens
+		summary.TotalOutputTokens += u.OutputTokens
+	}
+	return summary, nil
+}
+
+// ParseUsageTime parses a usage range bound: a date (2006-01-02, UTC) or an
+// RFC 3339 timestamp. An empty string is the zero time, an open bound.
+// endOfRange moves a date to the start of the next day, so an exclusive range
+// end includes the date.
+func ParseUsageTime(s string, endOfRange bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		if endOfRange {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%s' is neither a date like 2006-01-02 nor an RFC 3339 timestamp", s)
+	}
+	return t, nil
+}
+
+// Usage groupings accepted by ParseUsageGroupBy.
+const (
+	UsageGroupDay   = "day"
+	UsageGroupModel = "model"
+)
+
+// ParseUsageGroupBy parses a comma-separated list of usage groupings, such as
+// "day", "model" or "day,model".
+func ParseUsageGroupBy(s string) (byDay, byModel bool, err error) {
+	for _, g := range strings.Split(s, ",") {
+		switch strings.TrimSpace(strings.ToLower(g)) {
+		case UsageGroupDay:
+			byDay = true
+		case UsageGroupModel:
+			byModel = true
+		case "":
+		default:
+			return false, false, fmt.Errorf("invalid usage grouping %q: use %s, %s or both", g, UsageGroupDay, UsageGroupModel)
+		}
+	}
+	return byDay, byModel, nil
+}
+
+// ErrInvalidUsageRange is returned for usage ranges that do not end after they start.
+var ErrInvalidUsageRange = errors.New("usage range must end after it starts")
+
+// validateUsageRange rejects ranges that end before they start.
+func validateUsageRange(r store.UsageRange) error {
+	if !r.From.IsZero() && !r.To.IsZero() && !r.To.After(r.From) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidUsageRange, r.From.Format(time.RFC3339), r.To.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// ExportUsage calls fn for every usage log in r billed to the owner in ctx,
+// oldest first, streaming them from the store.
+func (s *CostService) ExportUsage(ctx context.Context, r store.UsageRange, fn func(log *models.AIUsageLog) error) error {
+	if err := validateUsageRange(r); err != nil {
+		return err
+	}
+	if err := s.store.StreamUsage(ctx, OwnerFromContext(ctx), r, fn); err != nil {
+		return fmt.Errorf("failed to export usage logs: %w", err)
+	}
+	return nil
+}
+
+// GetUsageGroups totals the usage in r billed to the owner in ctx per day
+// and/or model (see ParseUsageGroupBy).
+func (s *CostService) GetUsageGroups(ctx context.Context, r store.UsageRange, byDay, byModel bool) ([]store.UsageGroup, error) {
+	if err := validateUsageRange(r); err != nil {
+		return nil, err
+	}
+	groups, err := s.store.GetUsageGroups(ctx, OwnerFromContext(ctx), r, byDay, byModel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grouped usage from store: %w", err)
+	}
+	return groups, nil
+}
@@ -0,0 +1,112 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+// rangeCostStore records the owner and range of usage queries.
+type rangeCostStore struct {
+	store.CostTrackingStore
+	ownerID string
+	r       store.UsageRange
+	logs    []*models.AIUsageLog
+}
+
+func (s *rangeCostStore) StreamUsage(ctx context.Context, ownerID string, r store.UsageRange, fn func(log *models.AIUsageLog) error) error {
+	s.ownerID, s.r = ownerID, r
+	for _, l := range s.logs {
+		if err := fn(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *rangeCostStore) GetUsageSummaryByService(ctx context.Context, ownerID string, r store.UsageRange) ([]store.ServiceUsage, error) {
+	s.ownerID, s.r = ownerID, r
+	return []store.ServiceUsage{
+		{ServiceType: "embedding", Calls: 10, InputTokens: 5000, Cost: 0.3},
+		{ServiceType: "summarization", Calls: 2, InputTokens: 800, OutputTokens: 200, Cost: 0.1},
+	}, nil
+}
+
+func TestParseUsageGroupBy(t *testing.T) {
+	byDay, byModel, err := services.ParseUsageGroupBy("day, Model")
+	require.NoError(t, err)
+	assert.True(t, byDay)
+	assert.True(t, byModel)
+
+	byDay, byModel, err = services.ParseUsageGroupBy("")
+	require.NoError(t, err)
+	assert.False(t, byDay || byModel)
+
+	_, _, err = services.ParseUsageGroupBy("week")
+	assert.Error(t, err)
+}
+
+func TestCostService_ExportUsage(t *testing.T) {
+	cs := &rangeCostStore{logs: []*models.AIUsageLog{{ID: 1}, {ID: 2}}}
+	svc := services.NewCostService(cs)
+	ctx := services.WithOwner(context.Background(), "alice")
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := store.UsageRange{From: from, To: from.AddDate(0, 1, 0)}
+
+	var ids []int64
+	err := svc.ExportUsage(ctx, r, func(log *models.AIUsageLog) error {
+		ids = append(ids, log.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ids)
+	assert.Equal(t, "alice", cs.ownerID)
+	assert.Equal(t, r, cs.r)
+
+	err = svc.ExportUsage(ctx, store.UsageRange{From: from, To: from}, func(*models.AIUsageLog) error { return nil })
+	assert.Error(t, err, "an empty range is rejected")
+}
+
+func TestCostService_GetSummaryByService(t *testing.T) {
+	cs := &rangeCostStore{}
+	svc := services.NewCostService(cs)
+	ctx := services.WithOwner(context.Background(), "alice")
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	summary, err := svc.GetSummaryByService(ctx, store.UsageRange{From: from})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", cs.ownerID)
+	assert.Equal(t, from, cs.r.From)
+	assert.Len(t, summary.ByService, 2)
+	assert.InDelta(t, 0.4, summary.TotalCost, 1e-9)
+	assert.Equal(t, int64(12), summary.TotalCalls)
+	assert.Equal(t, int64(5800), summary.TotalInputTokens)
+	assert.Equal(t, int64(200), summary.TotalOutputTokens)
+
+	_, err = svc.GetSummaryByService(ctx, store.UsageRange{From: from, To: from.AddDate(0, 0, -1)})
+	assert.ErrorIs(t, err, services.ErrInvalidUsageRange)
+}
+
+func TestParseUsageTime(t *testing.T) {
+	to, err := services.ParseUsageTime("2026-03-31", true)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), to, "a to date includes the whole day")
+
+	from, err := services.ParseUsageTime("2026-03-31T12:00:00Z", false)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC), from)
+
+	zero, err := services.ParseUsageTime("", false)
+	require.NoError(t, err)
+	assert.True(t, zero.IsZero())
+
+	_, err = services.ParseUsageTime("March", false)
+	assert.Error(t, err)
+}
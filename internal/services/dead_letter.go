@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"mimir/internal/models"
+	"mimir/internal/store"
+	"mimir/internal/tasks"
+)
+
+// Job statuses used by the dead-letter queue.
+const (
+	JobStatusDead    = "dead"    // Retries exhausted; asynq archived the task
+	JobStatusRetried = "retried" // A dead job that was requeued as a new job
+)
+
+// ErrJobNotDead is returned when retrying a job that is not in the dead state.
+var ErrJobNotDead = errors.New("job is not dead")
+
+// DeadLetterService records background jobs that exhausted their retries and
+// requeues them once the cause has been fixed.
+type DeadLetterService struct {
+	jobs     store.JobStore
+	client   store.JobClient
+	contents store.ContentStore // Optional; records embedding errors on content
+}
+
+// NewDeadLetterService creates a DeadLetterService.
+func NewDeadLetterService(jobs store.JobStore, client store.JobClient) *DeadLetterService {
+	return &DeadLetterService{jobs: jobs, client: client}
+}
+
+// SetContentStore enables RecordEmbeddingFailure.
+func (s *DeadLetterService) SetContentStore(contents store.ContentStore) {
+	s.contents = contents
+}
+
+// TaskExhausted reports whether a task that failed with err will not be
+// retried again, so asynq moves it to its archive. ctx must be the context
+// passed to the task's handler or the server's ErrorHandler.
+func TaskExhausted(ctx context.Context, err error) bool {
+	if errors.Is(err, asynq.SkipRetry) {
+		return true
+	}
+	retried, ok := asynq.GetRetryCount(ctx)
+	if !ok {
+		return false
+	}
+	maxRetry, ok := asynq.GetMaxRetry(ctx)
+	return ok && retried >= maxRetry
+}
+
+// RecordDeadTask marks the job of the asynq task taskID dead with err as its last error.
+func (s *DeadLetterService) RecordDeadTask(ctx context.Context, taskID string, err error) error {
+	jobID, parseErr := uuid.Parse(taskID)
+	if parseErr != nil {
+		return fmt.Errorf("parse task id %q: %w", taskID, parseErr)
+	}
+	if err := s.jobs.MarkJobDead(ctx, jobID, err.Error()); err != nil {
+		return fmt.Errorf("record dead task %s: %w", taskID, err)
+	}
+	log.Printf("WARN: Task %s exhausted its retries and was moved to the dead queue: %v", taskID, err)
+	return nil
+}
+
+// RecordEmbeddingFailure records err in the metadata of the content of an
+// embedding task that exhausted its retries (see store.EmbeddingErrorMetadataKey),
+// so the content reads as failed with a reason instead of pending. For batch
+// embedding tasks every item is marked; items already embedded are left alone
+// by MarkEmbeddingFailed. Other task types are ignored, as are calls without a
+// content store.
+func (s *DeadLetterService) RecordEmbeddingFailure(ctx context.Context, task *asynq.Task, err error) error {
+	if s.contents == nil {
+		return nil
+	}
+	var payload struct {
+		ContentID  int64   `json:"content_id"`
+		ContentIDs []int64 `json:"content_ids"`
+	}
+	switch task.Type() {
+	case tasks.TypeEmbeddingJob, tasks.TypeEmbeddingAppendJob:
+		if jsonErr := json.Unmarshal(task.Payload(), &payload); jsonErr != nil || payload.ContentID == 0 {
+			return fmt.Errorf("embedding task has no content ID in payload %q", task.Payload())
+		}
+		payload.ContentIDs = []int64{payload.ContentID}
+	case tasks.TypeBatchEmbeddingJob:
+		if jsonErr := json.Unmarshal(task.Payload(), &payload); jsonErr != nil || len(payload.ContentIDs) == 0 {
+			return fmt.Errorf("batch embedding task has no content IDs in payload %q", task.Payload())
+		}
+	default:
+		return nil
+	}
+	for _, id := range payload.ContentIDs {
+		if markErr := s.contents.MarkEmbeddingFailed(ctx, id, err.Error()); markErr != nil {
+			return fmt.Errorf("record embedding failure of content %d: %w", id, markErr)
+		}
+	}
+	return nil
+}
+
+// EmbeddingError returns the recorded reason embedding of content failed
+// permanently, or "" when none is recorded.
+func EmbeddingError(content *models.Content) string {
+	if len(content.Metadata) == 0 {
+		return ""
+	}
+	var metadata map[string]json.RawMessage
+	if err := json.Unmarshal(content.Metadata, &metadata); err != nil {
+		return ""
+	}
+	var failure struct {
+		Error string `json:"error"`
+	}
+	if raw, ok := metadata[store.EmbeddingErrorMetadataKey]; ok && json.Unmarshal(raw, &failure) == nil {
+		return failure.Error
+	}
+	return ""
+}
+
+// ListDeadJobs lists dead jobs, most recently failed first.
+func (s *DeadLetterService) ListDeadJobs(ctx context.Context, limit, offset int) ([]*models.BackgroundJob, error) {
+	jobs, err := s.jobs.ListJobsByStatus(ctx, JobStatusDead, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("list dead jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// RetryDeadJob requeues the dead job with database ID id as a new task with
+// the same type, payload and queue, and marks the dead job as retried.
+func (s *DeadLetterService) RetryDeadJob(ctx context.Context, id int64) (*asynq.TaskInfo, error) {
+	job, err := s.jobs.GetJob(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get job %d: %w", id, err)
+	}
+	if job.Status != JobStatusDead {
+		return nil, fmt.Errorf("job %d has status %q: %w", id, job.Status, ErrJobNotDead)
+	}
+	if s.client == nil {
+		return nil, fmt.Errorf("job client is not initialized")
+	}
+
+	var entityType string
+	var entityID int64
+	if job.RelatedEntityType != nil {
+		entityType = *job.RelatedEntityType
+	}
+	if job.RelatedEntityID != nil {
+		entityID = *job.RelatedEntityID
+	}
+	task := asynq.NewTask(job.TaskType, job.Payload)
+	info, err := s.client.Enqueue(ctx, task, entityType, entityID, asynq.Queue(job.Queue))
+	if err != nil {
+		return nil, fmt.Errorf("requeue job %d: %w", id, err)
+	}
+	if err := s.jobs.UpdateJobStatus(ctx, job.JobID, JobStatusRetried); err != nil {
+		// The task is already requeued; a stale "dead" status only lists it twice.
+		log.Printf("WARN: Requeued job %d as task %s but failed to mark it retried: %v", id, info.ID, err)
+	}
+	return info, nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+type fakeDeadJobStore struct {
+	store.JobStore
+	jobs map[int64]*models.BackgroundJob
+}
+
+func (f *fakeDeadJobStore) GetJob(ctx context.Context, id int64) (*models.BackgroundJob, error) {
+	job, ok := f.jobs[id]
+	if !ok {
+		return nil, store.ErrNotFound
+	}
+	return job, nil
+}
+
+func (f *fakeDeadJobStore) MarkJobDead(ctx context.Context, jobID uuid.UUID, lastError string) error {
+	for _, job := range f.jobs {
+		if job.JobID == jobID {
+			job.Status = JobStatusDead
+			job.LastError = &lastError
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+func (f *fakeDeadJobStore) UpdateJobStatus(ctx context.Context, jobID uuid.UUID, status string) error {
+	for _, job := range f.jobs {
+		if job.JobID == jobID {
+			job.Status = status
+			return nil
+		}
+	}
+	return store.ErrNotFound
+}
+
+type enqueueRecorder struct {
+	store.JobClient
+	tasks    []*asynq.Task
+	entityID int64
+}
+
+func (e *enqueueRecorder) Enqueue(ctx context.Context, task *asynq.Task, relatedEntityType string, relatedEntityID int64, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	e.tasks = append(e.tasks, task)
+	e.entityID = relatedEntityID
+	return &asynq.TaskInfo{ID: uuid.NewString(), Queue: "embeddings"}, nil
+}
+
+func TestDeadLetterService_RecordAndRetry(t *testing.T) {
+	ctx := context.Background()
+	jobID := uuid.New()
+	entityType, entityID := "content", int64(7)
+	jobs := &fakeDeadJobStore{jobs: map[int64]*models.BackgroundJob{
+		1: {ID: 1, JobID: jobID, TaskType: "embedding:generate", Payload: []byte(`{"content_id":7}`), Queue: "embeddings",
+			Status: "enqueued", RelatedEntityType: &entityType, RelatedEntityID: &entityID},
+	}}
+	client := &enqueueRecorder{}
+	svc := NewDeadLetterService(jobs, client)
+
+	_, err := svc.RetryDeadJob(ctx, 1)
+	assert.ErrorIs(t, err, ErrJobNotDead)
+
+	require.NoError(t, svc.RecordDeadTask(ctx, jobID.String(), errors.New("provider unavailable")))
+	assert.Equal(t, JobStatusDead, jobs.jobs[1].Status)
+	assert.Equal(t, "provider unavailable", *jobs.jobs[1].LastError)
+
+	info, err := svc.RetryDeadJob(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "embeddings", info.Queue)
+	require.Len(t, client.tasks, 1)
+	assert.Equal(t, "embedding:generate", client.tasks[0].Type())
+	assert.JSONEq(t, `{"content_id":7}`, string(client.tasks[0].Payload()))
+	assert.Equal(t, int64(7), client.entityID)
+	assert.Equal(t, JobStatusRetried, jobs.jobs[1].Status)
+
+	_, err = svc.RetryDeadJob(ctx, 2)
+	assert.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestTaskExhausted(t *testing.T) {
+	assert.True(t, TaskExhausted(context.Background(), asynq.SkipRetry))
+	assert.False(t, TaskExhausted(context.Background(), errors.New("boom")))
+}
+
+type embeddingFailureStore struct {
+	store.ContentStore
+	reasons map[int64]string
+}
+
+func (f *embeddingFailureStore) MarkEmbeddingFailed(ctx context.Context, contentID int64, reason string) error {
+	f.reasons[contentID] = reason
+	return nil
+}
+
+func TestDeadLetterService_RecordEmbeddingFailure(t *testing.T) {
+	contents := &embeddingFailureStore{reasons: map[int64]string{}}
+	svc := NewDeadLetterService(nil, nil)
+	svc.SetContentStore(contents)
+	ctx := context.Background()
+
+	err := svc.RecordEmbeddingFailure(ctx, asynq.NewTask("embedding:generate", []byte(`{"content_id":7}`)), errors.New("input too large"))
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]string{7: "input too large"}, contents.reasons)
+
+	require.NoError(t, svc.RecordEmbeddingFailure(ctx, asynq.NewTask("summarization:generate", []byte(`{"content_id":8}`)), errors.New("boom")))
+	assert.NotContains(t, contents.reasons, int64(8), "only embedding tasks mark content")
+
+	err = svc.RecordEmbeddingFailure(ctx, asynq.NewTask("embedding:generate_batch", []byte(`{"content_ids":[9,10]}`)), errors.New("rate limited"))
+	require.NoError(t, err)
+	assert.Equal(t, "rate limited", contents.reasons[9])
+	assert.Equal(t, "rate limited", contents.reasons[10])
+
+	assert.Error(t, svc.RecordEmbeddingFailure(ctx, asynq.NewTask("embedding:generate", []byte(`{}`)), errors.New("boom")))
+}
+
+func TestEmbeddingError(t *testing.T) {
+	failed := &models.Content{Metadata: []byte(`{"author":"ann","embedding_error":{"error":"input too large","failed_at":"2026-10-18T10:00:00Z"}}`)}
+	assert.Equal(t, "input too large", EmbeddingError(failed))
+	assert.Empty(t, EmbeddingError(&models.Content{Metadata: []byte(`{"author":"ann"}`)}))
+	assert.Empty(t, EmbeddingError(&models.Content{}))
+}
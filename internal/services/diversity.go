@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// diversityOverfetchFactor multiplies the limit when diversifying, so near
+// duplicates of the top results can be replaced by other relevant content.
+const diversityOverfetchFactor = 3
+
+// validateDiversity checks a SemanticSearchParams.Diversity value.
+func validateDiversity(diversity float64) error {
+	if diversity < 0 || diversity > 1 {
+		return fmt.Errorf("diversity must be between 0 and 1, got %g", diversity)
+	}
+	return nil
+}
+
+// diversify reorders items by Maximal Marginal Relevance using the mean chunk
+// vector of each item. If the vectors cannot be fetched, items keep their order.
+func (s *SearchService) diversify(ctx context.Context, items []SearchResultItem, limit int, diversity float64) []SearchResultItem {
+	ids := make([]int64, 0, len(items))
+	for _, item := range items {
+		if item.Content != nil {
+			ids = append(ids, item.Content.ID)
+		}
+	}
+	vectors, err := s.vector.ContentVectors(ctx, ids)
+	if err != nil {
+		log.Printf("WARN: Failed to fetch content vectors for diversity, keeping result order: %v", err)
+		return items
+	}
+	return selectDiverse(items, vectors, limit, diversity)
+}
+
+// selectDiverse picks up to limit items by Maximal Marginal Relevance: each
+// step takes the item maximizing
+//
+//	(1-diversity)*relevance - diversity*(max cosine similarity to the items picked so far)
+//
+// Relevance is the item's similarity scaled to [0, 1] across items, so every
+// distance metric weighs the same against the cosine redundancy. Diversity 0
+// keeps the similarity order; items without a vector count as dissimilar to all.
+func selectDiverse(items []SearchResultItem, vectors map[int64]pgvector.Vector, limit int, diversity float64) []SearchResultItem {
+	if limit > len(items) {
+		limit = len(items)
+	}
+	relevance := make([]float64, len(items))
+	minSim, maxSim := math.Inf(1), math.Inf(-1)
+	for i, item := range items {
+		relevance[i] = item.Similarity()
+		minSim = math.Min(minSim, relevance[i])
+		maxSim = math.Max(maxSim, relevance[i])
+	}
+	for i := range relevance {
+		if maxSim > minSim {
+			relevance[i] = (relevance[i] - minSim) / (maxSim - minSim)
+		} else {
+			relevance[i] = 1
+		}
+	}
+
+	vectorOf := func(i int) []float32 {
+		if items[i].Content == nil {
+			return nil
+		}
+		return vectors[items[i].Content.ID].Slice()
+	}
+
+	// redundancy[i] is the highest similarity of item i to a picked item.
+	redundancy := make([]float64, len(items))
+	picked := make([]bool, len(items))
+	selected := make([]SearchResultItem, 0, limit)
+	for len(selected) < limit {
+		best, bestScore := -1, math.Inf(-1)
+		for i := range items {
+			if picked[i] {
+				continue
+			}
+			score := (1-diversity)*relevance[i] - diversity*redundancy[i]
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		picked[best] = true
+		selected = append(selected, items[best])
+
+		bestVector := vectorOf(best)
+		for i := range items {
+			if !picked[i] {
+				redundancy[i] = math.Max(redundancy[i], cosineSimilarity(vectorOf(i), bestVector))
+			}
+		}
+	}
+	return selected
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, or 0 when
+// either is empty, zero, or their dimensions differ.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
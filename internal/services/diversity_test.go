@@ -0,0 +1,67 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+// diversityVectorStore returns fixed search results and content vectors.
+type diversityVectorStore struct {
+	explainVectorStore
+	vectors map[int64]pgvector.Vector
+}
+
+func (v diversityVectorStore) ContentVectors(ctx context.Context, contentIDs []int64) (map[int64]pgvector.Vector, error) {
+	return v.vectors, nil
+}
+
+func TestSemanticSearch_Diversity(t *testing.T) {
+	contents := &memContentStore{contents: map[int64]*models.Content{
+		1: {ID: 1, Title: "setup guide", Visibility: store.VisibilityShared},
+		2: {ID: 2, Title: "setup guide (copy)", Visibility: store.VisibilityShared},
+		3: {ID: 3, Title: "troubleshooting", Visibility: store.VisibilityShared},
+	}}
+	vs := diversityVectorStore{
+		explainVectorStore: explainVectorStore{results: []models.SearchResult{
+			{ContentID: 1, Distance: 0.1},
+			{ContentID: 2, Distance: 0.12},
+			{ContentID: 3, Distance: 0.4},
+		}},
+		vectors: map[int64]pgvector.Vector{
+			1: pgvector.NewVector([]float32{1, 0}),
+			2: pgvector.NewVector([]float32{0.99, 0.01}),
+			3: pgvector.NewVector([]float32{0, 1}),
+		},
+	}
+	svc := services.NewSearchService(contents, nil, vs, historyEmbeddingService{}, nopSearchHistory{})
+	svc.SetRecordHistory(false)
+	ctx := context.Background()
+
+	ids := func(results []services.SearchResultItem) []int64 {
+		out := make([]int64, len(results))
+		for i, r := range results {
+			out[i] = r.Content.ID
+		}
+		return out
+	}
+
+	results, err := svc.SemanticSearch(ctx, services.SemanticSearchParams{Query: "setup", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, ids(results), "without diversity the near duplicate ranks second")
+
+	results, err = svc.SemanticSearch(ctx, services.SemanticSearchParams{Query: "setup", Limit: 2, Diversity: 0.5})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 3}, ids(results), "diversity replaces the near duplicate")
+	assert.Equal(t, 0.4, results[1].Score, "scores are kept")
+
+	_, err = svc.SemanticSearch(ctx, services.SemanticSearchParams{Query: "setup", Diversity: 1.5})
+	assert.Error(t, err)
+}
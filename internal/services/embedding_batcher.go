@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"mimir/internal/store"
+)
+
+// DefaultBatchEmbeddingSize is the number of content items per batch embedding
+// job when embedding.batch_job.size is not set.
+const DefaultBatchEmbeddingSize = 100
+
+// EmbeddingBatcher collects the IDs of content added with
+// AddContentParams.DeferEmbedding and enqueues their embedding. Once minItems
+// items have been collected they are embedded by batch embedding jobs of up to
+// size items; with fewer in total, each item gets its own embedding job as if
+// it had not been deferred. Call Flush when done adding. An EmbeddingBatcher is
+// not safe for concurrent use.
+type EmbeddingBatcher struct {
+	jobs     store.JobClient
+	minItems int
+	size     int
+	pending  []int64
+	batched  bool // A batch job was enqueued, so the remainder is batched too
+}
+
+// NewEmbeddingBatcher creates an EmbeddingBatcher enqueueing through the
+// service's job client, using the embedding.batch_job configuration.
+func (cs *ContentService) NewEmbeddingBatcher() *EmbeddingBatcher {
+	b := &EmbeddingBatcher{jobs: cs.jobs, size: DefaultBatchEmbeddingSize}
+	if cfg := cs.deps.Config; cfg != nil {
+		b.minItems = cfg.Embedding.BatchJob.MinItems
+		if cfg.Embedding.BatchJob.Size > 0 {
+			b.size = cfg.Embedding.BatchJob.Size
+		}
+	}
+	if b.minItems > b.size {
+		b.minItems = b.size
+	}
+	return b
+}
+
+// Add collects the content ID, enqueueing a batch job once size IDs are pending
+// and batch jobs are enabled.
+func (b *EmbeddingBatcher) Add(ctx context.Context, contentID int64) error {
+	b.pending = append(b.pending, contentID)
+	if b.minItems > 0 && len(b.pending) >= b.size {
+		return b.enqueueBatch(ctx)
+	}
+	return nil
+}
+
+// Flush enqueues the embedding of all pending IDs.
+func (b *EmbeddingBatcher) Flush(ctx context.Context) error {
+	if len(b.pending) == 0 {
+		return nil
+	}
+	if b.batched || (b.minItems > 0 && len(b.pending) >= b.minItems) {
+		return b.enqueueBatch(ctx)
+	}
+	return b.enqueueEach(ctx)
+}
+
+// enqueueBatch enqueues one batch embedding job for the pending IDs.
+func (b *EmbeddingBatcher) enqueueBatch(ctx context.Context) error {
+	ids := b.pending
+	b.pending = nil
+	b.batched = true
+	if b.jobs == nil {
+		log.Printf("WARN: Job client is nil, skipping batch embedding job for %d content items", len(ids))
+		return nil
+	}
+	if err := b.jobs.EnqueueBatchEmbeddingJob(ctx, ids); err != nil {
+		return err
+	}
+	log.Printf("Enqueued batch embedding job for %d content items", len(ids))
+	return nil
+}
+
+// enqueueEach enqueues an embedding job per pending ID.
+func (b *EmbeddingBatcher) enqueueEach(ctx context.Context) error {
+	ids := b.pending
+	b.pending = nil
+	if b.jobs == nil {
+		log.Printf("WARN: Job client is nil, skipping embedding jobs for %d content items", len(ids))
+		return nil
+	}
+	var errs []error
+	for _, id := range ids {
+		if err := b.jobs.EnqueueEmbeddingJob(ctx, id); err != nil {
+			errs = append(errs, fmt.Errorf("content %d: %w", id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// EmbeddingExportFormatJSONL is the only supported embedding export format.
+const EmbeddingExportFormatJSONL = "jsonl"
+
+// EmbeddingExportRecord is one line of an embedding export. It carries
+// everything needed to load the embedding into another vector database without
+// re-embedding the source text.
+type EmbeddingExportRecord struct {
+	ID         uuid.UUID       `json:"id"`
+	ContentID  int64           `json:"content_id"`
+	ChunkIndex *int            `json:"chunk_index"` // From metadata; null for embeddings stored without one
+	ChunkText  string          `json:"chunk_text"`
+	Vector     []float32       `json:"vector"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// ExportEmbeddingsJSONL writes every embedding in vs to w as one JSON object
+// per line and returns the number written.
+func ExportEmbeddingsJSONL(ctx context.Context, vs store.VectorStore, w io.Writer) (int, error) {
+	enc := json.NewEncoder(w)
+	count := 0
+	err := vs.StreamEmbeddings(ctx, func(entry *models.EmbeddingEntry) error {
+		if err := enc.Encode(newEmbeddingExportRecord(entry)); err != nil {
+			return fmt.Errorf("write embedding %s: %w", entry.ID, err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("export embeddings: %w", err)
+	}
+	return count, nil
+}
+
+func newEmbeddingExportRecord(entry *models.EmbeddingEntry) EmbeddingExportRecord {
+	rec := EmbeddingExportRecord{
+		ID:        entry.ID,
+		ContentID: entry.ContentID,
+		ChunkText: entry.ChunkText,
+		Vector:    entry.Vector.Slice(),
+		CreatedAt: entry.CreatedAt,
+	}
+	if len(entry.Metadata) > 0 {
+		rec.Metadata = entry.Metadata
+		rec.ChunkIndex = chunkIndexOf(entry.Metadata)
+	}
+	return rec
+}
+
+// chunkIndexOf returns the chunk_index recorded in embedding metadata, or nil
+// when there is none.
+func chunkIndexOf(metadata json.RawMessage) *int {
+	var meta struct {
+		ChunkIndex *int `json:"chunk_index"`
+	}
+	if err := json.Unmarshal(metadata, &meta); err != nil {
+		return nil
+	}
+	return meta.ChunkIndex
+}
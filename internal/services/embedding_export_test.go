@@ -0,0 +1,60 @@
+package services_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+type streamingVectorStore struct {
+	store.VectorStore
+	entries []*models.EmbeddingEntry
+}
+
+func (s *streamingVectorStore) StreamEmbeddings(ctx context.Context, fn func(entry *models.EmbeddingEntry) error) error {
+	for _, e := range s.entries {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestExportEmbeddingsJSONL(t *testing.T) {
+	vs := &streamingVectorStore{entries: []*models.EmbeddingEntry{
+		{ID: uuid.New(), ContentID: 7, ChunkText: "first", Vector: pgvector.NewVector([]float32{0.5, -1}), Metadata: json.RawMessage(`{"chunk_index": 0, "tag_ids": [2]}`)},
+		{ID: uuid.New(), ContentID: 7, ChunkText: "second", Vector: pgvector.NewVector([]float32{1, 0})},
+	}}
+
+	var buf bytes.Buffer
+	n, err := services.ExportEmbeddingsJSONL(context.Background(), vs, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	var records []services.EmbeddingExportRecord
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var rec services.EmbeddingExportRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+		records = append(records, rec)
+	}
+	require.Len(t, records, 2)
+	assert.Equal(t, int64(7), records[0].ContentID)
+	require.NotNil(t, records[0].ChunkIndex)
+	assert.Equal(t, 0, *records[0].ChunkIndex)
+	assert.Equal(t, []float32{0.5, -1}, records[0].Vector)
+	assert.JSONEq(t, `{"chunk_index": 0, "tag_ids": [2]}`, string(records[0].Metadata))
+	assert.Nil(t, records[1].ChunkIndex)
+	assert.Equal(t, "second", records[1].ChunkText)
+}
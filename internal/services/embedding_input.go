@@ -0,0 +1,119 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"text/template"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// DefaultEmbeddingInputTemplate embeds the body only.
+const DefaultEmbeddingInputTemplate = "{{.Body}}"
+
+// EmbeddingInputData is the data available to embedding.input_template.
+type EmbeddingInputData struct {
+	Title  string
+	Body   string
+	Source string   // Source name; empty when the source cannot be found
+	Tags   []string // Tag names, sorted by name
+}
+
+// EmbeddingInputTemplate builds the text fed to chunking and embedding from a
+// content item's title, body, source and tags, so short items such as bookmarks
+// can be embedded with their context.
+type EmbeddingInputTemplate struct {
+	text    string
+	tmpl    *template.Template
+	version string
+}
+
+// NewEmbeddingInputTemplate parses text as a Go text/template; empty text uses
+// DefaultEmbeddingInputTemplate. The template is executed once against sample
+// data so references to unknown fields fail here rather than in the worker.
+func NewEmbeddingInputTemplate(text string) (*EmbeddingInputTemplate, error) {
+	if text == "" {
+		text = DefaultEmbeddingInputTemplate
+	}
+	tmpl, err := template.New("embedding_input").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse embedding input template: %w", err)
+	}
+	t := &EmbeddingInputTemplate{text: text, tmpl: tmpl, version: EmbeddingInputVersion(text)}
+	sample := EmbeddingInputData{Title: "title", Body: "body", Source: "source", Tags: []string{"tag"}}
+	if _, err := t.Render(sample); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// EmbeddingInputVersion identifies an input template; embeddings built from
+// templates with different versions are not comparable.
+func EmbeddingInputVersion(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:6])
+}
+
+// Version returns the template's version, recorded with the embeddings built from it.
+func (t *EmbeddingInputTemplate) Version() string {
+	return t.version
+}
+
+// IsDefault reports whether the template embeds the body only.
+func (t *EmbeddingInputTemplate) IsDefault() bool {
+	return t.text == DefaultEmbeddingInputTemplate
+}
+
+// inputVersionOf returns the input template version of the content's embeddings.
+func inputVersionOf(content *models.Content) string {
+	if content.InputVersion == nil {
+		return EmbeddingInputVersion(DefaultEmbeddingInputTemplate)
+	}
+	return *content.InputVersion
+}
+
+// Render executes the template against data.
+func (t *EmbeddingInputTemplate) Render(data EmbeddingInputData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("execute embedding input template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Apply returns a copy of content whose Body is the rendered embedding input,
+// ready for chunking.ContentAwareChunk. The default template returns content
+// unchanged without looking up its source or tags.
+func (t *EmbeddingInputTemplate) Apply(ctx context.Context, sources store.SourceStore, tags store.TagStore, content *models.Content) (*models.Content, error) {
+	if t.IsDefault() {
+		return content, nil
+	}
+
+	data := EmbeddingInputData{Title: content.Title, Body: content.Body}
+	if sources != nil {
+		if source, err := sources.GetSource(ctx, content.SourceID); err == nil {
+			data.Source = source.Name
+		}
+	}
+	if tags != nil {
+		contentTags, err := tags.GetContentTags(ctx, content.ID)
+		if err != nil {
+			return nil, fmt.Errorf("get tags for content %d: %w", content.ID, err)
+		}
+		for _, tag := range contentTags {
+			data.Tags = append(data.Tags, tag.Name)
+		}
+	}
+
+	text, err := t.Render(data)
+	if err != nil {
+		return nil, fmt.Errorf("content %d: %w", content.ID, err)
+	}
+	input := *content
+	input.Body = text
+	return &input, nil
+}
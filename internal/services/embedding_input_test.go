@@ -0,0 +1,52 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+type inputSourceStore struct{ store.SourceStore }
+
+func (inputSourceStore) GetSource(ctx context.Context, id int64) (*models.Source, error) {
+	return &models.Source{ID: id, Name: "bookmarks"}, nil
+}
+
+type inputTagStore struct{ store.TagStore }
+
+func (inputTagStore) GetContentTags(ctx context.Context, contentID int64) ([]*models.Tag, error) {
+	return []*models.Tag{{ID: 1, Name: "go"}, {ID: 2, Name: "testing"}}, nil
+}
+
+func TestEmbeddingInputTemplate_Apply(t *testing.T) {
+	content := &models.Content{ID: 7, SourceID: 3, Title: "Table tests", Body: "Use t.Run."}
+
+	def, err := services.NewEmbeddingInputTemplate("")
+	require.NoError(t, err)
+	got, err := def.Apply(context.Background(), inputSourceStore{}, inputTagStore{}, content)
+	require.NoError(t, err)
+	assert.Same(t, content, got, "the default template embeds the body unchanged")
+
+	tmpl, err := services.NewEmbeddingInputTemplate("{{.Title}} ({{.Source}}){{range .Tags}} #{{.}}{{end}}\n\n{{.Body}}")
+	require.NoError(t, err)
+	got, err = tmpl.Apply(context.Background(), inputSourceStore{}, inputTagStore{}, content)
+	require.NoError(t, err)
+	assert.Equal(t, "Table tests (bookmarks) #go #testing\n\nUse t.Run.", got.Body)
+	assert.Equal(t, "Use t.Run.", content.Body, "the stored content is not modified")
+	assert.NotEqual(t, def.Version(), tmpl.Version())
+}
+
+func TestEmbeddingInputTemplate_RejectsUnknownFields(t *testing.T) {
+	_, err := services.NewEmbeddingInputTemplate("{{.Summary}}")
+	assert.Error(t, err)
+	_, err = services.NewEmbeddingInputTemplate("{{.Title")
+	assert.Error(t, err)
+	_, err = services.NewEmbeddingInputTemplate(`{{join .Tags ", "}}`)
+	assert.Error(t, err, "only built-in template functions are available")
+}
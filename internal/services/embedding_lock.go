@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hibiken/asynq"
+	"mimir/internal/store"
+	"mimir/internal/tasks"
+)
+
+// EmbeddingJobGuard runs at most one job that writes chunk embeddings for a
+// given content item at a time, across all workers. Without it, a re-embed
+// enqueued while an earlier embedding job is still running lets both store
+// chunks, duplicating them.
+type EmbeddingJobGuard struct {
+	locker   store.ContentLocker
+	contents store.ContentStore
+}
+
+// NewEmbeddingJobGuard creates an EmbeddingJobGuard.
+func NewEmbeddingJobGuard(locker store.ContentLocker, contents store.ContentStore) *EmbeddingJobGuard {
+	return &EmbeddingJobGuard{locker: locker, contents: contents}
+}
+
+// Middleware holds the content's lock while an embedding or append embedding
+// task runs; tasks for the same content wait for each other. An embedding task
+// that gets the lock after another job already embedded the current body
+// returns without doing the work again. Reindex tasks always run, since they
+// re-embed current content on purpose. Other task types pass through.
+func (g *EmbeddingJobGuard) Middleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+		if t.Type() != tasks.TypeEmbeddingJob && t.Type() != tasks.TypeEmbeddingAppendJob {
+			return next.ProcessTask(ctx, t)
+		}
+		var payload struct {
+			ContentID    int64 `json:"content_id"`
+			ReindexRunID int64 `json:"reindex_run_id"`
+		}
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil || payload.ContentID == 0 {
+			return next.ProcessTask(ctx, t) // The handler reports the bad payload
+		}
+
+		unlock, err := g.locker.LockContent(ctx, payload.ContentID)
+		if err != nil {
+			return fmt.Errorf("lock content %d for embedding: %w", payload.ContentID, err)
+		}
+		defer unlock()
+
+		if t.Type() == tasks.TypeEmbeddingJob && payload.ReindexRunID == 0 {
+			done, err := g.alreadyEmbedded(ctx, payload.ContentID)
+			if err != nil {
+				return err
+			}
+			if done {
+				log.Printf("INFO: Content %d was embedded by another job meanwhile, skipping embedding job", payload.ContentID)
+				return nil
+			}
+		}
+		return next.ProcessTask(ctx, t)
+	})
+}
+
+// alreadyEmbedded reports whether the content's embeddings cover its current body.
+func (g *EmbeddingJobGuard) alreadyEmbedded(ctx context.Context, contentID int64) (bool, error) {
+	content, err := g.contents.GetContent(ctx, contentID)
+	if err != nil {
+		return false, fmt.Errorf("get content %d: %w", contentID, err)
+	}
+	return content.IsEmbedded && content.EmbeddedHash != nil && *content.EmbeddedHash == content.ContentHash, nil
+}
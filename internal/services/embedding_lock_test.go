@@ -0,0 +1,98 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/tasks"
+)
+
+// memoryLocker is an in-process store.ContentLocker.
+type memoryLocker struct {
+	mu    sync.Mutex
+	locks map[int64]*sync.Mutex
+}
+
+func (l *memoryLocker) LockContent(ctx context.Context, contentID int64) (func(), error) {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[int64]*sync.Mutex)
+	}
+	m, ok := l.locks[contentID]
+	if !ok {
+		m = &sync.Mutex{}
+		l.locks[contentID] = m
+	}
+	l.mu.Unlock()
+	m.Lock()
+	return m.Unlock, nil
+}
+
+func embeddingTask(t *testing.T, payload map[string]interface{}) *asynq.Task {
+	t.Helper()
+	b, err := json.Marshal(payload)
+	require.NoError(t, err)
+	return asynq.NewTask(tasks.TypeEmbeddingJob, b)
+}
+
+func TestEmbeddingJobGuard_ConcurrentJobsEmbedOnce(t *testing.T) {
+	contents := newMemContentStore(&models.Content{ID: 7, ContentHash: "h1"})
+	guard := services.NewEmbeddingJobGuard(&memoryLocker{}, contents)
+
+	var running, maxRunning, runs int32
+	handler := guard.Middleware(asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		atomic.AddInt32(&runs, 1)
+		time.Sleep(20 * time.Millisecond) // Writing chunks
+		return contents.UpdateContentEmbeddingStatus(ctx, 7, uuid.New(), true)
+	}))
+
+	task := embeddingTask(t, map[string]interface{}{"content_id": 7})
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, handler.ProcessTask(context.Background(), task))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), maxRunning, "jobs for the same content must not overlap")
+	assert.Equal(t, int32(1), runs, "the second job sees the content embedded and exits")
+
+	// A reindex job re-embeds current content on purpose.
+	reindex := embeddingTask(t, map[string]interface{}{"content_id": 7, "reindex_run_id": 3})
+	require.NoError(t, handler.ProcessTask(context.Background(), reindex))
+	assert.Equal(t, int32(2), runs)
+}
+
+func TestEmbeddingJobGuard_OtherTasksPassThrough(t *testing.T) {
+	guard := services.NewEmbeddingJobGuard(nil, nil) // Neither is used for other task types
+	ran := false
+	handler := guard.Middleware(asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+		ran = true
+		return nil
+	}))
+
+	require.NoError(t, handler.ProcessTask(context.Background(), asynq.NewTask(tasks.TypeSummarizationJob, []byte(`{"ContentID":7}`))))
+	assert.True(t, ran)
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// BuildEmbeddingMetadata merges a chunk's metadata with the content's source ID and
+// tag IDs, so SimilaritySearch can filter on them without joining the primary DB.
+//
+// The denormalized fields are a snapshot taken at embed time: tag changes are not
+// visible to vector filters until the content is re-embedded or its embedding
+// metadata is updated.
+func BuildEmbeddingMetadata(chunkMeta map[string]interface{}, sourceID int64, tagIDs []int64) (json.RawMessage, error) {
+	meta := make(map[string]interface{}, len(chunkMeta)+2)
+	for k, v := range chunkMeta {
+		meta[k] = v
+	}
+	if tagIDs == nil {
+		tagIDs = []int64{} // Keep the key present so containment filters behave consistently
+	}
+	meta[store.FilterSourceID] = sourceID
+	meta[store.FilterTagIDs] = tagIDs
+
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("marshal embedding metadata: %w", err)
+	}
+	return raw, nil
+}
+
+// ContentEmbeddingMetadata builds embedding metadata for a chunk of content,
+// looking up the content's current tags.
+func ContentEmbeddingMetadata(ctx context.Context, tags store.TagStore, content *models.Content, chunkMeta map[string]interface{}) (json.RawMessage, error) {
+	contentTags, err := tags.GetContentTags(ctx, content.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get tags for content %d: %w", content.ID, err)
+	}
+	tagIDs := make([]int64, len(contentTags))
+	for i, t := range contentTags {
+		tagIDs[i] = t.ID
+	}
+	return BuildEmbeddingMetadata(chunkMeta, content.SourceID, tagIDs)
+}
+
+// EmbeddingMetadataSyncer rewrites the denormalized source and tag metadata on a
+// content item's embeddings, so tag changes reach vector filters without re-embedding.
+type EmbeddingMetadataSyncer struct {
+	contents store.ContentStore
+	tags     store.TagStore
+	vector   store.VectorStore
+}
+
+func NewEmbeddingMetadataSyncer(contents store.ContentStore, tags store.TagStore, vector store.VectorStore) *EmbeddingMetadataSyncer {
+	return &EmbeddingMetadataSyncer{contents: contents, tags: tags, vector: vector}
+}
+
+// SyncContent updates all embedding rows of the content with its current source and tags.
+func (s *EmbeddingMetadataSyncer) SyncContent(ctx context.Context, contentID int64) error {
+	content, err := s.contents.GetContent(ctx, contentID)
+	if err != nil {
+		return fmt.Errorf("get content %d: %w", contentID, err)
+	}
+	meta, err := ContentEmbeddingMetadata(ctx, s.tags, content, nil)
+	if err != nil {
+		return err
+	}
+	return s.vector.UpdateEmbeddingMetadataByContentID(ctx, contentID, meta)
+}
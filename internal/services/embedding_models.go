@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"mimir/internal/config"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Built-in embedding model dimensions, used when a model is not declared in
+// EmbeddingModelOptions.Models.
+var (
+	openAIEmbeddingDimensions = map[string]int{
+		"text-embedding-ada-002": 1536,
+		"text-embedding-3-small": 1536,
+		"text-embedding-3-large": 3072,
+	}
+	geminiEmbeddingDimensions = map[string]int{
+		"models/embedding-001": 768,
+	}
+	// openAIShortenableModels accept the dimensions request parameter, returning
+	// vectors of any size up to their native dimension.
+	openAIShortenableModels = map[string]bool{
+		"text-embedding-3-small": true,
+		"text-embedding-3-large": true,
+	}
+)
+
+// ErrUnsupportedDimensions is returned when embedding.dimensions is set for a
+// model that cannot shorten its output, or exceeds the model's native dimension.
+var ErrUnsupportedDimensions = errors.New("unsupported embedding dimensions")
+
+// EmbeddingModelOptions controls how provider constructors resolve a model's dimension and pricing.
+type EmbeddingModelOptions struct {
+	Models []config.EmbeddingModelConfig // Configured models; take precedence over the built-in tables
+	Strict bool                          // Fail on unknown models instead of assuming a default dimension
+	// Dimensions requests shortened vectors from models that support it; 0 keeps the native dimension.
+	Dimensions int
+}
+
+// lookup returns the configured entry for modelID, if any.
+func (o EmbeddingModelOptions) lookup(modelID string) (config.EmbeddingModelConfig, bool) {
+	for _, m := range o.Models {
+		if m.Name == modelID {
+			return m, true
+		}
+	}
+	return config.EmbeddingModelConfig{}, false
+}
+
+// resolveEmbeddingDimension looks up modelID in the configured models, then
+// in the provider's built-in table. Unknown models are an error in strict mode;
+// otherwise they fall back to fallbackDim with a warning.
+func resolveEmbeddingDimension(provider, modelID string, builtin map[string]int, opts EmbeddingModelOptions, fallbackDim int) (int, error) {
+	if m, ok := opts.lookup(modelID); ok && m.Dimension > 0 {
+		return m.Dimension, nil
+	}
+	if dim, ok := builtin[modelID]; ok {
+		return dim, nil
+	}
+	if opts.Strict {
+		return 0, fmt.Errorf("unknown %s embedding model '%s': declare it in embedding.models or disable embedding.strict_model", provider, modelID)
+	}
+	log.Warnf("Unknown %s embedding model '%s', defaulting dimension to %d. Accuracy may be affected.", provider, modelID, fallbackDim)
+	return fallbackDim, nil
+}
+
+// resolveOutputDimension returns the vector size a model produces when requested
+// dimensions are applied to its native dimension nativeDim.
+func resolveOutputDimension(modelID string, shortenable map[string]bool, nativeDim, requested int) (int, error) {
+	if requested == 0 {
+		return nativeDim, nil
+	}
+	if !shortenable[modelID] {
+		return 0, fmt.Errorf("%w: model '%s' does not support embedding.dimensions", ErrUnsupportedDimensions, modelID)
+	}
+	if requested < 0 || requested > nativeDim {
+		return 0, fmt.Errorf("%w: embedding.dimensions for model '%s' must be between 1 and %d, got %d", ErrUnsupportedDimensions, modelID, nativeDim, requested)
+	}
+	return requested, nil
+}
+
+// mergeModelPricing returns a copy of pricing with entries for every configured
+// model that declares a price, so embedding.models is the single place to set them.
+func mergeModelPricing(pricing map[string]config.PricingInfo, opts EmbeddingModelOptions) map[string]config.PricingInfo {
+	merged := make(map[string]config.PricingInfo, len(pricing)+len(opts.Models))
+	for name, info := range pricing {
+		merged[name] = info
+	}
+	for _, m := range opts.Models {
+		if m.InputPerToken > 0 || m.OutputPerToken > 0 {
+			info := merged[m.Name] // Batch rates from the pricing section apply unless the model sets its own
+			info.InputPerToken, info.OutputPerToken = m.InputPerToken, m.OutputPerToken
+			if m.BatchInputPerToken > 0 {
+				info.BatchInputPerToken = m.BatchInputPerToken
+			}
+			if m.BatchOutputPerToken > 0 {
+				info.BatchOutputPerToken = m.BatchOutputPerToken
+			}
+			merged[m.Name] = info
+		}
+	}
+	return merged
+}
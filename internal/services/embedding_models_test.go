@@ -0,0 +1,26 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/services"
+)
+
+func TestNewOpenAIProvider_Dimensions(t *testing.T) {
+	p, err := services.NewOpenAIProvider("test-key", "text-embedding-3-large", nil, nil, services.EmbeddingModelOptions{Dimensions: 256})
+	require.NoError(t, err)
+	assert.Equal(t, 256, p.Dimension())
+
+	p, err = services.NewOpenAIProvider("test-key", "text-embedding-3-small", nil, nil, services.EmbeddingModelOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1536, p.Dimension(), "0 keeps the native dimension")
+
+	_, err = services.NewOpenAIProvider("test-key", "text-embedding-ada-002", nil, nil, services.EmbeddingModelOptions{Dimensions: 512})
+	assert.ErrorIs(t, err, services.ErrUnsupportedDimensions)
+
+	_, err = services.NewOpenAIProvider("test-key", "text-embedding-3-small", nil, nil, services.EmbeddingModelOptions{Dimensions: 2048})
+	assert.ErrorIs(t, err, services.ErrUnsupportedDimensions)
+}
@@ -2,18 +2,28 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
+	"net/http"
 	// "sync" // Removed as struct definition moved to types.go
+	"syscall"
 	"time"
 
 	"github.com/pgvector/pgvector-go"
+	"github.com/sashabaranov/go-openai"
+	"google.golang.org/api/googleapi"
 	// "mimir/internal/store" // Removed unused import
 )
 
 // --- Fallback Embedding Service Methods ---
 // The FallbackEmbeddingService struct definition is in types.go
 
+// ErrProvidersUnavailable is returned when every provider's circuit breaker is open.
+var ErrProvidersUnavailable = errors.New("all embedding providers are unavailable (circuit breakers open)")
+
 // NewFallbackEmbeddingService creates a new fallback service.
 // Note: The struct definition is in types.go, this is the constructor.
 func NewFallbackEmbeddingService(providers []EmbeddingProvider, strategy RetryStrategy) (*FallbackEmbeddingService, error) {
@@ -36,12 +46,29 @@ func NewFallbackEmbeddingService(providers []EmbeddingProvider, strategy RetrySt
 	}
 
 	return &FallbackEmbeddingService{
-		Providers:      providers,
-		ActiveProvider: 0, // Start with the first provider
-		RetryStrategy:  strategy,
+		Providers:     providers,
+		RetryStrategy: strategy,
+		breakers:      newBreakers(len(providers), 0, 0),
 	}, nil
 }
 
+func newBreakers(n, threshold int, cooldown time.Duration) []*CircuitBreaker {
+	breakers := make([]*CircuitBreaker, n)
+	for i := range breakers {
+		breakers[i] = NewCircuitBreaker(threshold, cooldown)
+	}
+	return breakers
+}
+
+// SetCircuitBreaker replaces each provider's circuit breaker with a closed one
+// that opens after threshold consecutive failures and probes again after
+// cooldown. Zero values use the defaults.
+func (s *FallbackEmbeddingService) SetCircuitBreaker(threshold int, cooldown time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.breakers = newBreakers(len(s.Providers), threshold, cooldown)
+}
+
 // Dimension returns the dimension of the currently active provider.
 // Assumes all providers have the same dimension, enforced by constructor.
 func (s *FallbackEmbeddingService) Dimension() int {
@@ -51,174 +78,165 @@ func (s *FallbackEmbeddingService) Dimension() int {
 		log.Println("WARN: FallbackEmbeddingService has no providers, returning dimension 0")
 		return 0
 	}
-	return s.Providers[s.ActiveProvider].Dimension()
+	return s.Providers[s.activeIndexLocked()].Dimension()
 }
 
-// GenerateEmbedding tries providers with retries until one succeeds or all fail.
-func (s *FallbackEmbeddingService) GenerateEmbedding(ctx context.Context, text string) (pgvector.Vector, error) {
-	s.mu.RLock()
-	initialProviderIndex := s.ActiveProvider
-	numProviders := len(s.Providers)
-	if numProviders == 0 {
-		s.mu.RUnlock()
-		return pgvector.Vector{}, fmt.Errorf("no embedding providers configured")
+// activeIndexLocked returns the index of the first provider whose circuit
+// breaker is not open, or 0 when all are. s.mu must be held.
+func (s *FallbackEmbeddingService) activeIndexLocked() int {
+	for i := range s.Providers {
+		if s.breakerStateLocked(i) != BreakerOpen {
+			return i
+		}
 	}
-	s.mu.RUnlock()
-
-	var lastErr error
-	attempt := 0 // Track attempts across providers for the retry strategy
+	return 0
+}
 
-	for { // Loop indefinitely until success, context cancellation, or exhaustion
-		s.mu.RLock()
-		currentProviderIndex := s.ActiveProvider
-		provider := s.Providers[currentProviderIndex]
-		s.mu.RUnlock()
+// breakerStateLocked returns the state of provider i's breaker. s.mu must be held.
+func (s *FallbackEmbeddingService) breakerStateLocked(i int) BreakerState {
+	if i >= len(s.breakers) {
+		return BreakerClosed
+	}
+	return s.breakers[i].State()
+}
 
-		log.Printf("Attempt %d: Trying provider %s (%s)", attempt+1, provider.Name(), provider.ModelName())
-		vec, err := provider.GenerateEmbedding(ctx, text)
+// tryProviders runs call against each provider in order, retrying each per
+// the RetryStrategy, until one succeeds or all have failed. Providers whose
+// circuit breaker is open are skipped. Each provider's breaker is independent
+// and every call walks the providers itself, so concurrent calls cannot fail
+// early by switching a shared active provider under each other. what names the
+// operation in logs and errors.
+func (s *FallbackEmbeddingService) tryProviders(ctx context.Context, what string, call func(EmbeddingProvider) error) error {
+	s.mu.RLock()
+	providers, breakers := s.Providers, s.breakers
+	s.mu.RUnlock()
+	if len(providers) == 0 {
+		return fmt.Errorf("no embedding providers configured")
+	}
 
-		// Check context cancellation immediately after the potentially long call
-		if ctx.Err() != nil {
-			log.Printf("Context cancelled after attempt with provider %s", provider.Name())
-			return pgvector.Vector{}, fmt.Errorf("context cancelled during embedding generation: %w", ctx.Err())
+	var lastErr error
+	for i, provider := range providers {
+		breaker := breakers[i]
+		if !breaker.Allow() {
+			log.Printf("Skipping provider %s for %s: circuit breaker is %s", provider.Name(), what, breaker.State())
+			continue
 		}
+		for attempt := 0; ; attempt++ {
+			log.Printf("Attempt %d: Trying provider %s (%s) for %s", attempt+1, provider.Name(), provider.ModelName(), what)
+			err := call(provider)
+
+			// Check context cancellation immediately after the potentially long call
+			if ctx.Err() != nil {
+				log.Printf("Context cancelled after attempt with provider %s", provider.Name())
+				return fmt.Errorf("context cancelled during %s: %w", what, ctx.Err())
+			}
+			if err == nil {
+				log.Printf("Provider %s succeeded for %s.", provider.Name(), what)
+				breaker.Success()
+				return nil
+			}
 
-		if err == nil {
-			// Success
-			log.Printf("Provider %s succeeded.", provider.Name())
-			return vec, nil
-		}
+			lastErr = fmt.Errorf("provider %s failed: %w", provider.Name(), err)
+			log.Printf("WARN: Provider %s failed %s: %v", provider.Name(), what, err)
+			if providerOutage(err) {
+				breaker.Failure()
+			} else {
+				breaker.Success() // The provider answered; the request itself was at fault
+			}
+			if breaker.State() == BreakerOpen {
+				log.Printf("WARN: Circuit breaker for provider %s is open; skipping it until the cooldown ends", provider.Name())
+				break
+			}
 
-		// Provider failed
-		lastErr = fmt.Errorf("provider %s failed: %w", provider.Name(), err)
-		log.Printf("WARN: Provider %s failed: %v", provider.Name(), err)
-
-		// Decide whether to retry or switch based on strategy
-		backoffMs := s.RetryStrategy.NextBackoff(attempt)
-		if backoffMs < 0 { // Strategy says stop retrying (at this attempt level)
-			log.Printf("Retry strategy indicates stopping retries for provider %s after attempt %d.", provider.Name(), attempt+1)
-
-			// Switch to the next provider
-			s.mu.Lock()
-			nextProviderIndex := (s.ActiveProvider + 1) % numProviders
-			// Check if we've cycled through all providers *since the last successful switch*
-			if nextProviderIndex == initialProviderIndex {
-				s.mu.Unlock()
-				log.Printf("ERROR: Cycled through all providers. Embedding failed.")
-				return pgvector.Vector{}, fmt.Errorf("all embedding providers failed after cycling through: last error: %w", lastErr)
+			backoffMs := s.RetryStrategy.NextBackoff(attempt)
+			if backoffMs < 0 { // Strategy says stop retrying this provider
+				log.Printf("Retry strategy indicates stopping retries for provider %s after attempt %d.", provider.Name(), attempt+1)
+				break
 			}
-			s.ActiveProvider = nextProviderIndex
-			log.Printf("Switching active provider to index %d: %s", nextProviderIndex, s.Providers[nextProviderIndex].Name())
-			initialProviderIndex = nextProviderIndex // Reset the cycle detection start point
-			s.mu.Unlock()
 
-			attempt = 0 // Reset attempt count for the new provider cycle? Or keep global? Resetting for now.
-			// Don't wait after switching, try the new provider immediately
-			continue
+			log.Printf("Waiting %dms before retrying %s with provider %s (attempt %d)", backoffMs, what, provider.Name(), attempt+1)
+			select {
+			case <-time.After(time.Duration(backoffMs) * time.Millisecond):
+			case <-ctx.Done():
+				log.Printf("Context cancelled while waiting to retry provider %s", provider.Name())
+				return fmt.Errorf("context cancelled while waiting to retry %s: %w", what, ctx.Err())
+			}
 		}
+	}
 
-		// Wait before retrying (with the same provider)
-		log.Printf("Waiting %dms before retrying with provider %s (attempt %d)", backoffMs, provider.Name(), attempt+1)
-		select {
-		case <-time.After(time.Duration(backoffMs) * time.Millisecond):
-			attempt++ // Increment attempt count only if we waited and are retrying
-			// Continue to the next attempt in the loop
-		case <-ctx.Done():
-			log.Printf("Context cancelled while waiting to retry provider %s", provider.Name())
-			return pgvector.Vector{}, fmt.Errorf("context cancelled while waiting to retry: %w", ctx.Err())
-		}
+	if lastErr == nil {
+		return fmt.Errorf("%s: %w", what, ErrProvidersUnavailable)
 	}
+	log.Printf("ERROR: All embedding providers failed %s.", what)
+	return fmt.Errorf("all embedding providers failed %s: last error: %w", what, lastErr)
 }
 
-// GenerateEmbeddings handles batch generation with fallback and retries.
-// It attempts to use the active provider's batch method directly.
-func (s *FallbackEmbeddingService) GenerateEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
-	s.mu.RLock()
-	initialProviderIndex := s.ActiveProvider
-	numProviders := len(s.Providers)
-	if numProviders == 0 {
-		s.mu.RUnlock()
-		return nil, fmt.Errorf("no embedding providers configured")
+// providerOutage reports whether err means the provider is unreachable or
+// overloaded: a transport failure, a 5xx response or a 429 rate limit. Only
+// these trip a provider's circuit breaker; errors the provider returns for the
+// request itself, such as a 400 for oversized input, do not.
+func providerOutage(err error) bool {
+	outageStatus := func(code int) bool {
+		return code >= http.StatusInternalServerError || code == http.StatusTooManyRequests
 	}
-	s.mu.RUnlock()
 
-	var lastErr error
-	attempt := 0 // Track attempts across providers for the retry strategy
-
-	for { // Loop indefinitely until success, context cancellation, or exhaustion
-		s.mu.RLock()
-		currentProviderIndex := s.ActiveProvider
-		provider := s.Providers[currentProviderIndex]
-		s.mu.RUnlock()
-
-		log.Printf("Attempt %d: Trying provider %s (%s) for batch size %d", attempt+1, provider.Name(), provider.ModelName(), len(texts))
-		// Directly call the provider's batch method
-		vecs, err := provider.GenerateEmbeddings(ctx, texts)
-
-		// Check context cancellation immediately after the potentially long call
-		if ctx.Err() != nil {
-			log.Printf("Context cancelled after attempt with provider %s", provider.Name())
-			return nil, fmt.Errorf("context cancelled during batch embedding generation: %w", ctx.Err())
-		}
-
-		if err == nil {
-			// Success
-			log.Printf("Provider %s succeeded for batch.", provider.Name())
-			// Ensure the correct number of vectors was returned
-			if len(vecs) != len(texts) {
-				// This indicates a provider implementation issue
-				log.Printf("ERROR: Provider %s returned %d vectors for %d texts", provider.Name(), len(vecs), len(texts))
-				lastErr = fmt.Errorf("provider %s returned mismatched vector count (%d != %d)", provider.Name(), len(vecs), len(texts))
-				// Treat this as a failure and try the next provider (or retry logic)
-			} else {
-				log.Printf("Successfully generated embeddings for batch of size %d using %s", len(texts), provider.Name())
-				return vecs, nil // Success!
-			}
-		}
-
-		// Provider failed or returned mismatched count
-		if err != nil { // Log original error if it exists
-			lastErr = fmt.Errorf("provider %s failed batch generation: %w", provider.Name(), err)
-			log.Printf("WARN: Provider %s failed batch generation: %v", provider.Name(), err)
-		} else { // Log mismatch error
-			log.Printf("WARN: Provider %s returned mismatched vector count (%d != %d)", provider.Name(), len(vecs), len(texts))
-		}
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return outageStatus(apiErr.HTTPStatusCode)
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) && reqErr.HTTPStatusCode > 0 {
+		return outageStatus(reqErr.HTTPStatusCode)
+	}
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		return outageStatus(googleErr.Code)
+	}
+	// Google API errors from gax, which genai returns, expose the HTTP status.
+	var httpErr interface{ HTTPCode() int }
+	if errors.As(err, &httpErr) && httpErr.HTTPCode() > 0 {
+		return outageStatus(httpErr.HTTPCode())
+	}
 
-		// Decide whether to retry or switch based on strategy
-		backoffMs := s.RetryStrategy.NextBackoff(attempt)
-		if backoffMs < 0 { // Strategy says stop retrying (at this attempt level)
-			log.Printf("Retry strategy indicates stopping retries for provider %s after attempt %d.", provider.Name(), attempt+1)
-
-			// Switch to the next provider
-			s.mu.Lock()
-			nextProviderIndex := (s.ActiveProvider + 1) % numProviders
-			// Check if we've cycled through all providers *since the last successful switch*
-			if nextProviderIndex == initialProviderIndex {
-				s.mu.Unlock()
-				log.Printf("ERROR: Cycled through all providers. Batch embedding failed.")
-				return nil, fmt.Errorf("all embedding providers failed batch generation after cycling through: last error: %w", lastErr)
-			}
-			s.ActiveProvider = nextProviderIndex
-			log.Printf("Switching active provider to index %d: %s", nextProviderIndex, s.Providers[nextProviderIndex].Name())
-			initialProviderIndex = nextProviderIndex // Reset the cycle detection start point
-			s.mu.Unlock()
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
 
-			attempt = 0 // Reset attempt count for the new provider cycle
-			// Don't wait after switching, try the new provider immediately
-			continue
-		}
+// GenerateEmbedding tries providers with retries until one succeeds or all fail.
+func (s *FallbackEmbeddingService) GenerateEmbedding(ctx context.Context, text string) (pgvector.Vector, error) {
+	var vec pgvector.Vector
+	err := s.tryProviders(ctx, "embedding generation", func(p EmbeddingProvider) error {
+		var err error
+		vec, err = p.GenerateEmbedding(ctx, text)
+		return err
+	})
+	if err != nil {
+		return pgvector.Vector{}, err
+	}
+	return vec, nil
+}
 
-		// Wait before retrying (with the same provider)
-		log.Printf("Waiting %dms before retrying batch with provider %s (attempt %d)", backoffMs, provider.Name(), attempt+1)
-		select {
-		case <-time.After(time.Duration(backoffMs) * time.Millisecond):
-			attempt++ // Increment attempt count only if we waited and are retrying
-			// Continue to the next attempt in the loop
-		case <-ctx.Done():
-			log.Printf("Context cancelled while waiting to retry batch with provider %s", provider.Name())
-			return nil, fmt.Errorf("context cancelled while waiting to retry batch: %w", ctx.Err())
+// GenerateEmbeddings handles batch generation with fallback and retries.
+// It uses each provider's batch method directly.
+func (s *FallbackEmbeddingService) GenerateEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	var vecs []pgvector.Vector
+	err := s.tryProviders(ctx, fmt.Sprintf("batch embedding generation (%d texts)", len(texts)), func(p EmbeddingProvider) error {
+		var err error
+		vecs, err = p.GenerateEmbeddings(ctx, texts)
+		if err == nil && len(vecs) != len(texts) {
+			// This indicates a provider implementation issue; treat it as a failure
+			return fmt.Errorf("returned mismatched vector count (%d != %d)", len(vecs), len(texts))
 		}
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return vecs, nil
 }
 
 // --- Helper ---
@@ -0,0 +1,144 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+// stubProvider returns a fixed vector, or err when set.
+type stubProvider struct {
+	name  string
+	err   error
+	calls atomic.Int64
+}
+
+func (p *stubProvider) Name() string                 { return p.name }
+func (p *stubProvider) ModelName() string            { return p.name + "-model" }
+func (p *stubProvider) Status() store.ProviderStatus { return store.ProviderStatusActive }
+func (p *stubProvider) Dimension() int               { return 2 }
+
+func (p *stubProvider) GenerateEmbedding(ctx context.Context, text string) (pgvector.Vector, error) {
+	p.calls.Add(1)
+	if p.err != nil {
+		return pgvector.Vector{}, p.err
+	}
+	return pgvector.NewVector([]float32{1, 0}), nil
+}
+
+func (p *stubProvider) GenerateEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	p.calls.Add(1)
+	if p.err != nil {
+		return nil, p.err
+	}
+	vecs := make([]pgvector.Vector, len(texts))
+	for i := range texts {
+		vecs[i] = pgvector.NewVector([]float32{1, 0})
+	}
+	return vecs, nil
+}
+
+func TestFallbackEmbeddingService_ConcurrentProviderFailure(t *testing.T) {
+	primary := &stubProvider{name: "primary", err: &openai.APIError{HTTPStatusCode: 503}}
+	secondary := &stubProvider{name: "secondary"}
+	svc, err := services.NewFallbackEmbeddingService(
+		[]services.EmbeddingProvider{primary, secondary},
+		&services.SimpleRetryStrategy{MaxAttempts: 0},
+	)
+	require.NoError(t, err)
+
+	const calls = 64
+	var wg sync.WaitGroup
+	errs := make(chan error, calls)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				_, err := svc.GenerateEmbedding(context.Background(), "text")
+				errs <- err
+			} else {
+				_, err := svc.GenerateEmbeddings(context.Background(), []string{"a", "b"})
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int64(calls), secondary.calls.Load())
+	assert.Equal(t, "secondary", svc.Name(), "a failed provider is skipped while it is down")
+
+	// Once primary is marked down, later calls go straight to secondary.
+	before := primary.calls.Load()
+	_, err = svc.GenerateEmbedding(context.Background(), "text")
+	require.NoError(t, err)
+	assert.Equal(t, before, primary.calls.Load())
+}
+
+func TestFallbackEmbeddingService_AllProvidersFail(t *testing.T) {
+	primary := &stubProvider{name: "primary", err: errors.New("down")}
+	secondary := &stubProvider{name: "secondary", err: errors.New("down")}
+	svc, err := services.NewFallbackEmbeddingService(
+		[]services.EmbeddingProvider{primary, secondary},
+		&services.SimpleRetryStrategy{MaxAttempts: 0},
+	)
+	require.NoError(t, err)
+
+	_, err = svc.GenerateEmbedding(context.Background(), "text")
+	assert.ErrorContains(t, err, "all embedding providers failed")
+	assert.Equal(t, int64(1), primary.calls.Load())
+	assert.Equal(t, int64(1), secondary.calls.Load())
+}
+
+func TestFallbackEmbeddingService_SkipsOpenBreaker(t *testing.T) {
+	primary := &stubProvider{name: "primary", err: &openai.APIError{HTTPStatusCode: 503}}
+	secondary := &stubProvider{name: "secondary", err: &openai.APIError{HTTPStatusCode: 429}}
+	svc, err := services.NewFallbackEmbeddingService(
+		[]services.EmbeddingProvider{primary, secondary},
+		&services.SimpleRetryStrategy{MaxAttempts: 0},
+	)
+	require.NoError(t, err)
+	svc.SetCircuitBreaker(1, time.Hour)
+
+	_, err = svc.GenerateEmbedding(context.Background(), "text")
+	assert.ErrorContains(t, err, "all embedding providers failed")
+
+	_, err = svc.GenerateEmbedding(context.Background(), "text")
+	assert.ErrorIs(t, err, services.ErrProvidersUnavailable)
+	assert.Equal(t, int64(1), primary.calls.Load(), "open providers are not called")
+	assert.Equal(t, store.ProviderStatusInactive, svc.Status())
+	for _, info := range svc.ProviderInfos() {
+		assert.Equal(t, services.BreakerOpen, info.Breaker)
+	}
+}
+
+func TestFallbackEmbeddingService_RequestErrorsDoNotTripBreaker(t *testing.T) {
+	primary := &stubProvider{name: "primary", err: &openai.APIError{HTTPStatusCode: 400}}
+	svc, err := services.NewFallbackEmbeddingService(
+		[]services.EmbeddingProvider{primary},
+		&services.SimpleRetryStrategy{MaxAttempts: 0},
+	)
+	require.NoError(t, err)
+	svc.SetCircuitBreaker(1, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		_, err = svc.GenerateEmbedding(context.Background(), "text")
+		assert.ErrorContains(t, err, "all embedding providers failed")
+	}
+	assert.Equal(t, int64(3), primary.calls.Load(), "a bad request says nothing about the provider's health")
+	assert.Equal(t, services.BreakerClosed, svc.ProviderInfos()[0].Breaker)
+}
@@ -5,6 +5,7 @@ import (
 	"errors" // Add errors import
 	"fmt"
 	"os"
+	"strings"
 
 	"mimir/internal/store" // ProviderStatus is defined here
 
@@ -23,8 +24,13 @@ type GeminiProvider struct {
 	// Add cost tracking dependencies if needed for completion
 }
 
-// NewGeminiProvider creates a new Gemini embedding provider.
-func NewGeminiProvider(apiKey, modelName string) (*GeminiProvider, error) {
+// DefaultGeminiCompletionModel is used for chat completion when rag.model is not set.
+const DefaultGeminiCompletionModel = "gemini-1.5-flash"
+
+// NewGeminiProvider creates a new Gemini embedding provider. A non-empty
+// completionModel also enables GenerateChatCompletion with that model.
+// Unknown embedding models fail in strict mode; see EmbeddingModelOptions.
+func NewGeminiProvider(apiKey, modelName, completionModel string, modelOpts EmbeddingModelOptions) (*GeminiProvider, error) {
 	if apiKey == "" {
 		apiKey = os.Getenv("GEMINI_API_KEY") // Fallback to env var
 	}
@@ -34,18 +40,10 @@ func NewGeminiProvider(apiKey, modelName string) (*GeminiProvider, error) {
 		// Or return error: return nil, fmt.Errorf("Gemini API key not provided")
 	}
 
-	// Determine dimension based on model name (add more models as needed)
-	var dim int
-	switch modelName {
-	case "models/embedding-001":
-		dim = 768
-	// Add other known Gemini embedding models and their dimensions
-	// case "models/text-embedding-004": // Example future model
-	// 	dim = 768
-	default:
-		log.Warnf("Unknown Gemini embedding model '%s', defaulting dimension to 768 (embedding-001). Accuracy may be affected.", modelName)
-		dim = 768
-		// Or return error: return nil, fmt.Errorf("unknown or unsupported Gemini embedding model: %s", modelName)
+	// Determine dimension based on model name (configured models take precedence)
+	dim, err := resolveEmbeddingDimension("Gemini", modelName, geminiEmbeddingDimensions, modelOpts, 768)
+	if err != nil {
+		return nil, err
 	}
 
 	ctx := context.Background() // Use background context for initialization
@@ -58,10 +56,10 @@ func NewGeminiProvider(apiKey, modelName string) (*GeminiProvider, error) {
 	log.Infof("Gemini provider initialized with model %s (dimension %d)", modelName, dim)
 
 	return &GeminiProvider{
-		client:         client,
-		embeddingModel: modelName,
-		// completionModel: // Set this if/when completion is fully supported
-		dim:    dim,
+		client:          client,
+		embeddingModel:  modelName,
+		completionModel: completionModel,
+		dim:             dim,
 	}, nil
 }
 
@@ -144,9 +142,10 @@ func (p *GeminiProvider) GenerateEmbeddings(ctx context.Context, texts []string)
 	return results, nil
 }
 
-// GenerateChatCompletion implements the CompletionService interface (basic implementation).
-// NOTE: This is a placeholder. A proper implementation would need model selection,
-// prompt formatting, and potentially cost tracking specific to completion.
+// GenerateChatCompletion implements the CompletionService interface with the
+// completion model. System messages become the model's system instruction; the
+// last message is sent as the prompt, after any earlier turns as chat history.
+// It returns the text of the first candidate.
 func (p *GeminiProvider) GenerateChatCompletion(ctx context.Context, messages []ChatMessage) (string, error) {
 	if p.client == nil {
 		return "", fmt.Errorf("Gemini provider is not initialized (missing API key)")
@@ -154,8 +153,69 @@ func (p *GeminiProvider) GenerateChatCompletion(ctx context.Context, messages []
 	if p.completionModel == "" {
 		return "", errors.New("Gemini provider is not configured for chat completion (completion model not set)")
 	}
-	// TODO: Implement actual chat completion logic using p.client.GenerativeModel(p.completionModel)
-	return "", errors.New("Gemini chat completion not yet fully implemented in this provider")
+
+	system, history, prompt := geminiContents(messages)
+	if prompt == nil {
+		return "", errors.New("Gemini chat completion needs at least one user or assistant message")
+	}
+
+	model := p.client.GenerativeModel(p.completionModel)
+	model.SystemInstruction = system
+	var resp *genai.GenerateContentResponse
+	var err error
+	if len(history) == 0 {
+		resp, err = model.GenerateContent(ctx, prompt.Parts...)
+	} else {
+		chat := model.StartChat()
+		chat.History = history
+		resp, err = chat.SendMessage(ctx, prompt.Parts...)
+	}
+	if err != nil {
+		return "", fmt.Errorf("Gemini API error generating chat completion: %w", err)
+	}
+	return geminiCandidateText(resp)
+}
+
+// geminiContents maps messages to Gemini contents: the system messages joined
+// into one system instruction, the conversation before the last message, and
+// the last message itself. Assistant messages take Gemini's "model" role.
+func geminiContents(messages []ChatMessage) (system *genai.Content, history []*genai.Content, last *genai.Content) {
+	var turns []*genai.Content
+	for _, m := range messages {
+		switch m.Role {
+		case ChatMessageRoleSystem:
+			if system == nil {
+				system = &genai.Content{}
+			}
+			system.Parts = append(system.Parts, genai.Text(m.Content))
+		case ChatMessageRoleAssistant:
+			turns = append(turns, &genai.Content{Role: "model", Parts: []genai.Part{genai.Text(m.Content)}})
+		default:
+			turns = append(turns, &genai.Content{Role: "user", Parts: []genai.Part{genai.Text(m.Content)}})
+		}
+	}
+	if len(turns) == 0 {
+		return system, nil, nil
+	}
+	return system, turns[:len(turns)-1], turns[len(turns)-1]
+}
+
+// geminiCandidateText concatenates the text parts of the first candidate.
+func geminiCandidateText(resp *genai.GenerateContentResponse) (string, error) {
+	if resp == nil || len(resp.Candidates) == 0 {
+		return "", errors.New("Gemini API returned no candidates") // Blocked prompts already fail in the client
+	}
+	candidate := resp.Candidates[0]
+	if candidate.Content == nil {
+		return "", fmt.Errorf("Gemini API returned an empty candidate (finish reason %s)", candidate.FinishReason)
+	}
+	var b strings.Builder
+	for _, part := range candidate.Content.Parts {
+		if text, ok := part.(genai.Text); ok {
+			b.WriteString(string(text))
+		}
+	}
+	return b.String(), nil
 }
 
 // Dimension returns the expected embedding dimension for the configured model.
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeminiContents_MapsRoles(t *testing.T) {
+	system, history, last := geminiContents([]ChatMessage{
+		{Role: ChatMessageRoleSystem, Content: "Be brief."},
+		{Role: ChatMessageRoleUser, Content: "Hi"},
+		{Role: ChatMessageRoleAssistant, Content: "Hello"},
+		{Role: ChatMessageRoleUser, Content: "What is mimir?"},
+	})
+
+	require.NotNil(t, system)
+	assert.Equal(t, []genai.Part{genai.Text("Be brief.")}, system.Parts)
+	require.Len(t, history, 2)
+	assert.Equal(t, "user", history[0].Role)
+	assert.Equal(t, "model", history[1].Role)
+	require.NotNil(t, last)
+	assert.Equal(t, "user", last.Role)
+	assert.Equal(t, []genai.Part{genai.Text("What is mimir?")}, last.Parts)
+
+	_, _, last = geminiContents([]ChatMessage{{Role: ChatMessageRoleSystem, Content: "Be brief."}})
+	assert.Nil(t, last, "system messages alone leave nothing to send")
+}
+
+func TestGeminiCandidateText_ConcatenatesFirstCandidate(t *testing.T) {
+	text, err := geminiCandidateText(&genai.GenerateContentResponse{Candidates: []*genai.Candidate{
+		{Content: &genai.Content{Parts: []genai.Part{genai.Text("Mimir is "), genai.Text("a knowledge base.")}}},
+		{Content: &genai.Content{Parts: []genai.Part{genai.Text("ignored")}}},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "Mimir is a knowledge base.", text)
+
+	_, err = geminiCandidateText(&genai.GenerateContentResponse{})
+	assert.Error(t, err)
+}
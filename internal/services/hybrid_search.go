@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// rrfK is the Reciprocal Rank Fusion constant: a result at rank r (from 1)
+// contributes weight/(rrfK+r). 60 is the value from the original RRF paper;
+// larger values flatten the difference between top and lower ranks.
+const rrfK = 60
+
+// hybridOverfetchFactor multiplies the limit for each of the two result lists,
+// so content ranked just outside one list's top results can still be fused.
+const hybridOverfetchFactor = 2
+
+type HybridSearchParams struct {
+	Query          string
+	Limit          int
+	FilterTags     []string // Restricts results to content carrying any of these tags
+	KeywordWeight  float64  // Weight of keyword ranks; 0 uses the configured default
+	SemanticWeight float64  // Weight of semantic ranks; 0 uses the configured default
+	Explain        bool     // Report matching chunks and matched terms per result
+}
+
+// SetHybridWeights sets the default keyword and semantic weights of
+// HybridSearch. Zero or negative weights fall back to 1.
+func (s *SearchService) SetHybridWeights(keyword, semantic float64) {
+	s.hybridKeywordWeight = keyword
+	s.hybridSemanticWeight = semantic
+}
+
+// HybridSearch runs semantic and keyword search concurrently and fuses the two
+// rankings with weighted Reciprocal Rank Fusion. Content found by both appears
+// once. Each result's Score is its fused score, higher is better, so
+// SearchResultItem.Similarity does not apply. The search is recorded in
+// search history once, with the fused results.
+func (s *SearchService) HybridSearch(ctx context.Context, params HybridSearchParams) ([]SearchResultItem, error) {
+	if s.vector == nil {
+		return nil, fmt.Errorf("vector store is not initialized: %w", ErrEmbeddingDisabled)
+	}
+	if s.embedding == nil {
+		return nil, fmt.Errorf("embedding service is not initialized")
+	}
+	if s.keywordSearcher == nil {
+		return nil, fmt.Errorf("keyword searcher is not initialized")
+	}
+	if params.KeywordWeight < 0 || params.SemanticWeight < 0 {
+		return nil, fmt.Errorf("hybrid search weights must not be negative")
+	}
+	params.Limit = s.defaults.SearchLimitFor(params.Limit)
+	keywordWeight := weightOrDefault(params.KeywordWeight, s.hybridKeywordWeight)
+	semanticWeight := weightOrDefault(params.SemanticWeight, s.hybridSemanticWeight)
+	candidates := params.Limit * hybridOverfetchFactor
+	filterMetadata, ok, err := s.contentFilter(ctx, 0, params.FilterTags)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []SearchResultItem{}, nil
+	}
+
+	var (
+		wg                      sync.WaitGroup
+		semantic                []SearchResultItem
+		keyword                 []store.KeywordMatch
+		semanticErr, keywordErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		queryVector, err := s.embedding.GenerateEmbedding(ctx, params.Query)
+		if err != nil {
+			semanticErr = fmt.Errorf("failed to generate query embedding: %w", err)
+			return
+		}
+		semantic, semanticErr = s.searchByVector(ctx, params.Query, queryVector, candidates, filterMetadata, BoostNone, params.Explain, 0)
+	}()
+	go func() {
+		defer wg.Done()
+		keyword, keywordErr = s.keywordSearcher.KeywordSearchContent(ctx, params.Query, params.FilterTags, store.KeywordSortRelevance, OwnerFromContext(ctx), candidates, 0)
+	}()
+	wg.Wait()
+	if semanticErr != nil {
+		return nil, semanticErr
+	}
+	if keywordErr != nil {
+		return nil, fmt.Errorf("keyword search failed: %w", keywordErr)
+	}
+
+	results := fuseRankings(semantic, keyword, semanticWeight, keywordWeight)
+	if len(results) > params.Limit {
+		results = results[:params.Limit]
+	}
+	if params.Explain {
+		for i := range results {
+			if results[i].Explanation == nil {
+				results[i].Explanation = &SearchExplanation{}
+			}
+			results[i].Explanation.MatchedTerms = MatchedTerms(params.Query, results[i].Content.Title, results[i].Content.Body)
+		}
+	}
+
+	s.recordHybridSearch(ctx, params.Query, results)
+
+	ids := make([]int64, len(results))
+	for i, res := range results {
+		ids[i] = res.Content.ID
+	}
+	s.touchAccessed(ctx, ids)
+
+	return results, nil
+}
+
+// weightOrDefault returns weight, or def when weight is unset, or 1 when neither is set.
+func weightOrDefault(weight, def float64) float64 {
+	if weight > 0 {
+		return weight
+	}
+	if def > 0 {
+		return def
+	}
+	return 1
+}
+
+// fuseRankings combines the semantic and keyword rankings with weighted
+// Reciprocal Rank Fusion, ordered by fused score, highest first. Ties keep
+// semantic order, then keyword order.
+func fuseRankings(semantic []SearchResultItem, keyword []store.KeywordMatch, semanticWeight, keywordWeight float64) []SearchResultItem {
+	fused := make([]SearchResultItem, 0, len(semantic)+len(keyword))
+	index := make(map[int64]int, len(semantic)+len(keyword))
+	add := func(content *models.Content, rank int, weight float64, explanation *SearchExplanation) {
+		score := weight / float64(rrfK+rank)
+		if i, ok := index[content.ID]; ok {
+			fused[i].Score += score
+			return
+		}
+		index[content.ID] = len(fused)
+		fused = append(fused, SearchResultItem{Content: content, Score: score, Explanation: explanation})
+	}
+	for i, res := range semantic {
+		add(res.Content, i+1, semanticWeight, res.Explanation)
+	}
+	for i, match := range keyword {
+		if match.Content == nil {
+			continue
+		}
+		add(match.Content, i+1, keywordWeight, nil)
+	}
+
+	sort.SliceStable(fused, func(i, j int) bool { return fused[i].Score > fused[j].Score })
+	return fused
+}
+
+// recordHybridSearch records a hybrid search and its fused results in search
+// history. Failures are only logged.
+func (s *SearchService) recordHybridSearch(ctx context.Context, query string, results []SearchResultItem) {
+	if !s.recordHistory {
+		return
+	}
+	record, err := s.searchHistory.RecordSearchQuery(ctx, OwnerFromContext(ctx), query, len(results))
+	if err != nil {
+		log.Printf("WARN: Failed to record hybrid search query '%s': %v", query, err)
+		return
+	}
+	recorded := make([]models.SearchResult, len(results))
+	for i, res := range results {
+		recorded[i] = models.SearchResult{
+			ContentID:      res.Content.ID,
+			RelevanceScore: res.Score,
+			Rank:           i + 1,
+		}
+	}
+	if err := s.searchHistory.RecordSearchResults(ctx, record.ID, recorded); err != nil {
+		log.Printf("WARN: Failed to record hybrid search results for query ID %d: %v", record.ID, err)
+	}
+}
@@ -0,0 +1,53 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+func TestHybridSearch_FusesRankings(t *testing.T) {
+	shared := func(id int64) *models.Content {
+		return &models.Content{ID: id, Title: "go notes", Visibility: store.VisibilityShared}
+	}
+	contents := &memContentStore{contents: map[int64]*models.Content{
+		1: shared(1), 2: shared(2),
+	}}
+	// Semantic ranks 1, 2; keyword ranks 2, 3. Content 2 is found by both.
+	vs := explainVectorStore{results: []models.SearchResult{
+		{ContentID: 1, Distance: 0.1},
+		{ContentID: 2, Distance: 0.2},
+	}}
+	ks := &recordingKeywordSearcher{matches: []store.KeywordMatch{
+		{Content: shared(2), Rank: 0.9},
+		{Content: shared(3), Rank: 0.5},
+	}}
+	history := &countingSearchHistory{}
+	svc := services.NewSearchService(contents, ks, vs, historyEmbeddingService{}, history)
+
+	results, err := svc.HybridSearch(context.Background(), services.HybridSearchParams{Query: "go", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 3, "content found by both searches appears once")
+
+	ids := []int64{results[0].Content.ID, results[1].Content.ID, results[2].Content.ID}
+	assert.Equal(t, []int64{2, 1, 3}, ids)
+	assert.InDelta(t, 1.0/62+1.0/61, results[0].Score, 1e-12)
+	assert.InDelta(t, 1.0/61, results[1].Score, 1e-12)
+	assert.Equal(t, store.KeywordSortRelevance, ks.sortBy)
+	assert.Equal(t, 1, history.queries, "the fused search is recorded once")
+	assert.Equal(t, 1, history.results)
+
+	// Weighting keywords heavily puts the keyword-only hit above the semantic-only one.
+	results, err = svc.HybridSearch(context.Background(), services.HybridSearchParams{Query: "go", Limit: 10, KeywordWeight: 3})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), results[1].Content.ID)
+
+	_, err = svc.HybridSearch(context.Background(), services.HybridSearchParams{Query: "go", SemanticWeight: -1})
+	assert.Error(t, err)
+}
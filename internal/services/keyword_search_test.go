@@ -0,0 +1,103 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingKeywordSearcher struct {
+	sortBy        string
+	ownerID       string
+	limit, offset int
+	matches       []store.KeywordMatch
+}
+
+func (r *recordingKeywordSearcher) KeywordSearchContent(ctx context.Context, query string, filterTags []string, sortBy string, ownerID string, limit, offset int) ([]store.KeywordMatch, error) {
+	r.sortBy = sortBy
+	r.ownerID = ownerID
+	r.limit, r.offset = limit, offset
+	return r.matches, nil
+}
+
+type nopSearchHistory struct{}
+
+func (nopSearchHistory) RecordSearchQuery(ctx context.Context, ownerID, query string, resultsCount int) (*models.SearchQuery, error) {
+	return &models.SearchQuery{ID: 1, Query: query}, nil
+}
+
+func (nopSearchHistory) ListSearchQueries(ctx context.Context, ownerID string, limit int) ([]*models.SearchQuery, error) {
+	return nil, nil
+}
+
+func (nopSearchHistory) RecordSearchResults(ctx context.Context, queryID int64, results []models.SearchResult) error {
+	return nil
+}
+
+func (nopSearchHistory) DeleteSearchQueriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestKeywordSearchSortAndScore(t *testing.T) {
+	ks := &recordingKeywordSearcher{matches: []store.KeywordMatch{
+		{Content: &models.Content{ID: 2}, Rank: 0.6},
+		{Content: &models.Content{ID: 1}, Rank: 0.1},
+	}}
+	svc := services.NewSearchService(nil, ks, nil, nil, nopSearchHistory{})
+
+	results, err := svc.KeywordSearch(context.Background(), services.KeywordSearchParams{Query: "go"})
+	require.NoError(t, err)
+	assert.Equal(t, store.KeywordSortRelevance, ks.sortBy)
+	require.Len(t, results, 2)
+	assert.Equal(t, int64(2), results[0].Content.ID)
+	assert.InDelta(t, 0.6, results[0].Score, 1e-9)
+
+	_, err = svc.KeywordSearch(context.Background(), services.KeywordSearchParams{Query: "go", SortBy: store.KeywordSortModifiedAt})
+	require.NoError(t, err)
+	assert.Equal(t, store.KeywordSortModifiedAt, ks.sortBy)
+
+	_, err = svc.KeywordSearch(context.Background(), services.KeywordSearchParams{Query: "go", SortBy: "title"})
+	assert.Error(t, err)
+}
+
+func TestKeywordSearchScopedToOwner(t *testing.T) {
+	ks := &recordingKeywordSearcher{}
+	svc := services.NewSearchService(nil, ks, nil, nil, nopSearchHistory{})
+
+	_, err := svc.KeywordSearch(context.Background(), services.KeywordSearchParams{Query: "go"})
+	require.NoError(t, err)
+	assert.Equal(t, store.DefaultOwnerID, ks.ownerID, "without an owner the public owner is used")
+
+	_, err = svc.KeywordSearch(services.WithOwner(context.Background(), "alice"), services.KeywordSearchParams{Query: "go"})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", ks.ownerID)
+}
+
+func TestKeywordSearchPagination(t *testing.T) {
+	ks := &recordingKeywordSearcher{}
+	svc := services.NewSearchService(nil, ks, nil, nil, nopSearchHistory{})
+
+	_, err := svc.KeywordSearch(context.Background(), services.KeywordSearchParams{Query: "go", Limit: 10, Offset: 20})
+	require.NoError(t, err)
+	assert.Equal(t, 10, ks.limit)
+	assert.Equal(t, 20, ks.offset)
+
+	_, err = svc.KeywordSearch(context.Background(), services.KeywordSearchParams{Query: "go", Offset: -1})
+	assert.Error(t, err)
+}
+
+func TestVisibleTo(t *testing.T) {
+	private := &models.Content{OwnerID: "alice", Visibility: store.VisibilityPrivate}
+	shared := &models.Content{OwnerID: "alice", Visibility: store.VisibilityShared}
+
+	assert.True(t, store.VisibleTo(private, "alice"))
+	assert.False(t, store.VisibleTo(private, "bob"))
+	assert.True(t, store.VisibleTo(shared, "bob"))
+}
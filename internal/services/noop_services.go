@@ -2,6 +2,11 @@ package services
 
 import (
 	"context"
+	"errors"
+
+	"github.com/pgvector/pgvector-go"
+
+	"mimir/internal/store"
 )
 
 type NoopSummaryService struct{}
@@ -25,3 +30,34 @@ func NewNoopSummaryService() SummaryService {
 func NewNoopTaggingService() TaggingService {
 	return &NoopTaggingService{}
 }
+
+// ErrEmbeddingDisabled is returned for embedding and semantic search when the
+// app runs keyword-only (mode keyword-only, or embedding.required false
+// without an embedding provider).
+var ErrEmbeddingDisabled = errors.New("embedding and semantic search are disabled: mimir is running keyword-only")
+
+// NoopEmbeddingService stands in for the embedding service in keyword-only
+// deployments. Every embedding request fails with ErrEmbeddingDisabled.
+type NoopEmbeddingService struct {
+	dimension int
+}
+
+// NewNoopEmbeddingService creates a NoopEmbeddingService reporting dimension.
+func NewNoopEmbeddingService(dimension int) *NoopEmbeddingService {
+	return &NoopEmbeddingService{dimension: dimension}
+}
+
+func (s *NoopEmbeddingService) GenerateEmbedding(ctx context.Context, text string) (pgvector.Vector, error) {
+	return pgvector.Vector{}, ErrEmbeddingDisabled
+}
+
+func (s *NoopEmbeddingService) GenerateEmbeddings(ctx context.Context, texts []string) ([]pgvector.Vector, error) {
+	return nil, ErrEmbeddingDisabled
+}
+
+func (s *NoopEmbeddingService) Dimension() int               { return s.dimension }
+func (s *NoopEmbeddingService) ModelName() string            { return "" }
+func (s *NoopEmbeddingService) Name() string                 { return "none" }
+func (s *NoopEmbeddingService) Status() store.ProviderStatus { return store.ProviderStatusDisabled }
+
+var _ store.EmbeddingService = (*NoopEmbeddingService)(nil)
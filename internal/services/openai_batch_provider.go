@@ -1,13 +1,18 @@
 package services
 
 import (
-	// "bytes" // No longer needed if using FilePath
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"io" // Keep io import
-	"mimir/internal/config" // Import config package
+	"strings"
+	"time"
 
+	"mimir/internal/config" // Import config package
+	"mimir/internal/models"
 	"mimir/internal/store"  // Add store import
 
 	"github.com/sashabaranov/go-openai"
@@ -22,7 +27,7 @@ type OpenAIBatchProvider struct {
 }
 
 // NewOpenAIBatchProvider creates a new provider for OpenAI Batch API operations.
-func NewOpenAIBatchProvider(apiKey string, costStore store.CostTrackingStore, pricing map[string]config.PricingInfo) (*OpenAIBatchProvider, error) { // Update signature
+func NewOpenAIBatchProvider(apiKey string, costStore store.CostTrackingStore, pricing map[string]config.PricingInfo, modelOpts EmbeddingModelOptions) (*OpenAIBatchProvider, error) { // Update signature
 	if apiKey == "" {
 		apiKey = os.Getenv("OPENAI_API_KEY") // Fallback to env var
 	}
@@ -37,7 +42,7 @@ func NewOpenAIBatchProvider(apiKey string, costStore store.CostTrackingStore, pr
 	return &OpenAIBatchProvider{
 		client:    client,
 		costStore: costStore,
-		pricing:   pricing,
+		pricing:   mergeModelPricing(pricing, modelOpts), // Configured model prices override the pricing section
 	}, nil
 }
 
@@ -118,18 +123,8 @@ func (p *OpenAIBatchProvider) RetrieveBatch(ctx context.Context, batchID string)
 	// Note: OpenAI Batch API currently doesn't directly expose token counts or cost per batch job via API.
 	// This is a placeholder assuming future API updates or manual cost calculation based on input file size/model. // Use string comparison for status
 	// For now, we log a warning.
-	if string(batchResponse.Status) == "completed" && p.costStore != nil {
-		log.Warnf("OpenAI Batch API (job %s) completed, but cost/token tracking is not yet supported via API. Manual calculation needed.", batchID)
-		// Placeholder for future implementation:
-		// if batchResponse.Usage != nil { // Hypothetical usage field
-		//     priceInfo, ok := p.pricing[batchResponse.Model] // Hypothetical model field
-		//     if ok {
-		//         cost := calculateCost(batchResponse.Usage, priceInfo)
-		//         logEntry := &models.AIUsageLog{ ... }
-		//         if err := p.costStore.RecordUsage(ctx, logEntry); err != nil { ... }
-		//     }
-		// }
-	}
+	// The batch object carries no token counts; usage is recorded from the output
+	// file via RecordBatchUsage once the caller has downloaded it.
 	// --- End Cost Tracking ---
 
 	return batchResponse, nil
@@ -155,5 +150,105 @@ func (p *OpenAIBatchProvider) GetFileContent(ctx context.Context, fileID string)
 	return content, nil
 }
 
+// batchOutputLine is the subset of an OpenAI batch output line needed for cost tracking.
+type batchOutputLine struct {
+	Response *struct {
+		StatusCode int `json:"status_code"`
+		Body       struct {
+			Model string `json:"model"`
+			Usage struct {
+				PromptTokens     int `json:"prompt_tokens"`
+				CompletionTokens int `json:"completion_tokens"`
+			} `json:"usage"`
+		} `json:"body"`
+	} `json:"response"`
+}
+
+// batchUsage is the token usage of one model within a batch.
+type batchUsage struct {
+	inputTokens, outputTokens int
+}
+
+// sumBatchUsage totals token usage per model over the successful requests in a batch output file.
+func sumBatchUsage(outputFile []byte) (map[string]*batchUsage, error) {
+	usage := make(map[string]*batchUsage)
+	scanner := bufio.NewScanner(bytes.NewReader(outputFile))
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024) // Embedding responses make long lines
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var out batchOutputLine
+		if err := json.Unmarshal(line, &out); err != nil {
+			return nil, fmt.Errorf("parse batch output line: %w", err)
+		}
+		if out.Response == nil || out.Response.StatusCode != 200 {
+			continue // Failed requests are not billed
+		}
+		body := out.Response.Body
+		u, ok := usage[body.Model]
+		if !ok {
+			u = &batchUsage{}
+			usage[body.Model] = u
+		}
+		u.inputTokens += body.Usage.PromptTokens
+		u.outputTokens += body.Usage.CompletionTokens
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read batch output: %w", err)
+	}
+	return usage, nil
+}
+
+// RecordBatchUsage records one usage entry per model found in a completed batch's
+// output file, priced at the model's batch rates (see config.PricingInfo.BatchRates).
+func (p *OpenAIBatchProvider) RecordBatchUsage(ctx context.Context, batchID string, outputFile []byte) error {
+	if p.costStore == nil {
+		return nil
+	}
+	usage, err := sumBatchUsage(outputFile)
+	if err != nil {
+		return fmt.Errorf("batch %s: %w", batchID, err)
+	}
+	for model, u := range usage {
+		priceInfo, ok := p.lookupPricing(model)
+		if !ok {
+			log.Warnf("Pricing info not found for model '%s' (batch %s). Cannot record cost.", model, batchID)
+			continue
+		}
+		inputRate, outputRate := priceInfo.BatchRates()
+		logEntry := &models.AIUsageLog{
+			Timestamp:    time.Now(),
+			ProviderName: "openai",
+			ServiceType:  "embedding_batch",
+			ModelName:    model,
+			InputTokens:  u.inputTokens,
+			OutputTokens: u.outputTokens,
+			Cost:         float64(u.inputTokens)*inputRate + float64(u.outputTokens)*outputRate,
+			OwnerID:      OwnerFromContext(ctx),
+		}
+		if err := p.costStore.RecordUsage(ctx, logEntry); err != nil {
+			return fmt.Errorf("record usage for batch %s (model %s): %w", batchID, model, err)
+		}
+	}
+	return nil
+}
+
+// lookupPricing finds pricing for a model as reported in batch output, which may
+// carry a version suffix (e.g. "text-embedding-3-small-001") absent from the config.
+func (p *OpenAIBatchProvider) lookupPricing(model string) (config.PricingInfo, bool) {
+	if info, ok := p.pricing[model]; ok {
+		return info, true
+	}
+	best, found := "", false
+	for name := range p.pricing {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best, found = name, true
+		}
+	}
+	return p.pricing[best], found
+}
+
 // Ensure OpenAIBatchProvider implements the interface.
 var _ BatchAPIProvider = (*OpenAIBatchProvider)(nil)
@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/config"
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+type recordingCostStore struct{ logs []*models.AIUsageLog }
+
+func (s *recordingCostStore) RecordUsage(ctx context.Context, log *models.AIUsageLog) error {
+	s.logs = append(s.logs, log)
+	return nil
+}
+func (s *recordingCostStore) RecordUsageBatch(ctx context.Context, logs []*models.AIUsageLog) error {
+	s.logs = append(s.logs, logs...)
+	return nil
+}
+func (s *recordingCostStore) ListUsage(ctx context.Context, ownerID string, limit, offset int) ([]*models.AIUsageLog, error) {
+	return s.logs, nil
+}
+func (s *recordingCostStore) GetUsageSummary(ctx context.Context, ownerID string) (float64, int64, int64, error) {
+	return 0, 0, 0, nil
+}
+func (s *recordingCostStore) GetUsageByOwner(ctx context.Context) ([]store.OwnerUsage, error) {
+	return nil, nil
+}
+func (s *recordingCostStore) GetUsageSummaryByService(ctx context.Context, ownerID string, r store.UsageRange) ([]store.ServiceUsage, error) {
+	return nil, nil
+}
+func (s *recordingCostStore) StreamUsage(ctx context.Context, ownerID string, r store.UsageRange, fn func(log *models.AIUsageLog) error) error {
+	return nil
+}
+func (s *recordingCostStore) GetUsageGroups(ctx context.Context, ownerID string, r store.UsageRange, byDay, byModel bool) ([]store.UsageGroup, error) {
+	return nil, nil
+}
+
+func TestOpenAIBatchProvider_RecordBatchUsage_UsesBatchRate(t *testing.T) {
+	costs := &recordingCostStore{}
+	pricing := map[string]config.PricingInfo{
+		"text-embedding-3-small": {InputPerToken: 0.00000002, BatchInputPerToken: 0.00000001},
+	}
+	p, err := services.NewOpenAIBatchProvider("test-key", costs, pricing, services.EmbeddingModelOptions{})
+	require.NoError(t, err)
+
+	output := []byte(`{"id":"r1","response":{"status_code":200,"body":{"model":"text-embedding-3-small","usage":{"prompt_tokens":1000,"total_tokens":1000}}}}
+{"id":"r2","response":{"status_code":200,"body":{"model":"text-embedding-3-small","usage":{"prompt_tokens":500,"total_tokens":500}}}}
+{"id":"r3","response":{"status_code":400,"body":{}},"error":{"message":"bad request"}}
+`)
+	require.NoError(t, p.RecordBatchUsage(context.Background(), "batch_1", output))
+
+	require.Len(t, costs.logs, 1)
+	assert.Equal(t, 1500, costs.logs[0].InputTokens)
+	assert.InDelta(t, 1500*0.00000001, costs.logs[0].Cost, 1e-12)
+}
+
+func TestPricingInfo_BatchRatesFallBackToStandard(t *testing.T) {
+	input, output := config.PricingInfo{InputPerToken: 2, OutputPerToken: 4, BatchOutputPerToken: 1}.BatchRates()
+	assert.Equal(t, 2.0, input)
+	assert.Equal(t, 1.0, output)
+}
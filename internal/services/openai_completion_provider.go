@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"mimir/internal/config"
+	"mimir/internal/models"
+	"mimir/internal/store"
+
+	"github.com/sashabaranov/go-openai"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultOpenAICompletionModel is used for chat completion when rag.model is not set.
+const DefaultOpenAICompletionModel = "gpt-4o-mini"
+
+// OpenAICompletionProvider implements CompletionService using the OpenAI chat
+// completions API, recording the cost of every call.
+type OpenAICompletionProvider struct {
+	client    *openai.Client
+	model     string
+	costStore store.CostTrackingStore
+	pricing   map[string]config.PricingInfo
+}
+
+// NewOpenAICompletionProvider creates an OpenAI completion provider. Without an
+// API key the provider is disabled and every completion fails.
+func NewOpenAICompletionProvider(apiKey, model string, costStore store.CostTrackingStore, pricing map[string]config.PricingInfo) *OpenAICompletionProvider {
+	if apiKey == "" {
+		apiKey = os.Getenv("OPENAI_API_KEY") // Fallback to env var
+	}
+	if apiKey == "" {
+		log.Warn("OpenAI API key not provided for chat completion. OpenAI completion provider will be disabled.")
+		return &OpenAICompletionProvider{client: nil, model: model}
+	}
+	log.Infof("OpenAI completion provider initialized with model %s", model)
+	return &OpenAICompletionProvider{
+		client:    openai.NewClient(apiKey),
+		model:     model,
+		costStore: costStore,
+		pricing:   pricing,
+	}
+}
+
+// Name returns the provider name.
+func (p *OpenAICompletionProvider) Name() string { return "openai" }
+
+// ModelName returns the completion model.
+func (p *OpenAICompletionProvider) ModelName() string { return p.model }
+
+// Status reports the provider as disabled when it has no API key.
+func (p *OpenAICompletionProvider) Status() store.ProviderStatus {
+	if p.client == nil {
+		return store.ProviderStatusDisabled
+	}
+	return store.ProviderStatusActive
+}
+
+// GenerateChatCompletion sends messages to the completion model and returns the
+// content of the first choice.
+func (p *OpenAICompletionProvider) GenerateChatCompletion(ctx context.Context, messages []ChatMessage) (string, error) {
+	if p.client == nil {
+		return "", fmt.Errorf("OpenAI completion provider is not initialized (missing API key)")
+	}
+
+	req := openai.ChatCompletionRequest{
+		Model:    p.model,
+		Messages: make([]openai.ChatCompletionMessage, len(messages)),
+	}
+	for i, m := range messages {
+		req.Messages[i] = openai.ChatCompletionMessage{Role: string(m.Role), Content: m.Content}
+	}
+
+	resp, err := p.client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("OpenAI API error generating chat completion: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("OpenAI API returned no completion choices")
+	}
+
+	p.recordUsage(ctx, resp.Usage)
+	return resp.Choices[0].Message.Content, nil
+}
+
+// recordUsage logs the cost of a completion. Failures are logged, not returned,
+// so cost tracking never fails a completion.
+func (p *OpenAICompletionProvider) recordUsage(ctx context.Context, usage openai.Usage) {
+	if p.costStore == nil || usage.TotalTokens == 0 {
+		return
+	}
+	priceInfo, ok := p.pricing[p.model]
+	if !ok {
+		log.Warnf("Pricing info not found for model '%s'. Cannot record cost for chat completion.", p.model)
+		return
+	}
+	logEntry := &models.AIUsageLog{
+		Timestamp:    time.Now(),
+		ProviderName: p.Name(),
+		ServiceType:  "completion",
+		ModelName:    p.model,
+		InputTokens:  usage.PromptTokens,
+		OutputTokens: usage.CompletionTokens,
+		Cost:         float64(usage.PromptTokens)*priceInfo.InputPerToken + float64(usage.CompletionTokens)*priceInfo.OutputPerToken,
+		OwnerID:      OwnerFromContext(ctx),
+	}
+	if err := p.costStore.RecordUsage(ctx, logEntry); err != nil {
+		log.Errorf("Failed to record AI usage log for chat completion: %v", err)
+		return
+	}
+	log.Debugf("Recorded AI usage: Provider=%s, Service=%s, Model=%s, InputTokens=%d, OutputTokens=%d, Cost=%.8f",
+		logEntry.ProviderName, logEntry.ServiceType, logEntry.ModelName, logEntry.InputTokens, logEntry.OutputTokens, logEntry.Cost)
+}
+
+// Ensure OpenAICompletionProvider implements CompletionService
+var _ CompletionService = (*OpenAICompletionProvider)(nil)
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/config"
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+type completionCostStore struct {
+	store.CostTrackingStore
+	logs []*models.AIUsageLog
+}
+
+func (s *completionCostStore) RecordUsage(ctx context.Context, log *models.AIUsageLog) error {
+	s.logs = append(s.logs, log)
+	return nil
+}
+
+func TestOpenAICompletionProvider_GenerateChatCompletion_RecordsCost(t *testing.T) {
+	var got openai.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: "assistant", Content: "Mimir stores notes [1]."}}},
+			Usage:   openai.Usage{PromptTokens: 1000, CompletionTokens: 100, TotalTokens: 1100},
+		})
+	}))
+	defer server.Close()
+
+	costs := &completionCostStore{}
+	p := NewOpenAICompletionProvider("test-key", "gpt-4o-mini", costs, map[string]config.PricingInfo{
+		"gpt-4o-mini": {InputPerToken: 0.00000015, OutputPerToken: 0.0000006},
+	})
+	clientConfig := openai.DefaultConfig("test-key")
+	clientConfig.BaseURL = server.URL
+	p.client = openai.NewClientWithConfig(clientConfig)
+
+	answer, err := p.GenerateChatCompletion(context.Background(), []ChatMessage{
+		{Role: ChatMessageRoleSystem, Content: "Answer from the sources."},
+		{Role: ChatMessageRoleUser, Content: "What is mimir?"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Mimir stores notes [1].", answer)
+
+	assert.Equal(t, "gpt-4o-mini", got.Model)
+	require.Len(t, got.Messages, 2)
+	assert.Equal(t, "system", got.Messages[0].Role)
+	assert.Equal(t, "user", got.Messages[1].Role)
+
+	require.Len(t, costs.logs, 1)
+	assert.Equal(t, "completion", costs.logs[0].ServiceType)
+	assert.Equal(t, 1000, costs.logs[0].InputTokens)
+	assert.Equal(t, 100, costs.logs[0].OutputTokens)
+	assert.InDelta(t, 1000*0.00000015+100*0.0000006, costs.logs[0].Cost, 1e-12)
+}
@@ -22,13 +22,15 @@ type OpenAIProvider struct {
 	client    *openai.Client
 	model     openai.EmbeddingModel // Store the model ID
 	dim       int                   // Store the dimension
+	reqDims   int                   // dimensions request parameter; 0 omits it and keeps the native dimension
 	costStore store.CostTrackingStore
 	pricing   map[string]config.PricingInfo
 
 }
 
 // NewOpenAIProvider creates a new OpenAI embedding provider.
-func NewOpenAIProvider(apiKey, modelID string, costStore store.CostTrackingStore, pricing map[string]config.PricingInfo) (*OpenAIProvider, error) {
+// Unknown models fail in strict mode; see EmbeddingModelOptions.
+func NewOpenAIProvider(apiKey, modelID string, costStore store.CostTrackingStore, pricing map[string]config.PricingInfo, modelOpts EmbeddingModelOptions) (*OpenAIProvider, error) {
 	if apiKey == "" {
 		apiKey = os.Getenv("OPENAI_API_KEY") // Fallback to env var
 	}
@@ -37,17 +39,13 @@ func NewOpenAIProvider(apiKey, modelID string, costStore store.CostTrackingStore
 		return &OpenAIProvider{client: nil}, nil
 	}
 
-	var dim int
-	switch modelID {
-	case string(openai.AdaEmbeddingV2):
-		dim = 1536
-	case "text-embedding-3-small":
-		dim = 1536
-	case "text-embedding-3-large":
-		dim = 3072
-	default:
-		log.Warnf("Unknown OpenAI embedding model '%s', defaulting dimension to 1536 (AdaV2). Accuracy may be affected.", modelID)
-		dim = 1536
+	nativeDim, err := resolveEmbeddingDimension("OpenAI", modelID, openAIEmbeddingDimensions, modelOpts, 1536)
+	if err != nil {
+		return nil, err
+	}
+	dim, err := resolveOutputDimension(modelID, openAIShortenableModels, nativeDim, modelOpts.Dimensions)
+	if err != nil {
+		return nil, err
 	}
 
 	client := openai.NewClient(apiKey)
@@ -57,8 +55,9 @@ func NewOpenAIProvider(apiKey, modelID string, costStore store.CostTrackingStore
 		client:    client,
 		model:     openai.EmbeddingModel(modelID),
 		dim:       dim,
+		reqDims:   modelOpts.Dimensions,
 		costStore: costStore,
-		pricing:   pricing, // Assign the passed pricing map
+		pricing:   mergeModelPricing(pricing, modelOpts), // Configured model prices override the pricing section
 	}, nil
 }
 
@@ -78,8 +77,9 @@ func (p *OpenAIProvider) GenerateEmbedding(ctx context.Context, text string) (pg
 	}
 
 	req := openai.EmbeddingRequestStrings{
-		Input: []string{text},
-		Model: p.model,
+		Input:      []string{text},
+		Model:      p.model,
+		Dimensions: p.reqDims,
 	}
 
 	resp, err := p.client.CreateEmbeddings(ctx, req)
@@ -112,6 +112,7 @@ func (p *OpenAIProvider) GenerateEmbedding(ctx context.Context, text string) (pg
 				InputTokens:  resp.Usage.TotalTokens, // OpenAI embedding usage reports total tokens
 				OutputTokens: 0,                      // No output tokens for embeddings
 				Cost:         cost,
+				OwnerID:      OwnerFromContext(ctx),
 				// RelatedContentID and RelatedJobID should be set by the caller if available
 			}
 			if err := p.costStore.RecordUsage(ctx, logEntry); err != nil {
@@ -157,8 +158,9 @@ func (p *OpenAIProvider) GenerateEmbeddings(ctx context.Context, texts []string)
 	}
 
 	req := openai.EmbeddingRequestStrings{
-		Input: validTexts,
-		Model: p.model,
+		Input:      validTexts,
+		Model:      p.model,
+		Dimensions: p.reqDims,
 	}
 
 	resp, err := p.client.CreateEmbeddings(ctx, req)
@@ -186,6 +188,7 @@ func (p *OpenAIProvider) GenerateEmbeddings(ctx context.Context, texts []string)
 				InputTokens:  resp.Usage.TotalTokens,
 				OutputTokens: 0,
 				Cost:         cost,
+				OwnerID:      OwnerFromContext(ctx),
 			}
 			if err := p.costStore.RecordUsage(ctx, logEntry); err != nil {
 				log.Errorf("Failed to record AI usage log for batch embedding: %v", err)
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"context"
+
+	"mimir/internal/store"
+)
+
+type ownerContextKey struct{}
+
+// WithOwner returns a context identifying ownerID as the requesting owner.
+// Content added under it belongs to ownerID, and listings and searches are
+// limited to ownerID's content plus shared content.
+func WithOwner(ctx context.Context, ownerID string) context.Context {
+	return context.WithValue(ctx, ownerContextKey{}, ownerID)
+}
+
+// OwnerFromContext returns the owner set by WithOwner, or store.DefaultOwnerID
+// when there is none (authentication disabled, CLI).
+func OwnerFromContext(ctx context.Context) string {
+	if ownerID, ok := ctx.Value(ownerContextKey{}).(string); ok && ownerID != "" {
+		return ownerID
+	}
+	return store.DefaultOwnerID
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"log"
+	"math"
+	"sort"
+
+	"mimir/internal/config"
+)
+
+// Provider selection strategies for FallbackEmbeddingService.
+const (
+	ProviderStrategyFallback   = "fallback"    // Try providers in configured order
+	ProviderStrategyLowestCost = "lowest_cost" // Try the cheapest provider first, per the pricing map
+)
+
+// ProviderOrder controls the order in which FallbackEmbeddingService tries providers.
+type ProviderOrder struct {
+	Order    []string // Provider names tried first, in this order; unlisted providers follow in their original order
+	Primary  string   // Provider always tried first, regardless of Order and Strategy
+	Strategy string   // ProviderStrategyFallback (default) or ProviderStrategyLowestCost
+	Pricing  map[string]map[string]config.PricingInfo
+	Models   EmbeddingModelOptions
+}
+
+// OrderProviders returns a copy of providers arranged according to order.
+// With a zero ProviderOrder the original slice order is preserved.
+func OrderProviders(providers []EmbeddingProvider, order ProviderOrder) []EmbeddingProvider {
+	ordered := make([]EmbeddingProvider, len(providers))
+	copy(ordered, providers)
+
+	if len(order.Order) > 0 {
+		rank := make(map[string]int, len(order.Order))
+		for i, name := range order.Order {
+			if _, seen := rank[name]; !seen {
+				rank[name] = i
+			}
+		}
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return orderRank(rank, ordered[i].Name()) < orderRank(rank, ordered[j].Name())
+		})
+	}
+
+	if order.Strategy == ProviderStrategyLowestCost {
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return order.inputCost(ordered[i]) < order.inputCost(ordered[j])
+		})
+	}
+
+	if order.Primary != "" {
+		idx := -1
+		for i, p := range ordered {
+			if p.Name() == order.Primary {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			log.Printf("WARN: Primary embedding provider %q is not available; using %s", order.Primary, providerNames(ordered))
+		} else if idx > 0 {
+			primary := ordered[idx]
+			copy(ordered[1:idx+1], ordered[:idx])
+			ordered[0] = primary
+		}
+	}
+
+	return ordered
+}
+
+// orderRank places listed providers by position and unlisted ones after them.
+func orderRank(rank map[string]int, name string) int {
+	if r, ok := rank[name]; ok {
+		return r
+	}
+	return math.MaxInt
+}
+
+// inputCost returns the per-token input price of the provider's model.
+// Providers without pricing sort after every priced provider.
+func (o ProviderOrder) inputCost(p EmbeddingProvider) float64 {
+	pricing := mergeModelPricing(o.Pricing[p.Name()], o.Models)
+	info, ok := pricing[p.ModelName()]
+	if !ok {
+		return math.Inf(1)
+	}
+	return info.InputPerToken
+}
+
+func providerNames(providers []EmbeddingProvider) []string {
+	names := make([]string, len(providers))
+	for i, p := range providers {
+		names[i] = p.Name()
+	}
+	return names
+}
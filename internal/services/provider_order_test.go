@@ -0,0 +1,49 @@
+package services_test
+
+import (
+	"testing"
+
+	"mimir/internal/config"
+	"mimir/internal/services"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func names(providers []services.EmbeddingProvider) []string {
+	out := make([]string, len(providers))
+	for i, p := range providers {
+		out[i] = p.Name()
+	}
+	return out
+}
+
+func TestOrderProviders(t *testing.T) {
+	providers := []services.EmbeddingProvider{
+		&services.DummyProvider{NameStr: "openai", Model: "text-embedding-3-large"},
+		&services.DummyProvider{NameStr: "gemini", Model: "models/embedding-001"},
+		&services.DummyProvider{NameStr: "local", Model: "mini"},
+	}
+	pricing := map[string]map[string]config.PricingInfo{
+		"openai": {"text-embedding-3-large": {InputPerToken: 0.00000013}},
+		"gemini": {"models/embedding-001": {InputPerToken: 0.00000001}},
+	}
+
+	tests := []struct {
+		name  string
+		order services.ProviderOrder
+		want  []string
+	}{
+		{"default keeps slice order", services.ProviderOrder{}, []string{"openai", "gemini", "local"}},
+		{"explicit order", services.ProviderOrder{Order: []string{"local", "gemini"}}, []string{"local", "gemini", "openai"}},
+		{"primary", services.ProviderOrder{Primary: "gemini"}, []string{"gemini", "openai", "local"}},
+		{"unknown primary ignored", services.ProviderOrder{Primary: "anthropic"}, []string{"openai", "gemini", "local"}},
+		{"lowest cost, unpriced last", services.ProviderOrder{Strategy: services.ProviderStrategyLowestCost, Pricing: pricing}, []string{"gemini", "openai", "local"}},
+		{"primary overrides lowest cost", services.ProviderOrder{Strategy: services.ProviderStrategyLowestCost, Pricing: pricing, Primary: "local"}, []string{"local", "gemini", "openai"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, names(services.OrderProviders(providers, tt.order)))
+		})
+	}
+	assert.Equal(t, "openai", providers[0].Name(), "input slice must not be reordered")
+}
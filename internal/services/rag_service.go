@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNoRAGContext is returned by RAGService.Query when semantic search finds no
+// content to answer from.
+var ErrNoRAGContext = errors.New("no relevant content found to answer the question")
+
+// DefaultRAGPromptTemplate is used when no RAG prompt file can be loaded.
+// {{CONTEXT}} and {{QUESTION}} are replaced before the prompt is sent.
+const DefaultRAGPromptTemplate = `Answer the question using only the numbered sources below.
+Cite the sources you use by their number in square brackets, e.g. [1] or [2][3].
+If the sources do not contain the answer, say that you do not know.
+
+Sources:
+{{CONTEXT}}
+
+Question: {{QUESTION}}`
+
+// ragSnippetMaxLen caps the body excerpt used as context for a source that has
+// no matching chunks.
+const ragSnippetMaxLen = 1000
+
+// ragCitation matches source citations such as [2] in an answer.
+var ragCitation = regexp.MustCompile(`\[(\d+)\]`)
+
+// RAGParams configures the retrieval step of RAGService.Query.
+type RAGParams struct {
+	Limit        int      // Content items retrieved as context; 0 uses the default search limit
+	FilterTags   []string // Restricts the context to content carrying any of these tags
+	CollectionID int64    // Optional; 0 retrieves from all content
+}
+
+// RAGSource is a content item given to the model as context.
+type RAGSource struct {
+	ContentID int64    `json:"content_id"`
+	Title     string   `json:"title"`
+	Score     float64  `json:"score"`    // Similarity to the question, higher is better
+	Snippets  []string `json:"snippets"` // The text given to the model: the best matching chunks
+	Cited     bool     `json:"cited"`    // The answer cites this source by its number
+}
+
+// RAGAnswer is a generated answer with the sources it was generated from.
+type RAGAnswer struct {
+	Answer    string      `json:"answer"`
+	SourceIDs []int64     `json:"source_ids"` // Content IDs cited in the answer, in source order
+	Sources   []RAGSource `json:"sources"`    // Every source given as context, numbered from 1 in this order
+}
+
+// RAGService answers questions from stored content: it retrieves the content
+// most similar to the question, formats its best matching chunks into the RAG
+// prompt and asks the completion provider for an answer citing them.
+type RAGService struct {
+	search    *SearchService
+	completer CompletionService
+	prompt    string
+}
+
+// NewRAGService creates a RAGService. An empty prompt uses DefaultRAGPromptTemplate.
+func NewRAGService(search *SearchService, completer CompletionService, prompt string) *RAGService {
+	if strings.TrimSpace(prompt) == "" {
+		prompt = DefaultRAGPromptTemplate
+	}
+	return &RAGService{search: search, completer: completer, prompt: prompt}
+}
+
+// Query answers question from the content retrieved by semantic search. The
+// answer lists every source given to the model, with its snippets, and the IDs
+// of the sources it cites.
+func (r *RAGService) Query(ctx context.Context, question string, params RAGParams) (*RAGAnswer, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return nil, fmt.Errorf("question cannot be empty")
+	}
+
+	results, err := r.search.SemanticSearch(ctx, SemanticSearchParams{
+		Query:        question,
+		Limit:        params.Limit,
+		FilterTags:   params.FilterTags,
+		CollectionID: params.CollectionID,
+		Explain:      true, // The matching chunks are the context
+	})
+	if err != nil {
+		return nil, fmt.Errorf("retrieve context: %w", err)
+	}
+
+	sources := make([]RAGSource, 0, len(results))
+	for _, item := range results {
+		if item.Content == nil {
+			continue
+		}
+		source := RAGSource{ContentID: item.Content.ID, Title: item.Content.Title, Score: item.Similarity()}
+		if item.Explanation != nil {
+			for _, chunk := range item.Explanation.Chunks {
+				source.Snippets = append(source.Snippets, chunk.Text)
+			}
+		}
+		if len(source.Snippets) == 0 {
+			source.Snippets = []string{truncateSnippet(item.Content.Body, ragSnippetMaxLen)}
+		}
+		sources = append(sources, source)
+	}
+	if len(sources) == 0 {
+		return nil, ErrNoRAGContext
+	}
+
+	answer, err := r.completer.GenerateChatCompletion(ctx, r.messages(question, sources))
+	if err != nil {
+		return nil, fmt.Errorf("generate answer with %s: %w", r.completer.Name(), err)
+	}
+
+	result := &RAGAnswer{Answer: strings.TrimSpace(answer), SourceIDs: []int64{}, Sources: sources}
+	for _, m := range ragCitation.FindAllStringSubmatch(answer, -1) {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 || n > len(sources) {
+			continue
+		}
+		sources[n-1].Cited = true
+	}
+	for _, s := range sources {
+		if s.Cited {
+			result.SourceIDs = append(result.SourceIDs, s.ContentID)
+		}
+	}
+	return result, nil
+}
+
+// messages formats the prompt. A prompt with a {{CONTEXT}} placeholder is sent
+// as a single user message; otherwise it is the system message and the sources
+// and question follow as the user message.
+func (r *RAGService) messages(question string, sources []RAGSource) []ChatMessage {
+	var b strings.Builder
+	for i, s := range sources {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "[%d] %s\n%s", i+1, s.Title, strings.Join(s.Snippets, "\n...\n"))
+	}
+	sourceText := b.String()
+
+	if strings.Contains(r.prompt, "{{CONTEXT}}") {
+		prompt := strings.ReplaceAll(r.prompt, "{{CONTEXT}}", sourceText)
+		prompt = strings.ReplaceAll(prompt, "{{QUESTION}}", question)
+		return []ChatMessage{{Role: ChatMessageRoleUser, Content: prompt}}
+	}
+	return []ChatMessage{
+		{Role: ChatMessageRoleSystem, Content: r.prompt},
+		{Role: ChatMessageRoleUser, Content: "Sources:\n" + sourceText + "\n\nQuestion: " + question},
+	}
+}
+
+// truncateSnippet shortens s to at most maxLen bytes, cutting at a space where
+// possible.
+func truncateSnippet(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	cut := s[:maxLen]
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "..."
+}
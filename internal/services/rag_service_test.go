@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+// recordingCompleter returns a fixed answer and records the messages it was sent.
+type recordingCompleter struct {
+	services.CompletionService
+	answer   string
+	messages []services.ChatMessage
+}
+
+func (c *recordingCompleter) GenerateChatCompletion(ctx context.Context, messages []services.ChatMessage) (string, error) {
+	c.messages = messages
+	return c.answer, nil
+}
+
+func (c *recordingCompleter) Name() string { return "test" }
+
+func TestRAGService_Query(t *testing.T) {
+	contents := &memContentStore{contents: map[int64]*models.Content{
+		1: {ID: 1, Title: "Backups", Visibility: store.VisibilityShared},
+		2: {ID: 2, Title: "Restores", Visibility: store.VisibilityShared},
+	}}
+	vs := explainVectorStore{results: []models.SearchResult{
+		{ContentID: 1, Distance: 0.5, RelevanceScore: 1 / 1.5, ChunkText: "Backups run nightly at 02:00."},
+		{ContentID: 2, Distance: 1, RelevanceScore: 0.5, ChunkText: "Restores need the on-call key."},
+	}}
+	search := services.NewSearchService(contents, nil, vs, historyEmbeddingService{}, nopSearchHistory{})
+	search.SetRecordHistory(false)
+	completer := &recordingCompleter{answer: " Restoring needs the on-call key [2]. "}
+	rag := services.NewRAGService(search, completer, "")
+
+	answer, err := rag.Query(context.Background(), "How do I restore?", services.RAGParams{})
+	require.NoError(t, err)
+	assert.Equal(t, "Restoring needs the on-call key [2].", answer.Answer)
+	assert.Equal(t, []int64{2}, answer.SourceIDs)
+	require.Len(t, answer.Sources, 2)
+	assert.Equal(t, []string{"Backups run nightly at 02:00."}, answer.Sources[0].Snippets)
+	assert.False(t, answer.Sources[0].Cited)
+	assert.True(t, answer.Sources[1].Cited)
+
+	require.Len(t, completer.messages, 1, "the default prompt has a {{CONTEXT}} placeholder")
+	prompt := completer.messages[0].Content
+	assert.Contains(t, prompt, "[2] Restores\nRestores need the on-call key.")
+	assert.Contains(t, prompt, "Question: How do I restore?")
+
+	rag = services.NewRAGService(search, completer, "You answer questions about operations.")
+	_, err = rag.Query(context.Background(), "How do I restore?", services.RAGParams{})
+	require.NoError(t, err)
+	require.Len(t, completer.messages, 2, "a prompt without placeholders is the system message")
+	assert.Equal(t, services.ChatMessageRoleSystem, completer.messages[0].Role)
+	assert.Contains(t, completer.messages[1].Content, "[1] Backups")
+
+	empty := services.NewSearchService(contents, nil, explainVectorStore{}, historyEmbeddingService{}, nopSearchHistory{})
+	empty.SetRecordHistory(false)
+	_, err = services.NewRAGService(empty, completer, "").Query(context.Background(), "anything", services.RAGParams{})
+	assert.ErrorIs(t, err, services.ErrNoRAGContext)
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hibiken/asynq"
+	"mimir/internal/config"
+	"mimir/internal/models"
+	"mimir/internal/store"
+	"mimir/internal/tasks"
+)
+
+// ReindexService re-embeds all content and tracks aggregate progress in a reindex run.
+type ReindexService struct {
+	contents     store.ContentStore
+	runs         store.ReindexRunStore
+	jobs         store.JobClient
+	inputVersion string // Current embedding input template version; empty skips the check
+}
+
+// NewReindexService creates a new ReindexService.
+func NewReindexService(cs store.ContentStore, rs store.ReindexRunStore, jc store.JobClient) *ReindexService {
+	return &ReindexService{contents: cs, runs: rs, jobs: jc}
+}
+
+// SetInputVersion makes stale reindexing include content embedded from another
+// embedding input template version than version.
+func (s *ReindexService) SetInputVersion(version string) {
+	s.inputVersion = version
+}
+
+// StartReindex creates a reindex run and enqueues one embedding job per item.
+// With staleOnly, only content whose body changed since it was embedded, or
+// that was embedded from another input template (see SetInputVersion), is
+// included; otherwise every content item is. Items that cannot be enqueued are
+// counted as failed immediately, so the run still finishes.
+func (s *ReindexService) StartReindex(ctx context.Context, staleOnly bool) (*models.ReindexRun, error) {
+	if s.jobs == nil {
+		return nil, fmt.Errorf("job client is not initialized")
+	}
+
+	ids, err := s.contentIDs(ctx, staleOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := s.runs.CreateReindexRun(ctx, len(ids))
+	if err != nil {
+		return nil, fmt.Errorf("create reindex run: %w", err)
+	}
+
+	for _, id := range ids {
+		if err := s.jobs.EnqueueReindexEmbeddingJob(ctx, id, run.ID); err != nil {
+			log.Printf("ERROR: Reindex run %d: %v", run.ID, err)
+			if errRecord := s.runs.RecordReindexOutcome(ctx, run.ID, true); errRecord != nil {
+				log.Printf("ERROR: Reindex run %d: failed to record enqueue failure for content %d: %v", run.ID, id, errRecord)
+			}
+		}
+	}
+
+	return s.runs.GetReindexRun(ctx, run.ID)
+}
+
+// contentIDs lists the content to re-embed.
+func (s *ReindexService) contentIDs(ctx context.Context, staleOnly bool) ([]int64, error) {
+	var ids []int64
+	if staleOnly {
+		stale, err := s.contents.ListStaleEmbeddings(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list stale embeddings for reindex: %w", err)
+		}
+		seen := make(map[int64]bool, len(stale))
+		for _, c := range stale {
+			ids = append(ids, c.ID)
+			seen[c.ID] = true
+		}
+		if s.inputVersion == "" {
+			return ids, nil
+		}
+		outdated, err := s.contents.ListOutdatedInputVersion(ctx, s.inputVersion, EmbeddingInputVersion(DefaultEmbeddingInputTemplate))
+		if err != nil {
+			return nil, fmt.Errorf("list content with outdated embedding input for reindex: %w", err)
+		}
+		for _, id := range outdated {
+			if !seen[id] {
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+
+	for offset := 0; ; offset += config.DefaultMaxPageSize {
+		page, err := s.contents.ListContent(ctx, config.DefaultMaxPageSize, offset, "c.id", "ASC", nil, nil, "", "")
+		if err != nil {
+			return nil, fmt.Errorf("list content for reindex: %w", err)
+		}
+		for _, c := range page {
+			ids = append(ids, c.ID)
+		}
+		if len(page) < config.DefaultMaxPageSize {
+			break
+		}
+	}
+	return ids, nil
+}
+
+// GetRun returns the current progress of a reindex run.
+func (s *ReindexService) GetRun(ctx context.Context, id int64) (*models.ReindexRun, error) {
+	run, err := s.runs.GetReindexRun(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get reindex run %d: %w", id, err)
+	}
+	return run, nil
+}
+
+// RecordEmbeddingOutcome is called by the embedding worker (see Middleware) when
+// a job that carries a reindex_run_id completes (jobErr == nil) or permanently fails.
+func (s *ReindexService) RecordEmbeddingOutcome(ctx context.Context, runID int64, jobErr error) error {
+	if err := s.runs.RecordReindexOutcome(ctx, runID, jobErr != nil); err != nil {
+		return fmt.Errorf("record outcome for reindex run %d: %w", runID, err)
+	}
+	return nil
+}
+
+// Middleware records the outcome of embedding tasks carrying a reindex_run_id
+// in their run: a task that succeeds counts as completed, one that fails counts
+// as failed once it will not be retried (see TaskExhausted). Failing to record
+// is logged and does not fail the task. Other tasks pass through.
+func (s *ReindexService) Middleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+		if t.Type() != tasks.TypeEmbeddingJob {
+			return next.ProcessTask(ctx, t)
+		}
+		var payload struct {
+			ReindexRunID int64 `json:"reindex_run_id"`
+		}
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil || payload.ReindexRunID == 0 {
+			return next.ProcessTask(ctx, t)
+		}
+
+		err := next.ProcessTask(ctx, t)
+		if err == nil || TaskExhausted(ctx, err) {
+			if recErr := s.RecordEmbeddingOutcome(ctx, payload.ReindexRunID, err); recErr != nil {
+				log.Printf("ERROR: %v", recErr)
+			}
+		}
+		return err
+	})
+}
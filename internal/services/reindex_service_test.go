@@ -0,0 +1,45 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+
+	"mimir/internal/services"
+	"mimir/internal/store"
+	"mimir/internal/tasks"
+)
+
+type outcomeRunStore struct {
+	store.ReindexRunStore
+	outcomes []bool // failed flag per recorded outcome
+}
+
+func (s *outcomeRunStore) RecordReindexOutcome(ctx context.Context, id int64, failed bool) error {
+	s.outcomes = append(s.outcomes, failed)
+	return nil
+}
+
+func TestReindexService_MiddlewareRecordsOutcomes(t *testing.T) {
+	runs := &outcomeRunStore{}
+	svc := services.NewReindexService(nil, runs, nil)
+	var handlerErr error
+	h := svc.Middleware(asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error { return handlerErr }))
+	ctx := context.Background()
+	reindexTask := asynq.NewTask(tasks.TypeEmbeddingJob, []byte(`{"content_id":1,"reindex_run_id":4}`))
+
+	assert.NoError(t, h.ProcessTask(ctx, reindexTask))
+	assert.NoError(t, h.ProcessTask(ctx, asynq.NewTask(tasks.TypeEmbeddingJob, []byte(`{"content_id":1}`))))
+
+	handlerErr = errors.New("provider down")
+	assert.Error(t, h.ProcessTask(ctx, reindexTask), "retried failures are not counted yet")
+
+	handlerErr = fmt.Errorf("bad input: %w", asynq.SkipRetry)
+	assert.Error(t, h.ProcessTask(ctx, reindexTask))
+
+	assert.Equal(t, []bool{false, true}, runs.outcomes)
+}
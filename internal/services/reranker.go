@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// Reranker reorders semantic search candidates for a query.
+// Implementations must return a permutation (or subset) of the given items;
+// the caller is responsible for trimming the result to the requested limit.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, items []SearchResultItem) ([]SearchResultItem, error)
+}
+
+// LexicalReranker reorders candidates by the fraction of query terms that
+// appear in the content title and body. Ties keep the vector store order.
+type LexicalReranker struct{}
+
+// NewLexicalReranker creates a reranker based on query term overlap.
+func NewLexicalReranker() *LexicalReranker {
+	return &LexicalReranker{}
+}
+
+// Rerank sorts items by query term overlap, highest first.
+func (r *LexicalReranker) Rerank(ctx context.Context, query string, items []SearchResultItem) ([]SearchResultItem, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 || len(items) < 2 {
+		return items, nil
+	}
+
+	overlap := make(map[int64]float64, len(items))
+	for _, item := range items {
+		if item.Content == nil {
+			continue
+		}
+		text := strings.ToLower(item.Content.Title + " " + item.Content.Body)
+		matched := 0
+		for _, term := range terms {
+			if strings.Contains(text, term) {
+				matched++
+			}
+		}
+		overlap[item.Content.ID] = float64(matched) / float64(len(terms))
+	}
+
+	reranked := make([]SearchResultItem, len(items))
+	copy(reranked, items)
+	sort.SliceStable(reranked, func(i, j int) bool {
+		return itemOverlap(overlap, reranked[i]) > itemOverlap(overlap, reranked[j])
+	})
+	return reranked, nil
+}
+
+func itemOverlap(overlap map[int64]float64, item SearchResultItem) float64 {
+	if item.Content == nil {
+		return 0
+	}
+	return overlap[item.Content.ID]
+}
+
+// Ensure LexicalReranker implements Reranker
+var _ Reranker = (*LexicalReranker)(nil)
@@ -0,0 +1,29 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLexicalReranker_Rerank(t *testing.T) {
+	items := []services.SearchResultItem{
+		{Content: &models.Content{ID: 1, Title: "Cooking", Body: "pasta recipes"}, Score: 0.1},
+		{Content: &models.Content{ID: 2, Title: "Go tips", Body: "goroutines and channels"}, Score: 0.2},
+		{Content: &models.Content{ID: 3, Title: "Channels", Body: "tv listings"}, Score: 0.3},
+	}
+
+	reranked, err := services.NewLexicalReranker().Rerank(context.Background(), "goroutines channels", items)
+	require.NoError(t, err)
+	require.Len(t, reranked, 3)
+
+	assert.Equal(t, int64(2), reranked[0].Content.ID)
+	assert.Equal(t, int64(3), reranked[1].Content.ID)
+	assert.Equal(t, int64(1), reranked[2].Content.ID)
+	assert.Equal(t, int64(1), items[0].Content.ID, "input slice must not be reordered")
+}
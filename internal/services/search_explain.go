@@ -0,0 +1,99 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+
+	"mimir/internal/models"
+)
+
+// maxExplainChunks caps the matching chunks reported per semantic search result.
+const maxExplainChunks = 3
+
+// SearchExplanation tells a client why a search result matched. Semantic
+// search fills Chunks; keyword search fills MatchedTerms.
+type SearchExplanation struct {
+	Chunks       []ChunkMatch `json:"chunks,omitempty"`        // Best matching chunks of the content, closest first
+	MatchedTerms []string     `json:"matched_terms,omitempty"` // Query terms found in the title or body
+}
+
+// ChunkMatch is one chunk of a semantic search result and its own score.
+// Scores are from the vector store, before any boost or reranking.
+type ChunkMatch struct {
+	Text     string  `json:"text"`
+	Score    float64 `json:"score"`    // Similarity, higher is better (see store.Similarity)
+	Distance float64 `json:"distance"` // Raw vector distance, lower is better
+}
+
+// chunkMatchesByContent groups vector search results by content ID, keeping
+// up to maxExplainChunks per content in the store's (closest first) order.
+func chunkMatchesByContent(results []models.SearchResult) map[int64][]ChunkMatch {
+	matches := make(map[int64][]ChunkMatch)
+	for _, res := range results {
+		if len(matches[res.ContentID]) >= maxExplainChunks {
+			continue
+		}
+		matches[res.ContentID] = append(matches[res.ContentID], ChunkMatch{
+			Text:     res.ChunkText,
+			Score:    res.RelevanceScore,
+			Distance: res.Distance,
+		})
+	}
+	return matches
+}
+
+// keywordStopWords are common English words that Postgres full-text search
+// drops from queries, so they are never reported as matched.
+var keywordStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "in": true, "is": true, "it": true, "of": true,
+	"on": true, "or": true, "that": true, "the": true, "to": true, "with": true,
+}
+
+// MatchedTerms returns the words of a keyword query that occur in any of
+// texts, in query order. Like full-text search it ignores case, stop words and
+// common English suffixes ("indexing" matches "indexes"), but it approximates
+// Postgres stemming rather than reproducing it.
+func MatchedTerms(query string, texts ...string) []string {
+	words := make(map[string]bool)
+	for _, text := range texts {
+		for _, w := range splitWords(text) {
+			words[roughStem(w)] = true
+		}
+	}
+
+	var matched []string
+	seen := make(map[string]bool)
+	for _, term := range splitWords(query) {
+		if keywordStopWords[term] || seen[term] {
+			continue
+		}
+		seen[term] = true
+		if words[roughStem(term)] {
+			matched = append(matched, term)
+		}
+	}
+	return matched
+}
+
+// splitWords lowercases s and splits it into runs of letters and digits.
+func splitWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// roughStem strips one common English suffix from a lowercase word, so that
+// for example "note", "notes" and "noted" share a stem.
+func roughStem(w string) string {
+	for _, suffix := range []string{"ing", "es", "ed", "ly", "e", "s"} {
+		if !strings.HasSuffix(w, suffix) || len(w)-len(suffix) < 3 {
+			continue
+		}
+		if suffix == "s" && strings.HasSuffix(w, "ss") {
+			return w // "class", not "clas"
+		}
+		return strings.TrimSuffix(w, suffix)
+	}
+	return w
+}
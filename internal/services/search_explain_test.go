@@ -0,0 +1,62 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+func TestMatchedTerms(t *testing.T) {
+	terms := services.MatchedTerms("The indexing of Postgres classes and notes",
+		"Postgres indexes", "A note on CLASSES")
+	assert.Equal(t, []string{"indexing", "postgres", "classes", "notes"}, terms)
+
+	assert.Empty(t, services.MatchedTerms("kubernetes", "Postgres indexes"))
+}
+
+// explainVectorStore returns fixed chunk-level results, several per content.
+type explainVectorStore struct {
+	store.VectorStore
+	results []models.SearchResult
+}
+
+func (v explainVectorStore) SimilaritySearch(ctx context.Context, queryVector pgvector.Vector, k int, filterMetadata map[string]interface{}) ([]models.SearchResult, error) {
+	return v.results, nil
+}
+
+func TestSemanticSearch_Explain(t *testing.T) {
+	contents := &memContentStore{contents: map[int64]*models.Content{
+		1: {ID: 1, Title: "one", Visibility: store.VisibilityShared},
+		2: {ID: 2, Title: "two", Visibility: store.VisibilityShared},
+	}}
+	vs := explainVectorStore{results: []models.SearchResult{
+		{ContentID: 1, Distance: 0.5, RelevanceScore: 1 / 1.5, ChunkText: "best chunk of one"},
+		{ContentID: 2, Distance: 1, RelevanceScore: 0.5, ChunkText: "chunk of two"},
+		{ContentID: 1, Distance: 3, RelevanceScore: 0.25, ChunkText: "weaker chunk of one"},
+	}}
+	svc := services.NewSearchService(contents, nil, vs, historyEmbeddingService{}, nopSearchHistory{})
+	svc.SetRecordHistory(false)
+
+	results, err := svc.SemanticSearch(context.Background(), services.SemanticSearchParams{Query: "one", Limit: 10, Explain: true})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	require.NotNil(t, results[0].Explanation)
+	assert.Equal(t, []services.ChunkMatch{
+		{Text: "best chunk of one", Score: 1 / 1.5, Distance: 0.5},
+		{Text: "weaker chunk of one", Score: 0.25, Distance: 3},
+	}, results[0].Explanation.Chunks)
+	require.NotNil(t, results[1].Explanation)
+	assert.Len(t, results[1].Explanation.Chunks, 1)
+
+	results, err = svc.SemanticSearch(context.Background(), services.SemanticSearchParams{Query: "one", Limit: 10})
+	require.NoError(t, err)
+	assert.Nil(t, results[0].Explanation, "explanations are only added on request")
+}
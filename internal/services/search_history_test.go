@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+// countingSearchHistory counts history writes.
+type countingSearchHistory struct {
+	nopSearchHistory
+	queries, results int
+}
+
+func (h *countingSearchHistory) RecordSearchQuery(ctx context.Context, ownerID, query string, resultsCount int) (*models.SearchQuery, error) {
+	h.queries++
+	return &models.SearchQuery{ID: 1, Query: query}, nil
+}
+
+func (h *countingSearchHistory) RecordSearchResults(ctx context.Context, queryID int64, results []models.SearchResult) error {
+	h.results++
+	return nil
+}
+
+type historyEmbeddingService struct{ store.EmbeddingService }
+
+func (historyEmbeddingService) GenerateEmbedding(ctx context.Context, text string) (pgvector.Vector, error) {
+	return pgvector.NewVector([]float32{1, 0}), nil
+}
+
+type historyVectorStore struct{ store.VectorStore }
+
+func (historyVectorStore) SimilaritySearch(ctx context.Context, queryVector pgvector.Vector, k int, filterMetadata map[string]interface{}) ([]models.SearchResult, error) {
+	return nil, nil
+}
+
+func TestSearchHistoryRecording(t *testing.T) {
+	ks := &recordingKeywordSearcher{matches: []store.KeywordMatch{{Content: &models.Content{ID: 1}, Rank: 0.5}}}
+	newService := func(history *countingSearchHistory) *services.SearchService {
+		return services.NewSearchService(nil, ks, historyVectorStore{}, historyEmbeddingService{}, history)
+	}
+	search := func(t *testing.T, svc *services.SearchService) {
+		t.Helper()
+		_, err := svc.KeywordSearch(context.Background(), services.KeywordSearchParams{Query: "go"})
+		require.NoError(t, err)
+		_, err = svc.SemanticSearch(context.Background(), services.SemanticSearchParams{Query: "go"})
+		require.NoError(t, err)
+	}
+
+	enabled := &countingSearchHistory{}
+	search(t, newService(enabled))
+	assert.Equal(t, 2, enabled.queries, "history is recorded by default")
+	assert.Equal(t, 2, enabled.results)
+
+	disabled := &countingSearchHistory{}
+	svc := newService(disabled)
+	svc.SetRecordHistory(false)
+	search(t, svc)
+	assert.Zero(t, disabled.queries, "no queries may be recorded when history is disabled")
+	assert.Zero(t, disabled.results, "no results may be recorded when history is disabled")
+}
@@ -0,0 +1,31 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+func TestFindRelatedContent_SourceErrors(t *testing.T) {
+	contents := &memContentStore{contents: map[int64]*models.Content{
+		1: {ID: 1, OwnerID: store.DefaultOwnerID},
+		2: {ID: 2, OwnerID: "bob"},
+	}}
+	svc := services.NewSearchService(contents, nil, &filterRecordingVectorStore{}, historyEmbeddingService{}, nopSearchHistory{})
+	ctx := context.Background()
+
+	_, err := svc.FindRelatedContent(ctx, services.RelatedContentParams{SourceContentID: 1})
+	assert.True(t, errors.Is(err, services.ErrNotEmbedded), "unembedded source: %v", err)
+
+	_, err = svc.FindRelatedContent(ctx, services.RelatedContentParams{SourceContentID: 2})
+	assert.True(t, errors.Is(err, store.ErrNotFound), "private content of another owner is not found: %v", err)
+
+	_, err = svc.FindRelatedContent(ctx, services.RelatedContentParams{SourceContentID: 3})
+	assert.True(t, errors.Is(err, store.ErrNotFound), "missing source: %v", err)
+}
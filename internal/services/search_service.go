@@ -4,20 +4,23 @@ import (
 	"context" // Add context import
 	"fmt"
 	"log"
+	"time"
 
+	"mimir/internal/config"
 	"mimir/internal/models"
 	"mimir/internal/store"
+
+	"github.com/pgvector/pgvector-go"
 )
 import "errors" // Add errors import // Keep this one
 
 // SearchResultItem represents a single search result, potentially including chunk details.
 type SearchResultItem struct {
-	Content       *models.Content
-	Score         float64
-	// Removed ChunkText and ChunkMetadata as they are not available
-	// from the current vector.SimilaritySearch return type.
-	// ChunkText     string                 // Text of the specific chunk that matched
-	// ChunkMetadata map[string]interface{} // Metadata associated with the matched chunk
+	Content     *models.Content
+	Score       float64            // Distance from the query (lower is closer); see Similarity. HybridSearch sets the fused score instead
+	Explanation *SearchExplanation // Matching chunks; set when SemanticSearchParams.Explain is
+
+	metric string // Distance metric of Score (store.DistanceMetric*); empty is L2
 }
 
 type SearchService struct {
@@ -26,6 +29,22 @@ type SearchService struct {
 	vector          store.VectorStore
 	embedding       store.EmbeddingService
 	searchHistory   store.SearchHistoryStore
+
+	reranker         Reranker // Optional; nil disables reranking
+	rerankCandidates int      // Candidates fetched from the vector store when reranking
+
+	defaults config.DefaultsConfig // Default and maximum result counts
+
+	collections store.CollectionStore // Optional; required for collection-scoped search
+	tags        store.TagStore        // Optional; required for tag-filtered semantic search
+
+	booster *ScoreBooster // Optional; nil uses the default half-life and no source weights
+
+	recordHistory bool // Record queries and results in search history; on by default
+
+	hybridKeywordWeight, hybridSemanticWeight float64 // Default HybridSearch weights; 0 means 1
+
+	metric string // Distance metric of the vector store (store.DistanceMetric*); empty is L2
 }
 
 func NewSearchService(cs store.ContentStore, ks store.KeywordSearcher, vs store.VectorStore, es store.EmbeddingService, sh store.SearchHistoryStore) *SearchService {
@@ -35,28 +54,134 @@ func NewSearchService(cs store.ContentStore, ks store.KeywordSearcher, vs store.
 		vector:          vs,
 		embedding:       es,
 		searchHistory:   sh,
+		recordHistory:   true,
 	}
 }
 
+// SetReranker enables reranking of semantic search results.
+// candidates is the number of results requested from the vector store before
+// reranking; larger values improve recall at the cost of latency, since every
+// candidate is fetched from the primary store and scored by the reranker.
+func (s *SearchService) SetReranker(r Reranker, candidates int) {
+	s.reranker = r
+	s.rerankCandidates = candidates
+}
+
+// SetDefaults sets the default and maximum result counts used when a search
+// does not specify a limit or requests too many results.
+func (s *SearchService) SetDefaults(d config.DefaultsConfig) {
+	s.defaults = d
+}
+
+// SetDistanceMetric tells the service which distance the vector store
+// searches with (database.vector.metric), so scores convert to similarities
+// correctly.
+func (s *SearchService) SetDistanceMetric(metric string) {
+	s.metric = metric
+}
+
+// SetCollectionStore enables restricting semantic search to a collection.
+func (s *SearchService) SetCollectionStore(cs store.CollectionStore) {
+	s.collections = cs
+}
+
+// SetTagStore enables filtering semantic search results by tag.
+func (s *SearchService) SetTagStore(ts store.TagStore) {
+	s.tags = ts
+}
+
+// SetBooster configures score boosting requested through SemanticSearchParams.Boost.
+func (s *SearchService) SetBooster(b *ScoreBooster) {
+	s.booster = b
+}
+
+// SetRecordHistory enables or disables recording of keyword and semantic
+// searches in search history. When disabled, searches make no history writes.
+func (s *SearchService) SetRecordHistory(enabled bool) {
+	s.recordHistory = enabled
+}
+
 // --- Parameter Structs ---
 
 type KeywordSearchParams struct {
 	Query      string
 	FilterTags []string
-	Limit      int
-	Offset     int
+	Limit      int    // Page size; 0 returns all matches
+	Offset     int    // Matches to skip, for paging
+	SortBy     string // relevance (default), created_at or modified_at
+	Explain    bool   // Report the query terms each result matched
+}
+
+// ParseKeywordSort validates a keyword search sort order, defaulting an empty
+// value to relevance.
+func ParseKeywordSort(sortBy string) (string, error) {
+	switch sortBy {
+	case "":
+		return store.KeywordSortRelevance, nil
+	case store.KeywordSortRelevance, store.KeywordSortCreatedAt, store.KeywordSortModifiedAt:
+		return sortBy, nil
+	}
+	return "", fmt.Errorf("invalid sort_by %q: must be one of %s, %s, %s",
+		sortBy, store.KeywordSortRelevance, store.KeywordSortCreatedAt, store.KeywordSortModifiedAt)
 }
 
 type SemanticSearchParams struct {
-	Query      string
-	Limit      int
-	FilterTags []string
+	Query        string
+	Limit        int
+	FilterTags   []string
+	CollectionID int64   // Optional; 0 searches all content
+	Boost        string  // Optional score boost: BoostRecency or BoostSource
+	Explain      bool    // Report each result's best matching chunks and their scores
+	Diversity    float64 // Optional MMR trade-off in [0, 1]: 0 ranks by relevance only, higher favors results unlike those ranked above
 }
 
+// ErrNotEmbedded is returned by FindRelatedContent when the source content has
+// no embedding yet (still queued, failed, or cleared by an edit).
+var ErrNotEmbedded = errors.New("content has not been embedded")
+
 type RelatedContentParams struct {
 	SourceContentID int64
 	Limit           int
 	FilterTags      []string
+	MinScore        float64 // Minimum similarity (see SearchResultItem.Similarity); 0 disables the threshold
+}
+
+// relatedOverfetchFactor multiplies the limit when a similarity threshold is set,
+// so chunk duplicates and the source item do not crowd out related content.
+const relatedOverfetchFactor = 3
+
+// Similarity returns the item's score as a similarity, higher is better: in
+// (0, 1] for the L2 metric and 1-distance, the cosine similarity, for cosine
+// (see store.Similarity).
+func (r SearchResultItem) Similarity() float64 {
+	return store.Similarity(r.metric, r.Score)
+}
+
+// ScoreInfo tells clients how to interpret semantic search scores.
+type ScoreInfo struct {
+	Metric   string `json:"metric"`   // Distance metric of the vector store
+	Score    string `json:"score"`    // Meaning of the normalized score
+	Distance string `json:"distance"` // Meaning of the raw distance
+}
+
+// ScoreInfo describes the scores returned by semantic search.
+func (s *SearchService) ScoreInfo() ScoreInfo {
+	metric, err := store.ParseDistanceMetric(s.metric)
+	if err != nil {
+		metric = store.DistanceMetricL2
+	}
+	score := "similarity in (0, 1], computed as 1/(1+distance); higher is better"
+	switch metric {
+	case store.DistanceMetricCosine:
+		score = "cosine similarity in [-1, 1], computed as 1-distance; higher is better"
+	case store.DistanceMetricInnerProduct:
+		score = "inner product, computed as -distance; higher is better"
+	}
+	return ScoreInfo{
+		Metric:   metric,
+		Score:    score,
+		Distance: "raw " + metric + " distance (after any boost); lower is better",
+	}
 }
 
 // --- Service Methods ---
@@ -65,29 +190,41 @@ func (s *SearchService) KeywordSearch(ctx context.Context, params KeywordSearchP
 	if s.keywordSearcher == nil {
 		return nil, fmt.Errorf("keyword searcher is not initialized")
 	}
-
-	// Record the search query attempt
-	searchQueryRecord, errRecord := s.searchHistory.RecordSearchQuery(ctx, params.Query, 0) // Record with 0 results initially
-	if errRecord != nil {
-		// Log the error but don't fail the search itself
-		log.Printf("WARN: Failed to record keyword search query '%s': %v", params.Query, errRecord)
+	sortBy, err := ParseKeywordSort(params.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	if params.Limit < 0 || params.Offset < 0 {
+		return nil, fmt.Errorf("keyword search limit and offset must not be negative")
 	}
 
-	if params.Limit > 0 || params.Offset > 0 {
-		log.Printf("WARN: KeywordSearch Limit/Offset parameters are currently ignored.")
+	// Record the search query attempt
+	var searchQueryRecord *models.SearchQuery
+	var errRecord error
+	if s.recordHistory {
+		searchQueryRecord, errRecord = s.searchHistory.RecordSearchQuery(ctx, OwnerFromContext(ctx), params.Query, 0) // Record with 0 results initially
+		if errRecord != nil {
+			// Log the error but don't fail the search itself
+			log.Printf("WARN: Failed to record keyword search query '%s': %v", params.Query, errRecord)
+		}
 	}
 
-	results, err := s.keywordSearcher.KeywordSearchContent(ctx, params.Query, params.FilterTags)
+	results, err := s.keywordSearcher.KeywordSearchContent(ctx, params.Query, params.FilterTags, sortBy, OwnerFromContext(ctx), params.Limit, params.Offset)
 	if err != nil {
 		return nil, fmt.Errorf("keyword search failed: %w", err)
 	}
 
 	serviceResults := make([]KeywordResultItem, len(results))
 	for i, storeResult := range results {
-		if storeResult != nil {
+		if storeResult.Content != nil {
 			serviceResults[i] = KeywordResultItem{
-				Content: storeResult,
-				Score:   0.0,
+				Content: storeResult.Content,
+				Score:   storeResult.Rank,
+			}
+			if params.Explain {
+				serviceResults[i].Explanation = &SearchExplanation{
+					MatchedTerms: MatchedTerms(params.Query, storeResult.Content.Title, storeResult.Content.Body),
+				}
 			}
 		} else {
 			log.Printf("WARN: KeywordSearch store result or its content was nil at index %d", i)
@@ -101,10 +238,10 @@ func (s *SearchService) KeywordSearch(ctx context.Context, params KeywordSearchP
 		// Prepare results for recording
 		recordedResults := make([]models.SearchResult, len(results))
 		for i, res := range results {
-			if res != nil {
+			if res.Content != nil {
 				recordedResults[i] = models.SearchResult{
-					ContentID:      res.ID,
-					RelevanceScore: 0.0,
+					ContentID:      res.Content.ID,
+					RelevanceScore: res.Rank,
 					Rank:           i + 1,
 				}
 			}
@@ -116,26 +253,50 @@ func (s *SearchService) KeywordSearch(ctx context.Context, params KeywordSearchP
 		}
 	}
 
+	ids := make([]int64, 0, len(results))
+	for _, res := range results {
+		if res.Content != nil {
+			ids = append(ids, res.Content.ID)
+		}
+	}
+	s.touchAccessed(ctx, ids)
+
 	return serviceResults, nil
 }
 
 // SemanticSearch performs vector similarity search based on the query text.
 func (s *SearchService) SemanticSearch(ctx context.Context, params SemanticSearchParams) ([]SearchResultItem, error) {
 	if s.vector == nil {
-		return nil, fmt.Errorf("vector store is not initialized")
+		return nil, fmt.Errorf("vector store is not initialized: %w", ErrEmbeddingDisabled)
 	}
 	if s.embedding == nil {
 		return nil, fmt.Errorf("embedding service is not initialized")
 	}
-	if params.Limit <= 0 {
-		params.Limit = 10
+	params.Limit = s.defaults.SearchLimitFor(params.Limit)
+	if _, err := ParseBoost(params.Boost); err != nil {
+		return nil, err
+	}
+	if err := validateDiversity(params.Diversity); err != nil {
+		return nil, err
+	}
+
+	filterMetadata, ok, err := s.contentFilter(ctx, params.CollectionID, params.FilterTags)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []SearchResultItem{}, nil
 	}
 
 	// Record the search query attempt
-	searchQueryRecord, errRecord := s.searchHistory.RecordSearchQuery(ctx, params.Query, 0) // Record with 0 results initially
-	if errRecord != nil {
-		// Log the error but don't fail the search itself
-		log.Printf("WARN: Failed to record semantic search query '%s': %v", params.Query, errRecord)
+	var searchQueryRecord *models.SearchQuery
+	var errRecord error
+	if s.recordHistory {
+		searchQueryRecord, errRecord = s.searchHistory.RecordSearchQuery(ctx, OwnerFromContext(ctx), params.Query, 0) // Record with 0 results initially
+		if errRecord != nil {
+			// Log the error but don't fail the search itself
+			log.Printf("WARN: Failed to record semantic search query '%s': %v", params.Query, errRecord)
+		}
 	}
 
 	queryVector, err := s.embedding.GenerateEmbedding(ctx, params.Query)
@@ -143,23 +304,98 @@ func (s *SearchService) SemanticSearch(ctx context.Context, params SemanticSearc
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
-	filterMetadata := make(map[string]interface{})
-	if len(params.FilterTags) > 0 {
-		log.Printf("WARN: SemanticSearch tag filtering is not yet implemented in the vector query.")
+	results, err := s.searchByVector(ctx, params.Query, queryVector, params.Limit, filterMetadata, params.Boost, params.Explain, params.Diversity)
+	if err != nil {
+		return nil, err
+	}
+
+	// If recording was successful, update the count and record results
+	if errRecord == nil && searchQueryRecord != nil {
+		searchQueryRecord.ResultsCount = len(results) // Update count based on actual results
+
+		// Prepare results for recording
+		recordedResults := make([]models.SearchResult, len(results))
+		for i, res := range results {
+			if res.Content != nil {
+				recordedResults[i] = models.SearchResult{
+					ContentID:      res.Content.ID,
+					RelevanceScore: res.Score,
+					Rank:           i + 1,
+				}
+			}
+		}
+
+		errUpdate := s.searchHistory.RecordSearchResults(ctx, searchQueryRecord.ID, recordedResults)
+		if errUpdate != nil {
+			log.Printf("WARN: Failed to record semantic search results for query ID %d: %v", searchQueryRecord.ID, errUpdate)
+		}
+	}
+
+	ids := make([]int64, 0, len(results))
+	for _, res := range results {
+		if res.Content != nil {
+			ids = append(ids, res.Content.ID)
+		}
+	}
+	s.touchAccessed(ctx, ids)
+
+	return results, nil
+}
+
+// touchAccessed records that the content was returned by a search, which the
+// compaction policy uses to find rarely used content. Failures are only logged.
+func (s *SearchService) touchAccessed(ctx context.Context, ids []int64) {
+	if s.contentStore == nil || len(ids) == 0 {
+		return
+	}
+	if err := s.contentStore.TouchContentAccessed(ctx, ids); err != nil {
+		log.Printf("WARN: Failed to record search access: %v", err)
+	}
+}
+
+// searchByVector runs the vector search for an embedded query, resolves the
+// matching content, applies the boost, reranks if configured and trims to limit.
+// With explain, each result lists its matching chunks. A positive diversity
+// picks the limit results from the candidates by Maximal Marginal Relevance.
+func (s *SearchService) searchByVector(ctx context.Context, query string, queryVector pgvector.Vector, limit int, filterMetadata map[string]interface{}, boost string, explain bool, diversity float64) ([]SearchResultItem, error) {
+	// Empty queries embed to a zero vector, which has no meaningful neighbours.
+	if store.IsZeroVector(queryVector) {
+		log.Printf("WARN: Query %q produced a zero embedding vector; returning no results", query)
+		return []SearchResultItem{}, nil
+	}
+
+	// When reranking, fetch a wider candidate pool so the reranker can promote
+	// results that fall outside the vector store's top-k.
+	candidates := limit
+	if s.reranker != nil && s.rerankCandidates > candidates {
+		candidates = s.rerankCandidates
+	}
+	if boost != BoostNone && limit*boostOverfetchFactor > candidates {
+		candidates = limit * boostOverfetchFactor
+	}
+	if diversity > 0 && limit*diversityOverfetchFactor > candidates {
+		candidates = limit * diversityOverfetchFactor
 	}
 
 	// Use the modified SimilaritySearch which returns more details
-	vectorResults, err := s.vector.SimilaritySearch(ctx, queryVector, params.Limit, filterMetadata) // Returns []vector.VectorSearchResultItem
+	vectorResults, err := s.vector.SimilaritySearch(ctx, queryVector, candidates, filterMetadata) // Returns []vector.VectorSearchResultItem
 	if err != nil {
 		return nil, fmt.Errorf("vector similarity search failed: %w", err)
 	}
+	var chunkMatches map[int64][]ChunkMatch
+	if explain {
+		chunkMatches = chunkMatchesByContent(vectorResults)
+	}
+	// Several chunks of one document may match; keep only the best per content
+	// so the reranker scores distinct documents.
+	vectorResults = dedupeByContent(vectorResults)
 
 	contentIDs := make([]int64, len(vectorResults))
 	// Keep track of vector results by content ID for easier lookup later
 	scoresMap := make(map[int64]float64)
 	for i, res := range vectorResults {
 		contentIDs[i] = res.ContentID
-		scoresMap[res.ContentID] = res.RelevanceScore
+		scoresMap[res.ContentID] = res.Distance
 	}
 
 	if len(contentIDs) == 0 {
@@ -179,6 +415,9 @@ func (s *SearchService) SemanticSearch(ctx context.Context, params SemanticSearc
 		}
 	}
 
+	// Embeddings carry no owner, so content the requester may not see is
+	// dropped here; such results can leave fewer than limit items.
+	ownerID := OwnerFromContext(ctx)
 	results := make([]SearchResultItem, 0, len(contents))
 	// Iterate through the vector results to build the final SearchResultItems
 	for _, vecRes := range vectorResults {
@@ -187,37 +426,89 @@ func (s *SearchService) SemanticSearch(ctx context.Context, params SemanticSearc
 			log.Printf("WARN: Content %d found in vector search but not retrieved from primary store.", vecRes.ContentID)
 			continue
 		}
+		if !store.VisibleTo(content, ownerID) {
+			continue
+		}
 
-		results = append(results, SearchResultItem{
+		item := SearchResultItem{
 			Content: content,
-			Score:   vecRes.RelevanceScore,
-			// ChunkText and ChunkMetadata removed
-			// ChunkText: vecRes.ChunkText,
-			// ChunkMetadata: chunkMeta,
-		})
+			Score:   vecRes.Distance,
+			metric:  s.metric,
+		}
+		if explain {
+			item.Explanation = &SearchExplanation{Chunks: chunkMatches[vecRes.ContentID]}
+		}
+		results = append(results, item)
 	}
-	// If recording was successful, update the count and record results
-	if errRecord == nil && searchQueryRecord != nil {
-		searchQueryRecord.ResultsCount = len(results) // Update count based on actual results
 
-		// Prepare results for recording
-		recordedResults := make([]models.SearchResult, len(results))
-		for i, res := range results {
-			if res.Content != nil {
-				recordedResults[i] = models.SearchResult{
-					ContentID:      res.Content.ID,
-					RelevanceScore: res.Score,
-					Rank:           i + 1,
-				}
-			}
+	// Boosting runs before reranking, so boosted scores break reranker ties.
+	if boost != BoostNone {
+		booster := s.booster
+		if booster == nil {
+			booster = NewScoreBooster(0, nil, nil)
+		}
+		results = booster.Boost(ctx, boost, results)
+	}
+
+	if s.reranker != nil {
+		reranked, errRerank := s.reranker.Rerank(ctx, query, results)
+		if errRerank != nil {
+			log.Printf("WARN: Reranking failed for query '%s', keeping vector order: %v", query, errRerank)
+		} else {
+			results = reranked
 		}
+	}
+	if diversity > 0 && len(results) > 1 {
+		results = s.diversify(ctx, results, limit, diversity)
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
 
-		errUpdate := s.searchHistory.RecordSearchResults(ctx, searchQueryRecord.ID, recordedResults)
-		if errUpdate != nil {
-			log.Printf("WARN: Failed to record semantic search results for query ID %d: %v", searchQueryRecord.ID, errUpdate)
+	return results, nil
+}
+
+// BatchSemanticSearch runs several semantic searches, embedding all queries in a
+// single GenerateEmbeddings call. Results are keyed by query; duplicate queries
+// are searched once. Batch searches are not recorded in search history.
+func (s *SearchService) BatchSemanticSearch(ctx context.Context, queries []string, limit int) (map[string][]SearchResultItem, error) {
+	if s.vector == nil {
+		return nil, fmt.Errorf("vector store is not initialized: %w", ErrEmbeddingDisabled)
+	}
+	if s.embedding == nil {
+		return nil, fmt.Errorf("embedding service is not initialized")
+	}
+	limit = s.defaults.SearchLimitFor(limit)
+
+	unique := make([]string, 0, len(queries))
+	seen := make(map[string]bool, len(queries))
+	for _, q := range queries {
+		if q == "" || seen[q] {
+			continue
 		}
+		seen[q] = true
+		unique = append(unique, q)
+	}
+	if len(unique) == 0 {
+		return map[string][]SearchResultItem{}, nil
+	}
+
+	vectors, err := s.embedding.GenerateEmbeddings(ctx, unique)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embeddings: %w", err)
+	}
+	if len(vectors) != len(unique) {
+		return nil, fmt.Errorf("embedding service returned %d vectors for %d queries", len(vectors), len(unique))
 	}
 
+	results := make(map[string][]SearchResultItem, len(unique))
+	for i, q := range unique {
+		items, err := s.searchByVector(ctx, q, vectors[i], limit, map[string]interface{}{}, BoostNone, false, 0)
+		if err != nil {
+			return nil, fmt.Errorf("search for query '%s': %w", q, err)
+		}
+		results[q] = items
+	}
 	return results, nil
 }
 
@@ -226,25 +517,39 @@ func (s *SearchService) ListSearchHistory(ctx context.Context, limit int) ([]*mo
 	if s.searchHistory == nil {
 		return nil, fmt.Errorf("search history store is not initialized")
 	}
-	queries, err := s.searchHistory.ListSearchQueries(ctx, limit)
+	queries, err := s.searchHistory.ListSearchQueries(ctx, OwnerFromContext(ctx), limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list search history from store: %w", err)
 	}
 	return queries, nil
 }
 
+// PruneSearchHistory deletes search queries of all owners, and their recorded
+// results, executed more than olderThan ago. It returns the number of queries deleted.
+func (s *SearchService) PruneSearchHistory(ctx context.Context, olderThan time.Duration) (int64, error) {
+	if s.searchHistory == nil {
+		return 0, fmt.Errorf("search history store is not initialized")
+	}
+	if olderThan <= 0 {
+		return 0, fmt.Errorf("retention period must be positive")
+	}
+	deleted, err := s.searchHistory.DeleteSearchQueriesBefore(ctx, time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, fmt.Errorf("prune search history: %w", err)
+	}
+	return deleted, nil
+}
+
 // FindRelatedContent finds content semantically similar to a given source content item.
 func (s *SearchService) FindRelatedContent(ctx context.Context, params RelatedContentParams) ([]SearchResultItem, error) {
 	if s.contentStore == nil {
 		return nil, fmt.Errorf("content store is not initialized")
 	}
 	if s.vector == nil {
-		return nil, fmt.Errorf("vector store is not initialized")
+		return nil, fmt.Errorf("vector store is not initialized: %w", ErrEmbeddingDisabled)
 	}
 
-	if params.Limit <= 0 {
-		params.Limit = 10
-	}
+	params.Limit = s.defaults.SearchLimitFor(params.Limit)
 
 	sourceContent, err := s.contentStore.GetContent(ctx, params.SourceContentID)
 	if err != nil {
@@ -253,9 +558,12 @@ func (s *SearchService) FindRelatedContent(ctx context.Context, params RelatedCo
 		}
 		return nil, fmt.Errorf("failed to get source content %d: %w", params.SourceContentID, err)
 	}
+	if !store.VisibleTo(sourceContent, OwnerFromContext(ctx)) {
+		return nil, fmt.Errorf("source content with ID %d not found: %w", params.SourceContentID, store.ErrNotFound)
+	}
 
 	if !sourceContent.IsEmbedded || sourceContent.EmbeddingID == nil {
-		return nil, fmt.Errorf("source content %d has not been embedded, cannot find related content", params.SourceContentID)
+		return nil, fmt.Errorf("source content %d cannot be used to find related content: %w", params.SourceContentID, ErrNotEmbedded)
 	}
 
 	sourceEmbeddingEntry, err := s.vector.GetEmbedding(ctx, *sourceContent.EmbeddingID)
@@ -263,30 +571,40 @@ func (s *SearchService) FindRelatedContent(ctx context.Context, params RelatedCo
 		// No longer need to check for ErrOperationNotSupported as pgvector supports GetEmbedding
 		if errors.Is(err, store.ErrNotFound) {
 			log.Printf("ERROR: Embedding ID %s found in primary store for content %d, but not found in vector store.", sourceContent.EmbeddingID.String(), sourceContent.ID)
-			return nil, fmt.Errorf("embedding for source content %d not found in vector store (ID: %s)", params.SourceContentID, sourceContent.EmbeddingID.String())
+			return nil, fmt.Errorf("embedding for source content %d not found in vector store (ID: %s): %w", params.SourceContentID, sourceContent.EmbeddingID.String(), ErrNotEmbedded)
 		}
 		return nil, fmt.Errorf("failed to get source embedding (ID: %s) from vector store: %w", sourceContent.EmbeddingID.String(), err)
 	}
 	sourceVector := sourceEmbeddingEntry.Vector // Changed Embedding to Vector
 
-	filterMetadata := make(map[string]interface{})
-	if len(params.FilterTags) > 0 {
-		log.Printf("WARN: FindRelatedContent tag filtering is not yet implemented in the vector query.")
+	filterMetadata, ok, err := s.contentFilter(ctx, 0, params.FilterTags)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []SearchResultItem{}, nil
 	}
 
-	vectorResults, err := s.vector.SimilaritySearch(ctx, sourceVector, params.Limit+1, filterMetadata)
+	fetchK := params.Limit + 1
+	if params.MinScore > 0 {
+		fetchK = params.Limit*relatedOverfetchFactor + 1
+	}
+	vectorResults, err := s.vector.SimilaritySearch(ctx, sourceVector, fetchK, filterMetadata)
 	if err != nil {
 		return nil, fmt.Errorf("vector similarity search failed for related content: %w", err)
 	}
 
 	contentIDs := make([]int64, 0, len(vectorResults))
 	scoresMap := make(map[int64]float64)
-	for _, res := range vectorResults {
+	for _, res := range dedupeByContent(vectorResults) {
 		if res.ContentID == params.SourceContentID {
 			continue
 		}
+		if params.MinScore > 0 && res.RelevanceScore < params.MinScore {
+			continue
+		}
 		contentIDs = append(contentIDs, res.ContentID)
-		scoresMap[res.ContentID] = res.RelevanceScore
+		scoresMap[res.ContentID] = res.Distance
 	}
 
 	if len(contentIDs) == 0 {
@@ -298,12 +616,16 @@ func (s *SearchService) FindRelatedContent(ctx context.Context, params RelatedCo
 		return nil, fmt.Errorf("failed to fetch content details for related search results: %w", err)
 	}
 
+	ownerID := OwnerFromContext(ctx)
 	results := make([]SearchResultItem, 0, params.Limit)
 	for _, content := range contents {
 		if content == nil {
 			log.Printf("WARN: Related content not found for ID returned by vector search")
 			continue
 		}
+		if !store.VisibleTo(content, ownerID) {
+			continue
+		}
 		score, ok := scoresMap[content.ID]
 		if !ok {
 			log.Printf("WARN: Score not found for related content ID %d", content.ID)
@@ -312,6 +634,7 @@ func (s *SearchService) FindRelatedContent(ctx context.Context, params RelatedCo
 		results = append(results, SearchResultItem{
 			Content: content,
 			Score:   score,
+			metric:  s.metric,
 		})
 		if len(results) >= params.Limit {
 			break
@@ -320,3 +643,87 @@ func (s *SearchService) FindRelatedContent(ctx context.Context, params RelatedCo
 
 	return results, nil
 }
+
+// contentFilter returns the SimilaritySearch filter restricting results to the
+// content of a collection (0 for any) carrying any of tags (empty for any). It
+// reports false when no content can match, so the vector search can be skipped.
+func (s *SearchService) contentFilter(ctx context.Context, collectionID int64, tags []string) (map[string]interface{}, bool, error) {
+	filterMetadata := make(map[string]interface{})
+	var contentIDs []int64
+	if collectionID != 0 {
+		ids, err := s.collectionContentIDs(ctx, collectionID)
+		if err != nil {
+			return nil, false, err
+		}
+		contentIDs = ids
+		if len(contentIDs) == 0 {
+			return nil, false, nil
+		}
+	}
+	if len(tags) > 0 {
+		if s.tags == nil {
+			return nil, false, fmt.Errorf("tag store is not initialized")
+		}
+		ids, err := s.tags.ListContentIDsByTags(ctx, OwnerFromContext(ctx), tags)
+		if err != nil {
+			return nil, false, fmt.Errorf("resolve filter tags: %w", err)
+		}
+		if collectionID != 0 {
+			ids = intersectIDs(contentIDs, ids)
+		}
+		contentIDs = ids
+		if len(contentIDs) == 0 {
+			return nil, false, nil
+		}
+	}
+	if contentIDs != nil {
+		filterMetadata[store.FilterContentIDs] = contentIDs
+	}
+	return filterMetadata, true, nil
+}
+
+// intersectIDs returns the IDs in both a and b, in the order of b.
+func intersectIDs(a, b []int64) []int64 {
+	inA := make(map[int64]bool, len(a))
+	for _, id := range a {
+		inA[id] = true
+	}
+	both := make([]int64, 0, len(b))
+	for _, id := range b {
+		if inA[id] {
+			both = append(both, id)
+		}
+	}
+	return both
+}
+
+// collectionContentIDs returns the content IDs of a collection, failing with
+// store.ErrNotFound if the collection does not exist.
+func (s *SearchService) collectionContentIDs(ctx context.Context, collectionID int64) ([]int64, error) {
+	if s.collections == nil {
+		return nil, fmt.Errorf("collection store is not initialized")
+	}
+	if _, err := s.collections.GetCollection(ctx, OwnerFromContext(ctx), collectionID); err != nil {
+		return nil, fmt.Errorf("get collection %d: %w", collectionID, err)
+	}
+	ids, err := s.collections.ListCollectionContentIDs(ctx, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("list content of collection %d: %w", collectionID, err)
+	}
+	return ids, nil
+}
+
+// dedupeByContent keeps the first (best scoring) result for each content ID,
+// preserving the order returned by the vector store.
+func dedupeByContent(results []models.SearchResult) []models.SearchResult {
+	seen := make(map[int64]bool, len(results))
+	deduped := make([]models.SearchResult, 0, len(results))
+	for _, res := range results {
+		if seen[res.ContentID] {
+			continue
+		}
+		seen[res.ContentID] = true
+		deduped = append(deduped, res)
+	}
+	return deduped
+}
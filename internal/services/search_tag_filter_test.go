@@ -0,0 +1,63 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+// taggedContentStore resolves tags to fixed content IDs.
+type taggedContentStore struct {
+	store.TagStore
+	ids     []int64
+	ownerID string
+}
+
+func (s *taggedContentStore) ListContentIDsByTags(ctx context.Context, ownerID string, tags []string) ([]int64, error) {
+	s.ownerID = ownerID
+	return s.ids, nil
+}
+
+// filterRecordingVectorStore records the filter of each SimilaritySearch call.
+type filterRecordingVectorStore struct {
+	store.VectorStore
+	filters []map[string]interface{}
+}
+
+func (v *filterRecordingVectorStore) SimilaritySearch(ctx context.Context, queryVector pgvector.Vector, k int, filterMetadata map[string]interface{}) ([]models.SearchResult, error) {
+	v.filters = append(v.filters, filterMetadata)
+	return nil, nil
+}
+
+func TestSemanticSearch_FilterTags(t *testing.T) {
+	tags := &taggedContentStore{ids: []int64{4, 7}}
+	vs := &filterRecordingVectorStore{}
+	svc := services.NewSearchService(nil, nil, vs, historyEmbeddingService{}, nopSearchHistory{})
+	svc.SetRecordHistory(false)
+	svc.SetTagStore(tags)
+	ctx := services.WithOwner(context.Background(), "alice")
+
+	_, err := svc.SemanticSearch(ctx, services.SemanticSearchParams{Query: "go", FilterTags: []string{"golang"}})
+	require.NoError(t, err)
+	require.Len(t, vs.filters, 1)
+	assert.Equal(t, []int64{4, 7}, vs.filters[0][store.FilterContentIDs], "tags are pushed into the vector query as content IDs")
+	assert.Equal(t, "alice", tags.ownerID)
+
+	tags.ids = []int64{}
+	results, err := svc.SemanticSearch(ctx, services.SemanticSearchParams{Query: "go", FilterTags: []string{"unused"}})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Len(t, vs.filters, 1, "tags matching no content skip the vector search")
+
+	_, err = svc.SemanticSearch(ctx, services.SemanticSearchParams{Query: "go"})
+	require.NoError(t, err)
+	require.Len(t, vs.filters, 2)
+	assert.NotContains(t, vs.filters[1], store.FilterContentIDs, "no tags, no content filter")
+}
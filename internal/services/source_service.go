@@ -7,6 +7,7 @@ import (
 	"log"
 	"path/filepath"
 
+	"mimir/internal/config"
 	"mimir/internal/models"
 	"mimir/internal/store"
 )
@@ -123,7 +124,7 @@ func (s *SourceService) CreateSource(ctx context.Context, name, sourceType strin
 }
 
 func (s *SourceService) ListSources(ctx context.Context) ([]*models.Source, error) {
-	sources, err := s.primaryStore.ListSources(ctx, 100, 0)
+	sources, err := s.primaryStore.ListSources(ctx, config.DefaultMaxPageSize, 0)
 	if err != nil {
 		return nil, fmt.Errorf("could not list sources: %w", err)
 	}
@@ -0,0 +1,42 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type prefixTagStore struct {
+	store.TagStore
+	ownerID, prefix string
+	limit           int
+	usages          []*models.TagUsage
+}
+
+func (s *prefixTagStore) SearchTagsByPrefix(ctx context.Context, ownerID, prefix string, limit int) ([]*models.TagUsage, error) {
+	s.ownerID, s.prefix, s.limit = ownerID, prefix, limit
+	return s.usages, nil
+}
+
+func TestAutocompleteTags(t *testing.T) {
+	tags := &prefixTagStore{usages: []*models.TagUsage{{Tag: models.Tag{Name: "golang"}, Count: 7}}}
+	svc := services.NewTagService(tags)
+
+	usages, err := svc.AutocompleteTags(services.WithOwner(context.Background(), "alice"), " go ", 5)
+	require.NoError(t, err)
+	assert.Equal(t, tags.usages, usages)
+	assert.Equal(t, "alice", tags.ownerID, "suggestions are limited to the caller's tags")
+	assert.Equal(t, "go", tags.prefix)
+	assert.Equal(t, 5, tags.limit)
+
+	tags.usages = nil
+	usages, err = svc.AutocompleteTags(context.Background(), "zz", 5)
+	require.NoError(t, err)
+	assert.NotNil(t, usages, "no matches is an empty list, not null")
+}
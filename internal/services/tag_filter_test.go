@@ -0,0 +1,69 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type filterContentStore struct {
+	store.ContentStore
+	query string
+	ids   []int64
+}
+
+func (s *filterContentStore) ListContentIDs(ctx context.Context, query string, filterTags []string, pinned *bool) ([]int64, error) {
+	s.query = query
+	return s.ids, nil
+}
+
+type batchTagStore struct {
+	store.TagStore
+	batches [][]int64
+}
+
+func (s *batchTagStore) GetOrCreateTagsByName(ctx context.Context, ownerID string, names []string) ([]*models.Tag, error) {
+	tags := make([]*models.Tag, len(names))
+	for i, n := range names {
+		tags[i] = &models.Tag{ID: int64(i + 1), Name: n}
+	}
+	return tags, nil
+}
+
+func (s *batchTagStore) AddTagsToContents(ctx context.Context, contentIDs, tagIDs []int64) error {
+	s.batches = append(s.batches, contentIDs)
+	return nil
+}
+
+func TestTagContentByFilter(t *testing.T) {
+	ids := make([]int64, 1500)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	contents := &filterContentStore{ids: ids}
+	tags := &batchTagStore{}
+	ts := services.NewTagService(tags)
+	ts.SetContentStore(contents)
+
+	tagged, err := ts.TagContentByFilter(context.Background(), services.ListContentParams{Query: " kubernetes "}, []string{"k8s"})
+	require.NoError(t, err)
+	assert.Equal(t, 1500, tagged)
+	assert.Equal(t, "kubernetes", contents.query)
+	require.Len(t, tags.batches, 2)
+	assert.Len(t, tags.batches[0], 1000)
+	assert.Len(t, tags.batches[1], 500)
+}
+
+func TestTagContentByFilterRejectsEmptyFilter(t *testing.T) {
+	ts := services.NewTagService(&batchTagStore{})
+	ts.SetContentStore(&filterContentStore{})
+
+	_, err := ts.TagContentByFilter(context.Background(), services.ListContentParams{Limit: 10}, []string{"k8s"})
+	assert.ErrorIs(t, err, services.ErrEmptyFilter)
+}
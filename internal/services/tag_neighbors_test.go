@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+	"mimir/internal/store"
+)
+
+type staticRelatedFinder struct{ items []services.SearchResultItem }
+
+func (f staticRelatedFinder) FindRelatedContent(ctx context.Context, params services.RelatedContentParams) ([]services.SearchResultItem, error) {
+	return f.items, nil
+}
+
+// neighborTagStore holds the tags of each content item and counts the lookups.
+type neighborTagStore struct {
+	store.TagStore
+	tags        map[int64][]*models.Tag
+	singleCalls int
+	batchCalls  int
+}
+
+func (s *neighborTagStore) GetContentTags(ctx context.Context, contentID int64) ([]*models.Tag, error) {
+	s.singleCalls++
+	return s.tags[contentID], nil
+}
+
+func (s *neighborTagStore) GetTagsForContents(ctx context.Context, contentIDs []int64) (map[int64][]*models.Tag, error) {
+	s.batchCalls++
+	out := make(map[int64][]*models.Tag, len(contentIDs))
+	for _, id := range contentIDs {
+		out[id] = s.tags[id]
+	}
+	return out, nil
+}
+
+func TestTagService_SuggestTagsFromNeighbors(t *testing.T) {
+	goTag, dbTag, webTag := &models.Tag{ID: 1, Name: "go"}, &models.Tag{ID: 2, Name: "db"}, &models.Tag{ID: 3, Name: "web"}
+	tags := &neighborTagStore{tags: map[int64][]*models.Tag{
+		1: {goTag},
+		2: {goTag, dbTag},
+		3: {dbTag, webTag},
+		4: {dbTag},
+	}}
+	ts := services.NewTagService(tags)
+	ts.SetRelatedContentFinder(staticRelatedFinder{items: []services.SearchResultItem{
+		{Content: &models.Content{ID: 2}, Score: 0},
+		{Content: &models.Content{ID: 3}, Score: 1},
+		{Content: &models.Content{ID: 4}, Score: 3},
+	}})
+
+	suggestions, err := ts.SuggestTagsFromNeighbors(context.Background(), 1, 3)
+	require.NoError(t, err)
+
+	require.Len(t, suggestions, 2, "the item's own tags are not suggested")
+	assert.Equal(t, "db", suggestions[0].Tag.Name)
+	assert.Equal(t, 3, suggestions[0].Count)
+	assert.InDelta(t, 1+0.5+0.25, suggestions[0].Score, 1e-9)
+	assert.Equal(t, "web", suggestions[1].Tag.Name)
+	assert.Equal(t, 1, tags.singleCalls, "only the item's own tags are read singly")
+	assert.Equal(t, 1, tags.batchCalls, "neighbor tags are read in one query")
+}
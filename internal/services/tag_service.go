@@ -2,20 +2,63 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"sort"
+	"strings"
 
 	"mimir/internal/models"
 	"mimir/internal/store"
 )
 
 type TagService struct {
-	store store.TagStore
+	store    store.TagStore
+	related  RelatedContentFinder // Optional; required for neighbor-based tag suggestions
+	jobs     store.JobClient      // Optional; refreshes embedding metadata after tag changes
+	contents store.ContentStore   // Optional; required for TagContentByFilter
+}
+
+// ErrEmptyFilter is returned by TagContentByFilter when the filter would match
+// all content.
+var ErrEmptyFilter = errors.New("filter must set a query, tags or pinned state")
+
+// tagByFilterBatchSize caps the content IDs linked per AddTagsToContents call.
+const tagByFilterBatchSize = 1000
+
+// RelatedContentFinder finds content semantically similar to a given item.
+// It is satisfied by *SearchService.
+type RelatedContentFinder interface {
+	FindRelatedContent(ctx context.Context, params RelatedContentParams) ([]SearchResultItem, error)
+}
+
+// TagSuggestion is a tag proposed for a content item, scored by the summed
+// similarity of the neighboring items that carry it.
+type TagSuggestion struct {
+	Tag   *models.Tag `json:"tag"`
+	Score float64     `json:"score"`
+	Count int         `json:"count"` // Number of neighbors carrying the tag
 }
 
 func NewTagService(ts store.TagStore) *TagService {
 	return &TagService{store: ts}
 }
 
+// SetRelatedContentFinder enables SuggestTagsFromNeighbors.
+func (ts *TagService) SetRelatedContentFinder(f RelatedContentFinder) {
+	ts.related = f
+}
+
+// SetJobClient enables embedding metadata update jobs on tag changes.
+func (ts *TagService) SetJobClient(jc store.JobClient) {
+	ts.jobs = jc
+}
+
+// SetContentStore enables TagContentByFilter.
+func (ts *TagService) SetContentStore(cs store.ContentStore) {
+	ts.contents = cs
+}
+
 // TagContent associates the given tag names with the specified content.
 // It creates any missing tags, then links them to the content.
 func (ts *TagService) TagContent(ctx context.Context, contentID int64, tagNames []string) ([]*models.Tag, error) {
@@ -23,7 +66,7 @@ func (ts *TagService) TagContent(ctx context.Context, contentID int64, tagNames
 		return []*models.Tag{}, nil
 	}
 
-	tags, err := ts.store.GetOrCreateTagsByName(ctx, tagNames)
+	tags, err := ts.store.GetOrCreateTagsByName(ctx, OwnerFromContext(ctx), tagNames)
 	if err != nil {
 		return nil, fmt.Errorf("get or create tags: %w", err)
 	}
@@ -37,10 +80,84 @@ func (ts *TagService) TagContent(ctx context.Context, contentID int64, tagNames
 	if err != nil {
 		return nil, fmt.Errorf("add tags to content: %w", err)
 	}
+	ts.enqueueMetadataUpdate(ctx, contentID)
 
 	return tags, nil
 }
 
+// TagContentByFilter applies the named tags to every content item matching the
+// filter's Query, FilterTags and Pinned fields and returns the number of items
+// matched. Pagination and sort fields are ignored. At least one criterion must be
+// set so a mistaken request cannot tag the whole corpus.
+func (ts *TagService) TagContentByFilter(ctx context.Context, filter ListContentParams, tagNames []string) (int, error) {
+	if ts.contents == nil {
+		return 0, fmt.Errorf("content store is not configured for tagging by filter")
+	}
+	if len(tagNames) == 0 {
+		return 0, fmt.Errorf("no tags to apply")
+	}
+	if strings.TrimSpace(filter.Query) == "" && len(filter.FilterTags) == 0 && filter.Pinned == nil {
+		return 0, ErrEmptyFilter
+	}
+
+	ids, err := ts.contents.ListContentIDs(ctx, strings.TrimSpace(filter.Query), filter.FilterTags, filter.Pinned)
+	if err != nil {
+		return 0, fmt.Errorf("resolve content matching filter: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	tags, err := ts.store.GetOrCreateTagsByName(ctx, OwnerFromContext(ctx), tagNames)
+	if err != nil {
+		return 0, fmt.Errorf("get or create tags: %w", err)
+	}
+	tagIDs := make([]int64, len(tags))
+	for i, tag := range tags {
+		tagIDs[i] = tag.ID
+	}
+
+	for start := 0; start < len(ids); start += tagByFilterBatchSize {
+		end := min(start+tagByFilterBatchSize, len(ids))
+		if err := ts.store.AddTagsToContents(ctx, ids[start:end], tagIDs); err != nil {
+			return start, fmt.Errorf("add tags to content: %w", err)
+		}
+		for _, id := range ids[start:end] {
+			ts.enqueueMetadataUpdate(ctx, id)
+		}
+	}
+	return len(ids), nil
+}
+
+// UntagContent removes the tag with the given name from the content.
+func (ts *TagService) UntagContent(ctx context.Context, contentID int64, tagName string) error {
+	tags, err := ts.store.GetContentTags(ctx, contentID)
+	if err != nil {
+		return fmt.Errorf("get tags for content %d: %w", contentID, err)
+	}
+	for _, tag := range tags {
+		if strings.EqualFold(tag.Name, tagName) {
+			if err := ts.store.RemoveTagFromContent(ctx, contentID, tag.ID); err != nil {
+				return fmt.Errorf("remove tag from content: %w", err)
+			}
+			ts.enqueueMetadataUpdate(ctx, contentID)
+			return nil
+		}
+	}
+	return fmt.Errorf("content %d is not tagged %q: %w", contentID, tagName, store.ErrNotFound)
+}
+
+// enqueueMetadataUpdate schedules a refresh of the content's embedding metadata.
+// Failures are logged: the tag change itself has already been applied.
+func (ts *TagService) enqueueMetadataUpdate(ctx context.Context, contentID int64) {
+	if ts.jobs == nil {
+		return
+	}
+	if err := ts.jobs.EnqueueEmbeddingMetadataUpdateJob(ctx, contentID); err != nil {
+		log.Printf("WARN: Failed to enqueue embedding metadata update for content %d: %v", contentID, err)
+	}
+}
+
 // GetContentTags retrieves all tags associated with a specific content ID.
 func (ts *TagService) GetContentTags(ctx context.Context, contentID int64) ([]*models.Tag, error) {
 	tags, err := ts.store.GetContentTags(ctx, contentID)
@@ -54,3 +171,128 @@ func (ts *TagService) GetContentTags(ctx context.Context, contentID int64) ([]*m
 	}
 	return tags, nil
 }
+
+// TagGraphEdge connects two tags that appear together on Weight content items.
+type TagGraphEdge struct {
+	Source int64 `json:"source"`
+	Target int64 `json:"target"`
+	Weight int   `json:"weight"`
+}
+
+// TagGraph is a weighted tag co-occurrence graph.
+type TagGraph struct {
+	Nodes []*models.Tag  `json:"nodes"`
+	Edges []TagGraphEdge `json:"edges"`
+}
+
+// GetTagCooccurrence builds a graph of tags that co-occur on at least minCount content items.
+// Only tags participating in at least one edge are included as nodes.
+func (ts *TagService) GetTagCooccurrence(ctx context.Context, minCount int) (*TagGraph, error) {
+	pairs, err := ts.store.GetTagCooccurrence(ctx, OwnerFromContext(ctx), minCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tag co-occurrence from store: %w", err)
+	}
+
+	graph := &TagGraph{
+		Nodes: []*models.Tag{},
+		Edges: make([]TagGraphEdge, 0, len(pairs)),
+	}
+	seen := make(map[int64]bool)
+	for _, pair := range pairs {
+		for _, tag := range []models.Tag{pair.TagA, pair.TagB} {
+			if !seen[tag.ID] {
+				seen[tag.ID] = true
+				t := tag
+				graph.Nodes = append(graph.Nodes, &t)
+			}
+		}
+		graph.Edges = append(graph.Edges, TagGraphEdge{
+			Source: pair.TagA.ID,
+			Target: pair.TagB.ID,
+			Weight: pair.Count,
+		})
+	}
+	return graph, nil
+}
+
+// AutocompleteTags returns up to limit of the caller's tags starting with
+// prefix, most used first, for tag input suggestions.
+func (ts *TagService) AutocompleteTags(ctx context.Context, prefix string, limit int) ([]*models.TagUsage, error) {
+	usages, err := ts.store.SearchTagsByPrefix(ctx, OwnerFromContext(ctx), strings.TrimSpace(prefix), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tags by prefix: %w", err)
+	}
+	if usages == nil {
+		usages = []*models.TagUsage{}
+	}
+	return usages, nil
+}
+
+// SuggestTagsFromNeighbors proposes tags for a content item by aggregating the
+// tags of its k nearest neighbors. Each neighbor contributes its similarity
+// (derived from the vector distance) to every tag it carries; tags already
+// applied to the item are excluded. Suggestions are ordered by score, highest first.
+func (ts *TagService) SuggestTagsFromNeighbors(ctx context.Context, contentID int64, k int) ([]TagSuggestion, error) {
+	if ts.related == nil {
+		return nil, fmt.Errorf("related content search is not configured")
+	}
+
+	neighbors, err := ts.related.FindRelatedContent(ctx, RelatedContentParams{SourceContentID: contentID, Limit: k})
+	if err != nil {
+		return nil, fmt.Errorf("find neighbors of content %d: %w", contentID, err)
+	}
+
+	existing, err := ts.GetContentTags(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]bool, len(existing))
+	for _, tag := range existing {
+		applied[tag.ID] = true
+	}
+
+	neighborIDs := make([]int64, 0, len(neighbors))
+	for _, neighbor := range neighbors {
+		if neighbor.Content != nil {
+			neighborIDs = append(neighborIDs, neighbor.Content.ID)
+		}
+	}
+	neighborTags := map[int64][]*models.Tag{}
+	if len(neighborIDs) > 0 {
+		if neighborTags, err = ts.store.GetTagsForContents(ctx, neighborIDs); err != nil {
+			return nil, fmt.Errorf("get tags for %d neighbors of content %d: %w", len(neighborIDs), contentID, err)
+		}
+	}
+
+	byTag := make(map[int64]*TagSuggestion)
+	for _, neighbor := range neighbors {
+		if neighbor.Content == nil {
+			continue
+		}
+		similarity := neighbor.Similarity()
+		for _, tag := range neighborTags[neighbor.Content.ID] {
+			if applied[tag.ID] {
+				continue
+			}
+			suggestion, ok := byTag[tag.ID]
+			if !ok {
+				suggestion = &TagSuggestion{Tag: tag}
+				byTag[tag.ID] = suggestion
+			}
+			suggestion.Score += similarity
+			suggestion.Count++
+		}
+	}
+
+	suggestions := make([]TagSuggestion, 0, len(byTag))
+	for _, suggestion := range byTag {
+		suggestions = append(suggestions, *suggestion)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Tag.Name < suggestions[j].Tag.Name
+	})
+	return suggestions, nil
+}
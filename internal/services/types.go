@@ -25,11 +25,14 @@ type RetryStrategy interface {
 	NextBackoff(attempt int) int64 // ms
 }
 
+// FallbackEmbeddingService tries its providers in order, skipping to the next
+// one when a provider fails. Each provider has its own circuit breaker, so
+// concurrent calls never compete over a shared active provider.
 type FallbackEmbeddingService struct {
-	Providers      []EmbeddingProvider
-	ActiveProvider int
-	RetryStrategy  RetryStrategy
-	mu             sync.RWMutex
+	Providers     []EmbeddingProvider
+	RetryStrategy RetryStrategy
+	breakers      []*CircuitBreaker // Indexed like Providers
+	mu            sync.RWMutex
 }
 
 // ModelName returns the model name of the currently active provider.
@@ -38,10 +41,10 @@ func (s *FallbackEmbeddingService) ModelName() string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if len(s.Providers) == 0 || s.ActiveProvider < 0 || s.ActiveProvider >= len(s.Providers) {
+	if len(s.Providers) == 0 {
 		return "" // No active provider
 	}
-	return s.Providers[s.ActiveProvider].ModelName()
+	return s.Providers[s.activeIndexLocked()].ModelName()
 }
 
 // Name returns the name of the currently active provider.
@@ -50,10 +53,10 @@ func (s *FallbackEmbeddingService) Name() string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if len(s.Providers) == 0 || s.ActiveProvider < 0 || s.ActiveProvider >= len(s.Providers) {
+	if len(s.Providers) == 0 {
 		return "" // No active provider
 	}
-	return s.Providers[s.ActiveProvider].Name()
+	return s.Providers[s.activeIndexLocked()].Name()
 }
 
 // Status returns the status of the currently active provider.
@@ -61,11 +64,50 @@ func (s *FallbackEmbeddingService) Status() store.ProviderStatus { // Change ret
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	if len(s.Providers) == 0 || s.ActiveProvider < 0 || s.ActiveProvider >= len(s.Providers) {
+	if len(s.Providers) == 0 {
 		return store.ProviderStatusDisabled // Use store constant
 	}
+	return s.providerStatusLocked(s.activeIndexLocked())
+}
+
+// providerStatusLocked returns provider i's status, reporting it inactive while
+// its circuit breaker is open. s.mu must be held.
+func (s *FallbackEmbeddingService) providerStatusLocked(i int) store.ProviderStatus {
+	if s.breakerStateLocked(i) == BreakerOpen {
+		return store.ProviderStatusInactive
+	}
 	// Assuming s.Providers[x].Status() now returns store.ProviderStatus
-	return s.Providers[s.ActiveProvider].Status()
+	return s.Providers[i].Status()
+}
+
+// ProviderInfo describes one configured embedding provider.
+type ProviderInfo struct {
+	Name      string
+	Model     string
+	Dimension int
+	Status    store.ProviderStatus // Inactive while the circuit breaker is open
+	Breaker   BreakerState
+	Active    bool // True for the provider currently receiving requests
+}
+
+// ProviderInfos reports every configured provider in fallback order.
+func (s *FallbackEmbeddingService) ProviderInfos() []ProviderInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	active := s.activeIndexLocked()
+	infos := make([]ProviderInfo, len(s.Providers))
+	for i, p := range s.Providers {
+		infos[i] = ProviderInfo{
+			Name:      p.Name(),
+			Model:     p.ModelName(),
+			Dimension: p.Dimension(),
+			Status:    s.providerStatusLocked(i),
+			Breaker:   s.breakerStateLocked(i),
+			Active:    i == active,
+		}
+	}
+	return infos
 }
 
 // Ensure FallbackEmbeddingService implements the updated store.EmbeddingService interface
@@ -80,6 +122,8 @@ type BatchAPIProvider interface {
 	CreateBatch(ctx context.Context, inputFileID, endpoint string, completionWindow string) (openai.BatchResponse, error) // Corrected return type
 	RetrieveBatch(ctx context.Context, batchID string) (openai.BatchResponse, error)                                      // Corrected return type
 	GetFileContent(ctx context.Context, fileID string) ([]byte, error)
+	// RecordBatchUsage records the cost of a completed batch from its output file, at batch rates.
+	RecordBatchUsage(ctx context.Context, batchID string, outputFile []byte) error
 	// Add other necessary methods like CancelBatch, ListBatches if needed
 }
 
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// DefaultHeartbeatInterval is used when worker.heartbeat_interval is unset.
+const DefaultHeartbeatInterval = 15 * time.Second
+
+// heartbeatStaleIntervals is how many heartbeat intervals a worker may miss
+// before it is no longer listed as active.
+const heartbeatStaleIntervals = 3
+
+// WorkerService reports which background workers are alive.
+type WorkerService struct {
+	store    store.WorkerHeartbeatStore
+	interval time.Duration
+}
+
+// NewWorkerService creates a WorkerService; interval <= 0 uses DefaultHeartbeatInterval.
+func NewWorkerService(s store.WorkerHeartbeatStore, interval time.Duration) *WorkerService {
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+	return &WorkerService{store: s, interval: interval}
+}
+
+// ListActiveWorkers returns workers whose last heartbeat is recent enough that
+// they are presumed alive, most recently seen first.
+func (ws *WorkerService) ListActiveWorkers(ctx context.Context) ([]*models.WorkerHeartbeat, error) {
+	since := time.Now().Add(-heartbeatStaleIntervals * ws.interval)
+	workers, err := ws.store.ListWorkerHeartbeats(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("list active workers: %w", err)
+	}
+	return workers, nil
+}
+
+// NewHeartbeater creates a Heartbeater identifying the current process.
+func (ws *WorkerService) NewHeartbeater() *Heartbeater {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	pid := os.Getpid()
+	return &Heartbeater{
+		store:    ws.store,
+		interval: ws.interval,
+		hb: models.WorkerHeartbeat{
+			WorkerID:  fmt.Sprintf("%s:%d:%s", hostname, pid, uuid.NewString()[:8]),
+			Hostname:  hostname,
+			PID:       pid,
+			StartedAt: time.Now(),
+		},
+	}
+}
+
+// Heartbeater periodically records that a worker process is alive along with
+// the number of tasks it is processing.
+type Heartbeater struct {
+	store    store.WorkerHeartbeatStore
+	interval time.Duration
+	hb       models.WorkerHeartbeat
+	inFlight atomic.Int64
+}
+
+// ID returns the worker ID written to the heartbeat table.
+func (h *Heartbeater) ID() string { return h.hb.WorkerID }
+
+// Middleware counts tasks in flight for the heartbeat.
+func (h *Heartbeater) Middleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+		h.inFlight.Add(1)
+		defer h.inFlight.Add(-1)
+		return next.ProcessTask(ctx, t)
+	})
+}
+
+// Run writes a heartbeat immediately and then every interval until ctx is
+// done, when it removes the worker's row so it stops being listed at once.
+func (h *Heartbeater) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	h.beat(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			cleanupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := h.store.DeleteWorkerHeartbeat(cleanupCtx, h.hb.WorkerID); err != nil {
+				log.Printf("WARN: %v", err)
+			}
+			return
+		case <-ticker.C:
+			h.beat(ctx)
+		}
+	}
+}
+
+func (h *Heartbeater) beat(ctx context.Context) {
+	hb := h.hb
+	hb.InFlight = int(h.inFlight.Load())
+	hb.LastSeenAt = time.Now()
+	if err := h.store.UpsertWorkerHeartbeat(ctx, &hb); err != nil {
+		log.Printf("WARN: %v", err)
+	}
+}
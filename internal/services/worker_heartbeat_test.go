@@ -0,0 +1,85 @@
+package services_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/services"
+)
+
+type memoryHeartbeatStore struct {
+	mu      sync.Mutex
+	beats   []models.WorkerHeartbeat
+	deleted []string
+	since   time.Time
+}
+
+func (s *memoryHeartbeatStore) UpsertWorkerHeartbeat(ctx context.Context, hb *models.WorkerHeartbeat) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.beats = append(s.beats, *hb)
+	return nil
+}
+
+func (s *memoryHeartbeatStore) ListWorkerHeartbeats(ctx context.Context, since time.Time) ([]*models.WorkerHeartbeat, error) {
+	s.since = since
+	return nil, nil
+}
+
+func (s *memoryHeartbeatStore) DeleteWorkerHeartbeat(ctx context.Context, workerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deleted = append(s.deleted, workerID)
+	return nil
+}
+
+func TestHeartbeaterReportsInFlightTasks(t *testing.T) {
+	hs := &memoryHeartbeatStore{}
+	ws := services.NewWorkerService(hs, time.Hour)
+	h := ws.NewHeartbeater()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := h.Middleware(asynq.HandlerFunc(func(ctx context.Context, t *asynq.Task) error {
+		close(started)
+		<-release
+		return nil
+	}))
+	go handler.ProcessTask(context.Background(), asynq.NewTask("test", nil))
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.Run(ctx)
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		hs.mu.Lock()
+		defer hs.mu.Unlock()
+		return len(hs.beats) > 0
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+	close(release)
+
+	assert.Equal(t, h.ID(), hs.beats[0].WorkerID)
+	assert.Equal(t, 1, hs.beats[0].InFlight)
+	assert.Equal(t, []string{h.ID()}, hs.deleted)
+}
+
+func TestListActiveWorkersExcludesStaleHeartbeats(t *testing.T) {
+	hs := &memoryHeartbeatStore{}
+	ws := services.NewWorkerService(hs, 10*time.Second)
+
+	_, err := ws.ListActiveWorkers(context.Background())
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(-30*time.Second), hs.since, time.Second)
+}
@@ -8,4 +8,10 @@ var (
 	ErrConflict  = errors.New("store: conflicting resource state")
 	// ErrOperationNotSupported was removed
 	ErrForeignKeyViolation = errors.New("store: foreign key constraint violation")
+	// ErrZeroVector is returned for all-zero vectors, which providers produce for
+	// empty text and which carry no similarity information.
+	ErrZeroVector = errors.New("store: vector has no non-zero component")
+	// ErrEmbeddingJobsDisabled is returned when an embedding task is enqueued
+	// explicitly in a keyword-only deployment (see WithoutEmbeddingJobs).
+	ErrEmbeddingJobsDisabled = errors.New("store: embedding jobs are disabled in keyword-only mode")
 )
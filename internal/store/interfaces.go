@@ -2,7 +2,11 @@ package store
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json" // Add json import for RawMessage
+	"time"
+
 	"mimir/internal/models"
 
 	"github.com/google/uuid"
@@ -22,31 +26,152 @@ const (
 	ProviderStatusDisabled                       // Provider is not configured or explicitly disabled
 )
 
+func (s ProviderStatus) String() string {
+	switch s {
+	case ProviderStatusActive:
+		return "active"
+	case ProviderStatusInactive:
+		return "inactive"
+	case ProviderStatusDisabled:
+		return "disabled"
+	default:
+		return "unknown"
+	}
+}
+
 // --- Job Client ---
 
 type JobClient interface {
 	// Enqueue now includes related entity info for recording purposes
 	Enqueue(ctx context.Context, task *asynq.Task, relatedEntityType string, relatedEntityID int64, opts ...asynq.Option) (*asynq.TaskInfo, error)
 	EnqueueEmbeddingJob(ctx context.Context, contentID int64) error
+	// EnqueueReindexEmbeddingJob enqueues an embedding job that reports its outcome to a reindex run.
+	EnqueueReindexEmbeddingJob(ctx context.Context, contentID, runID int64) error
+	// EnqueueEmbeddingMetadataUpdateJob enqueues a refresh of the content's embedding metadata.
+	EnqueueEmbeddingMetadataUpdateJob(ctx context.Context, contentID int64) error
+	// EnqueueEmbeddingAppendJob enqueues embedding of text appended to content,
+	// changing its hash from fromHash to toHash.
+	EnqueueEmbeddingAppendJob(ctx context.Context, contentID int64, fromHash, toHash, text string) error
+	// EnqueueBatchEmbeddingJob enqueues one job embedding all the content items.
+	EnqueueBatchEmbeddingJob(ctx context.Context, contentIDs []int64) error
 	Close() error // Ensure Close is part of the interface
 }
 
+// --- Content Ownership ---
+
+// DefaultOwnerID owns all content when API authentication is disabled.
+const DefaultOwnerID = "public"
+
+// Content visibility values.
+const (
+	VisibilityPrivate = "private" // Visible to its owner only
+	VisibilityShared  = "shared"  // Visible to every owner
+)
+
+// VisibleTo reports whether content is visible to ownerID: it is shared or
+// owned by ownerID. An empty ownerID sees all content.
+func VisibleTo(c *models.Content, ownerID string) bool {
+	return ownerID == "" || c.Visibility == VisibilityShared || c.OwnerID == ownerID
+}
+
+// ContentHash returns the content_hash of body, the SHA256 by which an
+// owner's content is deduplicated.
+func ContentHash(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
 // --- Content Store ---
 
+// Embedding status filter values for ListContent.
+const (
+	EmbeddingStatusEmbedded = "embedded" // Content has current embeddings
+	EmbeddingStatusPending  = "pending"  // Not embedded yet, no failed embedding job
+	EmbeddingStatusFailed   = "failed"   // Not embedded and an embedding job failed or exhausted its retries
+)
+
+// EmbeddingErrorMetadataKey is the content metadata key recording why embedding
+// failed permanently: an object with the "error" of the last attempt and when it
+// "failed_at". It is removed once the content is embedded.
+const EmbeddingErrorMetadataKey = "embedding_error"
+
 type ContentStore interface {
 	CreateContent(ctx context.Context, content *models.Content) error
 	GetContent(ctx context.Context, id int64) (*models.Content, error)
 	UpdateContent(ctx context.Context, content *models.Content) error
 	DeleteContent(ctx context.Context, id int64) error
-	ListContent(ctx context.Context, limit, offset int, sortBy, sortOrder string, filterTags []string) ([]*models.Content, error)
-	FindContentByHash(ctx context.Context, hash string) (*models.Content, error)
+	// ListContent filters by pinned state when pinned is non-nil, to content
+	// visible to ownerID (owned by it or shared) when ownerID is non-empty, and by
+	// embedding status (EmbeddingStatus*) when embeddingStatus is non-empty.
+	ListContent(ctx context.Context, limit, offset int, sortBy, sortOrder string, filterTags []string, pinned *bool, ownerID, embeddingStatus string) ([]*models.Content, error)
+	// FindContentByHash returns ownerID's content with the given content hash.
+	// Content is deduplicated per owner, so other owners' content never matches.
+	FindContentByHash(ctx context.Context, ownerID, hash string) (*models.Content, error)
 	UpdateContentEmbeddingStatus(ctx context.Context, contentID int64, embeddingID uuid.UUID, isEmbedded bool) error
+	// ClearContentEmbedding marks the content not embedded and nulls its
+	// embedding ID and embedded hash, for when its embeddings are being replaced.
+	ClearContentEmbedding(ctx context.Context, contentID int64) error
+	UpdateContentSource(ctx context.Context, contentID, sourceID int64) error
+	SetContentPinned(ctx context.Context, contentID int64, pinned bool) error
+	// TouchContentAccessed sets last_accessed_at to now for the given content.
+	TouchContentAccessed(ctx context.Context, ids []int64) error
+	// ListArchivableContent returns embedded, unarchived content last used before
+	// cutoff; by creation time when byAge is set, by last access otherwise.
+	ListArchivableContent(ctx context.Context, cutoff time.Time, byAge bool) ([]*models.Content, error)
+	// MarkContentArchived records that the content's embeddings were removed.
+	MarkContentArchived(ctx context.Context, contentID int64) error
+	// MarkEmbeddingFailed records reason under EmbeddingErrorMetadataKey in the
+	// metadata of unembedded content. Embedded or missing content is left alone.
+	MarkEmbeddingFailed(ctx context.Context, contentID int64, reason string) error
+	// ListFailedEmbeddingContentIDs returns the IDs among ids of content that is
+	// not embedded and has a failed embedding job or a recorded embedding error
+	// (EmbeddingStatusFailed).
+	ListFailedEmbeddingContentIDs(ctx context.Context, ids []int64) ([]int64, error)
+	// ListStaleEmbeddings returns embedded content whose current hash differs from the embedded hash.
+	ListStaleEmbeddings(ctx context.Context) ([]*models.Content, error)
+	// SetEmbeddingInputVersion records the embedding input template version the
+	// content's embeddings were built from.
+	SetEmbeddingInputVersion(ctx context.Context, contentID int64, version string) error
+	// ListOutdatedInputVersion returns the IDs of embedded content whose embeddings
+	// were built from another input template version than version. Content without
+	// a recorded version counts as defaultVersion.
+	ListOutdatedInputVersion(ctx context.Context, version, defaultVersion string) ([]int64, error)
 	CreateContentIfNotExists(ctx context.Context, content *models.Content) (bool, error)
 	GetContentsByIDs(ctx context.Context, ids []int64) ([]*models.Content, error)
+	// ListContentIDs returns the IDs of all content matching a full-text query
+	// (when non-empty), any of filterTags (by name) and the pinned state (when non-nil).
+	ListContentIDs(ctx context.Context, query string, filterTags []string, pinned *bool) ([]int64, error)
+	// ListContentIDsByContentType returns the IDs of content whose content type,
+	// ignoring parameters such as charset, is contentType (case-insensitive).
+	ListContentIDsByContentType(ctx context.Context, contentType string) ([]int64, error)
+	// RehashContent recomputes the content's content_hash from its body with the
+	// current hashing rules, reporting whether it changed. It returns ErrDuplicate
+	// when other content already has the new hash.
+	RehashContent(ctx context.Context, id int64) (bool, error)
 
 	Ping(ctx context.Context) error
 }
 
+// --- Transactions ---
+
+// ContentTx is the set of store operations available inside a content transaction.
+type ContentTx interface {
+	CreateContentIfNotExists(ctx context.Context, content *models.Content) (bool, error)
+	GetOrCreateTagsByName(ctx context.Context, ownerID string, names []string) ([]*models.Tag, error)
+	AddTagsToContent(ctx context.Context, contentID int64, tagIDs []int64) error
+	AddContentToCollection(ctx context.Context, collectionID, contentID int64) error
+	DeleteContent(ctx context.Context, id int64) error
+	// AfterCommit registers fn to run once the outermost transaction has committed.
+	// Hooks are discarded on rollback; outside a transaction fn runs immediately.
+	AfterCommit(fn func())
+}
+
+// TxRunner runs fn in a single database transaction, committing when fn returns nil
+// and rolling back otherwise.
+type TxRunner interface {
+	RunInTx(ctx context.Context, fn func(tx ContentTx) error) error
+}
+
 // --- Source Store ---
 
 type SourceStore interface {
@@ -58,59 +183,192 @@ type SourceStore interface {
 
 // --- Tag Store ---
 
+// Tags, collections, search history and usage logs belong to an owner. Methods
+// taking an ownerID only see that owner's rows; an empty ownerID sees all rows.
 type TagStore interface {
 	CreateTag(ctx context.Context, tag *models.Tag) error
 	GetTag(ctx context.Context, id int64) (*models.Tag, error)
-	GetTagBySlug(ctx context.Context, slug string) (*models.Tag, error)
-	GetOrCreateTagsByName(ctx context.Context, names []string) ([]*models.Tag, error)
-	ListTags(ctx context.Context, limit, offset int) ([]*models.Tag, error)
+	GetTagBySlug(ctx context.Context, ownerID, slug string) (*models.Tag, error)
+	// GetOrCreateTagsByName finds ownerID's tags by name, creating missing ones
+	// under ownerID.
+	GetOrCreateTagsByName(ctx context.Context, ownerID string, names []string) ([]*models.Tag, error)
+	ListTags(ctx context.Context, ownerID string, limit, offset int) ([]*models.Tag, error)
 	AddTagsToContent(ctx context.Context, contentID int64, tagIDs []int64) error
+	// AddTagsToContents links each of tagIDs to each of contentIDs in one batch.
+	AddTagsToContents(ctx context.Context, contentIDs, tagIDs []int64) error
 	RemoveTagFromContent(ctx context.Context, contentID, tagID int64) error
 	GetContentTags(ctx context.Context, contentID int64) ([]*models.Tag, error)
 	GetTagsForContents(ctx context.Context, contentIDs []int64) (map[int64][]*models.Tag, error) // Add method for batch tag fetching
+	GetTagCooccurrence(ctx context.Context, ownerID string, minCount int) ([]*models.TagCooccurrence, error)
+	// ListContentIDsByTags returns the IDs of content carrying any of ownerID's
+	// tags whose name or slug matches one of tags, ignoring case.
+	ListContentIDsByTags(ctx context.Context, ownerID string, tags []string) ([]int64, error)
+	// SearchTagsByPrefix returns up to limit of ownerID's tags whose name starts
+	// with prefix (case-insensitive), most used first. An empty prefix returns
+	// the most used tags.
+	SearchTagsByPrefix(ctx context.Context, ownerID, prefix string, limit int) ([]*models.TagUsage, error)
 }
 
 // --- Collection Store ---
 
 type CollectionStore interface {
 	CreateCollection(ctx context.Context, collection *models.Collection) error
-	GetCollection(ctx context.Context, id int64) (*models.Collection, error)
-	GetCollectionByName(ctx context.Context, name string) (*models.Collection, error)
-	ListCollections(ctx context.Context, limit, offset int, pinned *bool) ([]*models.Collection, error)
+	GetCollection(ctx context.Context, ownerID string, id int64) (*models.Collection, error)
+	GetCollectionByName(ctx context.Context, ownerID, name string) (*models.Collection, error)
+	ListCollections(ctx context.Context, ownerID string, limit, offset int, pinned *bool) ([]*models.Collection, error)
+	// UpdateCollection updates the collection if it belongs to collection.OwnerID.
 	UpdateCollection(ctx context.Context, collection *models.Collection) error
-	DeleteCollection(ctx context.Context, id int64) error
+	// DeleteCollection deletes the collection and its content associations,
+	// returning how many associations were removed.
+	DeleteCollection(ctx context.Context, ownerID string, id int64) (int64, error)
+	// MergeCollections moves every content item of collection sourceID into
+	// targetID in one transaction, skipping items already in the target, and
+	// deletes the source when deleteSource is set. It returns how many items were
+	// added to the target, and ErrNotFound unless both collections belong to ownerID.
+	MergeCollections(ctx context.Context, ownerID string, sourceID, targetID int64, deleteSource bool) (int64, error)
 	AddContentToCollection(ctx context.Context, collectionID, contentID int64) error
 	RemoveContentFromCollection(ctx context.Context, collectionID, contentID int64) error
 	GetCollectionContent(ctx context.Context, collectionID int64, limit, offset int) ([]*models.Content, error)
 	ListContentByCollection(ctx context.Context, collectionID int64, limit, offset int, sortBy, sortOrder string) ([]*models.Content, error)
+	ListCollectionContentIDs(ctx context.Context, collectionID int64) ([]int64, error)
+	// ListContentCollectionIDs returns the IDs of the owner's collections containing the content.
+	ListContentCollectionIDs(ctx context.Context, ownerID string, contentID int64) ([]int64, error)
 }
 
 // --- Search History Store ---
 
 type SearchHistoryStore interface {
-	RecordSearchQuery(ctx context.Context, query string, resultsCount int) (*models.SearchQuery, error)
-	ListSearchQueries(ctx context.Context, limit int) ([]*models.SearchQuery, error)
+	RecordSearchQuery(ctx context.Context, ownerID, query string, resultsCount int) (*models.SearchQuery, error)
+	ListSearchQueries(ctx context.Context, ownerID string, limit int) ([]*models.SearchQuery, error)
 	RecordSearchResults(ctx context.Context, queryID int64, results []models.SearchResult) error
+	// DeleteSearchQueriesBefore deletes queries of all owners executed before the
+	// cutoff, with their results, and returns the number of queries deleted.
+	DeleteSearchQueriesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// --- Content Access Store ---
+
+// ContentView is a viewed content item with its view statistics.
+type ContentView struct {
+	Content      *models.Content `json:"content"`
+	ViewCount    int64           `json:"view_count"`
+	LastViewedAt time.Time       `json:"last_viewed_at"`
+}
+
+type ContentAccessStore interface {
+	// RecordContentView records that the content was viewed now.
+	RecordContentView(ctx context.Context, contentID int64) error
+	// ListRecentlyViewed returns viewed content, most recently viewed first.
+	ListRecentlyViewed(ctx context.Context, limit int) ([]ContentView, error)
+	// ListPopularContent returns viewed content, most viewed first.
+	ListPopularContent(ctx context.Context, limit int) ([]ContentView, error)
 }
 
 // --- Keyword Search ---
 
+// Sort orders accepted by KeywordSearchContent.
+const (
+	KeywordSortRelevance  = "relevance" // ts_rank, best match first
+	KeywordSortCreatedAt  = "created_at"
+	KeywordSortModifiedAt = "modified_at"
+)
+
+// KeywordMatch is a keyword search hit with its ts_rank score.
+type KeywordMatch struct {
+	Content *models.Content
+	Rank    float64
+}
+
 type KeywordSearcher interface {
-	KeywordSearchContent(ctx context.Context, query string, filterTags []string) ([]*models.Content, error)
+	// KeywordSearchContent returns matches ordered by sortBy, one of the
+	// KeywordSort* constants; an empty sortBy orders by relevance. Matches are
+	// limited to content visible to ownerID when it is non-empty. offset matches
+	// are skipped, and at most limit returned when limit is positive; ties are
+	// ordered by content ID, so pages are stable.
+	KeywordSearchContent(ctx context.Context, query string, filterTags []string, sortBy string, ownerID string, limit, offset int) ([]KeywordMatch, error)
 }
 
 // --- Vector Store ---
 
+// FilterContentIDs is a SimilaritySearch filterMetadata key that restricts
+// results to the given content IDs (value type []int64).
+const FilterContentIDs = "content_ids"
+
+// SimilaritySearch filterMetadata keys matched against fields denormalized into
+// embedding metadata at embed time. The keys double as the metadata field names.
+const (
+	FilterTagIDs   = "tag_ids"   // []int64; matches embeddings carrying any of the tags
+	FilterSourceID = "source_id" // int64
+)
+
+// IsZeroVector reports whether v is empty or has only zero components.
+func IsZeroVector(v pgvector.Vector) bool {
+	for _, x := range v.Slice() {
+		if x != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// VectorStats summarizes the contents of the vector store.
+type VectorStats struct {
+	EmbeddingCount int64 `json:"embedding_count"`
+	ContentCount   int64 `json:"content_count"` // Distinct content items with at least one embedding
+	Dimension      int   `json:"dimension"`     // Declared vector column dimension; 0 if unconstrained
+}
+
 type VectorStore interface {
 	AddEmbedding(ctx context.Context, entry *models.EmbeddingEntry) error
 	GetEmbedding(ctx context.Context, id uuid.UUID) (*models.EmbeddingEntry, error)
+	// ListEmbeddingsByContentID returns the content's embeddings ordered by chunk index.
+	ListEmbeddingsByContentID(ctx context.Context, contentID int64) ([]*models.EmbeddingEntry, error)
 	DeleteEmbeddingsByContentID(ctx context.Context, contentID int64) error
+	// UpdateEmbeddingMetadataByContentID merges metadata into every embedding row of the content.
+	UpdateEmbeddingMetadataByContentID(ctx context.Context, contentID int64, metadata json.RawMessage) error
+	// LastChunkIndex returns the highest chunk_index stored for the content, or -1 if it has no embeddings.
+	LastChunkIndex(ctx context.Context, contentID int64) (int, error)
+	// DeleteAppendedChunks deletes the content's chunks stored by an append
+	// that changed its hash to appendedHash (see the "appended_hash" metadata key).
+	DeleteAppendedChunks(ctx context.Context, contentID int64, appendedHash string) error
+	// SetTotalChunks sets total_chunks in the metadata of every chunk of the content.
+	SetTotalChunks(ctx context.Context, contentID int64, total int) error
+	// StreamEmbeddings calls fn for every stored embedding, ordered by content ID
+	// and chunk index, without loading the table into memory. It stops at the first
+	// error fn returns.
+	StreamEmbeddings(ctx context.Context, fn func(entry *models.EmbeddingEntry) error) error
+	// SimilaritySearch returns the k chunks closest to queryVector, closest first.
+	// Each result carries the chunk's content ID, distance and ChunkText; a
+	// content item appears once per matching chunk.
 	SimilaritySearch(ctx context.Context, queryVector pgvector.Vector, k int, filterMetadata map[string]interface{}) ([]models.SearchResult, error)
+	// ContentVectors returns the mean of each content item's chunk vectors,
+	// keyed by content ID; content without embeddings is absent from the map.
+	ContentVectors(ctx context.Context, contentIDs []int64) (map[int64]pgvector.Vector, error)
+	Stats(ctx context.Context) (VectorStats, error)
 
 	Ping(ctx context.Context) error
 	Close() error
 }
 
+// --- Content Locks ---
+
+// ContentLocker serializes work on a content item across worker processes.
+type ContentLocker interface {
+	// LockContent blocks until the caller holds the content's lock or ctx is
+	// done. unlock releases it and must be called exactly once.
+	LockContent(ctx context.Context, contentID int64) (unlock func(), err error)
+}
+
+// --- Worker Heartbeat Store ---
+
+type WorkerHeartbeatStore interface {
+	// UpsertWorkerHeartbeat records the worker's current state, creating its row on first use.
+	UpsertWorkerHeartbeat(ctx context.Context, hb *models.WorkerHeartbeat) error
+	// ListWorkerHeartbeats returns workers seen at or after since, most recently seen first.
+	ListWorkerHeartbeats(ctx context.Context, since time.Time) ([]*models.WorkerHeartbeat, error)
+	DeleteWorkerHeartbeat(ctx context.Context, workerID string) error
+}
+
 // --- Embedding Service ---
 
 type EmbeddingService interface {
@@ -143,12 +401,79 @@ type JobStore interface {
 	GetJobByBatchID(ctx context.Context, batchJobID string) (*models.BackgroundJob, error)         // Add missing method
 	UpdateJobData(ctx context.Context, jobID uuid.UUID, jobData json.RawMessage) error             // Add method to store job data (e.g., chunks)
 	ListBatchJobs(ctx context.Context, limit, offset int) ([]*models.BackgroundJob, error)         // Add method to list jobs with batch IDs
+	// GetJob retrieves a job by its database ID.
+	GetJob(ctx context.Context, id int64) (*models.BackgroundJob, error)
+	// MarkJobDead sets the job's status to "dead" and records the error of its last attempt.
+	MarkJobDead(ctx context.Context, jobID uuid.UUID, lastError string) error
+	// ListJobsByStatus lists jobs with the given status, most recently updated first.
+	ListJobsByStatus(ctx context.Context, status string, limit, offset int) ([]*models.BackgroundJob, error)
+}
+
+// --- Reindex Run Store ---
+
+type ReindexRunStore interface {
+	CreateReindexRun(ctx context.Context, total int) (*models.ReindexRun, error)
+	GetReindexRun(ctx context.Context, id int64) (*models.ReindexRun, error)
+	// RecordReindexOutcome increments the completed or failed counter of a run.
+	RecordReindexOutcome(ctx context.Context, id int64, failed bool) error
 }
 
 // --- Cost Tracking Store ---
 
+// OwnerUsage is the AI usage billed to one owner.
+type OwnerUsage struct {
+	OwnerID      string  `json:"owner_id"`
+	Calls        int64   `json:"calls"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// ServiceUsage is the AI usage of one service type, such as embedding or summarization.
+type ServiceUsage struct {
+	ServiceType  string  `json:"service_type"`
+	Calls        int64   `json:"calls"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	Cost         float64 `json:"cost"`
+}
+
+// UsageRange bounds AI usage queries by timestamp: From is inclusive and To
+// exclusive. A zero From or To leaves that side open.
+type UsageRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// UsageGroup is the AI usage of one day, one model, or one model on one day.
+type UsageGroup struct {
+	Day          time.Time `json:"day,omitempty"`           // Start of the day; zero unless grouped by day
+	ProviderName string    `json:"provider_name,omitempty"` // Empty unless grouped by model
+	ModelName    string    `json:"model_name,omitempty"`    // Empty unless grouped by model
+	Calls        int64     `json:"calls"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	Cost         float64   `json:"cost"`
+}
+
 type CostTrackingStore interface {
+	// RecordUsage bills the call to the owner of log.RelatedContentID when set,
+	// else to log.OwnerID, else to DefaultOwnerID.
 	RecordUsage(ctx context.Context, log *models.AIUsageLog) error
-	ListUsage(ctx context.Context, limit, offset int) ([]*models.AIUsageLog, error)
-	GetUsageSummary(ctx context.Context) (totalCost float64, totalInputTokens, totalOutputTokens int64, err error)
+	// RecordUsageBatch records several logs as RecordUsage does, in one round trip.
+	RecordUsageBatch(ctx context.Context, logs []*models.AIUsageLog) error
+	ListUsage(ctx context.Context, ownerID string, limit, offset int) ([]*models.AIUsageLog, error)
+	GetUsageSummary(ctx context.Context, ownerID string) (totalCost float64, totalInputTokens, totalOutputTokens int64, err error)
+	// GetUsageByOwner totals usage per owner, highest cost first.
+	GetUsageByOwner(ctx context.Context) ([]OwnerUsage, error)
+	// GetUsageSummaryByService totals usage in r per service type, highest cost
+	// first, limited to ownerID's when it is non-empty.
+	GetUsageSummaryByService(ctx context.Context, ownerID string, r UsageRange) ([]ServiceUsage, error)
+	// StreamUsage calls fn for every usage log in r, oldest first, limited to
+	// ownerID's when it is non-empty, without loading them into memory. It
+	// stops at the first error fn returns.
+	StreamUsage(ctx context.Context, ownerID string, r UsageRange, fn func(log *models.AIUsageLog) error) error
+	// GetUsageGroups totals usage in r per day and/or per provider and model,
+	// ordered by day, then provider and model.
+	GetUsageGroups(ctx context.Context, ownerID string, r UsageRange, byDay, byModel bool) ([]UsageGroup, error)
 }
@@ -20,6 +20,7 @@ var _ JobClient = (*AsynqJobClient)(nil)
 type AsynqJobClient struct {
 	client   *asynq.Client
 	jobStore JobStore // Add JobStore dependency
+	maxRetry int      // Default asynq.MaxRetry for enqueued tasks; 0 keeps asynq's default
 }
 
 func NewAsynqJobClient(redisAddr string, js JobStore) (*AsynqJobClient, error) {
@@ -36,6 +37,13 @@ func NewAsynqJobClient(redisAddr string, js JobStore) (*AsynqJobClient, error) {
 	return &AsynqJobClient{client: cli, jobStore: js}, nil
 }
 
+// SetMaxRetry sets how many times enqueued tasks are retried before asynq
+// archives them as dead. Options passed to Enqueue take precedence; n <= 0
+// keeps asynq's default.
+func (jc *AsynqJobClient) SetMaxRetry(n int) {
+	jc.maxRetry = n
+}
+
 func (jc *AsynqJobClient) Close() error {
 	return jc.client.Close()
 }
@@ -49,6 +57,10 @@ func (jc *AsynqJobClient) Enqueue(ctx context.Context, task *asynq.Task, related
 	}
 	// Add logging before the actual enqueue call
 	log.Printf("DEBUG: Enqueuing task type '%s' via client: %p", task.Type(), jc.client) // Log client pointer address
+	if jc.maxRetry > 0 {
+		// Later options win, so an explicit asynq.MaxRetry in opts still applies.
+		opts = append([]asynq.Option{asynq.MaxRetry(jc.maxRetry)}, opts...)
+	}
 	info, err := jc.client.EnqueueContext(ctx, task, opts...)
 	if err != nil {
 		// Log the error if the enqueue fails
@@ -95,6 +107,58 @@ func (jc *AsynqJobClient) EnqueueEmbeddingJob(ctx context.Context, contentID int
 	return nil
 }
 
+// EnqueueReindexEmbeddingJob enqueues an embedding job carrying the reindex run ID,
+// so the worker can update the run's counters when the job completes or fails.
+func (jc *AsynqJobClient) EnqueueReindexEmbeddingJob(ctx context.Context, contentID, runID int64) error {
+	payload := map[string]interface{}{"content_id": contentID, "reindex_run_id": runID}
+	task := asynq.NewTask(tasks.TypeEmbeddingJob, encodePayload(payload))
+	_, err := jc.Enqueue(ctx, task, "content", contentID, asynq.Queue("embeddings"))
+	if err != nil {
+		return fmt.Errorf("enqueue reindex embedding job for content %d (run %d): %w", contentID, runID, err)
+	}
+	return nil
+}
+
+// EnqueueEmbeddingMetadataUpdateJob enqueues a job that refreshes the denormalized
+// tag and source metadata on the content's embeddings.
+func (jc *AsynqJobClient) EnqueueEmbeddingMetadataUpdateJob(ctx context.Context, contentID int64) error {
+	payload := map[string]interface{}{"content_id": contentID}
+	task := asynq.NewTask(tasks.TypeEmbeddingMetadataUpdateJob, encodePayload(payload))
+	_, err := jc.Enqueue(ctx, task, "content", contentID, asynq.Queue("embeddings"))
+	if err != nil {
+		return fmt.Errorf("enqueue embedding metadata update job for content %d: %w", contentID, err)
+	}
+	return nil
+}
+
+// EnqueueEmbeddingAppendJob enqueues a job that embeds text appended to the
+// content as additional chunks. fromHash and toHash are the content hashes
+// before and after the append, letting the worker detect intervening changes.
+func (jc *AsynqJobClient) EnqueueEmbeddingAppendJob(ctx context.Context, contentID int64, fromHash, toHash, text string) error {
+	payload := map[string]interface{}{"content_id": contentID, "from_hash": fromHash, "to_hash": toHash, "text": text}
+	task := asynq.NewTask(tasks.TypeEmbeddingAppendJob, encodePayload(payload))
+	_, err := jc.Enqueue(ctx, task, "content", contentID, asynq.Queue("embeddings"))
+	if err != nil {
+		return fmt.Errorf("enqueue embedding append job for content %d: %w", contentID, err)
+	}
+	return nil
+}
+
+// EnqueueBatchEmbeddingJob enqueues a single job that embeds all the content
+// items, sharing embedding API calls between them.
+func (jc *AsynqJobClient) EnqueueBatchEmbeddingJob(ctx context.Context, contentIDs []int64) error {
+	if len(contentIDs) == 0 {
+		return nil
+	}
+	payload := map[string]interface{}{"content_ids": contentIDs}
+	task := asynq.NewTask(tasks.TypeBatchEmbeddingJob, encodePayload(payload))
+	_, err := jc.Enqueue(ctx, task, "", 0, asynq.Queue("embeddings"))
+	if err != nil {
+		return fmt.Errorf("enqueue batch embedding job for %d content items: %w", len(contentIDs), err)
+	}
+	return nil
+}
+
 func encodePayload(data map[string]interface{}) []byte {
 	// naive JSON encode with no error handling for brevity
 	b, _ := json.Marshal(data)
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+
+	"mimir/internal/tasks"
+)
+
+// embeddingTaskTypes are the task types WithoutEmbeddingJobs drops.
+var embeddingTaskTypes = map[string]bool{
+	tasks.TypeEmbeddingJob:               true,
+	tasks.TypeEmbeddingCheckBatch:        true,
+	tasks.TypeEmbeddingMetadataUpdateJob: true,
+	tasks.TypeEmbeddingAppendJob:         true,
+	tasks.TypeBatchEmbeddingJob:          true,
+}
+
+// WithoutEmbeddingJobs wraps jc for keyword-only deployments (embedding.required
+// false with no embedding provider): the embedding jobs enqueued automatically
+// when content changes are dropped rather than left to fail and pile up, and
+// embedding tasks enqueued explicitly fail with ErrEmbeddingJobsDisabled.
+// Other jobs are enqueued as usual.
+func WithoutEmbeddingJobs(jc JobClient) JobClient {
+	return &keywordOnlyJobClient{JobClient: jc}
+}
+
+type keywordOnlyJobClient struct {
+	JobClient
+}
+
+// Enqueue rejects embedding tasks, such as dead embedding jobs being retried.
+func (c *keywordOnlyJobClient) Enqueue(ctx context.Context, task *asynq.Task, relatedEntityType string, relatedEntityID int64, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	if embeddingTaskTypes[task.Type()] {
+		return nil, fmt.Errorf("enqueue %s: %w", task.Type(), ErrEmbeddingJobsDisabled)
+	}
+	return c.JobClient.Enqueue(ctx, task, relatedEntityType, relatedEntityID, opts...)
+}
+
+func (c *keywordOnlyJobClient) EnqueueEmbeddingJob(ctx context.Context, contentID int64) error {
+	return nil
+}
+
+func (c *keywordOnlyJobClient) EnqueueReindexEmbeddingJob(ctx context.Context, contentID, runID int64) error {
+	return nil
+}
+
+func (c *keywordOnlyJobClient) EnqueueEmbeddingMetadataUpdateJob(ctx context.Context, contentID int64) error {
+	return nil
+}
+
+func (c *keywordOnlyJobClient) EnqueueEmbeddingAppendJob(ctx context.Context, contentID int64, fromHash, toHash, text string) error {
+	return nil
+}
+
+func (c *keywordOnlyJobClient) EnqueueBatchEmbeddingJob(ctx context.Context, contentIDs []int64) error {
+	return nil
+}
+
+var _ JobClient = (*keywordOnlyJobClient)(nil)
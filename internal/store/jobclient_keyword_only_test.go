@@ -0,0 +1,41 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/tasks"
+)
+
+type countingJobClient struct {
+	JobClient
+	enqueued []string
+}
+
+func (c *countingJobClient) Enqueue(ctx context.Context, task *asynq.Task, relatedEntityType string, relatedEntityID int64, opts ...asynq.Option) (*asynq.TaskInfo, error) {
+	c.enqueued = append(c.enqueued, task.Type())
+	return &asynq.TaskInfo{Type: task.Type()}, nil
+}
+
+func TestWithoutEmbeddingJobs(t *testing.T) {
+	inner := &countingJobClient{}
+	jc := WithoutEmbeddingJobs(inner)
+	ctx := context.Background()
+
+	assert.NoError(t, jc.EnqueueEmbeddingJob(ctx, 1))
+	assert.NoError(t, jc.EnqueueReindexEmbeddingJob(ctx, 1, 2))
+	assert.NoError(t, jc.EnqueueEmbeddingMetadataUpdateJob(ctx, 1))
+	assert.NoError(t, jc.EnqueueEmbeddingAppendJob(ctx, 1, "a", "b", "text"))
+	assert.NoError(t, jc.EnqueueBatchEmbeddingJob(ctx, []int64{1, 2}))
+
+	_, err := jc.Enqueue(ctx, asynq.NewTask(tasks.TypeEmbeddingJob, nil), "content", 1)
+	assert.ErrorIs(t, err, ErrEmbeddingJobsDisabled)
+
+	_, err = jc.Enqueue(ctx, asynq.NewTask(tasks.TypeSummarizationJob, nil), "content", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{tasks.TypeSummarizationJob}, inner.enqueued, "only non-embedding jobs reach the queue")
+}
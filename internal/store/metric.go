@@ -0,0 +1,49 @@
+package store
+
+import "fmt"
+
+// Distance metrics of VectorStore.SimilaritySearch (database.vector.metric).
+const (
+	DistanceMetricL2           = "l2"            // Euclidean distance (<->); the default
+	DistanceMetricCosine       = "cosine"        // Cosine distance (<=>), recommended for OpenAI embeddings
+	DistanceMetricInnerProduct = "inner_product" // Negative inner product (<#>)
+)
+
+// ParseDistanceMetric validates a configured distance metric; empty means DistanceMetricL2.
+func ParseDistanceMetric(metric string) (string, error) {
+	switch metric {
+	case "":
+		return DistanceMetricL2, nil
+	case DistanceMetricL2, DistanceMetricCosine, DistanceMetricInnerProduct:
+		return metric, nil
+	}
+	return "", fmt.Errorf("distance metric must be %q, %q or %q, got %q",
+		DistanceMetricL2, DistanceMetricCosine, DistanceMetricInnerProduct, metric)
+}
+
+// Similarity converts a distance under metric into a similarity, higher is
+// closer: 1/(1+distance) in (0, 1] for L2, 1-distance (the cosine similarity)
+// in [-1, 1] for cosine, and the inner product itself for inner_product.
+// An empty metric is L2.
+func Similarity(metric string, distance float64) float64 {
+	switch metric {
+	case DistanceMetricCosine:
+		return 1 - distance
+	case DistanceMetricInnerProduct:
+		return -distance
+	default:
+		return 1 / (1 + distance)
+	}
+}
+
+// DistanceFromSimilarity is the inverse of Similarity.
+func DistanceFromSimilarity(metric string, similarity float64) float64 {
+	switch metric {
+	case DistanceMetricCosine:
+		return 1 - similarity
+	case DistanceMetricInnerProduct:
+		return -similarity
+	default:
+		return 1/similarity - 1
+	}
+}
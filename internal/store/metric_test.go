@@ -0,0 +1,31 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDistanceMetric(t *testing.T) {
+	metric, err := ParseDistanceMetric("")
+	require.NoError(t, err)
+	assert.Equal(t, DistanceMetricL2, metric)
+
+	metric, err = ParseDistanceMetric(DistanceMetricCosine)
+	require.NoError(t, err)
+	assert.Equal(t, DistanceMetricCosine, metric)
+
+	_, err = ParseDistanceMetric("manhattan")
+	assert.Error(t, err)
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.InDelta(t, 0.5, Similarity(DistanceMetricL2, 1), 1e-9)
+	assert.InDelta(t, 0.75, Similarity(DistanceMetricCosine, 0.25), 1e-9)
+	assert.InDelta(t, 0.9, Similarity(DistanceMetricInnerProduct, -0.9), 1e-9)
+
+	for _, metric := range []string{DistanceMetricL2, DistanceMetricCosine, DistanceMetricInnerProduct} {
+		assert.InDelta(t, 0.4, DistanceFromSimilarity(metric, Similarity(metric, 0.4)), 1e-9, metric)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"mimir/internal/config"
 	"mimir/internal/models"
 	"mimir/internal/store"
 
@@ -16,15 +17,29 @@ import (
 
 // --- Collection Management ---
 
+// collectionColumns are the collections columns scanned by scanCollection.
+const collectionColumns = `id, name, description, is_pinned, owner_id, created_at, updated_at`
+
+func scanCollection(row pgx.Row) (*models.Collection, error) {
+	c := &models.Collection{}
+	if err := row.Scan(&c.ID, &c.Name, &c.Description, &c.IsPinned, &c.OwnerID, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
 func (s *StoreImpl) CreateCollection(ctx context.Context, collection *models.Collection) error {
 	query := `
-		INSERT INTO collections (name, description, is_pinned, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO collections (name, description, is_pinned, owner_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, created_at, updated_at`
 
 	now := time.Now()
+	if collection.OwnerID == "" {
+		collection.OwnerID = store.DefaultOwnerID
+	}
 	err := s.db.QueryRow(ctx, query,
-		collection.Name, collection.Description, collection.IsPinned, now, now,
+		collection.Name, collection.Description, collection.IsPinned, collection.OwnerID, now, now,
 	).Scan(&collection.ID, &collection.CreatedAt, &collection.UpdatedAt)
 
 	if err != nil {
@@ -37,12 +52,9 @@ func (s *StoreImpl) CreateCollection(ctx context.Context, collection *models.Col
 	return nil
 }
 
-func (s *StoreImpl) GetCollection(ctx context.Context, id int64) (*models.Collection, error) {
-	query := `SELECT id, name, description, is_pinned, created_at, updated_at FROM collections WHERE id = $1`
-	c := &models.Collection{}
-	err := s.db.QueryRow(ctx, query, id).Scan(
-		&c.ID, &c.Name, &c.Description, &c.IsPinned, &c.CreatedAt, &c.UpdatedAt,
-	)
+func (s *StoreImpl) GetCollection(ctx context.Context, ownerID string, id int64) (*models.Collection, error) {
+	query := `SELECT ` + collectionColumns + ` FROM collections WHERE id = $1 AND ($2 = '' OR owner_id = $2)`
+	c, err := scanCollection(s.db.QueryRow(ctx, query, id, ownerID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, store.ErrNotFound
@@ -52,12 +64,9 @@ func (s *StoreImpl) GetCollection(ctx context.Context, id int64) (*models.Collec
 	return c, nil
 }
 
-func (s *StoreImpl) GetCollectionByName(ctx context.Context, name string) (*models.Collection, error) {
-	query := `SELECT id, name, description, is_pinned, created_at, updated_at FROM collections WHERE name = $1`
-	c := &models.Collection{}
-	err := s.db.QueryRow(ctx, query, name).Scan(
-		&c.ID, &c.Name, &c.Description, &c.IsPinned, &c.CreatedAt, &c.UpdatedAt,
-	)
+func (s *StoreImpl) GetCollectionByName(ctx context.Context, ownerID, name string) (*models.Collection, error) {
+	query := `SELECT ` + collectionColumns + ` FROM collections WHERE name = $1 AND ($2 = '' OR owner_id = $2)`
+	c, err := scanCollection(s.db.QueryRow(ctx, query, name, ownerID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, store.ErrNotFound
@@ -67,23 +76,31 @@ func (s *StoreImpl) GetCollectionByName(ctx context.Context, name string) (*mode
 	return c, nil
 }
 
-func (s *StoreImpl) ListCollections(ctx context.Context, limit, offset int, pinned *bool) ([]*models.Collection, error) {
-	query := `SELECT id, name, description, is_pinned, created_at, updated_at FROM collections`
+func (s *StoreImpl) ListCollections(ctx context.Context, ownerID string, limit, offset int, pinned *bool) ([]*models.Collection, error) {
+	query := `SELECT ` + collectionColumns + ` FROM collections`
+	if limit <= 0 {
+		limit = config.DefaultPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
 	args := []interface{}{}
-	whereClause := ""
+	var conditions []string
 	if pinned != nil {
-		whereClause = " WHERE is_pinned = $1"
 		args = append(args, *pinned)
+		conditions = append(conditions, fmt.Sprintf("is_pinned = $%d", len(args)))
 	}
-	query += whereClause + " ORDER BY name ASC LIMIT $2 OFFSET $3"
-	args = append(args, limit, offset)
-
-	if limit <= 0 {
-		limit = 50
+	if ownerID != "" {
+		args = append(args, ownerID)
+		conditions = append(conditions, fmt.Sprintf("owner_id = $%d", len(args)))
 	}
-	if offset < 0 {
-		offset = 0
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
 	}
+	query += whereClause + fmt.Sprintf(" ORDER BY name ASC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
 
 	rows, err := s.db.Query(ctx, query, args...)
 	if err != nil {
@@ -93,10 +110,7 @@ func (s *StoreImpl) ListCollections(ctx context.Context, limit, offset int, pinn
 
 	var collections []*models.Collection
 	for rows.Next() {
-		c := &models.Collection{}
-		err := rows.Scan(
-			&c.ID, &c.Name, &c.Description, &c.IsPinned, &c.CreatedAt, &c.UpdatedAt,
-		)
+		c, err := scanCollection(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan collection row: %w", err)
 		}
@@ -112,12 +126,12 @@ func (s *StoreImpl) UpdateCollection(ctx context.Context, collection *models.Col
 	query := `
 		UPDATE collections
 		SET name = $1, description = $2, is_pinned = $3, updated_at = $4
-		WHERE id = $5
+		WHERE id = $5 AND ($6 = '' OR owner_id = $6)
 		RETURNING updated_at`
 
 	now := time.Now()
 	err := s.db.QueryRow(ctx, query,
-		collection.Name, collection.Description, collection.IsPinned, now, collection.ID,
+		collection.Name, collection.Description, collection.IsPinned, now, collection.ID, collection.OwnerID,
 	).Scan(&collection.UpdatedAt)
 
 	if err != nil {
@@ -133,24 +147,71 @@ func (s *StoreImpl) UpdateCollection(ctx context.Context, collection *models.Col
 	return nil
 }
 
-func (s *StoreImpl) DeleteCollection(ctx context.Context, id int64) error {
+func (s *StoreImpl) DeleteCollection(ctx context.Context, ownerID string, id int64) (int64, error) {
 	// First delete collection_content associations
-	queryAssoc := `DELETE FROM collection_content WHERE collection_id = $1`
-	_, err := s.db.Exec(ctx, queryAssoc, id)
+	queryAssoc := `
+		DELETE FROM collection_content
+		WHERE collection_id = (SELECT id FROM collections WHERE id = $1 AND ($2 = '' OR owner_id = $2))`
+	assocTag, err := s.db.Exec(ctx, queryAssoc, id, ownerID)
 	if err != nil {
-		return fmt.Errorf("failed to delete collection-content associations for collection %d: %w", id, err)
+		return 0, fmt.Errorf("failed to delete collection-content associations for collection %d: %w", id, err)
 	}
 
 	// Then delete the collection itself
-	query := `DELETE FROM collections WHERE id = $1`
-	cmdTag, err := s.db.Exec(ctx, query, id)
+	query := `DELETE FROM collections WHERE id = $1 AND ($2 = '' OR owner_id = $2)`
+	cmdTag, err := s.db.Exec(ctx, query, id, ownerID)
 	if err != nil {
-		return fmt.Errorf("failed to delete collection %d: %w", id, err)
+		return 0, fmt.Errorf("failed to delete collection %d: %w", id, err)
 	}
 	if cmdTag.RowsAffected() == 0 {
-		return store.ErrNotFound
+		return 0, store.ErrNotFound
 	}
-	return nil
+	return assocTag.RowsAffected(), nil
+}
+
+func (s *StoreImpl) MergeCollections(ctx context.Context, ownerID string, sourceID, targetID int64, deleteSource bool) (int64, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin collection merge: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Both collections must belong to the owner; lock them against a concurrent delete
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM collections WHERE id IN ($1, $2) AND ($3 = '' OR owner_id = $3) FOR UPDATE`,
+		sourceID, targetID, ownerID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock collections %d and %d: %w", sourceID, targetID, err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock collections %d and %d: %w", sourceID, targetID, err)
+	}
+	if len(ids) != 2 {
+		return 0, store.ErrNotFound
+	}
+
+	moveQuery := `
+		INSERT INTO collection_content (collection_id, content_id, created_at)
+		SELECT $2, content_id, $3 FROM collection_content WHERE collection_id = $1
+		ON CONFLICT DO NOTHING`
+	moved, err := tx.Exec(ctx, moveQuery, sourceID, targetID, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to move content of collection %d to %d: %w", sourceID, targetID, err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM collection_content WHERE collection_id = $1`, sourceID); err != nil {
+		return 0, fmt.Errorf("failed to clear collection %d: %w", sourceID, err)
+	}
+	if deleteSource {
+		if _, err := tx.Exec(ctx, `DELETE FROM collections WHERE id = $1`, sourceID); err != nil {
+			return 0, fmt.Errorf("failed to delete collection %d: %w", sourceID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit collection merge: %w", err)
+	}
+	return moved.RowsAffected(), nil
 }
 
 func (s *StoreImpl) AddContentToCollection(ctx context.Context, collectionID, contentID int64) error {
@@ -177,14 +238,14 @@ func (s *StoreImpl) RemoveContentFromCollection(ctx context.Context, collectionI
 func (s *StoreImpl) GetCollectionContent(ctx context.Context, collectionID int64, limit, offset int) ([]*models.Content, error) {
 	query := `
 		SELECT c.id, c.source_id, c.title, c.body, c.content_hash, c.file_path, c.file_size, c.content_type, c.metadata, c.is_embedded, c.embedding_id, c.created_at, c.updated_at
-		FROM contents c
+		FROM content c
 		JOIN collection_content cc ON c.id = cc.content_id
 		WHERE cc.collection_id = $1
 		ORDER BY c.created_at DESC
 		LIMIT $2 OFFSET $3`
 
 	if limit <= 0 {
-		limit = 20
+		limit = config.DefaultPageSize
 	}
 	if offset < 0 {
 		offset = 0
@@ -218,7 +279,7 @@ func (s *StoreImpl) GetCollectionContent(ctx context.Context, collectionID int64
 func (s *StoreImpl) ListContentByCollection(ctx context.Context, collectionID int64, limit, offset int, sortBy, sortOrder string) ([]*models.Content, error) {
 	baseQuery := `
 		SELECT c.id, c.source_id, c.title, c.body, c.content_hash, c.file_path, c.file_size, c.content_type, c.metadata, c.is_embedded, c.embedding_id, c.created_at, c.updated_at
-		FROM contents c
+		FROM content c
 		JOIN collection_content cc ON c.id = cc.content_id
 		WHERE cc.collection_id = $1`
 
@@ -250,7 +311,7 @@ func (s *StoreImpl) ListContentByCollection(ctx context.Context, collectionID in
 
 	// Pagination
 	if limit <= 0 {
-		limit = 20
+		limit = config.DefaultPageSize
 	}
 	if offset < 0 {
 		offset = 0
@@ -321,5 +382,56 @@ func stringUpper(s string) string {
 	return map[bool]string{true: "ASC", false: "DESC"}[s == "ASC" || s == "asc"]
 }
 
+// ListCollectionContentIDs returns the IDs of all content in a collection.
+func (s *StoreImpl) ListCollectionContentIDs(ctx context.Context, collectionID int64) ([]int64, error) {
+	query := `SELECT content_id FROM collection_content WHERE collection_id = $1`
+
+	rows, err := s.db.Query(ctx, query, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list content IDs for collection %d: %w", collectionID, err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan content ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content ID rows: %w", err)
+	}
+	return ids, nil
+}
+
+func (s *StoreImpl) ListContentCollectionIDs(ctx context.Context, ownerID string, contentID int64) ([]int64, error) {
+	query := `
+		SELECT cc.collection_id FROM collection_content cc
+		JOIN collections c ON c.id = cc.collection_id
+		WHERE cc.content_id = $1 AND ($2 = '' OR c.owner_id = $2)
+		ORDER BY cc.collection_id`
+
+	rows, err := s.db.Query(ctx, query, contentID, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections of content %d: %w", contentID, err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan collection ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating collection ID rows: %w", err)
+	}
+	return ids, nil
+}
+
 // Ensure StoreImpl satisfies the CollectionStore interface
 var _ store.CollectionStore = (*StoreImpl)(nil)
@@ -6,21 +6,42 @@ import (
 	"strings"
 	"github.com/jackc/pgx/v5"
 	"mimir/internal/models"
+	"mimir/internal/store"
 )
 
 // Ensure pgx types are recognized as used, even if only implicitly via method calls.
 var _ pgx.Rows
 
-// KeywordSearchContent performs a full-text search on content body and title.
-// It also filters by tags if provided.
-func (s *StoreImpl) KeywordSearchContent(ctx context.Context, query string, filterTags []string) ([]*models.Content, error) {
+// keywordOrderClauses maps keyword sort orders to ORDER BY clauses. The id
+// tiebreaker keeps the order stable between calls.
+var keywordOrderClauses = map[string]string{
+	store.KeywordSortRelevance:  " ORDER BY rank DESC, c.id ASC",
+	store.KeywordSortCreatedAt:  " ORDER BY c.created_at DESC, c.id ASC",
+	store.KeywordSortModifiedAt: " ORDER BY c.modified_at DESC NULLS LAST, c.id ASC",
+}
+
+// KeywordSearchContent performs a full-text search on content body and title,
+// scoring each match with ts_rank. It also filters by tags if provided and
+// returns one page of matches when limit is positive.
+func (s *StoreImpl) KeywordSearchContent(ctx context.Context, query string, filterTags []string, sortBy string, ownerID string, limit, offset int) ([]store.KeywordMatch, error) {
+	if sortBy == "" {
+		sortBy = store.KeywordSortRelevance
+	}
+	orderByClause, ok := keywordOrderClauses[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported keyword sort order %q", sortBy)
+	}
+
+	args := []interface{}{query}
+	argID := 2
+
 	baseQuery := `
-		SELECT DISTINCT c.id, c.source_id, c.title, c.body, c.content_hash, c.file_path, c.file_size, c.content_type, c.metadata, c.embedding_id, c.is_embedded, c.last_accessed_at, c.modified_at, c.summary, c.created_at, c.updated_at
-		FROM contents c`
+		SELECT DISTINCT c.id, c.source_id, c.title, c.body, c.content_hash, c.file_path, c.file_size, c.content_type, c.metadata, c.embedding_id, c.is_embedded, c.last_accessed_at, c.modified_at, c.summary, c.created_at, c.updated_at,
+			c.owner_id, c.visibility,
+			ts_rank(` + s.keywordDocument("c.title || ' ' || c.body") + `, ` + s.keywordQuery("$1") + `) AS rank
+		FROM content c`
 	var joinClause string
 	var whereClauses []string
-	args := []interface{}{}
-	argID := 1
 
 	// Filter by tags if provided
 	if len(filterTags) > 0 {
@@ -34,12 +55,25 @@ func (s *StoreImpl) KeywordSearchContent(ctx context.Context, query string, filt
 		whereClauses = append(whereClauses, fmt.Sprintf("t.slug IN (%s)", strings.Join(tagPlaceholders, ",")))
 	}
 
+	if ownerID != "" {
+		whereClauses = append(whereClauses, visibleToOwnerClause(argID))
+		args = append(args, ownerID)
+		argID++
+	}
+
 	// Add full-text search condition
-	whereClauses = append(whereClauses, fmt.Sprintf("(to_tsvector('english', c.title) @@ plainto_tsquery('english', $%d) OR to_tsvector('english', c.body) @@ plainto_tsquery('english', $%d))", argID, argID))
-	args = append(args, query)
-	argID++
+	whereClauses = append(whereClauses, s.keywordMatchClause("$1"))
 
-	finalQuery := baseQuery + joinClause + " WHERE " + strings.Join(whereClauses, " AND ") + " ORDER BY c.created_at DESC" // Add default ordering
+	finalQuery := baseQuery + joinClause + " WHERE " + strings.Join(whereClauses, " AND ") + orderByClause
+	if limit > 0 {
+		finalQuery += fmt.Sprintf(" LIMIT $%d", argID)
+		args = append(args, limit)
+		argID++
+	}
+	if offset > 0 {
+		finalQuery += fmt.Sprintf(" OFFSET $%d", argID)
+		args = append(args, offset)
+	}
 
 	rows, err := s.db.Query(ctx, finalQuery, args...)
 	if err != nil {
@@ -47,14 +81,19 @@ func (s *StoreImpl) KeywordSearchContent(ctx context.Context, query string, filt
 	}
 	defer rows.Close()
 
-	var contents []*models.Content
+	var matches []store.KeywordMatch
 	for rows.Next() {
 		content := &models.Content{}
-		// Use the existing scanContent helper function (assuming it takes pgx.Rows)
-		if err := scanContent(rows, content); err != nil { // Use the package-level scanContent helper
+		var rank float32
+		if err := rows.Scan(
+			&content.ID, &content.SourceID, &content.Title, &content.Body, &content.ContentHash,
+			&content.FilePath, &content.FileSize, &content.ContentType, &content.Metadata,
+			&content.EmbeddingID, &content.IsEmbedded, &content.LastAccessedAt, &content.ModifiedAt,
+			&content.Summary, &content.CreatedAt, &content.UpdatedAt, &content.OwnerID, &content.Visibility, &rank,
+		); err != nil {
 			return nil, fmt.Errorf("failed to scan content row during keyword search: %w", err)
 		}
-		contents = append(contents, content)
+		matches = append(matches, store.KeywordMatch{Content: content, Rank: float64(rank)})
 	}
-	return contents, rows.Err()
+	return matches, rows.Err()
 }
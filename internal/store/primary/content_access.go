@@ -0,0 +1,83 @@
+package primary
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mimir/internal/config"
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// --- Content Access Store Implementation ---
+
+// RecordContentView inserts a view event for the content.
+func (s *StoreImpl) RecordContentView(ctx context.Context, contentID int64) error {
+	query := `INSERT INTO content_access (content_id, accessed_at) VALUES ($1, $2)`
+	if _, err := s.db.Exec(ctx, query, contentID, time.Now()); err != nil {
+		return fmt.Errorf("failed to record view of content %d: %w", contentID, err)
+	}
+	return nil
+}
+
+// ListRecentlyViewed returns viewed content, most recently viewed first.
+func (s *StoreImpl) ListRecentlyViewed(ctx context.Context, limit int) ([]store.ContentView, error) {
+	return s.listContentViews(ctx, limit, "last_viewed_at DESC")
+}
+
+// ListPopularContent returns viewed content, most viewed first.
+func (s *StoreImpl) ListPopularContent(ctx context.Context, limit int) ([]store.ContentView, error) {
+	return s.listContentViews(ctx, limit, "view_count DESC, last_viewed_at DESC")
+}
+
+// listContentViews aggregates view events per content item; orderBy is a fixed
+// clause chosen by the caller, never user input.
+func (s *StoreImpl) listContentViews(ctx context.Context, limit int, orderBy string) ([]store.ContentView, error) {
+	if limit <= 0 {
+		limit = config.DefaultPageSize
+	}
+	query := `
+		SELECT c.id, c.source_id, c.title, c.body, c.content_hash,
+			   c.file_path, c.file_size, c.content_type, c.metadata,
+			   c.summary, c.is_embedded, c.embedding_id, c.is_pinned, c.created_at, c.updated_at, c.modified_at,
+			   v.view_count, v.last_viewed_at
+		FROM (
+			SELECT content_id, COUNT(*) AS view_count, MAX(accessed_at) AS last_viewed_at
+			FROM content_access
+			GROUP BY content_id
+		) v
+		JOIN content c ON c.id = v.content_id
+		ORDER BY ` + orderBy + `
+		LIMIT $1`
+
+	rows, err := s.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list content views: %w", err)
+	}
+	defer rows.Close()
+
+	views := []store.ContentView{}
+	for rows.Next() {
+		content := &models.Content{}
+		var view store.ContentView
+		err := rows.Scan(
+			&content.ID, &content.SourceID, &content.Title, &content.Body, &content.ContentHash,
+			&content.FilePath, &content.FileSize, &content.ContentType, &content.Metadata,
+			&content.Summary, &content.IsEmbedded, &content.EmbeddingID, &content.IsPinned, &content.CreatedAt, &content.UpdatedAt,
+			&content.ModifiedAt,
+			&view.ViewCount, &view.LastViewedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan content view row: %w", err)
+		}
+		view.Content = content
+		views = append(views, view)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content view rows: %w", err)
+	}
+	return views, nil
+}
+
+var _ store.ContentAccessStore = (*StoreImpl)(nil)
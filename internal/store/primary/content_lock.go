@@ -0,0 +1,50 @@
+package primary
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"mimir/internal/store"
+)
+
+// contentUnlockTimeout bounds releasing a content lock, which runs after the
+// locked work, when its context may already be done.
+const contentUnlockTimeout = 5 * time.Second
+
+// contentLockNamespace is the first key of content locks ("mimr" in ASCII).
+// Two-key advisory locks never collide with single-key ones, such as the
+// migration tool's, or with two-key locks of other namespaces.
+const contentLockNamespace int32 = 0x6d696d72
+
+// LockContent takes a session-level Postgres advisory lock keyed by the content
+// ID on a connection reserved for the caller, waiting while another session
+// holds it. Unlike pg_advisory_xact_lock it needs no open transaction, so the
+// lock can span slow work such as calling an embedding API. The second key holds
+// the low 32 bits of the ID: content IDs 2^32 apart share a lock, which only
+// serializes their jobs.
+func (s *StoreImpl) LockContent(ctx context.Context, contentID int64) (func(), error) {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection to lock content %d: %w", contentID, err)
+	}
+	key := int32(contentID)
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1, $2)`, contentLockNamespace, key); err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("failed to lock content %d: %w", contentID, err)
+	}
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), contentUnlockTimeout)
+		defer cancel()
+		if _, err := conn.Exec(ctx, `SELECT pg_advisory_unlock($1, $2)`, contentLockNamespace, key); err != nil {
+			// Closing the connection ends its session, which releases the lock.
+			log.Printf("WARN: Failed to unlock content %d, closing its connection: %v", contentID, err)
+			conn.Conn().Close(ctx)
+		}
+		conn.Release()
+	}, nil
+}
+
+var _ store.ContentLocker = (*StoreImpl)(nil)
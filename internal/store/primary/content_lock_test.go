@@ -0,0 +1,38 @@
+package primary
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLockContent runs against a real database when MIMIR_TEST_PRIMARY_DSN is
+// set. The lock key is an ID no content uses.
+func TestLockContent(t *testing.T) {
+	dsn := os.Getenv("MIMIR_TEST_PRIMARY_DSN")
+	if dsn == "" {
+		t.Skip("MIMIR_TEST_PRIMARY_DSN not set")
+	}
+	ctx := context.Background()
+	s, err := NewPrimaryStore(ctx, dsn, 0)
+	require.NoError(t, err)
+	defer s.Close()
+
+	contentID := -time.Now().UnixNano() // Negative IDs never belong to content
+	unlock, err := s.LockContent(ctx, contentID)
+	require.NoError(t, err)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	_, err = s.LockContent(waitCtx, contentID)
+	assert.Error(t, err, "a second lock waits while the first is held")
+
+	unlock()
+	unlock2, err := s.LockContent(ctx, contentID)
+	require.NoError(t, err, "the lock is free again after unlock")
+	unlock2()
+}
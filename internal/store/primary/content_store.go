@@ -2,14 +2,13 @@ package primary
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"mimir/internal/config"
 	"mimir/internal/models"
 	"mimir/internal/store"
 
@@ -20,11 +19,39 @@ import (
 
 // --- Content Management ---
 
+// visibleToOwnerClause restricts content aliased c to rows the owner bound to
+// parameter argID may see: its own content and shared content.
+func visibleToOwnerClause(argID int) string {
+	return fmt.Sprintf("(c.owner_id = $%d OR c.visibility = 'shared')", argID)
+}
+
+// failedEmbeddingJobExists matches content aliased c with an embedding job that
+// failed or exhausted its retries. Requeued dead jobs are marked "retried".
+const failedEmbeddingJobExists = `EXISTS (SELECT 1 FROM background_jobs j
+	WHERE j.related_entity_type = 'content' AND j.related_entity_id = c.id
+	AND j.task_type LIKE 'embedding:%' AND j.status IN ('failed', 'dead'))`
+
+// embeddingFailed matches content aliased c with a failed embedding job or a
+// recorded embedding error (see MarkEmbeddingFailed).
+const embeddingFailed = `(COALESCE(c.metadata ? '` + store.EmbeddingErrorMetadataKey + `', FALSE) OR ` + failedEmbeddingJobExists + `)`
+
+// embeddingStatusClause restricts content aliased c to an embedding status.
+func embeddingStatusClause(status string) (string, error) {
+	switch status {
+	case store.EmbeddingStatusEmbedded:
+		return "c.is_embedded", nil
+	case store.EmbeddingStatusPending:
+		return "NOT c.is_embedded AND NOT " + embeddingFailed, nil
+	case store.EmbeddingStatusFailed:
+		return "NOT c.is_embedded AND " + embeddingFailed, nil
+	default:
+		return "", fmt.Errorf("unknown embedding status %q", status)
+	}
+}
+
 // calculateHash generates a SHA256 hash for the content body.
 func calculateHash(body string) string {
-	hasher := sha256.New()
-	hasher.Write([]byte(body))
-	return hex.EncodeToString(hasher.Sum(nil))
+	return store.ContentHash(body)
 }
 
 // CreateContent inserts a new content record.
@@ -35,9 +62,10 @@ func (s *StoreImpl) CreateContent(ctx context.Context, content *models.Content)
 		INSERT INTO content (
 			source_id, title, body, content_hash, 
 			file_path, file_size, content_type, 
-			metadata, summary, created_at, updated_at, modified_at
+			metadata, summary, created_at, updated_at, modified_at,
+			owner_id, visibility
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id, created_at, updated_at`
 
 	now := time.Now()
@@ -45,11 +73,18 @@ func (s *StoreImpl) CreateContent(ctx context.Context, content *models.Content)
 	if content.Metadata == nil {
 		content.Metadata = json.RawMessage("{}") // Default to empty JSON object
 	}
+	if content.OwnerID == "" {
+		content.OwnerID = store.DefaultOwnerID
+	}
+	if content.Visibility == "" {
+		content.Visibility = store.VisibilityPrivate
+	}
 
 	err := s.db.QueryRow(ctx, query,
 		content.SourceID, content.Title, content.Body, content.ContentHash,
 		content.FilePath, content.FileSize, content.ContentType, content.Metadata,
 		content.Summary, now, now, content.ModifiedAt,
+		content.OwnerID, content.Visibility,
 	).Scan(&content.ID, &content.CreatedAt, &content.UpdatedAt)
 
 	if err != nil {
@@ -69,11 +104,14 @@ func (s *StoreImpl) CreateContent(ctx context.Context, content *models.Content)
 	return nil
 }
 
-// CreateContentIfNotExists checks for existing content by hash before inserting.
+// CreateContentIfNotExists checks for existing content of the same owner by hash before inserting.
 // Returns true if content already existed (based on hash), false otherwise.
 func (s *StoreImpl) CreateContentIfNotExists(ctx context.Context, content *models.Content) (bool, error) {
 	content.ContentHash = calculateHash(content.Body)
-	existing, err := s.FindContentByHash(ctx, content.ContentHash)
+	if content.OwnerID == "" {
+		content.OwnerID = store.DefaultOwnerID
+	}
+	existing, err := s.FindContentByHash(ctx, content.OwnerID, content.ContentHash)
 	if err != nil && !errors.Is(err, store.ErrNotFound) {
 		return false, fmt.Errorf("failed checking for existing content by hash: %w", err)
 	}
@@ -84,13 +122,13 @@ func (s *StoreImpl) CreateContentIfNotExists(ctx context.Context, content *model
 	}
 
 	// Content doesn't exist, create it
-	err = s.CreateContent(ctx, content)
+	err = s.createContentNested(ctx, content)
 	if err != nil {
 		// Handle potential race condition if another process inserted between check and create
 		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation on hash
+		if errors.Is(err, store.ErrDuplicate) || (errors.As(err, &pgErr) && pgErr.Code == "23505") { // unique_violation on hash
 			// Re-fetch the content that was just inserted by the other process
-			existing, errFetch := s.FindContentByHash(ctx, content.ContentHash)
+			existing, errFetch := s.FindContentByHash(ctx, content.OwnerID, content.ContentHash)
 			if errFetch != nil {
 				return false, fmt.Errorf("failed to fetch concurrently inserted content (hash %s): %w", content.ContentHash, errFetch)
 			}
@@ -108,11 +146,28 @@ func (s *StoreImpl) CreateContentIfNotExists(ctx context.Context, content *model
 	return false, nil // Indicate content was newly created
 }
 
+// createContentNested inserts content in a nested transaction (a savepoint inside
+// RunInTx), so a unique violation does not abort an enclosing transaction before
+// CreateContentIfNotExists re-fetches the concurrently inserted row.
+func (s *StoreImpl) createContentNested(ctx context.Context, content *models.Content) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin content insert: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := (&StoreImpl{pool: s.pool, db: tx}).CreateContent(ctx, content); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
 func (s *StoreImpl) GetContent(ctx context.Context, id int64) (*models.Content, error) {
 	query := `
 		SELECT id, source_id, title, body, content_hash, 
 			   file_path, file_size, content_type, metadata, 
-			   summary, is_embedded, embedding_id, created_at, updated_at, modified_at
+			   summary, is_embedded, embedding_id, created_at, updated_at, modified_at, is_pinned,
+			   embedded_hash, owner_id, visibility, embedding_input_version
 		FROM content
 		WHERE id = $1`
 	content := &models.Content{}
@@ -120,7 +175,8 @@ func (s *StoreImpl) GetContent(ctx context.Context, id int64) (*models.Content,
 		&content.ID, &content.SourceID, &content.Title, &content.Body, &content.ContentHash,
 		&content.FilePath, &content.FileSize, &content.ContentType, &content.Metadata,
 		&content.Summary, &content.IsEmbedded, &content.EmbeddingID, &content.CreatedAt, &content.UpdatedAt,
-		&content.ModifiedAt,
+		&content.ModifiedAt, &content.IsPinned, &content.EmbeddedHash, &content.OwnerID, &content.Visibility,
+		&content.InputVersion,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -139,7 +195,8 @@ func (s *StoreImpl) GetContentsByIDs(ctx context.Context, ids []int64) ([]*model
 	query := `
 		SELECT id, source_id, title, body, content_hash, 
 			   file_path, file_size, content_type, metadata, 
-			   summary, is_embedded, embedding_id, created_at, updated_at, modified_at
+			   summary, is_embedded, embedding_id, created_at, updated_at, modified_at,
+			   owner_id, visibility
 		FROM content
 		WHERE id = ANY($1)` // Use ANY for efficient lookup
 
@@ -156,7 +213,7 @@ func (s *StoreImpl) GetContentsByIDs(ctx context.Context, ids []int64) ([]*model
 			&content.ID, &content.SourceID, &content.Title, &content.Body, &content.ContentHash,
 			&content.FilePath, &content.FileSize, &content.ContentType, &content.Metadata,
 			&content.Summary, &content.IsEmbedded, &content.EmbeddingID, &content.CreatedAt, &content.UpdatedAt,
-			&content.ModifiedAt,
+			&content.ModifiedAt, &content.OwnerID, &content.Visibility,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed scanning content row: %w", err)
@@ -219,6 +276,42 @@ func (s *StoreImpl) UpdateContent(ctx context.Context, content *models.Content)
 	return nil
 }
 
+// RehashContent recomputes content_hash from the stored body. An embedded_hash
+// that matched the old hash is moved along, so the embeddings are not reported
+// as stale merely because the hashing rules changed.
+func (s *StoreImpl) RehashContent(ctx context.Context, id int64) (bool, error) {
+	var body, oldHash string
+	err := s.db.QueryRow(ctx, `SELECT body, content_hash FROM content WHERE id = $1`, id).Scan(&body, &oldHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, store.ErrNotFound
+		}
+		return false, fmt.Errorf("failed to get content %d for rehash: %w", id, err)
+	}
+
+	newHash := calculateHash(body)
+	if newHash == oldHash {
+		return false, nil
+	}
+
+	// Matching on the old hash leaves content edited in the meantime alone; its
+	// update already stored a current hash.
+	query := `
+		UPDATE content SET
+			content_hash = $1,
+			embedded_hash = CASE WHEN embedded_hash = content_hash THEN $1 ELSE embedded_hash END
+		WHERE id = $2 AND content_hash = $3`
+	cmdTag, err := s.db.Exec(ctx, query, newHash, id, oldHash)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return false, fmt.Errorf("content with hash %s already exists: %w", newHash, store.ErrDuplicate)
+		}
+		return false, fmt.Errorf("failed to rehash content %d: %w", id, err)
+	}
+	return cmdTag.RowsAffected() > 0, nil
+}
+
 func (s *StoreImpl) DeleteContent(ctx context.Context, id int64) error {
 	// We might need to delete associated tags first if foreign keys have ON DELETE RESTRICT
 	// Delete associations from content_tags
@@ -249,11 +342,14 @@ func (s *StoreImpl) DeleteContent(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (s *StoreImpl) ListContent(ctx context.Context, limit, offset int, sortBy, sortOrder string, filterTags []string) ([]*models.Content, error) {
+// ListContent lists content, optionally restricted to items carrying any of
+// filterTags and, when pinned is non-nil, to items with that pinned state.
+func (s *StoreImpl) ListContent(ctx context.Context, limit, offset int, sortBy, sortOrder string, filterTags []string, pinned *bool, ownerID, embeddingStatus string) ([]*models.Content, error) {
 	baseQuery := `
 		SELECT DISTINCT c.id, c.source_id, c.title, c.body, c.content_hash, 
 						c.file_path, c.file_size, c.content_type, c.metadata, 
-						c.summary, c.is_embedded, c.embedding_id, c.created_at, c.updated_at, c.modified_at
+						c.summary, c.is_embedded, c.embedding_id, c.created_at, c.updated_at, c.modified_at,
+						c.is_pinned, c.owner_id, c.visibility
 		FROM content c`
 	var joinClause string
 	var whereClause string
@@ -274,6 +370,41 @@ func (s *StoreImpl) ListContent(ctx context.Context, limit, offset int, sortBy,
 		// If filtering by slug: WHERE t.slug IN (...)
 	}
 
+	if pinned != nil {
+		if whereClause == "" {
+			whereClause = " WHERE "
+		} else {
+			whereClause += " AND "
+		}
+		whereClause += fmt.Sprintf("c.is_pinned = $%d", argID)
+		args = append(args, *pinned)
+		argID++
+	}
+
+	if ownerID != "" {
+		if whereClause == "" {
+			whereClause = " WHERE "
+		} else {
+			whereClause += " AND "
+		}
+		whereClause += visibleToOwnerClause(argID)
+		args = append(args, ownerID)
+		argID++
+	}
+
+	if embeddingStatus != "" {
+		clause, err := embeddingStatusClause(embeddingStatus)
+		if err != nil {
+			return nil, err
+		}
+		if whereClause == "" {
+			whereClause = " WHERE "
+		} else {
+			whereClause += " AND "
+		}
+		whereClause += clause
+	}
+
 	// Sorting
 	validSortColumns := map[string]bool{"c.id": true, "c.title": true, "c.created_at": true, "c.updated_at": true, "c.modified_at": true} // Add modified_at
 	if !validSortColumns[sortBy] {
@@ -287,7 +418,7 @@ func (s *StoreImpl) ListContent(ctx context.Context, limit, offset int, sortBy,
 
 	// Pagination
 	if limit <= 0 {
-		limit = 20
+		limit = config.DefaultPageSize
 	}
 	if offset < 0 {
 		offset = 0
@@ -311,7 +442,7 @@ func (s *StoreImpl) ListContent(ctx context.Context, limit, offset int, sortBy,
 			&content.ID, &content.SourceID, &content.Title, &content.Body, &content.ContentHash,
 			&content.FilePath, &content.FileSize, &content.ContentType, &content.Metadata,
 			&content.Summary, &content.IsEmbedded, &content.EmbeddingID, &content.CreatedAt, &content.UpdatedAt,
-			&content.ModifiedAt,
+			&content.ModifiedAt, &content.IsPinned, &content.OwnerID, &content.Visibility,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan content row: %w", err)
@@ -326,19 +457,20 @@ func (s *StoreImpl) ListContent(ctx context.Context, limit, offset int, sortBy,
 	return contents, nil
 }
 
-func (s *StoreImpl) FindContentByHash(ctx context.Context, hash string) (*models.Content, error) {
+func (s *StoreImpl) FindContentByHash(ctx context.Context, ownerID, hash string) (*models.Content, error) {
 	query := `
 		SELECT id, source_id, title, body, content_hash, 
 			   file_path, file_size, content_type, metadata, 
-			   summary, is_embedded, embedding_id, created_at, updated_at, modified_at
+			   summary, is_embedded, embedding_id, created_at, updated_at, modified_at,
+			   owner_id, visibility
 		FROM content
-		WHERE content_hash = $1`
+		WHERE content_hash = $1 AND owner_id = $2`
 	content := &models.Content{}
-	err := s.db.QueryRow(ctx, query, hash).Scan(
+	err := s.db.QueryRow(ctx, query, hash, ownerID).Scan(
 		&content.ID, &content.SourceID, &content.Title, &content.Body, &content.ContentHash,
 		&content.FilePath, &content.FileSize, &content.ContentType, &content.Metadata,
 		&content.Summary, &content.IsEmbedded, &content.EmbeddingID, &content.CreatedAt, &content.UpdatedAt,
-		&content.ModifiedAt,
+		&content.ModifiedAt, &content.OwnerID, &content.Visibility,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -350,7 +482,14 @@ func (s *StoreImpl) FindContentByHash(ctx context.Context, hash string) (*models
 }
 
 func (s *StoreImpl) UpdateContentEmbeddingStatus(ctx context.Context, contentID int64, embeddingID uuid.UUID, isEmbedded bool) error {
-	query := `UPDATE content SET is_embedded = $1, embedding_id = $2, updated_at = $3 WHERE id = $4`
+	// Remember which body version was embedded so stale embeddings can be detected later.
+	// Embedding archived content again brings it out of the archive and clears
+	// the error of an earlier failed embedding.
+	query := `UPDATE content SET is_embedded = $1, embedding_id = $2,
+		embedded_hash = CASE WHEN $1 THEN content_hash ELSE NULL END,
+		archived_at = CASE WHEN $1 THEN NULL ELSE archived_at END,
+		metadata = CASE WHEN $1 THEN metadata - '` + store.EmbeddingErrorMetadataKey + `' ELSE metadata END, updated_at = $3
+		WHERE id = $4`
 	now := time.Now()
 	commandTag, err := s.db.Exec(ctx, query, isEmbedded, embeddingID, now, contentID)
 	if err != nil {
@@ -362,5 +501,307 @@ func (s *StoreImpl) UpdateContentEmbeddingStatus(ctx context.Context, contentID
 	return nil
 }
 
+// MarkEmbeddingFailed merges {"error": reason, "failed_at": now} into the
+// content's metadata under store.EmbeddingErrorMetadataKey. Content whose
+// metadata is not a JSON object is left alone rather than overwritten.
+func (s *StoreImpl) MarkEmbeddingFailed(ctx context.Context, contentID int64, reason string) error {
+	query := `UPDATE content SET metadata = COALESCE(metadata, '{}'::jsonb) ||
+			jsonb_build_object($1::text, jsonb_build_object('error', $2::text, 'failed_at', $3::timestamptz))
+		WHERE id = $4 AND NOT is_embedded AND (metadata IS NULL OR jsonb_typeof(metadata) = 'object')`
+	if _, err := s.db.Exec(ctx, query, store.EmbeddingErrorMetadataKey, reason, time.Now(), contentID); err != nil {
+		return fmt.Errorf("failed to record embedding error of content %d: %w", contentID, err)
+	}
+	return nil
+}
+
+// ClearContentEmbedding marks the content not embedded and nulls embedding_id
+// and embedded_hash, so nothing looks up an embedding that is being deleted.
+func (s *StoreImpl) ClearContentEmbedding(ctx context.Context, contentID int64) error {
+	query := `UPDATE content SET is_embedded = FALSE, embedding_id = NULL, embedded_hash = NULL, updated_at = $1
+		WHERE id = $2`
+	commandTag, err := s.db.Exec(ctx, query, time.Now(), contentID)
+	if err != nil {
+		return fmt.Errorf("failed to clear embedding of content %d: %w", contentID, err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// SetEmbeddingInputVersion records the input template version of the content's embeddings.
+func (s *StoreImpl) SetEmbeddingInputVersion(ctx context.Context, contentID int64, version string) error {
+	query := `UPDATE content SET embedding_input_version = $1 WHERE id = $2`
+	commandTag, err := s.db.Exec(ctx, query, version, contentID)
+	if err != nil {
+		return fmt.Errorf("failed to set embedding input version for content %d: %w", contentID, err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// ListOutdatedInputVersion returns the IDs of embedded content built from an
+// input template version other than version; a NULL version is defaultVersion.
+func (s *StoreImpl) ListOutdatedInputVersion(ctx context.Context, version, defaultVersion string) ([]int64, error) {
+	query := `
+		SELECT id FROM content
+		WHERE is_embedded AND COALESCE(embedding_input_version, $2) <> $1
+		ORDER BY id`
+	rows, err := s.db.Query(ctx, query, version, defaultVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list content with outdated embedding input: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan content ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content ID rows: %w", err)
+	}
+	return ids, nil
+}
+
+// ListFailedEmbeddingContentIDs returns the IDs among ids of unembedded content
+// with a failed or dead embedding job or a recorded embedding error.
+func (s *StoreImpl) ListFailedEmbeddingContentIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	query := `
+		SELECT c.id FROM content c
+		WHERE c.id = ANY($1) AND NOT c.is_embedded AND ` + embeddingFailed + `
+		ORDER BY c.id`
+	rows, err := s.db.Query(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list content with failed embedding jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var failed []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan content ID: %w", err)
+		}
+		failed = append(failed, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content ID rows: %w", err)
+	}
+	return failed, nil
+}
+
+// ListStaleEmbeddings returns embedded content whose body changed after it was
+// embedded. Content embedded before embedded_hash was tracked is not reported.
+func (s *StoreImpl) ListStaleEmbeddings(ctx context.Context) ([]*models.Content, error) {
+	query := `
+		SELECT id, source_id, title, body, content_hash,
+			   file_path, file_size, content_type, metadata,
+			   summary, is_embedded, embedded_hash, embedding_id, created_at, updated_at, modified_at
+		FROM content
+		WHERE is_embedded AND embedded_hash IS NOT NULL AND embedded_hash <> content_hash
+		ORDER BY id`
+
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	var contents []*models.Content
+	for rows.Next() {
+		content := &models.Content{}
+		err := rows.Scan(
+			&content.ID, &content.SourceID, &content.Title, &content.Body, &content.ContentHash,
+			&content.FilePath, &content.FileSize, &content.ContentType, &content.Metadata,
+			&content.Summary, &content.IsEmbedded, &content.EmbeddedHash, &content.EmbeddingID, &content.CreatedAt, &content.UpdatedAt,
+			&content.ModifiedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan content row: %w", err)
+		}
+		contents = append(contents, content)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content rows: %w", err)
+	}
+	return contents, nil
+}
+
+// UpdateContentSource reassigns content to another source without touching the body or hash.
+func (s *StoreImpl) UpdateContentSource(ctx context.Context, contentID, sourceID int64) error {
+	query := `UPDATE content SET source_id = $1, updated_at = $2 WHERE id = $3`
+	commandTag, err := s.db.Exec(ctx, query, sourceID, time.Now(), contentID)
+	if err != nil {
+		return fmt.Errorf("failed to update source for content %d: %w", contentID, err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// SetContentPinned sets the pinned flag on a content item.
+func (s *StoreImpl) SetContentPinned(ctx context.Context, contentID int64, pinned bool) error {
+	query := `UPDATE content SET is_pinned = $1, updated_at = $2 WHERE id = $3`
+	commandTag, err := s.db.Exec(ctx, query, pinned, time.Now(), contentID)
+	if err != nil {
+		return fmt.Errorf("failed to set pinned for content %d: %w", contentID, err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// TouchContentAccessed records that the content was just returned by a search.
+func (s *StoreImpl) TouchContentAccessed(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := `UPDATE content SET last_accessed_at = $1 WHERE id = ANY($2)`
+	if _, err := s.db.Exec(ctx, query, time.Now(), ids); err != nil {
+		return fmt.Errorf("failed to update last access for %d content items: %w", len(ids), err)
+	}
+	return nil
+}
+
+// ListArchivableContent returns embedded content that is not archived yet and
+// was last accessed (or, with byAge, created) before cutoff. Content never
+// returned by a search counts as last accessed when it was created.
+func (s *StoreImpl) ListArchivableContent(ctx context.Context, cutoff time.Time, byAge bool) ([]*models.Content, error) {
+	usedAt := "COALESCE(last_accessed_at, created_at)"
+	if byAge {
+		usedAt = "created_at"
+	}
+	query := `
+		SELECT id, source_id, title, content_hash, content_type, is_embedded,
+			   created_at, updated_at, last_accessed_at
+		FROM content
+		WHERE is_embedded AND archived_at IS NULL AND NOT is_pinned AND ` + usedAt + ` < $1
+		ORDER BY ` + usedAt + `, id`
+
+	rows, err := s.db.Query(ctx, query, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archivable content: %w", err)
+	}
+	defer rows.Close()
+
+	var contents []*models.Content
+	for rows.Next() {
+		content := &models.Content{}
+		if err := rows.Scan(
+			&content.ID, &content.SourceID, &content.Title, &content.ContentHash, &content.ContentType, &content.IsEmbedded,
+			&content.CreatedAt, &content.UpdatedAt, &content.LastAccessedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan archivable content row: %w", err)
+		}
+		contents = append(contents, content)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating archivable content rows: %w", err)
+	}
+	return contents, nil
+}
+
+// MarkContentArchived clears the content's embedding status and stamps archived_at.
+func (s *StoreImpl) MarkContentArchived(ctx context.Context, contentID int64) error {
+	query := `UPDATE content SET is_embedded = FALSE, embedding_id = NULL, embedded_hash = NULL,
+		embedding_input_version = NULL, archived_at = $1, updated_at = $1
+		WHERE id = $2`
+	commandTag, err := s.db.Exec(ctx, query, time.Now(), contentID)
+	if err != nil {
+		return fmt.Errorf("failed to archive content %d: %w", contentID, err)
+	}
+	if commandTag.RowsAffected() == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// ListContentIDs returns the IDs of all content matching the filter, in ID
+// order. query, when set, must match the title or body as full text; filterTags
+// match tag names, any of which qualifies.
+func (s *StoreImpl) ListContentIDs(ctx context.Context, query string, filterTags []string, pinned *bool) ([]int64, error) {
+	var whereClauses []string
+	args := []interface{}{}
+	argID := 1
+
+	if query != "" {
+		whereClauses = append(whereClauses, s.keywordMatchClause(fmt.Sprintf("$%d", argID)))
+		args = append(args, query)
+		argID++
+	}
+	if len(filterTags) > 0 {
+		whereClauses = append(whereClauses, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM content_tags ct JOIN tags t ON ct.tag_id = t.id WHERE ct.content_id = c.id AND t.name = ANY($%d))", argID))
+		args = append(args, filterTags)
+		argID++
+	}
+	if pinned != nil {
+		whereClauses = append(whereClauses, fmt.Sprintf("c.is_pinned = $%d", argID))
+		args = append(args, *pinned)
+	}
+
+	fullQuery := `SELECT c.id FROM content c`
+	if len(whereClauses) > 0 {
+		fullQuery += " WHERE " + strings.Join(whereClauses, " AND ")
+	}
+	fullQuery += " ORDER BY c.id"
+
+	rows, err := s.db.Query(ctx, fullQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list content IDs: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan content ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content IDs: %w", err)
+	}
+	return ids, nil
+}
+
+// ListContentIDsByContentType returns the IDs of content of the given media
+// type, in ID order; "text/html" matches "text/html; charset=utf-8".
+func (s *StoreImpl) ListContentIDsByContentType(ctx context.Context, contentType string) ([]int64, error) {
+	query := `SELECT id FROM content
+		WHERE lower(trim(split_part(content_type, ';', 1))) = lower($1)
+		ORDER BY id`
+	rows, err := s.db.Query(ctx, query, strings.TrimSpace(contentType))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list content IDs of type %q: %w", contentType, err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan content ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content IDs: %w", err)
+	}
+	return ids, nil
+}
+
 // Ensure StoreImpl satisfies the ContentStore interface
 var _ store.ContentStore = (*StoreImpl)(nil)
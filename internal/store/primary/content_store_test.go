@@ -0,0 +1,66 @@
+package primary
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// TestCreateContentIfNotExistsPerOwner runs against a real database when
+// MIMIR_TEST_PRIMARY_DSN is set. Its rows belong to unique owners and are
+// deleted afterwards.
+func TestCreateContentIfNotExistsPerOwner(t *testing.T) {
+	dsn := os.Getenv("MIMIR_TEST_PRIMARY_DSN")
+	if dsn == "" {
+		t.Skip("MIMIR_TEST_PRIMARY_DSN not set")
+	}
+	ctx := context.Background()
+	s, err := NewPrimaryStore(ctx, dsn, 0)
+	require.NoError(t, err)
+	defer s.Close()
+
+	run := time.Now().UnixNano()
+	ownerA, ownerB := fmt.Sprintf("test-dedupe-a-%d", run), fmt.Sprintf("test-dedupe-b-%d", run)
+	source := &models.Source{Name: ownerA, SourceType: "test"}
+	require.NoError(t, s.CreateSource(ctx, source))
+	var contentIDs []int64
+	t.Cleanup(func() {
+		_, err := s.db.Exec(ctx, `DELETE FROM content WHERE id = ANY($1)`, contentIDs)
+		require.NoError(t, err)
+		_, err = s.db.Exec(ctx, `DELETE FROM sources WHERE id = $1`, source.ID)
+		require.NoError(t, err)
+	})
+	body := fmt.Sprintf("identical text %d", run)
+	add := func(owner, title string) (*models.Content, bool) {
+		content := &models.Content{SourceID: source.ID, Title: title, Body: body, ContentType: "text/plain", OwnerID: owner, Visibility: store.VisibilityPrivate}
+		existed, err := s.CreateContentIfNotExists(ctx, content)
+		require.NoError(t, err)
+		if !existed {
+			contentIDs = append(contentIDs, content.ID)
+		}
+		return content, existed
+	}
+
+	a, existed := add(ownerA, "A's notes")
+	require.False(t, existed)
+	b, existed := add(ownerB, "B's notes")
+	assert.False(t, existed, "another owner's identical text is not a duplicate")
+	assert.NotEqual(t, a.ID, b.ID)
+	assert.Equal(t, ownerB, b.OwnerID)
+	assert.Equal(t, "B's notes", b.Title, "B never receives A's row")
+
+	again, existed := add(ownerA, "A again")
+	assert.True(t, existed, "the same owner's identical text is a duplicate")
+	assert.Equal(t, a.ID, again.ID)
+
+	_, err = s.FindContentByHash(ctx, ownerB+"-other", a.ContentHash)
+	assert.ErrorIs(t, err, store.ErrNotFound, "lookups are scoped to the owner")
+}
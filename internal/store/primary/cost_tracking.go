@@ -7,6 +7,7 @@ import (
 
 	"github.com/jackc/pgx/v5" // Import pgx
 	"mimir/internal/models"
+	"mimir/internal/store"
 	// "mimir/internal/store/primary/sqlc" // Import generated sqlc code - Not used in this file
 )
 
@@ -15,21 +16,35 @@ import (
 // 	db *pgxpool.Pool // Use pgxpool.Pool
 // }
 
-// RecordUsage inserts a new AI usage log entry.
+// RecordUsage inserts a new AI usage log entry. The call is billed to the
+// owner of the related content, so work done by background workers on behalf
+// of an owner is attributed to it; without related content it is billed to
+// log.OwnerID.
 func (s *StoreImpl) RecordUsage(ctx context.Context, log *models.AIUsageLog) error { // Implement on StoreImpl
-	query := `
-		INSERT INTO ai_usage_logs (
-			timestamp, provider_name, service_type, model_name,
-			input_tokens, output_tokens, cost,
-			related_content_id, related_job_id
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id
-	`
-	now := time.Now()
+	err := s.db.QueryRow(ctx, insertUsageQuery, insertUsageArgs(log)...).Scan(&log.ID, &log.OwnerID)
+	if err != nil {
+		return fmt.Errorf("failed to insert ai_usage_log: %w", err)
+	}
+	return nil
+}
+
+const insertUsageQuery = `
+	INSERT INTO ai_usage_logs (
+		timestamp, provider_name, service_type, model_name,
+		input_tokens, output_tokens, cost,
+		related_content_id, related_job_id, owner_id
+	) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9,
+		COALESCE((SELECT owner_id FROM content WHERE id = $8), NULLIF($10, ''), $11))
+	RETURNING id, owner_id
+`
+
+// insertUsageArgs returns the arguments of insertUsageQuery for log, stamping
+// it with the current time if it has no timestamp.
+func insertUsageArgs(log *models.AIUsageLog) []interface{} {
 	if log.Timestamp.IsZero() {
-		log.Timestamp = now
+		log.Timestamp = time.Now()
 	}
-	err := s.db.QueryRow(ctx, query,
+	return []interface{}{
 		log.Timestamp,
 		log.ProviderName,
 		log.ServiceType,
@@ -39,23 +54,47 @@ func (s *StoreImpl) RecordUsage(ctx context.Context, log *models.AIUsageLog) err
 		log.Cost,
 		log.RelatedContentID,
 		log.RelatedJobID,
-	).Scan(&log.ID)
+		log.OwnerID,
+		store.DefaultOwnerID,
+	}
+}
+
+// RecordUsageBatch inserts the logs in one transaction and round trip, billing
+// each as RecordUsage does. If any insert fails, none of the logs are recorded.
+func (s *StoreImpl) RecordUsageBatch(ctx context.Context, logs []*models.AIUsageLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to insert ai_usage_log: %w", err)
+		return fmt.Errorf("failed to begin ai_usage_log batch: %w", err)
 	}
-	return nil
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for _, log := range logs {
+		log := log
+		batch.Queue(insertUsageQuery, insertUsageArgs(log)...).QueryRow(func(row pgx.Row) error {
+			return row.Scan(&log.ID, &log.OwnerID)
+		})
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to insert %d ai_usage_logs: %w", len(logs), err)
+	}
+	return tx.Commit(ctx)
 }
 
-// ListUsage returns a list of AI usage logs, optionally filtered.
-func (s *StoreImpl) ListUsage(ctx context.Context, limit, offset int) ([]*models.AIUsageLog, error) { // Implement on StoreImpl
+// ListUsage returns a list of AI usage logs, limited to ownerID's when it is non-empty.
+func (s *StoreImpl) ListUsage(ctx context.Context, ownerID string, limit, offset int) ([]*models.AIUsageLog, error) { // Implement on StoreImpl
 	query := `
 		SELECT id, timestamp, provider_name, service_type, model_name,
-		       input_tokens, output_tokens, cost, related_content_id, related_job_id
+		       input_tokens, output_tokens, cost, related_content_id, related_job_id, owner_id
 		FROM ai_usage_logs
+		WHERE $3 = '' OR owner_id = $3
 		ORDER BY timestamp DESC
 		LIMIT $1 OFFSET $2
 	`
-	rows, err := s.db.Query(ctx, query, limit, offset) // Use db.Query
+	rows, err := s.db.Query(ctx, query, limit, offset, ownerID) // Use db.Query
 	if err != nil { // Use s.db
 		return nil, fmt.Errorf("failed to query ai_usage_logs: %w", err)
 	}
@@ -78,6 +117,7 @@ func (s *StoreImpl) ListUsage(ctx context.Context, limit, offset int) ([]*models
 			&log.Cost,
 			&log.RelatedContentID,
 			&log.RelatedJobID,
+			&log.OwnerID,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan ai_usage_log: %w", err)
@@ -88,18 +128,176 @@ func (s *StoreImpl) ListUsage(ctx context.Context, limit, offset int) ([]*models
 	return logs, err // Return collected logs and any error from CollectRows
 }
 
-// GetUsageSummary returns the total cost and token usage.
-func (s *StoreImpl) GetUsageSummary(ctx context.Context) (totalCost float64, totalInputTokens, totalOutputTokens int64, err error) { // Method receiver should be StoreImpl
+// GetUsageSummary returns the total cost and token usage, limited to ownerID's
+// when it is non-empty.
+func (s *StoreImpl) GetUsageSummary(ctx context.Context, ownerID string) (totalCost float64, totalInputTokens, totalOutputTokens int64, err error) { // Method receiver should be StoreImpl
 	query := `
 		SELECT
 			COALESCE(SUM(cost),0),
 			COALESCE(SUM(input_tokens),0),
 			COALESCE(SUM(output_tokens),0)
 		FROM ai_usage_logs
+		WHERE $1 = '' OR owner_id = $1
 	`
-	err = s.db.QueryRow(ctx, query).Scan(&totalCost, &totalInputTokens, &totalOutputTokens)
+	err = s.db.QueryRow(ctx, query, ownerID).Scan(&totalCost, &totalInputTokens, &totalOutputTokens)
 	if err != nil {
 		return 0, 0, 0, fmt.Errorf("failed to summarize ai_usage_logs: %w", err)
 	}
 	return totalCost, totalInputTokens, totalOutputTokens, nil
 }
+
+// GetUsageByOwner totals usage per owner for billing, highest cost first.
+func (s *StoreImpl) GetUsageByOwner(ctx context.Context) ([]store.OwnerUsage, error) {
+	query := `
+		SELECT owner_id, COUNT(*), COALESCE(SUM(input_tokens),0), COALESCE(SUM(output_tokens),0), COALESCE(SUM(cost),0)
+		FROM ai_usage_logs
+		GROUP BY owner_id
+		ORDER BY 5 DESC, owner_id ASC
+	`
+	rows, err := s.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize ai_usage_logs by owner: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []store.OwnerUsage
+	for rows.Next() {
+		var u store.OwnerUsage
+		if err := rows.Scan(&u.OwnerID, &u.Calls, &u.InputTokens, &u.OutputTokens, &u.Cost); err != nil {
+			return nil, fmt.Errorf("failed to scan owner usage row: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating owner usage rows: %w", err)
+	}
+	return usage, nil
+}
+
+// usageRangeArgs returns the bounds of r as query arguments, nil for open sides.
+func usageRangeArgs(r store.UsageRange) (from, to *time.Time) {
+	if !r.From.IsZero() {
+		from = &r.From
+	}
+	if !r.To.IsZero() {
+		to = &r.To
+	}
+	return from, to
+}
+
+// GetUsageSummaryByService totals the usage in r per service type, highest
+// cost first, limited to ownerID's when it is non-empty.
+func (s *StoreImpl) GetUsageSummaryByService(ctx context.Context, ownerID string, r store.UsageRange) ([]store.ServiceUsage, error) {
+	query := `
+		SELECT service_type, COUNT(*), COALESCE(SUM(input_tokens),0), COALESCE(SUM(output_tokens),0), COALESCE(SUM(cost),0)
+		FROM ai_usage_logs
+		WHERE ($1 = '' OR owner_id = $1)
+		  AND ($2::timestamp IS NULL OR timestamp >= $2)
+		  AND ($3::timestamp IS NULL OR timestamp < $3)
+		GROUP BY service_type
+		ORDER BY 5 DESC, service_type ASC
+	`
+	from, to := usageRangeArgs(r)
+	rows, err := s.db.Query(ctx, query, ownerID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize ai_usage_logs by service: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []store.ServiceUsage{}
+	for rows.Next() {
+		var u store.ServiceUsage
+		if err := rows.Scan(&u.ServiceType, &u.Calls, &u.InputTokens, &u.OutputTokens, &u.Cost); err != nil {
+			return nil, fmt.Errorf("failed to scan service usage row: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating service usage rows: %w", err)
+	}
+	return usage, nil
+}
+
+// StreamUsage streams the usage logs in r, oldest first, limited to ownerID's
+// when it is non-empty.
+func (s *StoreImpl) StreamUsage(ctx context.Context, ownerID string, r store.UsageRange, fn func(log *models.AIUsageLog) error) error {
+	query := `
+		SELECT id, timestamp, provider_name, service_type, model_name,
+		       input_tokens, output_tokens, cost, related_content_id, related_job_id, owner_id
+		FROM ai_usage_logs
+		WHERE ($1 = '' OR owner_id = $1)
+		  AND ($2::timestamp IS NULL OR timestamp >= $2)
+		  AND ($3::timestamp IS NULL OR timestamp < $3)
+		ORDER BY timestamp, id
+	`
+	from, to := usageRangeArgs(r)
+	rows, err := s.db.Query(ctx, query, ownerID, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to stream ai_usage_logs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var log models.AIUsageLog
+		if err := rows.Scan(&log.ID, &log.Timestamp, &log.ProviderName, &log.ServiceType, &log.ModelName,
+			&log.InputTokens, &log.OutputTokens, &log.Cost, &log.RelatedContentID, &log.RelatedJobID, &log.OwnerID); err != nil {
+			return fmt.Errorf("failed to scan ai_usage_log: %w", err)
+		}
+		if err := fn(&log); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating ai_usage_logs: %w", err)
+	}
+	return nil
+}
+
+// GetUsageGroups totals the usage in r per day and/or per provider and model,
+// limited to ownerID's when it is non-empty. Without either grouping it
+// returns a single total.
+func (s *StoreImpl) GetUsageGroups(ctx context.Context, ownerID string, r store.UsageRange, byDay, byModel bool) ([]store.UsageGroup, error) {
+	day := "NULL::timestamp"
+	if byDay {
+		day = "date_trunc('day', timestamp)"
+	}
+	provider, model := "''", "''"
+	if byModel {
+		provider, model = "provider_name", "model_name"
+	}
+	query := `
+		SELECT ` + day + `, ` + provider + `, ` + model + `, COUNT(*),
+		       COALESCE(SUM(input_tokens),0), COALESCE(SUM(output_tokens),0), COALESCE(SUM(cost),0)
+		FROM ai_usage_logs
+		WHERE ($1 = '' OR owner_id = $1)
+		  AND ($2::timestamp IS NULL OR timestamp >= $2)
+		  AND ($3::timestamp IS NULL OR timestamp < $3)
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3
+	`
+	from, to := usageRangeArgs(r)
+	rows, err := s.db.Query(ctx, query, ownerID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to group ai_usage_logs: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []store.UsageGroup
+	for rows.Next() {
+		var g store.UsageGroup
+		var dayStart *time.Time
+		if err := rows.Scan(&dayStart, &g.ProviderName, &g.ModelName, &g.Calls, &g.InputTokens, &g.OutputTokens, &g.Cost); err != nil {
+			return nil, fmt.Errorf("failed to scan usage group row: %w", err)
+		}
+		if dayStart != nil {
+			g.Day = *dayStart
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating usage group rows: %w", err)
+	}
+	return groups, nil
+}
+
+var _ store.CostTrackingStore = (*StoreImpl)(nil)
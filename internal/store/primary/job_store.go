@@ -198,5 +198,73 @@ func (s *StoreImpl) ListBatchJobs(ctx context.Context, limit, offset int) ([]*mo
 	return jobs, nil
 }
 
+const jobColumns = `id, job_id, task_type, payload, queue, status, related_entity_type, related_entity_id,
+	batch_api_job_id, batch_input_file_id, batch_output_file_id, job_data, last_error, created_at, updated_at`
+
+func scanJob(row pgx.Row) (*models.BackgroundJob, error) {
+	job := &models.BackgroundJob{}
+	err := row.Scan(
+		&job.ID, &job.JobID, &job.TaskType, &job.Payload, &job.Queue, &job.Status,
+		&job.RelatedEntityType, &job.RelatedEntityID, &job.BatchAPIJobID,
+		&job.BatchInputFileID, &job.BatchOutputFileID, &job.JobData, &job.LastError,
+		&job.CreatedAt, &job.UpdatedAt,
+	)
+	return job, err
+}
+
+// GetJob retrieves a job by its database ID.
+func (s *StoreImpl) GetJob(ctx context.Context, id int64) (*models.BackgroundJob, error) {
+	query := `SELECT ` + jobColumns + ` FROM background_jobs WHERE id = $1`
+	job, err := scanJob(s.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get job %d: %w", id, err)
+	}
+	return job, nil
+}
+
+// MarkJobDead records that a job exhausted its retries, keeping the error of its last attempt.
+func (s *StoreImpl) MarkJobDead(ctx context.Context, jobID uuid.UUID, lastError string) error {
+	query := `UPDATE background_jobs SET status = 'dead', last_error = $1, updated_at = $2 WHERE job_id = $3`
+	cmdTag, err := s.db.Exec(ctx, query, lastError, time.Now(), jobID)
+	if err != nil {
+		return fmt.Errorf("failed to mark job %s dead: %w", jobID, err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("job %s not found to mark dead: %w", jobID, store.ErrNotFound)
+	}
+	return nil
+}
+
+// ListJobsByStatus retrieves jobs with the given status, most recently updated first.
+func (s *StoreImpl) ListJobsByStatus(ctx context.Context, status string, limit, offset int) ([]*models.BackgroundJob, error) {
+	query := `SELECT ` + jobColumns + `
+		FROM background_jobs
+		WHERE status = $1
+		ORDER BY updated_at DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := s.db.Query(ctx, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s jobs: %w", status, err)
+	}
+	defer rows.Close()
+
+	var jobs []*models.BackgroundJob
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return jobs, fmt.Errorf("failed to scan %s job row: %w", status, err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return jobs, fmt.Errorf("error iterating %s job rows: %w", status, err)
+	}
+	return jobs, nil
+}
+
 // Ensure StoreImpl satisfies the JobStore interface
 var _ store.JobStore = (*StoreImpl)(nil)
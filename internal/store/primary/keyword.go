@@ -1,6 +1,8 @@
 package primary
 
 import (
+	"fmt"
+
 	"mimir/internal/store"
 )
 
@@ -8,3 +10,36 @@ import (
 
 // Ensure StoreImpl satisfies the KeywordSearcher interface
 var _ store.KeywordSearcher = (*StoreImpl)(nil)
+
+// unaccentFunction is the immutable wrapper around unaccent() created by
+// migration 011; unaccent itself cannot be used in index expressions.
+const unaccentFunction = "mimir_unaccent"
+
+// SetKeywordUnaccent makes keyword search accent-insensitive ("cafe" matches
+// "café") by stripping accents from both the indexed text and the query.
+// Requires migration 011, which installs the unaccent extension.
+func (s *StoreImpl) SetKeywordUnaccent(enabled bool) {
+	s.keywordUnaccent = enabled
+}
+
+// keywordDocument returns the tsvector expression for the SQL text expression text.
+func (s *StoreImpl) keywordDocument(text string) string {
+	if s.keywordUnaccent {
+		text = fmt.Sprintf("%s(%s)", unaccentFunction, text)
+	}
+	return fmt.Sprintf("to_tsvector('english', %s)", text)
+}
+
+// keywordQuery returns the tsquery expression for the query text bound to placeholder.
+func (s *StoreImpl) keywordQuery(placeholder string) string {
+	if s.keywordUnaccent {
+		placeholder = fmt.Sprintf("%s(%s)", unaccentFunction, placeholder)
+	}
+	return fmt.Sprintf("plainto_tsquery('english', %s)", placeholder)
+}
+
+// keywordMatchClause matches content whose title or body matches the query bound to placeholder.
+func (s *StoreImpl) keywordMatchClause(placeholder string) string {
+	query := s.keywordQuery(placeholder)
+	return fmt.Sprintf("(%s @@ %s OR %s @@ %s)", s.keywordDocument("c.title"), query, s.keywordDocument("c.body"), query)
+}
@@ -0,0 +1,54 @@
+package primary
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeywordMatchClause_Unaccent(t *testing.T) {
+	s := &StoreImpl{}
+	assert.Equal(t,
+		"(to_tsvector('english', c.title) @@ plainto_tsquery('english', $1) OR to_tsvector('english', c.body) @@ plainto_tsquery('english', $1))",
+		s.keywordMatchClause("$1"))
+
+	s.SetKeywordUnaccent(true)
+	assert.Equal(t,
+		"(to_tsvector('english', mimir_unaccent(c.title)) @@ plainto_tsquery('english', mimir_unaccent($2)) OR to_tsvector('english', mimir_unaccent(c.body)) @@ plainto_tsquery('english', mimir_unaccent($2)))",
+		s.keywordMatchClause("$2"))
+	assert.Equal(t, "to_tsvector('english', mimir_unaccent(c.title || ' ' || c.body))", s.keywordDocument("c.title || ' ' || c.body"))
+}
+
+// TestKeywordUnaccent_MatchesAccentedText runs against a real database with
+// migration 011 applied when MIMIR_TEST_PRIMARY_DSN is set.
+func TestKeywordUnaccent_MatchesAccentedText(t *testing.T) {
+	dsn := os.Getenv("MIMIR_TEST_PRIMARY_DSN")
+	if dsn == "" {
+		t.Skip("MIMIR_TEST_PRIMARY_DSN not set")
+	}
+	ctx := context.Background()
+	s, err := NewPrimaryStore(ctx, dsn, 0)
+	require.NoError(t, err)
+	defer s.Close()
+
+	matches := func(text, query string) bool {
+		var ok bool
+		require.NoError(t, s.db.QueryRow(ctx, "SELECT "+s.keywordDocument("$1::text")+" @@ "+s.keywordQuery("$2"), text, query).Scan(&ok))
+		return ok
+	}
+	cases := []struct{ text, query string }{
+		{"Meet at the café on Friday", "cafe"},
+		{"Crème brûlée recipe", "creme brulee"},
+		{"Notes on Dvořák", "dvorak"},
+		{"Plain cafe menu", "café"}, // Accented queries match unaccented text too
+	}
+	for _, tc := range cases {
+		s.SetKeywordUnaccent(false)
+		assert.False(t, matches(tc.text, tc.query), "%q should not match %q without unaccent", tc.query, tc.text)
+		s.SetKeywordUnaccent(true)
+		assert.True(t, matches(tc.text, tc.query), "%q should match %q with unaccent", tc.query, tc.text)
+	}
+}
@@ -6,17 +6,32 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"mimir/internal/models"
+	"mimir/internal/store"
 )
 
+// dbtx is the query interface shared by the connection pool and transactions.
+type dbtx interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
 // StoreImpl implements the store.PrimaryStore interface using PostgreSQL.
 type StoreImpl struct {
-	db *pgxpool.Pool
+	pool        *pgxpool.Pool
+	db          dbtx      // The pool, or the transaction when created by RunInTx
+	afterCommit *[]func() // Post-commit hooks; nil outside a transaction
+
+	keywordUnaccent bool // Strip accents from text and queries in keyword search (database.keyword_unaccent)
 }
 
-// NewPrimaryStore creates a new PostgreSQL primary store implementation.
-func NewPrimaryStore(ctx context.Context, dsn string) (*StoreImpl, error) {
+// NewPrimaryStore creates a new PostgreSQL primary store implementation whose
+// pool holds at most maxConns connections; 0 keeps pgx's default.
+func NewPrimaryStore(ctx context.Context, dsn string, maxConns int) (*StoreImpl, error) {
 	if dsn == "" {
 		return nil, errors.New("database DSN cannot be empty")
 	}
@@ -24,6 +39,9 @@ func NewPrimaryStore(ctx context.Context, dsn string) (*StoreImpl, error) {
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse database DSN: %w", err)
 	}
+	if maxConns > 0 {
+		poolConfig.MaxConns = int32(maxConns)
+	}
 
 	dbpool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
@@ -35,19 +53,60 @@ func NewPrimaryStore(ctx context.Context, dsn string) (*StoreImpl, error) {
 		return nil, fmt.Errorf("unable to ping database: %w", err)
 	}
 
-	return &StoreImpl{db: dbpool}, nil
+	return &StoreImpl{pool: dbpool, db: dbpool}, nil
 }
 
 // Ping checks the database connection.
 func (s *StoreImpl) Ping(ctx context.Context) error {
-	return s.db.Ping(ctx)
+	return s.pool.Ping(ctx)
 }
 
 // Close closes the database connection pool.
 func (s *StoreImpl) Close() {
-	s.db.Close()
+	s.pool.Close()
+}
+
+// RunInTx runs fn against a store bound to a single transaction. The transaction
+// commits when fn returns nil and rolls back otherwise. Called on a store that is
+// already inside a transaction, fn runs in a savepoint.
+func (s *StoreImpl) RunInTx(ctx context.Context, fn func(tx store.ContentTx) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // No-op after a successful commit
+
+	var hooks []func()
+	if err := fn(&StoreImpl{pool: s.pool, db: tx, afterCommit: &hooks, keywordUnaccent: s.keywordUnaccent}); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if s.afterCommit != nil {
+		// Savepoint released; the hooks wait for the enclosing transaction.
+		*s.afterCommit = append(*s.afterCommit, hooks...)
+		return nil
+	}
+	for _, hook := range hooks {
+		hook()
+	}
+	return nil
 }
 
+// AfterCommit registers fn to run after the transaction commits, or runs it
+// immediately when the store is not inside a transaction.
+func (s *StoreImpl) AfterCommit(fn func()) {
+	if s.afterCommit == nil {
+		fn()
+		return
+	}
+	*s.afterCommit = append(*s.afterCommit, fn)
+}
+
+var _ store.TxRunner = (*StoreImpl)(nil)
+
 // --- Helper Functions ---
 
 // scanContent scans a single row from pgx.Rows into a models.Content struct.
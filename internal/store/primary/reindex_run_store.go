@@ -0,0 +1,83 @@
+package primary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// --- Reindex Run Store Implementation ---
+
+func (s *StoreImpl) CreateReindexRun(ctx context.Context, total int) (*models.ReindexRun, error) {
+	query := `
+		INSERT INTO reindex_runs (total, completed, failed, status, created_at, updated_at)
+		VALUES ($1, 0, 0, $2, $3, $3)
+		RETURNING id, total, completed, failed, status, created_at, updated_at`
+
+	status := "running"
+	if total == 0 {
+		status = "finished"
+	}
+
+	run := &models.ReindexRun{}
+	err := s.db.QueryRow(ctx, query, total, status, time.Now()).Scan(
+		&run.ID, &run.Total, &run.Completed, &run.Failed, &run.Status, &run.CreatedAt, &run.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reindex run: %w", err)
+	}
+	return run, nil
+}
+
+func (s *StoreImpl) GetReindexRun(ctx context.Context, id int64) (*models.ReindexRun, error) {
+	query := `
+		SELECT id, total, completed, failed, status, created_at, updated_at
+		FROM reindex_runs
+		WHERE id = $1`
+
+	run := &models.ReindexRun{}
+	err := s.db.QueryRow(ctx, query, id).Scan(
+		&run.ID, &run.Total, &run.Completed, &run.Failed, &run.Status, &run.CreatedAt, &run.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, store.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get reindex run %d: %w", id, err)
+	}
+	return run, nil
+}
+
+// RecordReindexOutcome atomically bumps one counter and marks the run finished
+// once every job has reported.
+func (s *StoreImpl) RecordReindexOutcome(ctx context.Context, id int64, failed bool) error {
+	query := `
+		UPDATE reindex_runs
+		SET completed = completed + $2,
+			failed = failed + $3,
+			status = CASE WHEN completed + failed + 1 >= total THEN 'finished' ELSE 'running' END,
+			updated_at = $4
+		WHERE id = $1`
+
+	completedInc, failedInc := 1, 0
+	if failed {
+		completedInc, failedInc = 0, 1
+	}
+
+	cmdTag, err := s.db.Exec(ctx, query, id, completedInc, failedInc, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record reindex outcome for run %d: %w", id, err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+var _ store.ReindexRunStore = (*StoreImpl)(nil)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"mimir/internal/config"
 	"mimir/internal/models"
 	"mimir/internal/store"
 
@@ -14,19 +15,23 @@ import (
 
 // --- Search History Store Implementation ---
 
-func (s *StoreImpl) RecordSearchQuery(ctx context.Context, query string, resultsCount int) (*models.SearchQuery, error) {
+func (s *StoreImpl) RecordSearchQuery(ctx context.Context, ownerID, query string, resultsCount int) (*models.SearchQuery, error) {
 	sql := `
-		INSERT INTO search_queries (query, results_count, executed_at, created_at, updated_at)
-		VALUES ($1, $2, $3, $3, $3)
+		INSERT INTO search_queries (query, results_count, owner_id, executed_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4, $4)
 		RETURNING id, executed_at, created_at, updated_at`
 
 	now := time.Now()
+	if ownerID == "" {
+		ownerID = store.DefaultOwnerID
+	}
 	searchQuery := &models.SearchQuery{
 		Query:        query,
 		ResultsCount: resultsCount,
+		OwnerID:      ownerID,
 	}
 
-	err := s.db.QueryRow(ctx, sql, query, resultsCount, now).Scan(
+	err := s.db.QueryRow(ctx, sql, query, resultsCount, ownerID, now).Scan(
 		&searchQuery.ID, &searchQuery.ExecutedAt, &searchQuery.CreatedAt, &searchQuery.UpdatedAt,
 	)
 	if err != nil {
@@ -35,17 +40,18 @@ func (s *StoreImpl) RecordSearchQuery(ctx context.Context, query string, results
 	return searchQuery, nil
 }
 
-func (s *StoreImpl) ListSearchQueries(ctx context.Context, limit int) ([]*models.SearchQuery, error) {
+func (s *StoreImpl) ListSearchQueries(ctx context.Context, ownerID string, limit int) ([]*models.SearchQuery, error) {
 	if limit <= 0 {
-		limit = 20 // Default limit
+		limit = config.DefaultPageSize
 	}
 	sql := `
-		SELECT id, query, results_count, executed_at, created_at, updated_at
+		SELECT id, query, results_count, owner_id, executed_at, created_at, updated_at
 		FROM search_queries
+		WHERE $2 = '' OR owner_id = $2
 		ORDER BY executed_at DESC
 		LIMIT $1`
 
-	rows, err := s.db.Query(ctx, sql, limit)
+	rows, err := s.db.Query(ctx, sql, limit, ownerID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return []*models.SearchQuery{}, nil // Return empty slice if no history
@@ -58,7 +64,7 @@ func (s *StoreImpl) ListSearchQueries(ctx context.Context, limit int) ([]*models
 	for rows.Next() {
 		q := &models.SearchQuery{}
 		err := rows.Scan(
-			&q.ID, &q.Query, &q.ResultsCount, &q.ExecutedAt, &q.CreatedAt, &q.UpdatedAt,
+			&q.ID, &q.Query, &q.ResultsCount, &q.OwnerID, &q.ExecutedAt, &q.CreatedAt, &q.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan search query row: %w", err)
@@ -103,5 +109,14 @@ func (s *StoreImpl) RecordSearchResults(ctx context.Context, queryID int64, resu
 	return nil
 }
 
+func (s *StoreImpl) DeleteSearchQueriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	// search_results rows are removed by ON DELETE CASCADE.
+	tag, err := s.db.Exec(ctx, `DELETE FROM search_queries WHERE executed_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete search queries before %s: %w", before.Format(time.RFC3339), err)
+	}
+	return tag.RowsAffected(), nil
+}
+
 // Ensure StoreImpl satisfies the SearchHistoryStore interface
 var _ store.SearchHistoryStore = (*StoreImpl)(nil)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"mimir/internal/config"
 	"mimir/internal/models"
 	"mimir/internal/store"
 
@@ -71,7 +72,7 @@ func (s *StoreImpl) GetSourceByName(ctx context.Context, name string) (*models.S
 func (s *StoreImpl) ListSources(ctx context.Context, limit int, offset int) ([]*models.Source, error) {
 	query := `SELECT id, name, description, url, source_type, created_at, updated_at FROM sources ORDER BY name ASC LIMIT $1 OFFSET $2`
 	if limit <= 0 {
-		limit = 20 // Default limit
+		limit = config.DefaultPageSize
 	}
 	if offset < 0 {
 		offset = 0
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"mimir/internal/config"
 	"mimir/internal/models"
 	"mimir/internal/store"
 
@@ -16,19 +17,33 @@ import (
 
 // --- Tag Management ---
 
+// tagColumns are the tags columns scanned by scanTag.
+const tagColumns = `id, name, slug, owner_id, created_at, updated_at`
+
+func scanTag(row pgx.Row) (*models.Tag, error) {
+	tag := &models.Tag{}
+	if err := row.Scan(&tag.ID, &tag.Name, &tag.Slug, &tag.OwnerID, &tag.CreatedAt, &tag.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
 func (s *StoreImpl) CreateTag(ctx context.Context, tag *models.Tag) error {
 	query := `
-		INSERT INTO tags (name, slug, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO tags (name, slug, owner_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, created_at, updated_at`
 
 	now := time.Now()
 	if tag.Slug == "" {
 		tag.Slug = strings.ToLower(strings.ReplaceAll(tag.Name, " ", "-"))
 	}
+	if tag.OwnerID == "" {
+		tag.OwnerID = store.DefaultOwnerID
+	}
 
 	err := s.db.QueryRow(ctx, query,
-		tag.Name, tag.Slug, now, now,
+		tag.Name, tag.Slug, tag.OwnerID, now, now,
 	).Scan(&tag.ID, &tag.CreatedAt, &tag.UpdatedAt)
 
 	if err != nil {
@@ -42,11 +57,8 @@ func (s *StoreImpl) CreateTag(ctx context.Context, tag *models.Tag) error {
 }
 
 func (s *StoreImpl) GetTag(ctx context.Context, id int64) (*models.Tag, error) {
-	query := `SELECT id, name, slug, created_at, updated_at FROM tags WHERE id = $1`
-	tag := &models.Tag{}
-	err := s.db.QueryRow(ctx, query, id).Scan(
-		&tag.ID, &tag.Name, &tag.Slug, &tag.CreatedAt, &tag.UpdatedAt,
-	)
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE id = $1`
+	tag, err := scanTag(s.db.QueryRow(ctx, query, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, store.ErrNotFound
@@ -56,12 +68,9 @@ func (s *StoreImpl) GetTag(ctx context.Context, id int64) (*models.Tag, error) {
 	return tag, nil
 }
 
-func (s *StoreImpl) GetTagBySlug(ctx context.Context, slug string) (*models.Tag, error) {
-	query := `SELECT id, name, slug, created_at, updated_at FROM tags WHERE slug = $1`
-	tag := &models.Tag{}
-	err := s.db.QueryRow(ctx, query, slug).Scan(
-		&tag.ID, &tag.Name, &tag.Slug, &tag.CreatedAt, &tag.UpdatedAt,
-	)
+func (s *StoreImpl) GetTagBySlug(ctx context.Context, ownerID, slug string) (*models.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE slug = $1 AND ($2 = '' OR owner_id = $2)`
+	tag, err := scanTag(s.db.QueryRow(ctx, query, slug, ownerID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, store.ErrNotFound
@@ -71,10 +80,13 @@ func (s *StoreImpl) GetTagBySlug(ctx context.Context, slug string) (*models.Tag,
 	return tag, nil
 }
 
-func (s *StoreImpl) GetOrCreateTagsByName(ctx context.Context, names []string) ([]*models.Tag, error) {
+func (s *StoreImpl) GetOrCreateTagsByName(ctx context.Context, ownerID string, names []string) ([]*models.Tag, error) {
 	if len(names) == 0 {
 		return []*models.Tag{}, nil
 	}
+	if ownerID == "" {
+		ownerID = store.DefaultOwnerID
+	}
 
 	var tags []*models.Tag
 	for _, name := range names {
@@ -83,16 +95,14 @@ func (s *StoreImpl) GetOrCreateTagsByName(ctx context.Context, names []string) (
 			continue
 		}
 		// Try to find by name (case-insensitive)
-		query := `SELECT id, name, slug, created_at, updated_at FROM tags WHERE LOWER(name) = LOWER($1)`
-		tag := &models.Tag{}
-		err := s.db.QueryRow(ctx, query, name).Scan(
-			&tag.ID, &tag.Name, &tag.Slug, &tag.CreatedAt, &tag.UpdatedAt,
-		)
+		query := `SELECT ` + tagColumns + ` FROM tags WHERE LOWER(name) = LOWER($1) AND owner_id = $2`
+		tag, err := scanTag(s.db.QueryRow(ctx, query, name, ownerID))
 		if err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
 				// Not found, create it
 				newTag := &models.Tag{
-					Name: name,
+					Name:    name,
+					OwnerID: ownerID,
 				}
 				if err := s.CreateTag(ctx, newTag); err != nil {
 					return nil, fmt.Errorf("failed to create tag '%s': %w", name, err)
@@ -108,15 +118,15 @@ func (s *StoreImpl) GetOrCreateTagsByName(ctx context.Context, names []string) (
 	return tags, nil
 }
 
-func (s *StoreImpl) ListTags(ctx context.Context, limit, offset int) ([]*models.Tag, error) {
-	query := `SELECT id, name, slug, created_at, updated_at FROM tags ORDER BY name ASC LIMIT $1 OFFSET $2`
+func (s *StoreImpl) ListTags(ctx context.Context, ownerID string, limit, offset int) ([]*models.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE $3 = '' OR owner_id = $3 ORDER BY name ASC LIMIT $1 OFFSET $2`
 	if limit <= 0 {
-		limit = 50
+		limit = config.DefaultPageSize
 	}
 	if offset < 0 {
 		offset = 0
 	}
-	rows, err := s.db.Query(ctx, query, limit, offset)
+	rows, err := s.db.Query(ctx, query, limit, offset, ownerID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tags: %w", err)
 	}
@@ -124,10 +134,7 @@ func (s *StoreImpl) ListTags(ctx context.Context, limit, offset int) ([]*models.
 
 	var tags []*models.Tag
 	for rows.Next() {
-		tag := &models.Tag{}
-		err := rows.Scan(
-			&tag.ID, &tag.Name, &tag.Slug, &tag.CreatedAt, &tag.UpdatedAt,
-		)
+		tag, err := scanTag(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan tag row: %w", err)
 		}
@@ -156,6 +163,23 @@ func (s *StoreImpl) AddTagsToContent(ctx context.Context, contentID int64, tagID
 	return nil
 }
 
+// AddTagsToContents links every tag to every content item in a single
+// statement. Existing links are left as they are.
+func (s *StoreImpl) AddTagsToContents(ctx context.Context, contentIDs, tagIDs []int64) error {
+	if len(contentIDs) == 0 || len(tagIDs) == 0 {
+		return nil
+	}
+	query := `
+		INSERT INTO content_tags (content_id, tag_id, created_at)
+		SELECT c.id, t.id, $3
+		FROM unnest($1::bigint[]) AS c(id) CROSS JOIN unnest($2::bigint[]) AS t(id)
+		ON CONFLICT DO NOTHING`
+	if _, err := s.db.Exec(ctx, query, contentIDs, tagIDs, time.Now()); err != nil {
+		return fmt.Errorf("failed to add tags to %d content items: %w", len(contentIDs), err)
+	}
+	return nil
+}
+
 func (s *StoreImpl) RemoveTagFromContent(ctx context.Context, contentID, tagID int64) error {
 	query := `DELETE FROM content_tags WHERE content_id = $1 AND tag_id = $2`
 	_, err := s.db.Exec(ctx, query, contentID, tagID)
@@ -167,7 +191,7 @@ func (s *StoreImpl) RemoveTagFromContent(ctx context.Context, contentID, tagID i
 
 func (s *StoreImpl) GetContentTags(ctx context.Context, contentID int64) ([]*models.Tag, error) {
 	query := `
-		SELECT t.id, t.name, t.slug, t.created_at, t.updated_at
+		SELECT t.id, t.name, t.slug, t.owner_id, t.created_at, t.updated_at
 		FROM tags t
 		JOIN content_tags ct ON t.id = ct.tag_id
 		WHERE ct.content_id = $1
@@ -180,10 +204,7 @@ func (s *StoreImpl) GetContentTags(ctx context.Context, contentID int64) ([]*mod
 
 	var tags []*models.Tag
 	for rows.Next() {
-		tag := &models.Tag{}
-		err := rows.Scan(
-			&tag.ID, &tag.Name, &tag.Slug, &tag.CreatedAt, &tag.UpdatedAt,
-		)
+		tag, err := scanTag(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan tag row: %w", err)
 		}
@@ -203,7 +224,7 @@ func (s *StoreImpl) GetTagsForContents(ctx context.Context, contentIDs []int64)
 	}
 
 	query := `
-		SELECT ct.content_id, t.id, t.name, t.slug, t.created_at, t.updated_at
+		SELECT ct.content_id, t.id, t.name, t.slug, t.owner_id, t.created_at, t.updated_at
 		FROM tags t
 		JOIN content_tags ct ON t.id = ct.tag_id
 		WHERE ct.content_id = ANY($1)
@@ -220,7 +241,7 @@ func (s *StoreImpl) GetTagsForContents(ctx context.Context, contentIDs []int64)
 		var contentID int64
 		tag := &models.Tag{}
 		err := rows.Scan(
-			&contentID, &tag.ID, &tag.Name, &tag.Slug, &tag.CreatedAt, &tag.UpdatedAt,
+			&contentID, &tag.ID, &tag.Name, &tag.Slug, &tag.OwnerID, &tag.CreatedAt, &tag.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan tag row for multiple contents: %w", err)
@@ -242,5 +263,138 @@ func (s *StoreImpl) GetTagsForContents(ctx context.Context, contentIDs []int64)
 	return tagsByContentID, nil
 }
 
+// GetTagCooccurrence returns pairs of tags applied to the same content item,
+// with the number of content items they share. Pairs seen fewer than minCount
+// times are omitted. Each pair is reported once, with TagA.ID < TagB.ID.
+// A non-empty ownerID limits the pairs to ownerID's tags.
+func (s *StoreImpl) GetTagCooccurrence(ctx context.Context, ownerID string, minCount int) ([]*models.TagCooccurrence, error) {
+	if minCount <= 0 {
+		minCount = 1
+	}
+	query := `
+		SELECT ta.id, ta.name, ta.slug, ta.owner_id, ta.created_at, ta.updated_at,
+		       tb.id, tb.name, tb.slug, tb.owner_id, tb.created_at, tb.updated_at,
+		       COUNT(*) AS pair_count
+		FROM content_tags a
+		JOIN content_tags b ON a.content_id = b.content_id AND a.tag_id < b.tag_id
+		JOIN tags ta ON ta.id = a.tag_id
+		JOIN tags tb ON tb.id = b.tag_id
+		WHERE $2 = '' OR (ta.owner_id = $2 AND tb.owner_id = $2)
+		GROUP BY ta.id, tb.id
+		HAVING COUNT(*) >= $1
+		ORDER BY pair_count DESC, ta.name ASC, tb.name ASC`
+
+	rows, err := s.db.Query(ctx, query, minCount, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tag co-occurrence: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []*models.TagCooccurrence
+	for rows.Next() {
+		pair := &models.TagCooccurrence{}
+		err := rows.Scan(
+			&pair.TagA.ID, &pair.TagA.Name, &pair.TagA.Slug, &pair.TagA.OwnerID, &pair.TagA.CreatedAt, &pair.TagA.UpdatedAt,
+			&pair.TagB.ID, &pair.TagB.Name, &pair.TagB.Slug, &pair.TagB.OwnerID, &pair.TagB.CreatedAt, &pair.TagB.UpdatedAt,
+			&pair.Count,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tag co-occurrence row: %w", err)
+		}
+		pairs = append(pairs, pair)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tag co-occurrence rows: %w", err)
+	}
+	return pairs, nil
+}
+
+// ListContentIDsByTags returns the IDs of content carrying any of the tags,
+// matched by name or slug ignoring case, in ascending order. A non-empty
+// ownerID only considers ownerID's tags. No tags match no content.
+func (s *StoreImpl) ListContentIDsByTags(ctx context.Context, ownerID string, tags []string) ([]int64, error) {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+			names = append(names, tag)
+		}
+	}
+	if len(names) == 0 {
+		return []int64{}, nil
+	}
+	query := `
+		SELECT DISTINCT ct.content_id
+		FROM content_tags ct
+		JOIN tags t ON t.id = ct.tag_id
+		WHERE (LOWER(t.name) = ANY($1) OR LOWER(t.slug) = ANY($1)) AND ($2 = '' OR t.owner_id = $2)
+		ORDER BY ct.content_id`
+
+	rows, err := s.db.Query(ctx, query, names, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list content IDs by tags: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan content ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating content IDs by tags: %w", err)
+	}
+	return ids, nil
+}
+
+// SearchTagsByPrefix returns tags whose name starts with prefix, ignoring case,
+// ordered by the number of content items carrying them and then by name. A
+// non-empty ownerID limits the tags to ownerID's.
+func (s *StoreImpl) SearchTagsByPrefix(ctx context.Context, ownerID, prefix string, limit int) ([]*models.TagUsage, error) {
+	if limit <= 0 {
+		limit = config.DefaultPageSize
+	}
+	query := `
+		SELECT t.id, t.name, t.slug, t.owner_id, t.created_at, t.updated_at,
+		       COUNT(ct.content_id) AS usage_count
+		FROM tags t
+		LEFT JOIN content_tags ct ON ct.tag_id = t.id
+		WHERE t.name ILIKE $1 ESCAPE '\' AND ($3 = '' OR t.owner_id = $3)
+		GROUP BY t.id
+		ORDER BY usage_count DESC, t.name ASC
+		LIMIT $2`
+
+	rows, err := s.db.Query(ctx, query, escapeLikePattern(prefix)+"%", limit, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tags by prefix '%s': %w", prefix, err)
+	}
+	defer rows.Close()
+
+	var usages []*models.TagUsage
+	for rows.Next() {
+		usage := &models.TagUsage{}
+		err := rows.Scan(
+			&usage.Tag.ID, &usage.Tag.Name, &usage.Tag.Slug, &usage.Tag.OwnerID, &usage.Tag.CreatedAt, &usage.Tag.UpdatedAt,
+			&usage.Count,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tag usage row: %w", err)
+		}
+		usages = append(usages, usage)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tag usage rows: %w", err)
+	}
+	return usages, nil
+}
+
+// escapeLikePattern escapes the LIKE wildcards in s, so it matches literally
+// in a pattern with ESCAPE '\'.
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
 // Ensure StoreImpl satisfies the TagStore interface
 var _ store.TagStore = (*StoreImpl)(nil)
@@ -0,0 +1,83 @@
+package primary
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+)
+
+func TestEscapeLikePattern(t *testing.T) {
+	assert.Equal(t, "go", escapeLikePattern("go"))
+	assert.Equal(t, `100\%`, escapeLikePattern("100%"))
+	assert.Equal(t, `snake\_case`, escapeLikePattern("snake_case"))
+	assert.Equal(t, `a\\b`, escapeLikePattern(`a\b`))
+}
+
+// TestSearchTagsByPrefix runs against a real database when
+// MIMIR_TEST_PRIMARY_DSN is set. Its rows belong to a unique owner and are
+// deleted afterwards.
+func TestSearchTagsByPrefix(t *testing.T) {
+	dsn := os.Getenv("MIMIR_TEST_PRIMARY_DSN")
+	if dsn == "" {
+		t.Skip("MIMIR_TEST_PRIMARY_DSN not set")
+	}
+	ctx := context.Background()
+	s, err := NewPrimaryStore(ctx, dsn, 0)
+	require.NoError(t, err)
+	defer s.Close()
+
+	run := time.Now().UnixNano()
+	owner := fmt.Sprintf("test-tag-prefix-%d", run)
+	tags, err := s.GetOrCreateTagsByName(ctx, owner, []string{"Golang", "gopher", "GORM", "python", "go_lang"})
+	require.NoError(t, err)
+
+	source := &models.Source{Name: owner, SourceType: "test"}
+	require.NoError(t, s.CreateSource(ctx, source))
+	var contentIDs []int64
+	t.Cleanup(func() {
+		// Deleting the content removes its content_tags rows before the tags go.
+		_, err := s.db.Exec(ctx, `DELETE FROM content WHERE id = ANY($1)`, contentIDs)
+		require.NoError(t, err)
+		_, err = s.db.Exec(ctx, `DELETE FROM tags WHERE owner_id = $1`, owner)
+		require.NoError(t, err)
+		_, err = s.db.Exec(ctx, `DELETE FROM sources WHERE id = $1`, source.ID)
+		require.NoError(t, err)
+	})
+	// gopher is used twice, GORM once, the others never.
+	for i, tagIDs := range [][]int64{{tags[1].ID, tags[2].ID}, {tags[1].ID}} {
+		content := &models.Content{SourceID: source.ID, Title: "t", Body: fmt.Sprintf("tag prefix %d %d", run, i), ContentType: "text/plain", OwnerID: owner}
+		_, err := s.CreateContentIfNotExists(ctx, content)
+		require.NoError(t, err)
+		contentIDs = append(contentIDs, content.ID)
+		require.NoError(t, s.AddTagsToContent(ctx, content.ID, tagIDs))
+	}
+
+	names := func(prefix string, limit int) []string {
+		usages, err := s.SearchTagsByPrefix(ctx, owner, prefix, limit)
+		require.NoError(t, err)
+		var names []string
+		for _, u := range usages {
+			names = append(names, u.Tag.Name)
+		}
+		return names
+	}
+
+	// Unused tags tie on usage and are ordered by name, which depends on the
+	// database collation, so only the used tags' order is asserted.
+	got := names("GO", 10)
+	assert.ElementsMatch(t, []string{"gopher", "GORM", "go_lang", "Golang"}, got, "prefix match is case-insensitive")
+	assert.Equal(t, []string{"gopher", "GORM"}, got[:2], "most used first")
+	assert.Equal(t, []string{"gopher", "GORM"}, names("go", 2), "limit applies")
+	assert.Equal(t, []string{"go_lang"}, names("go_", 10), "LIKE wildcards in the prefix match literally")
+
+	got = names("", 10)
+	assert.Len(t, got, 5, "empty prefix returns all tags")
+	assert.Equal(t, []string{"gopher", "GORM"}, got[:2], "empty prefix returns the most used tags first")
+}
@@ -0,0 +1,60 @@
+package primary
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// --- Worker Heartbeat Store Implementation ---
+
+func (s *StoreImpl) UpsertWorkerHeartbeat(ctx context.Context, hb *models.WorkerHeartbeat) error {
+	query := `
+		INSERT INTO worker_heartbeats (worker_id, hostname, pid, in_flight, started_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (worker_id) DO UPDATE
+		SET in_flight = EXCLUDED.in_flight, last_seen_at = EXCLUDED.last_seen_at`
+	_, err := s.db.Exec(ctx, query, hb.WorkerID, hb.Hostname, hb.PID, hb.InFlight, hb.StartedAt, hb.LastSeenAt)
+	if err != nil {
+		return fmt.Errorf("failed to record heartbeat of worker %s: %w", hb.WorkerID, err)
+	}
+	return nil
+}
+
+func (s *StoreImpl) ListWorkerHeartbeats(ctx context.Context, since time.Time) ([]*models.WorkerHeartbeat, error) {
+	query := `
+		SELECT worker_id, hostname, pid, in_flight, started_at, last_seen_at
+		FROM worker_heartbeats
+		WHERE last_seen_at >= $1
+		ORDER BY last_seen_at DESC`
+	rows, err := s.db.Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worker heartbeats: %w", err)
+	}
+	defer rows.Close()
+
+	heartbeats := []*models.WorkerHeartbeat{}
+	for rows.Next() {
+		hb := &models.WorkerHeartbeat{}
+		if err := rows.Scan(&hb.WorkerID, &hb.Hostname, &hb.PID, &hb.InFlight, &hb.StartedAt, &hb.LastSeenAt); err != nil {
+			return nil, fmt.Errorf("failed to scan worker heartbeat row: %w", err)
+		}
+		heartbeats = append(heartbeats, hb)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating worker heartbeat rows: %w", err)
+	}
+	return heartbeats, nil
+}
+
+func (s *StoreImpl) DeleteWorkerHeartbeat(ctx context.Context, workerID string) error {
+	if _, err := s.db.Exec(ctx, `DELETE FROM worker_heartbeats WHERE worker_id = $1`, workerID); err != nil {
+		return fmt.Errorf("failed to delete heartbeat of worker %s: %w", workerID, err)
+	}
+	return nil
+}
+
+var _ store.WorkerHeartbeatStore = (*StoreImpl)(nil)
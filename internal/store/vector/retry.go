@@ -0,0 +1,156 @@
+package vector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pgvector/pgvector-go"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// defaultRetryBaseDelay is the wait before the first retry; it doubles on each
+// further attempt.
+const defaultRetryBaseDelay = 100 * time.Millisecond
+
+// retryingStore retries vector store operations that fail with transient
+// connection errors, such as resets during a database failover. Query errors
+// are returned immediately.
+type retryingStore struct {
+	store.VectorStore
+	maxRetries int
+	baseDelay  time.Duration
+}
+
+// WithRetry wraps vs so SimilaritySearch, ContentVectors, GetEmbedding, ListEmbeddingsByContentID,
+// AddEmbedding and LastChunkIndex are retried up to maxRetries times with exponential backoff on
+// transient errors. maxRetries <= 0 returns vs unchanged.
+func WithRetry(vs store.VectorStore, maxRetries int) store.VectorStore {
+	if maxRetries <= 0 {
+		return vs
+	}
+	return &retryingStore{VectorStore: vs, maxRetries: maxRetries, baseDelay: defaultRetryBaseDelay}
+}
+
+// retry runs op until it succeeds, fails with an error retryable rejects, the
+// retries are used up, or ctx is done.
+func (r *retryingStore) retry(ctx context.Context, name string, retryable func(error) bool, op func() error) error {
+	delay := r.baseDelay
+	for attempt := 0; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= r.maxRetries || !retryable(err) {
+			return err
+		}
+		log.Printf("WARN: Vector store %s failed with transient error (attempt %d/%d), retrying in %s: %v",
+			name, attempt+1, r.maxRetries+1, delay, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (r *retryingStore) SimilaritySearch(ctx context.Context, queryVector pgvector.Vector, k int, filterMetadata map[string]interface{}) ([]models.SearchResult, error) {
+	var results []models.SearchResult
+	err := r.retry(ctx, "SimilaritySearch", isTransientError, func() error {
+		var err error
+		results, err = r.VectorStore.SimilaritySearch(ctx, queryVector, k, filterMetadata)
+		return err
+	})
+	return results, err
+}
+
+func (r *retryingStore) ContentVectors(ctx context.Context, contentIDs []int64) (map[int64]pgvector.Vector, error) {
+	var vectors map[int64]pgvector.Vector
+	err := r.retry(ctx, "ContentVectors", isTransientError, func() error {
+		var err error
+		vectors, err = r.VectorStore.ContentVectors(ctx, contentIDs)
+		return err
+	})
+	return vectors, err
+}
+
+func (r *retryingStore) GetEmbedding(ctx context.Context, id uuid.UUID) (*models.EmbeddingEntry, error) {
+	var entry *models.EmbeddingEntry
+	err := r.retry(ctx, "GetEmbedding", isTransientError, func() error {
+		var err error
+		entry, err = r.VectorStore.GetEmbedding(ctx, id)
+		return err
+	})
+	return entry, err
+}
+
+func (r *retryingStore) ListEmbeddingsByContentID(ctx context.Context, contentID int64) ([]*models.EmbeddingEntry, error) {
+	var entries []*models.EmbeddingEntry
+	err := r.retry(ctx, "ListEmbeddingsByContentID", isTransientError, func() error {
+		var err error
+		entries, err = r.VectorStore.ListEmbeddingsByContentID(ctx, contentID)
+		return err
+	})
+	return entries, err
+}
+
+// AddEmbedding is only retried when the insert was never sent to the server, so
+// a retry cannot insert the row twice or fail on a row the lost attempt committed.
+func (r *retryingStore) AddEmbedding(ctx context.Context, entry *models.EmbeddingEntry) error {
+	return r.retry(ctx, "AddEmbedding", pgconn.SafeToRetry, func() error {
+		return r.VectorStore.AddEmbedding(ctx, entry)
+	})
+}
+
+func (r *retryingStore) LastChunkIndex(ctx context.Context, contentID int64) (int, error) {
+	var last int
+	err := r.retry(ctx, "LastChunkIndex", isTransientError, func() error {
+		var err error
+		last, err = r.VectorStore.LastChunkIndex(ctx, contentID)
+		return err
+	})
+	return last, err
+}
+
+func (r *retryingStore) UpdateEmbeddingMetadataByContentID(ctx context.Context, contentID int64, metadata json.RawMessage) error {
+	// The update is idempotent, so any transient failure can be retried.
+	return r.retry(ctx, "UpdateEmbeddingMetadataByContentID", isTransientError, func() error {
+		return r.VectorStore.UpdateEmbeddingMetadataByContentID(ctx, contentID, metadata)
+	})
+}
+
+// isTransientError reports whether err is a connection-level failure worth
+// retrying, as opposed to an error in the query or its data.
+func isTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case len(pgErr.Code) == 5 && pgErr.Code[:2] == "08": // connection_exception class
+			return true
+		case pgErr.Code == "57P01", pgErr.Code == "57P02", pgErr.Code == "57P03": // admin/crash shutdown, cannot_connect_now
+			return true
+		}
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE)
+}
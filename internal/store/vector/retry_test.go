@@ -0,0 +1,65 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+// flakyVectorStore fails the first failures SimilaritySearch calls with err.
+type flakyVectorStore struct {
+	store.VectorStore
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *flakyVectorStore) SimilaritySearch(ctx context.Context, queryVector pgvector.Vector, k int, filterMetadata map[string]interface{}) ([]models.SearchResult, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, fmt.Errorf("similarity search: %w", f.err)
+	}
+	return []models.SearchResult{{ContentID: 1}}, nil
+}
+
+func newTestRetryStore(inner store.VectorStore, maxRetries int) *retryingStore {
+	return &retryingStore{VectorStore: inner, maxRetries: maxRetries, baseDelay: 0}
+}
+
+func TestRetryingStore_RetriesTransientError(t *testing.T) {
+	inner := &flakyVectorStore{failures: 1, err: io.ErrUnexpectedEOF}
+	results, err := newTestRetryStore(inner, 2).SimilaritySearch(context.Background(), pgvector.NewVector([]float32{1}), 5, nil)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestRetryingStore_DoesNotRetryQueryError(t *testing.T) {
+	inner := &flakyVectorStore{failures: 1, err: &pgconn.PgError{Code: "42703", Message: "column does not exist"}}
+	_, err := newTestRetryStore(inner, 2).SimilaritySearch(context.Background(), pgvector.NewVector([]float32{1}), 5, nil)
+	require.Error(t, err)
+	assert.Equal(t, 1, inner.calls)
+}
+
+func TestRetryingStore_GivesUpAfterMaxRetries(t *testing.T) {
+	inner := &flakyVectorStore{failures: 5, err: &pgconn.PgError{Code: "08006"}}
+	_, err := newTestRetryStore(inner, 2).SimilaritySearch(context.Background(), pgvector.NewVector([]float32{1}), 5, nil)
+	var pgErr *pgconn.PgError
+	assert.True(t, errors.As(err, &pgErr))
+	assert.Equal(t, 3, inner.calls)
+}
+
+func TestWithRetry_ZeroDisables(t *testing.T) {
+	inner := &flakyVectorStore{}
+	assert.Same(t, store.VectorStore(inner), WithRetry(inner, 0))
+}
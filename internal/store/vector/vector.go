@@ -6,6 +6,7 @@ import (
 	"errors" // Add missing import
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -27,13 +28,20 @@ type VectorSearchResultItem struct {
 }
 
 type StoreImpl struct {
-	db *pgxpool.Pool
+	db     *pgxpool.Pool
+	metric string // store.DistanceMetric*; empty means L2
 }
 
-func NewStore(ctx context.Context, dsn string) (store.VectorStore, error) {
+// NewStore connects to the vector store. metric selects the distance used by
+// SimilaritySearch (store.DistanceMetric*); empty means L2.
+func NewStore(ctx context.Context, dsn, metric string) (store.VectorStore, error) {
 	if dsn == "" {
 		return nil, fmt.Errorf("vector store DSN cannot be empty")
 	}
+	metric, err := store.ParseDistanceMetric(metric)
+	if err != nil {
+		return nil, err
+	}
 	cfg, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse vector store DSN: %w", err)
@@ -47,7 +55,19 @@ func NewStore(ctx context.Context, dsn string) (store.VectorStore, error) {
 		return nil, fmt.Errorf("failed to ping vector store: %w", err)
 	}
 	log.Printf("Successfully connected to PostgreSQL vector store.")
-	return &StoreImpl{db: pool}, nil
+	return &StoreImpl{db: pool, metric: metric}, nil
+}
+
+// distanceOperator returns the pgvector operator of the store's metric.
+func (vs *StoreImpl) distanceOperator() string {
+	switch vs.metric {
+	case store.DistanceMetricCosine:
+		return "<=>"
+	case store.DistanceMetricInnerProduct:
+		return "<#>"
+	default:
+		return "<->"
+	}
 }
 
 func (vs *StoreImpl) Close() error {
@@ -66,6 +86,9 @@ func (vs *StoreImpl) Ping(ctx context.Context) error {
 }
 
 func (vs *StoreImpl) AddEmbedding(ctx context.Context, entry *models.EmbeddingEntry) error {
+	if store.IsZeroVector(entry.Vector) {
+		return fmt.Errorf("add embedding for content %d: %w", entry.ContentID, store.ErrZeroVector)
+	}
 	if entry.ID == uuid.Nil {
 		entry.ID = uuid.New()
 	}
@@ -94,6 +117,78 @@ func (vs *StoreImpl) GetEmbedding(ctx context.Context, id uuid.UUID) (*models.Em
 	return entry, nil
 }
 
+func (vs *StoreImpl) ListEmbeddingsByContentID(ctx context.Context, contentID int64) ([]*models.EmbeddingEntry, error) {
+	query := `SELECT id, content_id, chunk_text, vector, metadata, created_at FROM embeddings
+		WHERE content_id = $1
+		ORDER BY COALESCE((metadata->>'chunk_index')::int, 0), created_at`
+	rows, err := vs.db.Query(ctx, query, contentID)
+	if err != nil {
+		return nil, fmt.Errorf("list embeddings for content %d: %w", contentID, err)
+	}
+	defer rows.Close()
+
+	var entries []*models.EmbeddingEntry
+	for rows.Next() {
+		entry := &models.EmbeddingEntry{}
+		if err := rows.Scan(&entry.ID, &entry.ContentID, &entry.ChunkText, &entry.Vector, &entry.Metadata, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("list embeddings for content %d: scan row: %w", contentID, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list embeddings for content %d: %w", contentID, err)
+	}
+	return entries, nil
+}
+
+// ContentVectors averages the chunk vectors of each content item in the
+// database (pgvector's AVG aggregate), in a single query for all items.
+func (vs *StoreImpl) ContentVectors(ctx context.Context, contentIDs []int64) (map[int64]pgvector.Vector, error) {
+	vectors := make(map[int64]pgvector.Vector, len(contentIDs))
+	if len(contentIDs) == 0 {
+		return vectors, nil
+	}
+	query := `SELECT content_id, AVG(vector) FROM embeddings WHERE content_id = ANY($1) GROUP BY content_id`
+	rows, err := vs.db.Query(ctx, query, contentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("content vectors query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var contentID int64
+		var vec pgvector.Vector
+		if err := rows.Scan(&contentID, &vec); err != nil {
+			return nil, fmt.Errorf("scan content vector row: %w", err)
+		}
+		vectors[contentID] = vec
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate content vector rows: %w", err)
+	}
+	return vectors, nil
+}
+
+// Stats returns embedding counts and the declared dimension of the vector column.
+func (vs *StoreImpl) Stats(ctx context.Context) (store.VectorStats, error) {
+	var stats store.VectorStats
+	query := `SELECT COUNT(*), COUNT(DISTINCT content_id) FROM embeddings`
+	if err := vs.db.QueryRow(ctx, query).Scan(&stats.EmbeddingCount, &stats.ContentCount); err != nil {
+		return stats, fmt.Errorf("count embeddings: %w", err)
+	}
+
+	// pgvector stores the declared dimension as the column's type modifier (-1 when unconstrained).
+	dimQuery := `SELECT atttypmod FROM pg_attribute WHERE attrelid = 'embeddings'::regclass AND attname = 'vector'`
+	var typmod int
+	if err := vs.db.QueryRow(ctx, dimQuery).Scan(&typmod); err != nil {
+		return stats, fmt.Errorf("get vector column dimension: %w", err)
+	}
+	if typmod > 0 {
+		stats.Dimension = typmod
+	}
+	return stats, nil
+}
+
 func (vs *StoreImpl) DeleteEmbeddingsByContentID(ctx context.Context, contentID int64) error {
 	query := `DELETE FROM embeddings WHERE content_id = $1`
 	_, err := vs.db.Exec(ctx, query, contentID)
@@ -103,16 +198,150 @@ func (vs *StoreImpl) DeleteEmbeddingsByContentID(ctx context.Context, contentID
 	return nil
 }
 
+// chunkMetadataKeys are set per chunk by the chunkers (see chunking.Chunk) and
+// must survive content-level metadata updates.
+var chunkMetadataKeys = []string{"parser", "chunk_index", "total_chunks", "source_heading", "source_tags", "warning", "appended_hash"}
+
+// contentLevelMetadata decodes a metadata patch and drops chunk-specific keys,
+// so applying it to every row of a content cannot clobber per-chunk values.
+func contentLevelMetadata(metadata json.RawMessage) (json.RawMessage, error) {
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(metadata, &patch); err != nil {
+		return nil, fmt.Errorf("metadata must be a JSON object: %w", err)
+	}
+	for _, key := range chunkMetadataKeys {
+		delete(patch, key)
+	}
+	return json.Marshal(patch)
+}
+
+// LastChunkIndex returns the highest chunk_index in the metadata of the content's
+// embeddings, or -1 when the content has none.
+func (vs *StoreImpl) LastChunkIndex(ctx context.Context, contentID int64) (int, error) {
+	query := `SELECT COALESCE(MAX((metadata->>'chunk_index')::int), -1) FROM embeddings WHERE content_id = $1`
+	var last int
+	if err := vs.db.QueryRow(ctx, query, contentID).Scan(&last); err != nil {
+		return 0, fmt.Errorf("get last chunk index for content %d: %w", contentID, err)
+	}
+	return last, nil
+}
+
+// DeleteAppendedChunks deletes the content's embeddings whose "appended_hash"
+// metadata is appendedHash.
+func (vs *StoreImpl) DeleteAppendedChunks(ctx context.Context, contentID int64, appendedHash string) error {
+	query := `DELETE FROM embeddings WHERE content_id = $1 AND metadata->>'appended_hash' = $2`
+	if _, err := vs.db.Exec(ctx, query, contentID, appendedHash); err != nil {
+		return fmt.Errorf("delete appended chunks of content %d: %w", contentID, err)
+	}
+	return nil
+}
+
+// SetTotalChunks sets total_chunks in the metadata of each of the content's embeddings.
+func (vs *StoreImpl) SetTotalChunks(ctx context.Context, contentID int64, total int) error {
+	query := `UPDATE embeddings SET metadata = jsonb_set(COALESCE(metadata, '{}'::jsonb), '{total_chunks}', to_jsonb($2::int))
+		WHERE content_id = $1`
+	if _, err := vs.db.Exec(ctx, query, contentID, total); err != nil {
+		return fmt.Errorf("set total chunks of content %d: %w", contentID, err)
+	}
+	return nil
+}
+
+// StreamEmbeddings reads every embedding row with a single query, handing rows to
+// fn as they arrive.
+func (vs *StoreImpl) StreamEmbeddings(ctx context.Context, fn func(entry *models.EmbeddingEntry) error) error {
+	query := `SELECT id, content_id, chunk_text, vector, metadata, created_at FROM embeddings
+		ORDER BY content_id, COALESCE((metadata->>'chunk_index')::int, 0), created_at`
+	rows, err := vs.db.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("stream embeddings: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := &models.EmbeddingEntry{}
+		if err := rows.Scan(&entry.ID, &entry.ContentID, &entry.ChunkText, &entry.Vector, &entry.Metadata, &entry.CreatedAt); err != nil {
+			return fmt.Errorf("stream embeddings: scan row: %w", err)
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("stream embeddings: %w", err)
+	}
+	return nil
+}
+
+// UpdateEmbeddingMetadataByContentID merges metadata into the metadata of all the
+// content's embedding rows with a single jsonb || update. Keys absent from metadata
+// are kept, and chunk-specific keys in metadata are ignored.
+func (vs *StoreImpl) UpdateEmbeddingMetadataByContentID(ctx context.Context, contentID int64, metadata json.RawMessage) error {
+	patch, err := contentLevelMetadata(metadata)
+	if err != nil {
+		return fmt.Errorf("update embedding metadata for content %d: %w", contentID, err)
+	}
+	query := `UPDATE embeddings SET metadata = COALESCE(metadata, '{}'::jsonb) || $2::jsonb WHERE content_id = $1`
+	if _, err := vs.db.Exec(ctx, query, contentID, patch); err != nil {
+		return fmt.Errorf("update embedding metadata for content %d: %w", contentID, err)
+	}
+	return nil
+}
+
 func (vs *StoreImpl) SimilaritySearch(ctx context.Context, queryVector pgvector.Vector, k int, filterMetadata map[string]interface{}) ([]models.SearchResult, error) {
-	if len(filterMetadata) > 0 {
-		log.Println("WARN: Metadata filtering not yet implemented for pgvector SimilaritySearch")
+	if store.IsZeroVector(queryVector) {
+		return nil, fmt.Errorf("similarity search: %w", store.ErrZeroVector)
+	}
+	args := []interface{}{queryVector, k}
+	var conditions []string
+	for key, value := range filterMetadata {
+		switch key {
+		case store.FilterContentIDs:
+			ids, ok := value.([]int64)
+			if !ok {
+				return nil, fmt.Errorf("similarity search: %s filter must be []int64, got %T", key, value)
+			}
+			if len(ids) == 0 {
+				return []models.SearchResult{}, nil
+			}
+			args = append(args, ids)
+			conditions = append(conditions, fmt.Sprintf("content_id = ANY($%d)", len(args)))
+		case store.FilterTagIDs:
+			ids, ok := value.([]int64)
+			if !ok {
+				return nil, fmt.Errorf("similarity search: %s filter must be []int64, got %T", key, value)
+			}
+			// One containment test per tag keeps the GIN (jsonb_path_ops) index usable.
+			var anyTag []string
+			for _, id := range ids {
+				args = append(args, fmt.Sprintf(`{"%s": [%d]}`, store.FilterTagIDs, id))
+				anyTag = append(anyTag, fmt.Sprintf("metadata @> $%d::jsonb", len(args)))
+			}
+			if len(anyTag) == 0 {
+				return []models.SearchResult{}, nil
+			}
+			conditions = append(conditions, "("+strings.Join(anyTag, " OR ")+")")
+		case store.FilterSourceID:
+			id, ok := value.(int64)
+			if !ok {
+				return nil, fmt.Errorf("similarity search: %s filter must be int64, got %T", key, value)
+			}
+			args = append(args, fmt.Sprintf(`{"%s": %d}`, store.FilterSourceID, id))
+			conditions = append(conditions, fmt.Sprintf("metadata @> $%d::jsonb", len(args)))
+		default:
+			log.Printf("WARN: Metadata filter '%s' not yet implemented for pgvector SimilaritySearch", key)
+		}
+	}
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	// Select chunk_text and metadata explicitly
-	query := `SELECT id, content_id, chunk_text, (vector <-> $1) as score, metadata, created_at
-             FROM embeddings ORDER BY vector <-> $1 LIMIT $2`
+	// Select chunk_text and metadata explicitly. Every operator orders closest first.
+	op := vs.distanceOperator()
+	query := `SELECT id, content_id, chunk_text, (vector ` + op + ` $1) as distance, metadata, created_at
+             FROM embeddings` + whereClause + ` ORDER BY vector ` + op + ` $1 LIMIT $2`
 
-	rows, err := vs.db.Query(ctx, query, queryVector, k)
+	rows, err := vs.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("similarity search query: %w", err)
 	}
@@ -121,15 +350,16 @@ func (vs *StoreImpl) SimilaritySearch(ctx context.Context, queryVector pgvector.
 	var results []models.SearchResult
 	for rows.Next() {
 		var entry models.EmbeddingEntry // Use to scan easily
-		var score float64
+		var distance float64
 		// Scan chunk_text and metadata
-		if err := rows.Scan(&entry.ID, &entry.ContentID, &entry.ChunkText, &score, &entry.Metadata, &entry.CreatedAt); err != nil {
+		if err := rows.Scan(&entry.ID, &entry.ContentID, &entry.ChunkText, &distance, &entry.Metadata, &entry.CreatedAt); err != nil {
 			return nil, fmt.Errorf("scan similarity search row: %w", err)
 		}
 		results = append(results, models.SearchResult{
 			ContentID:      entry.ContentID,
-			RelevanceScore: score,
-			// ChunkText and Metadata are not part of models.SearchResult
+			RelevanceScore: store.Similarity(vs.metric, distance),
+			Distance:       distance,
+			ChunkText:      entry.ChunkText,
 			// Rank needs to be assigned later if needed
 		})
 	}
@@ -0,0 +1,96 @@
+package vector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pgvector/pgvector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mimir/internal/models"
+	"mimir/internal/store"
+)
+
+func TestContentLevelMetadata_DropsChunkKeys(t *testing.T) {
+	patch, err := contentLevelMetadata(json.RawMessage(`{"chunk_index": 9, "parser": "html", "tag_ids": [1, 2], "source_id": 3}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"tag_ids": [1, 2], "source_id": 3}`, string(patch))
+
+	_, err = contentLevelMetadata(json.RawMessage(`[1, 2]`))
+	assert.Error(t, err)
+}
+
+func TestZeroVectorsRejected(t *testing.T) {
+	vs := &StoreImpl{} // The guard runs before any database access.
+	zero := pgvector.NewVector([]float32{0, 0, 0})
+
+	_, err := vs.SimilaritySearch(context.Background(), zero, 5, nil)
+	assert.ErrorIs(t, err, store.ErrZeroVector)
+
+	err = vs.AddEmbedding(context.Background(), &models.EmbeddingEntry{ContentID: 1, ChunkText: " ", Vector: zero})
+	assert.ErrorIs(t, err, store.ErrZeroVector)
+}
+
+func TestSimilaritySearch_EmptyContentIDsSkipsQuery(t *testing.T) {
+	vs := &StoreImpl{} // No database: an empty filter must return before querying.
+	results, err := vs.SimilaritySearch(context.Background(), pgvector.NewVector([]float32{1, 0}), 5,
+		map[string]interface{}{store.FilterContentIDs: []int64{}})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+// TestUpdateEmbeddingMetadataByContentID runs against a real pgvector database
+// when MIMIR_TEST_VECTOR_DSN is set.
+func TestUpdateEmbeddingMetadataByContentID(t *testing.T) {
+	dsn := os.Getenv("MIMIR_TEST_VECTOR_DSN")
+	if dsn == "" {
+		t.Skip("MIMIR_TEST_VECTOR_DSN not set")
+	}
+	ctx := context.Background()
+	vs, err := NewStore(ctx, dsn, "")
+	require.NoError(t, err)
+	impl := vs.(*StoreImpl)
+	defer impl.Close()
+
+	var dim int
+	require.NoError(t, impl.db.QueryRow(ctx, `SELECT atttypmod FROM pg_attribute WHERE attrelid = 'embeddings'::regclass AND attname = 'vector'`).Scan(&dim))
+	if dim <= 0 {
+		dim = 3
+	}
+
+	contentID := int64(-1) - int64(uuid.New().ID()) // Negative IDs never collide with real content
+	defer vs.DeleteEmbeddingsByContentID(ctx, contentID)
+
+	vector := make([]float32, dim)
+	vector[0] = 1 // AddEmbedding rejects zero vectors
+	for i := 0; i < 2; i++ {
+		require.NoError(t, vs.AddEmbedding(ctx, &models.EmbeddingEntry{
+			ContentID: contentID,
+			ChunkText: "chunk",
+			Vector:    pgvector.NewVector(vector),
+			Metadata:  json.RawMessage(fmt.Sprintf(`{"chunk_index": %d, "parser": "fallback", "tag_ids": [1]}`, i)),
+		}))
+	}
+
+	require.NoError(t, vs.UpdateEmbeddingMetadataByContentID(ctx, contentID, json.RawMessage(`{"tag_ids": [2, 3], "chunk_index": 99}`)))
+
+	rows, err := impl.db.Query(ctx, `SELECT metadata FROM embeddings WHERE content_id = $1 ORDER BY metadata->>'chunk_index'`, contentID)
+	require.NoError(t, err)
+	defer rows.Close()
+	i := 0
+	for rows.Next() {
+		var meta map[string]interface{}
+		require.NoError(t, rows.Scan(&meta))
+		assert.Equal(t, float64(i), meta["chunk_index"], "chunk_index must be preserved")
+		assert.Equal(t, "fallback", meta["parser"])
+		assert.Equal(t, []interface{}{float64(2), float64(3)}, meta["tag_ids"])
+		i++
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, 2, i)
+}
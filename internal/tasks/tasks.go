@@ -7,6 +7,13 @@ const (
 	TypeEmbeddingJob = "embedding:generate" // Task to initiate batch job
 	// TypeEmbeddingCheckBatch is the task type for checking batch status.
 	TypeEmbeddingCheckBatch = "embedding:check_batch" // Task to check batch status
+	// TypeEmbeddingMetadataUpdateJob rewrites a content item's denormalized embedding metadata after its tags change.
+	TypeEmbeddingMetadataUpdateJob = "embedding:update_metadata"
+	// TypeEmbeddingAppendJob embeds only the text appended to already-embedded content.
+	TypeEmbeddingAppendJob = "embedding:append"
+	// TypeBatchEmbeddingJob embeds several content items with shared embedding API
+	// calls. Unrelated to the provider Batch API checked by TypeEmbeddingCheckBatch.
+	TypeBatchEmbeddingJob = "embedding:generate_batch"
 
 	// TypeSummarizationJob is the task type for generating content summaries.
 	TypeSummarizationJob = "summarization:generate"
@@ -0,0 +1,3 @@
+-- Remove the is_pinned column from the content table
+DROP INDEX IF EXISTS idx_content_is_pinned;
+ALTER TABLE content DROP COLUMN is_pinned;
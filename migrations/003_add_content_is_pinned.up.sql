@@ -0,0 +1,6 @@
+-- Add the is_pinned column to the content table
+ALTER TABLE content ADD COLUMN is_pinned BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN content.is_pinned IS 'Whether the user pinned (favorited) this content';
+
+CREATE INDEX IF NOT EXISTS idx_content_is_pinned ON content (is_pinned) WHERE is_pinned;
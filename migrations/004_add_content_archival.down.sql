@@ -0,0 +1,3 @@
+-- Remove the archived_at column from the content table.
+-- last_accessed_at may predate this migration, so it is kept.
+ALTER TABLE content DROP COLUMN archived_at;
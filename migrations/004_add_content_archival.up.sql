@@ -0,0 +1,6 @@
+-- Track search access and archival so compaction can drop embeddings of rarely used content
+ALTER TABLE content ADD COLUMN IF NOT EXISTS last_accessed_at TIMESTAMP NULL;
+ALTER TABLE content ADD COLUMN archived_at TIMESTAMP NULL;
+
+COMMENT ON COLUMN content.last_accessed_at IS 'Last time the content was returned by a search';
+COMMENT ON COLUMN content.archived_at IS 'When compaction removed the content''s embeddings; content stays keyword-searchable';
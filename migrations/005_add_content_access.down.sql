@@ -0,0 +1,2 @@
+-- Drop the content_access table
+DROP TABLE IF EXISTS content_access;
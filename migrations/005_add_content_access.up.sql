@@ -0,0 +1,11 @@
+-- Record content views for "recently viewed" and popularity listings
+CREATE TABLE IF NOT EXISTS content_access (
+    id BIGSERIAL PRIMARY KEY,
+    content_id BIGINT NOT NULL REFERENCES content(id) ON DELETE CASCADE,
+    accessed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE content_access IS 'One row per view of a content item';
+
+CREATE INDEX IF NOT EXISTS idx_content_access_content_id_accessed_at ON content_access (content_id, accessed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_content_access_accessed_at ON content_access (accessed_at DESC);
@@ -0,0 +1,2 @@
+-- Drop the worker_heartbeats table
+DROP TABLE IF EXISTS worker_heartbeats;
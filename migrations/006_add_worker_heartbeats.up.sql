@@ -0,0 +1,13 @@
+-- Track worker liveness: each worker upserts its row on a ticker
+CREATE TABLE IF NOT EXISTS worker_heartbeats (
+    worker_id TEXT PRIMARY KEY,
+    hostname TEXT NOT NULL,
+    pid INTEGER NOT NULL,
+    in_flight INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL
+);
+
+COMMENT ON COLUMN worker_heartbeats.in_flight IS 'Tasks the worker was processing at its last heartbeat';
+
+CREATE INDEX IF NOT EXISTS idx_worker_heartbeats_last_seen_at ON worker_heartbeats (last_seen_at DESC);
@@ -0,0 +1,3 @@
+-- Drop the dead job index and last_error column
+DROP INDEX IF EXISTS idx_background_jobs_dead;
+ALTER TABLE background_jobs DROP COLUMN IF EXISTS last_error;
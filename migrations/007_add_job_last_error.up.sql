@@ -0,0 +1,6 @@
+-- Keep the final error of jobs that exhausted their retries ("dead" jobs)
+ALTER TABLE background_jobs ADD COLUMN IF NOT EXISTS last_error TEXT;
+
+COMMENT ON COLUMN background_jobs.last_error IS 'Error returned by the last attempt of a dead job';
+
+CREATE INDEX IF NOT EXISTS idx_background_jobs_dead ON background_jobs (updated_at DESC) WHERE status = 'dead';
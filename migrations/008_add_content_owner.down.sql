@@ -0,0 +1,5 @@
+-- Drop content ownership and visibility
+DROP INDEX IF EXISTS idx_content_shared;
+DROP INDEX IF EXISTS idx_content_owner_id;
+ALTER TABLE content DROP COLUMN IF EXISTS visibility;
+ALTER TABLE content DROP COLUMN IF EXISTS owner_id;
@@ -0,0 +1,11 @@
+-- Content ownership and visibility, the basis for multi-user deployments.
+-- Existing content belongs to the single "public" owner used when auth is disabled.
+ALTER TABLE content ADD COLUMN IF NOT EXISTS owner_id TEXT NOT NULL DEFAULT 'public';
+ALTER TABLE content ADD COLUMN IF NOT EXISTS visibility TEXT NOT NULL DEFAULT 'private'
+    CHECK (visibility IN ('private', 'shared'));
+
+COMMENT ON COLUMN content.owner_id IS 'Identity of the user or API key that added the content';
+COMMENT ON COLUMN content.visibility IS 'private: owner only; shared: all owners';
+
+CREATE INDEX IF NOT EXISTS idx_content_owner_id ON content (owner_id);
+CREATE INDEX IF NOT EXISTS idx_content_shared ON content (id) WHERE visibility = 'shared';
@@ -0,0 +1,17 @@
+-- Drop per-owner isolation. Fails if two owners have collections or tags with the same name,
+-- or content with the same hash.
+ALTER TABLE content DROP CONSTRAINT IF EXISTS content_owner_hash_key;
+ALTER TABLE content ADD CONSTRAINT content_content_hash_key UNIQUE (content_hash);
+DROP INDEX IF EXISTS idx_ai_usage_logs_owner_id;
+DROP INDEX IF EXISTS idx_search_queries_owner_id;
+DROP INDEX IF EXISTS idx_tags_owner_slug;
+DROP INDEX IF EXISTS idx_tags_owner_name;
+DROP INDEX IF EXISTS idx_collections_owner_name;
+ALTER TABLE tags ADD CONSTRAINT tags_slug_key UNIQUE (slug);
+ALTER TABLE tags ADD CONSTRAINT tags_name_key UNIQUE (name);
+ALTER TABLE collections ADD CONSTRAINT collections_name_key UNIQUE (name);
+
+ALTER TABLE ai_usage_logs DROP COLUMN IF EXISTS owner_id;
+ALTER TABLE search_queries DROP COLUMN IF EXISTS owner_id;
+ALTER TABLE tags DROP COLUMN IF EXISTS owner_id;
+ALTER TABLE collections DROP COLUMN IF EXISTS owner_id;
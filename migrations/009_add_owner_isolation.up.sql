@@ -0,0 +1,23 @@
+-- Per-owner isolation of collections, tags, search history and AI usage (multi_tenant mode).
+-- Existing rows belong to the single "public" owner used when multi-tenancy is disabled.
+ALTER TABLE collections ADD COLUMN IF NOT EXISTS owner_id TEXT NOT NULL DEFAULT 'public';
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS owner_id TEXT NOT NULL DEFAULT 'public';
+ALTER TABLE search_queries ADD COLUMN IF NOT EXISTS owner_id TEXT NOT NULL DEFAULT 'public';
+ALTER TABLE ai_usage_logs ADD COLUMN IF NOT EXISTS owner_id TEXT NOT NULL DEFAULT 'public';
+
+COMMENT ON COLUMN ai_usage_logs.owner_id IS 'Owner billed for the call: the owner of the related content, else the caller';
+
+-- Names only need to be unique within an owner
+ALTER TABLE collections DROP CONSTRAINT IF EXISTS collections_name_key;
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS tags_name_key;
+ALTER TABLE tags DROP CONSTRAINT IF EXISTS tags_slug_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_owner_name ON collections (owner_id, name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_owner_name ON tags (owner_id, name);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_owner_slug ON tags (owner_id, slug);
+
+-- Content is deduplicated per owner, so owners can store the same text
+ALTER TABLE content DROP CONSTRAINT IF EXISTS content_content_hash_key;
+ALTER TABLE content ADD CONSTRAINT content_owner_hash_key UNIQUE (owner_id, content_hash);
+
+CREATE INDEX IF NOT EXISTS idx_search_queries_owner_id ON search_queries (owner_id, executed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ai_usage_logs_owner_id ON ai_usage_logs (owner_id, timestamp);
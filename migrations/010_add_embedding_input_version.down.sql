@@ -0,0 +1,2 @@
+-- Drop tracking of the embedding input template version
+ALTER TABLE content DROP COLUMN IF EXISTS embedding_input_version;
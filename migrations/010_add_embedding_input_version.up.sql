@@ -0,0 +1,6 @@
+-- Version of embedding.input_template the content's embeddings were built from,
+-- so a template change can be detected and the content re-embedded.
+-- NULL means the default body-only template (or content embedded before tracking).
+ALTER TABLE content ADD COLUMN IF NOT EXISTS embedding_input_version TEXT;
+
+COMMENT ON COLUMN content.embedding_input_version IS 'Version of embedding.input_template used for the current embeddings';
@@ -0,0 +1,5 @@
+-- Drop accent-insensitive keyword search support
+DROP INDEX IF EXISTS idx_content_fts_body_unaccent;
+DROP INDEX IF EXISTS idx_content_fts_title_unaccent;
+DROP FUNCTION IF EXISTS mimir_unaccent(text);
+DROP EXTENSION IF EXISTS unaccent;
@@ -0,0 +1,14 @@
+-- Accent-insensitive keyword search (database.keyword_unaccent).
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- unaccent() is only STABLE (it depends on the dictionary search path), so it
+-- cannot appear in an index expression. Pinning the dictionary makes this
+-- wrapper safe to declare IMMUTABLE.
+CREATE OR REPLACE FUNCTION mimir_unaccent(text) RETURNS text
+    LANGUAGE sql IMMUTABLE PARALLEL SAFE STRICT
+    AS $$ SELECT public.unaccent('public.unaccent'::regdictionary, $1) $$;
+
+-- Full-text indexes over the unaccented title and body, matching the
+-- expressions keyword search uses when keyword_unaccent is enabled.
+CREATE INDEX IF NOT EXISTS idx_content_fts_title_unaccent ON content USING GIN (to_tsvector('english', mimir_unaccent(title)));
+CREATE INDEX IF NOT EXISTS idx_content_fts_body_unaccent ON content USING GIN (to_tsvector('english', mimir_unaccent(body)));
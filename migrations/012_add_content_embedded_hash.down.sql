@@ -0,0 +1,2 @@
+-- Drop tracking of the embedded body version
+ALTER TABLE content DROP COLUMN IF EXISTS embedded_hash;
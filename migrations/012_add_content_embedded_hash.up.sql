@@ -0,0 +1,6 @@
+-- content_hash of the body version the content's embeddings were built from,
+-- so embeddings made stale by a later edit can be detected and re-embedded.
+-- NULL means not embedded, or embedded before the hash was tracked.
+ALTER TABLE content ADD COLUMN IF NOT EXISTS embedded_hash TEXT;
+
+COMMENT ON COLUMN content.embedded_hash IS 'content_hash of the body version the current embeddings were built from';
@@ -0,0 +1,2 @@
+-- Drop reindex run progress tracking
+DROP TABLE IF EXISTS reindex_runs;
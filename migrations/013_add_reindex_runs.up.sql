@@ -0,0 +1,11 @@
+-- Progress of bulk re-embedding runs (mimir reindex): each embedding job carrying
+-- the run's ID bumps completed or failed; the run finishes once all have reported.
+CREATE TABLE IF NOT EXISTS reindex_runs (
+    id BIGSERIAL PRIMARY KEY,
+    total INTEGER NOT NULL,
+    completed INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'running', -- 'running' or 'finished'
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+-- Index embedding metadata so SimilaritySearch can filter on the denormalized
+-- source_id and tag_ids fields with jsonb containment (@>).
+CREATE INDEX IF NOT EXISTS idx_embeddings_metadata ON embeddings USING gin (metadata jsonb_path_ops);